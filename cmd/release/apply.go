@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/fernferret/release/pkg/release/notify"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func applyMain(args []string) {
+	var remote, user, email, sshKeyPath, overrideFreeze, overrideDCO, overrideScan, acks string
+	var verbose, doPush, dryRun, noNotify, ackBreaking, ackLint, ackLeadTime, allowSkew, requireBranch, allowDetached bool
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.BoolVar(&doPush, "push", false, "push all tags in a single push, nothing is kept if it fails")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.BoolVar(&noNotify, "no-notify", false, "don't send the notifications configured in .release.yaml after pushing")
+	fs.StringVar(&overrideFreeze, "override-freeze", "", "release during a freeze window, the reason given is recorded in the tag annotations")
+	fs.BoolVar(&ackLint, "acknowledge-lint", false, "release even though commit messages since the last releases break the lint rules")
+	fs.StringVar(&overrideDCO, "override-dco", "", "release commits that aren't signed off by their author, the reason given is recorded in the tag annotations")
+	fs.StringVar(&overrideScan, "override-scan", "", "release despite scanner findings that block it, the reason given is recorded in the tag annotations")
+	fs.BoolVar(&ackBreaking, "acknowledge-breaking", false, "release even though commits since the last releases are marked as breaking changes")
+	fs.BoolVar(&ackLeadTime, "acknowledge-lead-time", false, "release right before a freeze window or outside business hours without being asked")
+	fs.BoolVar(&allowSkew, "allow-clock-skew", false, "apply the plan even though existing releases are dated after the local clock and clock_skew.refuse is set")
+	fs.StringVar(&acks, "ack", "", "comma separated checklist items to acknowledge instead of being asked, see checklist in .release.yaml")
+	fs.BoolVar(&requireBranch, "require-branch", false, "refuse to release when HEAD is detached (also require_branch: true in .release.yaml)")
+	fs.BoolVar(&allowDetached, "allow-detached", false, "release from a detached HEAD even with --require-branch")
+	fs.BoolVarP(&dryRun, "dry-run", "n", false, "check the plan and show what would be released")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release apply <plan.yaml> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if doPush && release.Offline() {
+		log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+	}
+
+	plan, err := release.LoadPlan(fs.Arg(0))
+	release.CheckIfError(err, fmt.Sprintf("failed to load plan %s", fs.Arg(0)))
+	user, email = gitIdentity(user, email)
+	rm := openManager("", sshKeyPath)
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.LoadOwnership(repoCfg.Components), "failed to load CODEOWNERS")
+	setupOIDC(rm, repoCfg)
+	rm.AlwaysIncludeNumber = true
+	rm.Scheme = repoCfg.Scheme
+	release.CheckIfError(checkTrust(rm, repoCfg.Trust), "refusing to apply the plan")
+
+	opts := release.ApplyOptions{
+		User:       user,
+		Email:      email,
+		Now:        repoCfg.Train.Date(time.Now()),
+		Increments: repoCfg.Increments,
+		Components: repoCfg.Components,
+	}
+	components := []string{}
+	newReleases := []string{}
+	targets := map[string]string{}
+	for _, p := range plan.Releases {
+		proposal, err := rm.GetProposedReleaseAt(p.Component, opts.Now, repoCfg.Increments.ResetPolicy(p.Component))
+		release.CheckIfError(err, fmt.Sprintf("failed to propose a release for %s", p.Component))
+		if p.Tag != "" && p.Tag != proposal.TagName {
+			log.Fatal().Msgf("the plan releases %s but the next release of %s is %s now, the plan is stale", p.Tag, p.Component, proposal.TagName)
+		}
+		components = append(components, p.Component)
+		newReleases = append(newReleases, proposal.TagName)
+		targets[proposal.TagName] = p.Target
+	}
+	gates := checkGates(rm, repoCfg, components, newReleases, gateOptions{
+		by:             releasedBy(user, email),
+		overrideFreeze: overrideFreeze,
+		overrideDCO:    overrideDCO,
+		ackBreaking:    ackBreaking,
+		ackLint:        ackLint,
+		ackLeadTime:    ackLeadTime,
+		allowSkew:      allowSkew,
+		acks:           strings.Split(acks, ","),
+		targets:        targets,
+		requireBranch:  requireBranch,
+		allowDetached:  allowDetached,
+		dryRun:         dryRun,
+	})
+	for idx, p := range plan.Releases {
+		plan.Releases[idx].Message = gates.annotate(p.Message, p.Component, newReleases[idx])
+	}
+	if repoCfg.Annotate {
+		opts.Kind = release.TagAnnotated
+	}
+	// Components without --remote push to their remote in the config, all
+	// tags go in a single push so they have to agree on one
+	remotes := []string{remote}
+	if !fs.Changed("remote") {
+		remotes = planRemotes(repoCfg, components)
+		remote = remotes[0]
+	}
+	if doPush && len(remotes) > 1 {
+		log.Fatal().Msgf("the releases of the plan push to different remotes (%s) but are pushed at once, pick one with --remote or push them yourself", strings.Join(remotes, ", "))
+	}
+	if doPush {
+		release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remote))
+		opts.Remote = remote
+		opts.Auth = authForRemote(rm, remote, sshKeyPath)
+	}
+
+	if dryRun {
+		t := newTable("COMPONENT", "TAG", "TARGET", "CHANNEL").color(1, colorCyan).color(2, colorYellow)
+		for idx, p := range plan.Releases {
+			target := p.Target
+			if target == "" {
+				target = "HEAD"
+			}
+			t.row(p.Component, newReleases[idx], target, p.Channel)
+			if c := repoCfg.Components[p.Component]; c.SemVer != nil {
+				hash, err := rm.ResolveCommit(target)
+				release.CheckIfError(err, fmt.Sprintf("failed to resolve target %s of %s", target, p.Component))
+				semverTag, err := rm.NextSemVerTag(c, hash)
+				release.CheckIfError(err, fmt.Sprintf("failed to work out the SemVer tag of %s", p.Component))
+				t.row(p.Component, semverTag, target, p.Channel)
+			}
+		}
+		say("would apply:")
+		t.render(os.Stdout)
+		return
+	}
+
+	// Builds, hooks and scanners run before any tag is created so a failure
+	// keeps the whole plan from being applied
+	by := releasedBy(user, email)
+	steps, failed := runPlanSteps(rm, repoCfg, plan, newReleases, overrideScan, by)
+	for _, newRelease := range newReleases {
+		if err, ok := failed[newRelease]; ok {
+			logError(err, fmt.Sprintf("not releasing %s", newRelease))
+		}
+	}
+	if len(failed) > 0 {
+		log.Fatal().Msg("failed to apply the plan, no tags were created")
+	}
+
+	applied, err := rm.ApplyPlan(plan, opts)
+	if doPush {
+		settleCredentials(err)
+	}
+	release.CheckIfError(err, "failed to apply the plan, no tags were kept")
+	t := newTable("COMPONENT", "TAG", "COMMIT").color(1, colorGreen).color(2, colorYellow)
+	for _, a := range applied {
+		t.row(a.Component, a.Tag, a.Target[:8])
+		if a.SemVerTag != "" {
+			t.row(a.Component, a.SemVerTag, a.Target[:8])
+		}
+	}
+	if doPush {
+		say(fmt.Sprintf("created and pushed %d release(s) to %s:", len(applied), remote), "count", len(applied), "remote", remote)
+	} else if len(remotes) > 1 {
+		say(fmt.Sprintf("created %d release(s), push them to their remotes %s with 'git push <remote> <tag>':", len(applied), strings.Join(remotes, ", ")), "count", len(applied), "remote", strings.Join(remotes, ","))
+	} else {
+		say(fmt.Sprintf("created %d release(s), push them with 'git push %s --tags' or use --push:", len(applied), remote), "count", len(applied), "remote", remote)
+	}
+	t.render(os.Stdout)
+
+	for _, a := range applied {
+		floating := release.FloatingTagsFor(repoCfg.Floating, rm.ParseRelease(a.Tag), a.Message)
+		release.CheckIfError(rm.MoveFloatingTags(floating, a.Tag), fmt.Sprintf("failed to move the floating tags of %s", a.Tag))
+		if len(floating) == 0 {
+			continue
+		}
+		say(fmt.Sprintf("moved floating tags: %s", strings.Join(floating, ", ")), "tags", strings.Join(floating, ","), "release", a.Tag)
+		if doPush {
+			release.CheckIfError(rm.PushFloatingTags(floating, remote, opts.Auth), fmt.Sprintf("failed to push the floating tags %s", strings.Join(floating, ", ")))
+		}
+	}
+
+	if doPush && !noNotify {
+		notifyApplied(rm, repoCfg, applied, remote, by)
+	}
+	if steps.finish(rm, applied, user, email, by) {
+		log.Fatal().Msg("at least one post-release hook failed, see above")
+	}
+}
+
+// planRemotes returns the remotes the components of a plan push to, their
+// remote in the config or origin, in the order they're first used
+func planRemotes(cfg *release.Config, components []string) []string {
+	remotes := []string{}
+	for _, c := range components {
+		remote := cfg.ForComponent(c).Remote
+		if remote == "" {
+			remote = "origin"
+		}
+		if !containsString(remotes, remote) {
+			remotes = append(remotes, remote)
+		}
+	}
+	if len(remotes) == 0 {
+		return []string{"origin"}
+	}
+	return remotes
+}
+
+// notifyApplied sends the release notifications for every applied release
+func notifyApplied(rm *release.Manager, repoCfg *release.Config, applied []*release.AppliedRelease, remote, by string) {
+	notifiers, err := notify.FromConfig(repoCfg.Notify)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to set up notifications, none were sent")
+		return
+	}
+	for _, a := range applied {
+		if notify.SendAll(notifiers, rm.ReleaseEvent(a.Tag, remote, by, a.Message)) > 0 {
+			log.Warn().Msgf("tag %s was pushed but some notifications failed, see above", a.Tag)
+		}
+	}
+}
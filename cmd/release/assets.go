@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func checksumsMain(args []string) {
+	var user, email string
+	var verbose, commit bool
+	fs := flag.NewFlagSet("checksums", flag.ExitOnError)
+	fs.BoolVar(&commit, "commit", false, "commit the checksum file (and signature)")
+	fs.StringVar(&user, "user", "", "committer name for --commit")
+	fs.StringVar(&email, "email", "", "committer email for --commit")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release checksums [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	cfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	if len(cfg.Assets.Paths) == 0 {
+		fmt.Fprintf(os.Stderr, "no asset paths configured in %s\n", release.ConfigFile)
+		os.Exit(2)
+	}
+
+	// Everything in the config is relative to the repository root
+	release.CheckIfError(os.Chdir(rm.RepoDir()), "failed to change to the repository root")
+	output := cfg.Assets.OutputFile()
+	sums, err := release.Checksums(cfg.Assets.Paths, filepath.Dir(output))
+	release.CheckIfError(err, "failed to checksum assets")
+	release.CheckIfError(release.WriteChecksums(output, sums), fmt.Sprintf("failed to write %s", output))
+	say(fmt.Sprintf("wrote %d checksum(s) to %s", len(sums), output), "count", len(sums), "file", output)
+	files := []string{output}
+	if cfg.Assets.Sign != "" {
+		release.CheckIfError(release.SignChecksums(cfg.Assets), fmt.Sprintf("failed to sign %s", output))
+		say(fmt.Sprintf("signed %s with %s", output, cfg.Assets.Sign), "file", output, "signer", cfg.Assets.Sign)
+		files = append(files, cfg.Assets.SignatureFile())
+	}
+	if commit {
+		err := rm.CommitFiles(files, fmt.Sprintf("Update %s", filepath.Base(output)), user, email)
+		release.CheckIfError(err, "failed to commit checksums")
+		say(fmt.Sprintf("committed %s", output), "file", output)
+	}
+}
+
+func verifyAssetsMain(args []string) {
+	var file, dir, sign, key string
+	var verbose bool
+	fs := flag.NewFlagSet("verify-assets", flag.ExitOnError)
+	fs.StringVarP(&file, "file", "f", release.DefaultChecksumFile, "checksum file to verify")
+	fs.StringVar(&dir, "dir", "", "directory the assets are in, defaults to the checksum file's")
+	fs.StringVar(&sign, "sign", "", "also verify the detached signature (gpg or cosign)")
+	fs.StringVar(&key, "key", "", "public key for cosign signatures")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release verify-assets [-f SHA256SUMS] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	if dir == "" {
+		dir = filepath.Dir(file)
+	}
+	if sign != "" {
+		signature := file + ".asc"
+		if sign == "cosign" {
+			signature = file + ".sig"
+		}
+		release.CheckIfError(release.VerifySignature(sign, key, file, signature), fmt.Sprintf("bad signature for %s", file))
+		say(fmt.Sprintf("signature of %s is valid", file), "file", file, "status", "valid")
+	}
+	sums, err := release.ReadChecksums(file)
+	release.CheckIfError(err, fmt.Sprintf("failed to read %s", file))
+	mismatches := release.VerifyChecksums(sums, dir)
+	for _, m := range mismatches {
+		if m.Err != nil {
+			say(fmt.Sprintf("FAILED %s: %s", m.Path, m.Err), "status", "failed", "path", m.Path, "error", m.Err)
+			continue
+		}
+		say(fmt.Sprintf("FAILED %s: expected %s, got %s", m.Path, m.Expected, m.Actual), "status", "failed", "path", m.Path, "expected", m.Expected, "actual", m.Actual)
+	}
+	if len(mismatches) > 0 {
+		os.Exit(1)
+	}
+	say(fmt.Sprintf("all %d asset(s) match %s", len(sums), file), "status", "ok", "count", len(sums), "file", file)
+}
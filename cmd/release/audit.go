@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func auditMain(args []string) {
+	var tag string
+	var asJSON, verbose bool
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	fs.StringVarP(&tag, "tag", "t", "", "only show events about this tag")
+	fs.BoolVar(&asJSON, "json", false, "print the events as json")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release audit [--tag <tag>] [--json]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	useMetadata(rm)
+	all, err := rm.AuditEvents()
+	release.CheckIfError(err, fmt.Sprintf("failed to load the audit log from %s", rm.MetadataStore()))
+	events := []release.AuditEvent{}
+	for _, e := range all {
+		if tag == "" || e.Tag == tag {
+			events = append(events, e)
+		}
+	}
+	if asJSON {
+		writeJSON(events)
+		return
+	}
+	if len(events) == 0 {
+		say("nothing in the audit log yet")
+		return
+	}
+	t := newTable("DATE", "ACTION", "TAG", "ENV", "BY", "DETAIL").color(1, colorCyan).color(2, colorGreen)
+	for _, e := range events {
+		t.row(e.Date.Local().Format("2006-01-02 15:04"), e.Action, e.Tag, e.Env, e.By, e.Detail)
+	}
+	t.render(os.Stdout)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/internal/keyring"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// forgeHosts are the names 'auth login' accepts for the public forges
+var forgeHosts = map[string]string{
+	"github": "github.com",
+	"gitlab": "gitlab.com",
+}
+
+func authUsage() {
+	fmt.Fprintf(os.Stderr, "usage: release auth login <forge|host|NAME> [options]\n")
+	fmt.Fprintf(os.Stderr, "       release auth logout <forge|host|NAME> [options]\n")
+}
+
+func authMain(args []string) {
+	if len(args) == 0 {
+		authUsage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "login":
+		authLoginMain(args[1:])
+	case "logout":
+		authLogoutMain(args[1:])
+	default:
+		authUsage()
+		os.Exit(2)
+	}
+}
+
+// secretName maps github and gitlab to their hosts, anything else (a host or
+// the name of a *_env variable) is used as is
+func secretName(arg string) string {
+	if host, ok := forgeHosts[strings.ToLower(arg)]; ok {
+		return host
+	}
+	return arg
+}
+
+// readSecret asks for the secret on a terminal without echoing it, or reads
+// the first line of stdin when it's piped
+func readSecret(name string) (string, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprint(os.Stderr, i18n.T("token for %s: ", name))
+	secret, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return strings.TrimSpace(string(secret)), err
+}
+
+func authLoginMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("auth login", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		authUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := secretName(fs.Arg(0))
+	secret, err := readSecret(name)
+	release.CheckIfError(err, "failed to read the token")
+	if secret == "" {
+		log.Fatal().Msg("the token can't be empty")
+	}
+	release.CheckIfError(keyring.Set(name, secret), fmt.Sprintf("failed to store the token in the %s", keyring.Backend()))
+	log.Info().Msgf("stored the token for %s in the %s", name, keyring.Backend())
+}
+
+func authLogoutMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("auth logout", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		authUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := secretName(fs.Arg(0))
+	err := keyring.Delete(name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		log.Fatal().Msgf("the %s has no token for %s", keyring.Backend(), name)
+	}
+	release.CheckIfError(err, fmt.Sprintf("failed to remove the token from the %s", keyring.Backend()))
+	log.Info().Msgf("removed the token for %s from the %s", name, keyring.Backend())
+}
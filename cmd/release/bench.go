@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/fernferret/release/pkg/release/releasetest"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// perfBudget is how long each benchmark may take per 10k tags, it scales
+// linearly with the tag count but never goes below minBudget. Keep in sync
+// with the README.
+var perfBudget = map[string]time.Duration{
+	"load":      300 * time.Millisecond,
+	"increment": 250 * time.Millisecond,
+	"sort":      25 * time.Millisecond,
+}
+
+// minBudget keeps small repositories from failing on timer noise
+const minBudget = 20 * time.Millisecond
+
+var benchNames = []string{"load", "increment", "sort"}
+
+type benchResult struct {
+	Name     string        `json:"name"`
+	Tags     int           `json:"tags"`
+	Duration time.Duration `json:"duration_ns"`
+	Allocs   uint64        `json:"allocs"`
+	Budget   time.Duration `json:"budget_ns"`
+}
+
+func (b benchResult) overBudget() bool {
+	return b.Duration > b.Budget
+}
+
+// benchMain times the core paths on synthetic in-memory repositories. It's
+// hidden from the usage, it's for working on release itself.
+func benchMain(args []string) {
+	var sizes string
+	var runs int
+	var asJSON, verbose bool
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.StringVar(&sizes, "tags", "1000,10000,100000", "comma separated tag counts of the synthetic repositories")
+	fs.IntVar(&runs, "runs", 3, "runs per benchmark, the median is reported")
+	fs.BoolVar(&asJSON, "json", false, "print the results as json")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if runs < 1 {
+		log.Fatal().Msg("--runs must be at least 1")
+	}
+
+	results := []benchResult{}
+	for _, size := range strings.Split(sizes, ",") {
+		count, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil || count < 1 {
+			log.Fatal().Msgf("invalid tag count '%s'", size)
+		}
+		fmt.Fprintf(os.Stderr, "building a repository with %d tags\n", count)
+		repo, err := releasetest.NewMemoryRepo()
+		release.CheckIfError(err, "failed to create the repository")
+		release.CheckIfError(repo.SyntheticTags(count), "failed to create the tags")
+		for _, name := range benchNames {
+			durations := make([]time.Duration, runs)
+			var allocs uint64
+			for i := range durations {
+				durations[i], allocs = runBench(name, repo)
+			}
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			budget := perfBudget[name] * time.Duration(count) / 10000
+			if budget < minBudget {
+				budget = minBudget
+			}
+			results = append(results, benchResult{Name: name, Tags: count, Duration: durations[runs/2], Allocs: allocs, Budget: budget})
+		}
+	}
+
+	failed := false
+	if asJSON {
+		writeJSON(results)
+	}
+	t := newTable("BENCHMARK", "TAGS", "TIME", "ALLOCS", "BUDGET").color(0, colorCyan)
+	for _, r := range results {
+		budget := r.Budget.String()
+		if r.overBudget() {
+			budget = colorize(colorRed, budget+" (over)")
+			failed = true
+		}
+		t.row(r.Name, strconv.Itoa(r.Tags), r.Duration.Round(time.Microsecond).String(), strconv.FormatUint(r.Allocs, 10), budget)
+	}
+	if !asJSON {
+		t.render(os.Stdout)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runBench times one run of a benchmark and counts its allocations, the
+// manager it needs is set up outside the timing
+func runBench(name string, repo *releasetest.Repo) (time.Duration, uint64) {
+	var rm *release.Manager
+	if name != "load" {
+		var err error
+		rm, err = release.NewManagerFromRepo(repo.Repo, dateFormat, incrementFormat)
+		release.CheckIfError(err, "failed to load the tags")
+	}
+	var releases []release.Release
+	if name == "sort" {
+		releases = rm.Releases()
+		rand.New(rand.NewSource(1)).Shuffle(len(releases), func(i, j int) {
+			releases[i], releases[j] = releases[j], releases[i]
+		})
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	switch name {
+	case "load":
+		_, err := release.NewManagerFromRepo(repo.Repo, dateFormat, incrementFormat)
+		release.CheckIfError(err, "failed to load the tags")
+	case "increment":
+		for _, component := range releasetest.SyntheticComponents {
+			_, err := rm.GetProposedRelease(component)
+			release.CheckIfError(err, fmt.Sprintf("failed to propose a release of %s", component))
+		}
+	case "sort":
+		release.SortReleases(releases)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return elapsed, after.Mallocs - before.Mallocs
+}
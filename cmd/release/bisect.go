@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func bisectMain(args []string) {
+	var bad, good, run string
+	var allPaths, verbose bool
+	fs := flag.NewFlagSet("bisect", flag.ExitOnError)
+	fs.StringVar(&bad, "bad", "", "the first release known to be broken")
+	fs.StringVar(&good, "good", "", "the last release known to work, the release before --bad by default")
+	fs.StringVar(&run, "run", "", "shell command that exits 0 on good commits, runs the bisect to the end")
+	fs.BoolVar(&allPaths, "all-paths", false, "bisect every commit, not only those touching the component's paths")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release bisect --bad <tag> [--good <tag>] [--run <command>]\n\n")
+		fmt.Fprintf(os.Stderr, "Starts git bisect between two releases of a component.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if bad == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	opts := release.BisectOptions{Bad: bad, Good: good, Run: run}
+	if !allPaths {
+		opts.Paths = repoCfg.Components[rm.ParseRelease(bad).Component()].Paths
+	}
+	result, err := rm.Bisect(opts, os.Stderr)
+	release.CheckIfError(err, fmt.Sprintf("failed to bisect %s", bad))
+	if result == nil {
+		log.Info().Msg("mark commits with 'git bisect good' or 'git bisect bad', finish with 'git bisect reset'")
+		return
+	}
+	say(fmt.Sprintf("first bad commit: %s %s", result.Hash[:8], result.Subject), "commit", result.Hash, "subject", result.Subject)
+	say(fmt.Sprintf("first released in: %s", result.Release), "release", result.Release)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/fernferret/release/pkg/release/apidiff"
+	"github.com/rs/zerolog/log"
+)
+
+// checkBreaking warns about breaking changes in each of the new releases and
+// returns which releases have them. Unless acknowledged the user is asked to
+// confirm if they can be asked, otherwise the release is refused. Releases
+// that couldn't be checked need the same confirmation. targets are the
+// revisions the releases tag, HEAD if missing.
+func checkBreaking(rm *release.Manager, cfg *release.Config, newReleases []string, targets map[string]string, acknowledged, ask bool) (map[string]bool, error) {
+	breaking := map[string]bool{}
+	unchecked := 0
+	for _, newRelease := range newReleases {
+		isBreaking, err := findBreaking(rm, cfg, newRelease, targets[newRelease])
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to check %s for breaking changes, treating it as breaking", newRelease))
+			unchecked++
+			continue
+		}
+		if isBreaking {
+			breaking[newRelease] = true
+		}
+	}
+	if (len(breaking) == 0 && unchecked == 0) || acknowledged {
+		return breaking, nil
+	}
+	if !ask {
+		return nil, fmt.Errorf("refusing to release breaking (or unchecked) changes, use --acknowledge-breaking")
+	}
+	fmt.Fprint(os.Stderr, i18n.T("release anyway? [y/N] "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return nil, fmt.Errorf("not releasing")
+	}
+	return breaking, nil
+}
+
+// findBreaking prints the breaking changes of a new release and returns
+// whether it has any. Incompatible Go API changes count as breaking when the
+// apidiff policy is block, failing to compare the API is only an error then.
+func findBreaking(rm *release.Manager, cfg *release.Config, newRelease, target string) (bool, error) {
+	commits, err := rm.BreakingChanges(newRelease, target)
+	if err != nil {
+		return false, err
+	}
+	breaking := len(commits) > 0
+	if breaking {
+		log.Warn().Msgf("%s contains %d breaking change(s):", newRelease, len(commits))
+		for _, c := range commits {
+			fmt.Fprintf(os.Stderr, "  * %s %s\n", c.Hash[:8], release.Subject(c.Message))
+		}
+		rel := rm.ParseRelease(newRelease)
+		if teams := rm.Teams(rel.Component()); len(teams) > 0 {
+			log.Warn().Msgf("check with %s before releasing, they own %s", strings.Join(teams, ", "), rel.Component())
+		}
+	}
+	incompatible, err := checkAPI(rm, cfg.APIDiff, newRelease, target)
+	switch {
+	case err != nil && cfg.APIDiff.Blocks():
+		return breaking, fmt.Errorf("failed to compare the API: %w", err)
+	case err != nil:
+		log.Warn().Err(err).Msgf("failed to compare the API of %s", newRelease)
+	case incompatible && !breaking && cfg.APIDiff.Blocks():
+		breaking = true
+	case incompatible && !breaking:
+		log.Warn().Msgf("%s changes the API incompatibly but no commit is marked as breaking", newRelease)
+	}
+	return breaking, nil
+}
+
+// checkAPI prints the incompatible API changes of a Go module component and
+// returns whether there are any
+func checkAPI(rm *release.Manager, cfg release.APIDiffConfig, newRelease, target string) (bool, error) {
+	rel := rm.ParseRelease(newRelease)
+	dir, ok := cfg.Modules[rel.Component()]
+	if !ok {
+		return false, nil
+	}
+	changes, err := rm.APIChanges(newRelease, dir, target)
+	if err != nil {
+		return false, err
+	}
+	incompatible := apidiff.Incompatible(changes)
+	if len(incompatible) == 0 {
+		return false, nil
+	}
+	log.Warn().Msgf("%s has %d incompatible API change(s) in %s:", newRelease, len(incompatible), dir)
+	for _, c := range incompatible {
+		fmt.Fprintf(os.Stderr, "  * %s\n", c)
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// buildReleases runs the build phase of the new releases of components with a
+// build and writes the manifests of their artifacts. The releases whose build
+// failed are returned, they mustn't be tagged.
+func buildReleases(rm *release.Manager, cfg *release.Config, modules, newReleases []string) map[string]bool {
+	jobs := []release.BuildJob{}
+	for idx, newRelease := range newReleases {
+		if build := cfg.Components[modules[idx]].Build; build != nil {
+			jobs = append(jobs, release.BuildJob{Release: rm.ParseRelease(newRelease), Build: *build})
+		}
+	}
+	failed := map[string]bool{}
+	if len(jobs) == 0 {
+		return failed
+	}
+	if rm.RepoDir() == "" {
+		log.Fatal().Msg("building needs a checkout, it can't be used with --repo-url")
+	}
+	workers := cfg.Build.Jobs
+	if workers < 1 {
+		workers = 1
+	}
+	log.Info().Msgf("building %d release(s), %d at a time", len(jobs), workers)
+	for _, result := range release.BuildAll(jobs, rm.RepoDir(), workers) {
+		if result.Output != "" {
+			fmt.Fprintf(os.Stderr, "--- build of %s\n%s", result.Tag, result.Output)
+			if !strings.HasSuffix(result.Output, "\n") {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+		if result.Err != nil {
+			logError(result.Err, fmt.Sprintf("failed to build %s, not releasing it", result.Tag))
+			failed[result.Tag] = true
+			continue
+		}
+		manifest := cfg.Build.ManifestFile(result.Tag)
+		if err := release.WriteBuildManifest(filepath.Join(rm.RepoDir(), manifest), result.Manifest); err != nil {
+			logError(err, fmt.Sprintf("failed to write %s, not releasing %s", manifest, result.Tag))
+			failed[result.Tag] = true
+			continue
+		}
+		say(fmt.Sprintf("built %d artifact(s) of %s, listed in %s", len(result.Manifest.Artifacts), result.Tag, manifest), "tag", result.Tag, "artifacts", len(result.Manifest.Artifacts), "manifest", manifest)
+	}
+	return failed
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func bundleUsage() {
+	fmt.Fprintf(os.Stderr, "usage: release bundle create -o <file> [tag...] [--since <tag>] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release bundle apply <file> [--push] [options]\n")
+}
+
+func bundleMain(args []string) {
+	if len(args) == 0 {
+		bundleUsage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "create":
+		bundleCreateMain(args[1:])
+	case "apply":
+		bundleApplyMain(args[1:])
+	default:
+		bundleUsage()
+		os.Exit(2)
+	}
+}
+
+func bundleCreateMain(args []string) {
+	var output, since string
+	var verbose bool
+	basisRevs := []string{}
+	fs := flag.NewFlagSet("bundle create", flag.ExitOnError)
+	fs.StringVarP(&output, "output", "o", "", "file to write the bundle to")
+	fs.StringVar(&since, "since", "", "bundle every release created after this one, the receiving side must have it")
+	fs.StringArrayVar(&basisRevs, "basis", []string{}, "revision the receiving side already has, objects reachable from it are left out (can be repeated)")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		bundleUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if output == "" {
+		log.Fatal().Msg("-o is required")
+	}
+
+	rm := openManager("", "")
+	tags := fs.Args()
+	basis := []string{}
+	if since != "" {
+		prev := rm.FindRelease(since)
+		if prev == nil {
+			log.Fatal().Msgf("there's no release %s", since)
+		}
+		for _, rel := range rm.Releases() {
+			if rel.Date().After(prev.Date()) {
+				tags = append(tags, rel.Tag)
+			}
+		}
+		basis = append(basis, prev.Hash)
+	}
+	for _, rev := range basisRevs {
+		hash, err := rm.ResolveCommit(rev)
+		release.CheckIfError(err, fmt.Sprintf("failed to resolve basis %s", rev))
+		basis = append(basis, hash)
+	}
+	if len(tags) == 0 {
+		log.Fatal().Msg("nothing to bundle, name the tags or use --since")
+	}
+	sort.Strings(tags)
+
+	f, err := os.Create(output)
+	release.CheckIfError(err, fmt.Sprintf("failed to create %s", output))
+	manifest, err := rm.CreateBundle(f, tags, basis)
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		os.Remove(output)
+	}
+	release.CheckIfError(err, "failed to create the bundle")
+	say(fmt.Sprintf("bundled %d release(s) in %s:", len(manifest.Tags), output), "count", len(manifest.Tags), "file", output)
+	for _, t := range manifest.Tags {
+		say("  "+t.Name, "tag", t.Name)
+	}
+}
+
+func bundleApplyMain(args []string) {
+	var remote, sshKeyPath string
+	var verbose, doPush bool
+	fs := flag.NewFlagSet("bundle apply", flag.ExitOnError)
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push the imported tags to (if --push)")
+	fs.BoolVar(&doPush, "push", false, "push the imported tags in a single push")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		bundleUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if doPush && release.Offline() {
+		log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	release.CheckIfError(err, fmt.Sprintf("failed to open %s", fs.Arg(0)))
+	bundle, err := release.ReadBundle(f)
+	f.Close()
+	release.CheckIfError(err, fmt.Sprintf("failed to read %s", fs.Arg(0)))
+	log.Info().Msgf("bundle of %d release(s) created %s verified", len(bundle.Manifest.Tags), bundle.Manifest.Created.Format("2006-01-02 15:04"))
+
+	rm := openManager("", sshKeyPath)
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	if doPush {
+		release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remote))
+	}
+	created, err := rm.ImportBundle(bundle)
+	release.CheckIfError(err, "failed to import the bundle")
+
+	// The bundle crossed an air gap, make sure the tags are ones we trust
+	untrusted, err := rm.ApplyTrust(repoCfg.Trust)
+	release.CheckIfError(err, "failed to load trusted keys")
+	for _, tag := range created {
+		reason, ok := untrusted[tag]
+		if !ok {
+			continue
+		}
+		if repoCfg.Trust.Untrusted == "fail" {
+			for _, tag := range created {
+				rm.DeleteTag(tag)
+			}
+			log.Fatal().Msgf("bundled tag %s isn't trusted (%s), nothing was imported", tag, reason)
+		}
+		log.Warn().Msgf("bundled tag %s isn't trusted: %s", tag, reason)
+	}
+
+	if len(created) == 0 {
+		say("every release in the bundle already exists")
+	} else {
+		say(fmt.Sprintf("imported %d release(s):", len(created)), "count", len(created))
+		for _, tag := range created {
+			say("  "+tag, "tag", tag)
+		}
+	}
+	if !doPush {
+		say(fmt.Sprintf("push them with 'git push %s --tags' or use --push", remote), "remote", remote)
+		return
+	}
+	// Tags that already existed are pushed too, they may never have made it
+	// to the remote
+	tags := []string{}
+	for _, t := range bundle.Manifest.Tags {
+		tags = append(tags, t.Name)
+	}
+	pushTags(rm, tags, remote, authForRemote(rm, remote, sshKeyPath))
+}
+
+// pushTags pushes tags to the remote in a single push
+func pushTags(rm *release.Manager, tags []string, remote string, auth transport.AuthMethod) {
+	refSpecs := []config.RefSpec{}
+	for _, tag := range tags {
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)))
+	}
+	_, err := rm.PushTagToRemoteWithOptions(tags[0], remote, auth, release.PushOptions{RefSpecs: refSpecs})
+	release.CheckIfError(err, fmt.Sprintf("failed to push to %s", remote))
+	say(fmt.Sprintf("pushed %d tag(s) to remote %s", len(tags), remote), "count", len(tags), "remote", remote)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// changelogMarkdown renders a component's changelog as a markdown section
+// with a heading of the given level, linked to the forge if links isn't nil
+func changelogMarkdown(c *release.ComponentChangelog, level int, links *release.NoteLinker) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", level), c.Component)
+	if c.Previous != "" {
+		// The next tag doesn't exist yet, compare with the newest change
+		fmt.Fprintf(b, "Changes since %s, to be released as %s:\n\n", links.Compare(c.Previous, c.Commits[0].Hash), c.Next)
+	} else {
+		fmt.Fprintf(b, "Never released before, to be released as %s:\n\n", c.Next)
+	}
+	notes := c.Notes
+	if links != nil {
+		notes = links.Notes(c.Commits)
+	}
+	for _, note := range notes {
+		fmt.Fprintf(b, "- %s\n", note)
+	}
+	return b.String()
+}
+
+func changelogMain(args []string) {
+	var output, dir, remote string
+	var all, verbose bool
+	var workers int
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	fs.BoolVar(&all, "all", false, "every component that changed since its last release")
+	fs.StringVarP(&output, "output", "o", "", "write one combined document to this file instead of stdout")
+	fs.StringVar(&dir, "dir", "", "write a <component>.md file per component to this directory")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote of the forge commits and issues are linked to")
+	fs.IntVarP(&workers, "jobs", "j", runtime.NumCPU(), "how many components to walk at once")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release changelog --all|<component...> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if all == (fs.NArg() > 0) {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if output != "" && dir != "" {
+		log.Fatal().Msg("--output and --dir can't be used together")
+	}
+
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	rm.AlwaysIncludeNumber = true
+	rm.Scheme = repoCfg.Scheme
+	_, err = rm.ApplyTrust(repoCfg.Trust)
+	release.CheckIfError(err, "failed to load trusted keys")
+
+	now := time.Now()
+	changelogs, err := rm.UnreleasedChangelogs(release.PlanOptions{
+		Now:        repoCfg.Train.Date(now),
+		Increments: repoCfg.Increments,
+		Components: repoCfg.Components,
+		Only:       fs.Args(),
+	}, workers)
+	release.CheckIfError(err, "failed to build the changelogs")
+	if len(changelogs) == 0 {
+		log.Info().Msg("no component changed since its last release")
+		return
+	}
+
+	linkers := map[string]*release.NoteLinker{}
+	for _, c := range changelogs {
+		compCfg := repoCfg.ForComponent(c.Component)
+		compRemote := remote
+		if !fs.Changed("remote") && compCfg.Remote != "" {
+			compRemote = compCfg.Remote
+		}
+		links, err := rm.NoteLinker(compRemote, compCfg.Forge)
+		if err != nil {
+			log.Debug().Err(err).Msgf("not linking the changelog of %s to the forge", c.Component)
+		}
+		linkers[c.Component] = links
+	}
+
+	if dir != "" {
+		release.CheckIfError(os.MkdirAll(dir, 0755), fmt.Sprintf("failed to create %s", dir))
+		for _, c := range changelogs {
+			path := filepath.Join(dir, c.Component+".md")
+			release.CheckIfError(ioutil.WriteFile(path, []byte(changelogMarkdown(c, 1, linkers[c.Component])), 0644), fmt.Sprintf("failed to write %s", path))
+		}
+		log.Info().Msgf("wrote the changelogs of %d component(s) to %s", len(changelogs), dir)
+		return
+	}
+
+	doc := &strings.Builder{}
+	fmt.Fprintf(doc, "# Release notes %s\n", now.Format("2006-01-02"))
+	for _, c := range changelogs {
+		fmt.Fprintf(doc, "\n%s", changelogMarkdown(c, 2, linkers[c.Component]))
+	}
+	if output == "" {
+		fmt.Print(doc.String())
+		return
+	}
+	release.CheckIfError(ioutil.WriteFile(output, []byte(doc.String()), 0644), fmt.Sprintf("failed to write %s", output))
+	log.Info().Msgf("wrote the changelogs of %d component(s) to %s", len(changelogs), output)
+}
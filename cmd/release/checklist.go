@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// checkChecklists makes sure the checklist of each new release's component is
+// acknowledged, by --ack or by asking about the rest, and returns the
+// acknowledged items of each release. When nobody can be asked anything left
+// refuses the release.
+func checkChecklists(rm *release.Manager, cfg *release.Config, newReleases []string, acks []string, dryRun, ask bool) (map[string][]string, error) {
+	acked := map[string][]string{}
+	var reader *bufio.Reader
+	for _, newRelease := range newReleases {
+		rel := rm.ParseRelease(newRelease)
+		checklist := cfg.Checklist(rel.Component())
+		if len(checklist) == 0 {
+			continue
+		}
+		missing := release.MissingAcks(checklist, acks)
+		if dryRun {
+			if len(missing) > 0 {
+				log.Info().Msgf("%s needs the checklist acknowledged: %s", newRelease, strings.Join(missing, ", "))
+			}
+			continue
+		}
+		if len(missing) > 0 && !ask {
+			return nil, fmt.Errorf("refusing to release %s, the checklist isn't acknowledged, use --ack '%s'", newRelease, strings.Join(missing, ","))
+		}
+		for _, item := range missing {
+			if reader == nil {
+				reader = bufio.NewReader(os.Stdin)
+			}
+			fmt.Fprint(os.Stderr, i18n.T("%s: %s? [y/N] ", newRelease, item))
+			answer, _ := reader.ReadString('\n')
+			if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+				return nil, fmt.Errorf("'%s' isn't acknowledged, not releasing %s", item, newRelease)
+			}
+		}
+		acked[newRelease] = checklist
+	}
+	return acked, nil
+}
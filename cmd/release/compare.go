@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// Exit codes of release compare, usage errors and tags that aren't releases
+// exit 2
+const (
+	compareNewer = 0
+	compareOlder = 1
+	compareSame  = 3
+)
+
+func compareMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release compare <tag> <other-tag>\n\n")
+		fmt.Fprintf(os.Stderr, "Says whether the first release is newer than the other. Exits 0 if it's\n")
+		fmt.Fprintf(os.Stderr, "newer, 1 if it's older, 3 if they're the same version and 2 if either\n")
+		fmt.Fprintf(os.Stderr, "isn't a release tag.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	tmpl := repoTagTemplate()
+	a, b := fs.Arg(0), fs.Arg(1)
+	result, err := tmpl.CompareTags(a, b)
+	if err != nil {
+		logError(err, "failed to compare releases")
+		os.Exit(2)
+	}
+	switch result {
+	case 1:
+		say(fmt.Sprintf("%s is newer than %s", a, b), "tag", a, "other", b, "result", "newer")
+		os.Exit(compareNewer)
+	case -1:
+		say(fmt.Sprintf("%s is older than %s", a, b), "tag", a, "other", b, "result", "older")
+		os.Exit(compareOlder)
+	}
+	say(fmt.Sprintf("%s is the same version as %s", a, b), "tag", a, "other", b, "result", "same")
+	os.Exit(compareSame)
+}
+
+func sortMain(args []string) {
+	var reverse, verbose bool
+	fs := flag.NewFlagSet("sort", flag.ExitOnError)
+	fs.BoolVarP(&reverse, "reverse", "r", false, "newest first")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release sort [-r] < tags\n\n")
+		fmt.Fprintf(os.Stderr, "Sorts the tag names read from stdin by version, oldest first. Lines that\n")
+		fmt.Fprintf(os.Stderr, "aren't release tags are printed last in the order they were read.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	tmpl := repoTagTemplate()
+	var tags, others []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if _, _, _, err := tmpl.ParseTag(line); err != nil {
+			log.Warn().Msgf("%s isn't a release tag", line)
+			others = append(others, line)
+			continue
+		}
+		tags = append(tags, line)
+	}
+	release.CheckIfError(scanner.Err(), "failed to read tags")
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if reverse {
+			a, b = b, a
+		}
+		result, _ := tmpl.CompareTags(a, b)
+		if result == 0 {
+			return a < b
+		}
+		return result < 0
+	})
+	for _, tag := range append(tags, others...) {
+		fmt.Println(tag)
+	}
+}
+
+// repoTagTemplate returns the tag_template of the repository we're in to read
+// tags with, without loading its releases. Outside of a repository, or
+// without a tag_template, it's nil and only the built-in formats are read.
+func repoTagTemplate() *release.TagTemplate {
+	cwd, err := os.Getwd()
+	release.CheckIfError(err, "failed to get current dir")
+	repoDir, err := release.FindRepoDir(cwd)
+	if err != nil {
+		log.Debug().Err(err).Msg("not in a repository, using the built-in tag formats")
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(repoDir, release.ConfigFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	release.CheckIfError(err, fmt.Sprintf("failed to read %s", release.ConfigFile))
+	cfg, err := release.ParseConfig(data)
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	if cfg.TagTemplate == "" {
+		return nil
+	}
+	tmpl, err := release.ParseTagTemplate(cfg.TagTemplate)
+	release.CheckIfError(err, "failed to use tag_template")
+	return tmpl
+}
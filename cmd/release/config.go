@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+func configUsage() {
+	fmt.Fprintf(os.Stderr, "usage: release config validate [options]\n")
+	fmt.Fprintf(os.Stderr, "       release config show [--effective] [-c <component>] [options]\n")
+}
+
+func configMain(args []string) {
+	if len(args) == 0 {
+		configUsage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "validate":
+		configValidateMain(args[1:])
+	case "show":
+		configShowMain(args[1:])
+	default:
+		configUsage()
+		os.Exit(2)
+	}
+}
+
+func configValidateMain(args []string) {
+	var strict, verbose bool
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	fs.BoolVar(&strict, "strict", false, "exit non-zero on warnings too")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		configUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	cfg, err := rm.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s is invalid: %s\n", release.ConfigFile, err)
+		os.Exit(1)
+	}
+	warnings := rm.CheckConfig(cfg)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if strict && len(warnings) > 0 {
+		os.Exit(1)
+	}
+	log.Info().Msgf("%s is valid", release.ConfigFile)
+}
+
+func configShowMain(args []string) {
+	var effective, verbose bool
+	var component string
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	fs.BoolVar(&effective, "effective", false, "fill in the default of every unset setting")
+	fs.StringVarP(&component, "component", "c", "", "show the config with the overrides of this component applied")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		configUsage()
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	cfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	if component != "" {
+		if _, ok := cfg.Components[component]; !ok && len(cfg.Components) > 0 {
+			log.Warn().Msgf("component %s isn't configured, it has no overrides", component)
+		}
+		cfg = cfg.ForComponent(component)
+	}
+	if effective {
+		cfg = cfg.WithDefaults()
+	}
+	data, err := yaml.Marshal(cfg)
+	release.CheckIfError(err, "failed to render the config")
+	fmt.Print(string(data))
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fernferret/release/internal/cron"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// daemon releases every component that changed since its last release on a
+// cron schedule, see daemonMain
+type daemon struct {
+	rm          *release.Manager
+	cfg         *release.Config
+	remote      string
+	branch      string
+	auth        transport.AuthMethod
+	user, email string
+	dryRun      bool
+	noNotify    bool
+
+	// What the health endpoint reports
+	mu        sync.Mutex
+	Cron      string     `json:"cron"`
+	Runs      int        `json:"runs"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	NextRun   time.Time  `json:"next_run"`
+	Released  []string   `json:"released"` // By the last run
+}
+
+// ServeHTTP is the health endpoint, 503 while the last run failed so
+// monitoring catches it
+func (d *daemon) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := http.StatusOK
+	if d.LastError != "" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(d)
+}
+
+// tick runs once and records how it went for the health endpoint
+func (d *daemon) tick() error {
+	released, err := d.run()
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Runs++
+	d.LastRun = &now
+	d.Released = released
+	d.LastError = ""
+	if err != nil {
+		d.LastError = err.Error()
+	}
+	return err
+}
+
+// run fetches the branch and releases every component that changed on it,
+// returning the new tags
+func (d *daemon) run() ([]string, error) {
+	target, err := d.rm.FetchBranch(d.remote, d.branch, d.auth)
+	if err != nil {
+		return nil, err
+	}
+	// The fetch may have brought tags nobody trusted signed
+	if err := untrustedError(d.rm.UntrustedReleases(), d.cfg.Trust); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	plan, err := d.rm.DraftPlan(release.PlanOptions{
+		Now:        d.cfg.Train.Date(now),
+		Increments: d.cfg.Increments,
+		Components: d.cfg.Components,
+		Only:       d.cfg.Daemon.Components,
+		Target:     target,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to draft a release plan: %w", err)
+	}
+	// The releases go through the gates of interactive releases, but nobody
+	// is around to override a freeze, acknowledge breaking changes or go
+	// through a checklist. Releases that would need it are skipped.
+	planned := plan.Releases[:0]
+	for _, p := range plan.Releases {
+		g, err := runGates(d.rm, d.cfg, []string{p.Component}, []string{p.Tag}, gateOptions{
+			by: releasedBy(d.user, d.email),
+			// The schedule was picked by people, the warnings are only logged
+			ackLeadTime:   true,
+			targets:       map[string]string{p.Tag: p.Target},
+			allowDetached: true,
+			unattended:    true,
+		})
+		if err != nil {
+			log.Warn().Msgf("not releasing %s: %s", p.Component, err)
+			continue
+		}
+		p.Message = g.annotate(p.Message, p.Component, p.Tag)
+		planned = append(planned, p)
+	}
+	plan.Releases = planned
+	if len(plan.Releases) == 0 {
+		log.Info().Msgf("nothing changed on %s/%s since the last releases", d.remote, d.branch)
+		return nil, nil
+	}
+	if d.dryRun {
+		for _, p := range plan.Releases {
+			log.Info().Msgf("would release %s at %s", p.Tag, p.Target[:8])
+		}
+		return nil, nil
+	}
+
+	// Releases whose build, hooks or scanners fail are skipped too
+	defer d.rm.RemoveWorktrees()
+	by := releasedBy(d.user, d.email)
+	newReleases := []string{}
+	for _, p := range plan.Releases {
+		newReleases = append(newReleases, p.Tag)
+	}
+	steps, failed := runPlanSteps(d.rm, d.cfg, plan, newReleases, "", by)
+	planned = plan.Releases[:0]
+	for _, p := range plan.Releases {
+		if err, ok := failed[p.Tag]; ok {
+			log.Warn().Msgf("not releasing %s: %s", p.Component, err)
+			continue
+		}
+		planned = append(planned, p)
+	}
+	plan.Releases = planned
+	if len(plan.Releases) == 0 {
+		return nil, nil
+	}
+
+	opts := release.ApplyOptions{
+		User:       d.user,
+		Email:      d.email,
+		Now:        d.cfg.Train.Date(now),
+		Increments: d.cfg.Increments,
+		Components: d.cfg.Components,
+		Remote:     d.remote,
+		Auth:       d.auth,
+	}
+	if d.cfg.Annotate {
+		opts.Kind = release.TagAnnotated
+	}
+	applied, err := d.rm.ApplyPlan(plan, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release, no tags were kept: %w", err)
+	}
+	tags := []string{}
+	for _, a := range applied {
+		tags = append(tags, a.Tag)
+		log.Info().Msgf("released %s at %s", a.Tag, a.Target[:8])
+		audit(d.rm, release.AuditEvent{Action: "release", Tag: a.Tag, Detail: "released by the daemon"}, d.user, d.email)
+		floating := release.FloatingTagsFor(d.cfg.Floating, d.rm.ParseRelease(a.Tag), a.Message)
+		if err := d.rm.MoveFloatingTags(floating, a.Tag); err != nil {
+			logError(err, fmt.Sprintf("failed to move the floating tags of %s", a.Tag))
+		} else if err := d.rm.PushFloatingTags(floating, d.remote, d.auth); err != nil {
+			logError(err, fmt.Sprintf("failed to push the floating tags %s", strings.Join(floating, ", ")))
+		}
+	}
+	if !d.noNotify {
+		notifyApplied(d.rm, d.cfg, applied, d.remote, by)
+	}
+	steps.finish(d.rm, applied, d.user, d.email, by)
+	return tags, nil
+}
+
+func daemonMain(args []string) {
+	var cronExpr, remote, branch, listen, user, email, sshKeyPath string
+	var verbose, once, dryRun, noNotify bool
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	fs.StringVar(&cronExpr, "cron", "", "when to release, like \"0 9 * * TUE\" (daemon.cron in .release.yaml)")
+	fs.StringVarP(&remote, "remote", "r", "", "git remote to fetch from and push to, remote in .release.yaml or origin")
+	fs.StringVarP(&branch, "branch", "b", "", "branch of the remote to release (daemon.branch in .release.yaml), the current branch by default")
+	fs.StringVarP(&listen, "listen", "l", "", "serve the health endpoint on this address, like :8081")
+	fs.BoolVar(&once, "once", false, "release once right away and exit, for running from an external scheduler")
+	fs.BoolVarP(&dryRun, "dry-run", "n", false, "only log what would be released")
+	fs.BoolVar(&noNotify, "no-notify", false, "don't send the notifications configured in .release.yaml")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release daemon [--cron <expr>] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	user, email = gitIdentity(user, email)
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.LoadOwnership(repoCfg.Components), "failed to load CODEOWNERS")
+	setupOIDC(rm, repoCfg)
+	rm.AlwaysIncludeNumber = true
+	rm.Scheme = repoCfg.Scheme
+	release.CheckIfError(checkTrust(rm, repoCfg.Trust), "refusing to release")
+
+	if cronExpr == "" {
+		cronExpr = repoCfg.Daemon.Cron
+	}
+	if cronExpr == "" && !once {
+		log.Fatal().Msgf("no schedule, use --cron or set daemon.cron in %s", release.ConfigFile)
+	}
+	if remote == "" {
+		remote = repoCfg.Remote
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+	if branch == "" {
+		branch = repoCfg.Daemon.Branch
+	}
+	if branch == "" {
+		branch, err = rm.CurrentBranch()
+		release.CheckIfError(err, "failed to find the branch to release, use --branch")
+	}
+	release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s'", remote))
+
+	d := &daemon{
+		rm:       rm,
+		cfg:      repoCfg,
+		remote:   remote,
+		branch:   branch,
+		auth:     authForRemote(rm, remote, sshKeyPath),
+		user:     user,
+		email:    email,
+		dryRun:   dryRun,
+		noNotify: noNotify,
+		Cron:     cronExpr,
+		Released: []string{},
+	}
+	if once {
+		release.CheckIfError(d.tick(), "release run failed")
+		return
+	}
+	schedule, err := cron.Parse(cronExpr)
+	release.CheckIfError(err, "invalid --cron")
+
+	if listen != "" {
+		http.Handle("/healthz", d)
+		go func() {
+			release.CheckIfError(http.ListenAndServe(listen, nil), "health endpoint failed")
+		}()
+		log.Info().Msgf("health endpoint on http://%s/healthz", listen)
+	}
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Fatal().Msgf("'%s' never matches", cronExpr)
+		}
+		d.mu.Lock()
+		d.NextRun = next
+		d.mu.Unlock()
+		log.Info().Msgf("next release run at %s", next.Format("2006-01-02 15:04 MST"))
+		time.Sleep(time.Until(next))
+		if err := d.tick(); err != nil {
+			// Keep going, the next run may well work and the health endpoint
+			// reports the failure until then
+			logError(err, "release run failed")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// checkDCO lists the commits of new releases of components requiring a DCO
+// that their author didn't sign off. Releasing them needs an override with a
+// reason, the releases it's used for are returned. Releases that couldn't be
+// checked need the override too.
+func checkDCO(rm *release.Manager, cfg *release.Config, components, newReleases []string, targets map[string]string, override string, dryRun bool) (map[string]bool, error) {
+	overridden := map[string]bool{}
+	for idx, newRelease := range newReleases {
+		component := cfg.Components[components[idx]]
+		if !component.DCO {
+			continue
+		}
+		missing, err := rm.MissingSignOffs(newRelease, targets[newRelease], component)
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to check the sign-offs of %s", newRelease))
+		} else if len(missing) == 0 {
+			continue
+		} else {
+			log.Warn().Msgf("%s contains %d commit(s) not signed off by their author:", newRelease, len(missing))
+			for _, c := range missing {
+				fmt.Fprintf(os.Stderr, "  * %s %s <%s> %s\n", c.Hash[:8], c.Author.Name, c.Author.Email, release.Subject(c.Message))
+			}
+		}
+		if dryRun {
+			continue
+		}
+		if override == "" {
+			return nil, fmt.Errorf("%s requires a DCO sign-off on every commit, amend them with 'git commit --signoff' or use --override-dco with a reason", components[idx])
+		}
+		log.Warn().Msgf("overriding the DCO check of %s: %s", newRelease, override)
+		overridden[newRelease] = true
+	}
+	return overridden, nil
+}
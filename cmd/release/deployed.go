@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fernferret/release/internal/keyring"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// defaultServeTokenEnv holds the bearer token 'release serve' requires
+const defaultServeTokenEnv = release.DefaultMetadataTokenEnv
+
+func deployedMain(args []string) {
+	var d release.Deployment
+	var remote, user, email, sshKeyPath string
+	var verbose, doPush bool
+	fs := flag.NewFlagSet("deployed", flag.ExitOnError)
+	fs.StringVarP(&d.Env, "env", "e", "", "the environment the release was deployed to (required)")
+	fs.StringVarP(&d.Status, "status", "s", "", fmt.Sprintf("the status of the deployment: %s (required)", strings.Join(release.DeploymentStatuses, ", ")))
+	fs.StringVar(&d.URL, "url", "", "link to the deployment's logs or dashboard")
+	fs.StringVarP(&d.Description, "description", "d", "", "a short description of the status")
+	fs.BoolVar(&doPush, "push", false, "push the deployment statuses and environment refs to the remote")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release deployed <tag> --env <env> --status <status> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if d.Env == "" || d.Status == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if doPush && release.Offline() {
+		log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+	}
+	d.Tag = fs.Arg(0)
+
+	user, email = gitIdentity(user, email)
+	d.By = releasedBy(user, email)
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	sig := object.Signature{Name: user, Email: email, When: time.Now()}
+	release.CheckIfError(rm.RecordDeployment(d, sig), fmt.Sprintf("failed to record the deployment of %s", d.Tag))
+	say(fmt.Sprintf("recorded %s of %s in %s", d.Status, d.Tag, d.Env), "status", d.Status, "tag", d.Tag, "env", d.Env)
+	if doPush {
+		release.CheckIfError(rm.PushEnvironments(remote, authForRemote(rm, remote, sshKeyPath)), fmt.Sprintf("failed to push the deployment statuses to %s", remote))
+		say(fmt.Sprintf("pushed deployment statuses to %s", remote), "remote", remote)
+	}
+}
+
+// metadataServer takes deployment statuses over HTTP for deployment systems
+// that would rather not shell out, and is the http metadata store of other
+// checkouts
+type metadataServer struct {
+	mu     sync.Mutex // go-git's storage isn't safe for concurrent writes
+	rm     *release.Manager
+	token  string
+	user   string
+	email  string
+	push   func() error // nil without --push
+	remote string
+}
+
+func (s *metadataServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if req.Method != "GET" && req.Method != "POST" {
+		http.Error(w, "use GET or POST", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Pick up tags created since the server started
+	s.rm.Reload()
+
+	var result interface{}
+	var err error
+	var status int
+	switch req.URL.Path {
+	case "/deployments":
+		result, status, err = s.deployments(req)
+	case "/environments":
+		result, status, err = s.environments(req)
+	case "/audit":
+		result, status, err = s.audit(req)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if req.Method == "POST" {
+		if s.push != nil {
+			if err := s.push(); err != nil {
+				logError(err, fmt.Sprintf("failed to push the metadata to %s", s.remote))
+				http.Error(w, fmt.Sprintf("recorded but not pushed: %s", err), http.StatusBadGateway)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *metadataServer) sig() object.Signature {
+	return object.Signature{Name: s.user, Email: s.email, When: time.Now()}
+}
+
+func (s *metadataServer) deployments(req *http.Request) (interface{}, int, error) {
+	if req.Method == "POST" {
+		var d release.Deployment
+		if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid deployment: %s", err)
+		}
+		if d.By == "" {
+			d.By = releasedBy(s.user, s.email)
+		}
+		d.Date = time.Time{}
+		if err := s.rm.RecordDeployment(d, s.sig()); err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		log.Info().Msgf("recorded %s of %s in %s", d.Status, d.Tag, d.Env)
+		return nil, 0, nil
+	}
+	deployments, err := s.rm.Deployments()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if tag := req.URL.Query().Get("tag"); tag != "" {
+		matching := []release.Deployment{}
+		for _, d := range deployments {
+			if d.Tag == tag {
+				matching = append(matching, d)
+			}
+		}
+		deployments = matching
+	}
+	return deployments, 0, nil
+}
+
+func (s *metadataServer) environments(req *http.Request) (interface{}, int, error) {
+	if req.Method == "POST" {
+		var u release.EnvironmentUpdate
+		if err := json.NewDecoder(req.Body).Decode(&u); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid environment update: %s", err)
+		}
+		if !release.IsHash(u.Hash) {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("invalid commit '%s'", u.Hash)
+		}
+		if err := s.rm.SetEnvironment(u.Env, u.Component, u.Hash); err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		log.Info().Msgf("%s of %s is in %s", u.Hash, u.Component, u.Env)
+		return nil, 0, nil
+	}
+	envs, err := s.rm.Environments()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	updates := []release.EnvironmentUpdate{}
+	for _, e := range envs {
+		updates = append(updates, release.EnvironmentUpdate{Env: e.Env, Component: e.Component, Hash: e.Hash})
+	}
+	return updates, 0, nil
+}
+
+func (s *metadataServer) audit(req *http.Request) (interface{}, int, error) {
+	if req.Method == "POST" {
+		var e release.AuditEvent
+		if err := json.NewDecoder(req.Body).Decode(&e); err != nil || e.Action == "" {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid audit event: %v", err)
+		}
+		if err := s.rm.Audit(e, s.sig()); err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		return nil, 0, nil
+	}
+	events, err := s.rm.AuditEvents()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return events, 0, nil
+}
+
+// isLoopback reports whether the listen address only takes connections from
+// this machine, an empty host listens on every interface
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func serveMain(args []string) {
+	var listen, tokenEnv, remote, user, email, sshKeyPath string
+	var verbose, doPush, insecure bool
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVarP(&listen, "listen", "l", "127.0.0.1:8080", "address to listen on")
+	fs.BoolVar(&insecure, "insecure", false, "accept requests without a token on an address other than loopback")
+	fs.StringVar(&tokenEnv, "token-env", defaultServeTokenEnv, "environment variable (or keyring secret) with the bearer token clients must send")
+	fs.BoolVar(&doPush, "push", false, "push the deployment statuses, environment refs and audit log after every update")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release serve [--listen <addr>] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	user, email = gitIdentity(user, email)
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	s := &metadataServer{rm: rm, token: keyring.Getenv(tokenEnv), user: user, email: email, remote: remote}
+	if s.token == "" {
+		if !insecure && !isLoopback(listen) {
+			log.Fatal().Msgf("%s isn't set, set it or use --insecure to let anyone who can reach %s record deployments and environments", tokenEnv, listen)
+		}
+		log.Warn().Msgf("%s isn't set, anyone who can reach %s can record deployments and environments", tokenEnv, listen)
+	}
+	if doPush {
+		if release.Offline() {
+			log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+		}
+		auth := authForRemote(rm, remote, sshKeyPath)
+		s.push = func() error { return rm.PushEnvironments(remote, auth) }
+	}
+	http.Handle("/", s)
+	log.Info().Msgf("taking deployment statuses on http://%s/deployments, environments and the audit log on /environments and /audit", listen)
+	release.CheckIfError(http.ListenAndServe(listen, nil), "server failed")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func markMain(args []string) {
+	var env, remote, sshKeyPath string
+	var verbose, doPush bool
+	fs := flag.NewFlagSet("mark", flag.ExitOnError)
+	fs.StringVarP(&env, "env", "e", "", "the environment the release is now in (required)")
+	fs.BoolVar(&doPush, "push", false, "push the environment refs to the remote")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release mark <tag> --env <env> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	if env == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	tag := fs.Arg(0)
+
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	release.CheckIfError(rm.MarkEnvironment(tag, env), fmt.Sprintf("failed to mark %s as in %s", tag, env))
+	say(fmt.Sprintf("marked %s as in %s", tag, env), "tag", tag, "env", env)
+	user, email := gitIdentity("", "")
+	audit(rm, release.AuditEvent{Action: "mark", Tag: tag, Env: env}, user, email)
+	if doPush {
+		release.CheckIfError(rm.PushEnvironments(remote, authForRemote(rm, remote, sshKeyPath)), fmt.Sprintf("failed to push environments to %s", remote))
+		say(fmt.Sprintf("pushed environments to %s", remote), "remote", remote)
+	}
+}
+
+func statusMain(args []string) {
+	var remote, sshKeyPath string
+	var verbose, fetch bool
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	fs.BoolVar(&fetch, "fetch", false, "fetch the environment refs from the remote first")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to fetch from (if --fetch)")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	if fetch {
+		release.CheckIfError(rm.FetchEnvironments(remote, authForRemote(rm, remote, sshKeyPath)), fmt.Sprintf("failed to fetch environments from %s", remote))
+	}
+	deployed, err := rm.Environments()
+	release.CheckIfError(err, "failed to load environments")
+	deployments, err := rm.Deployments()
+	release.CheckIfError(err, "failed to load deployment statuses")
+	if len(deployed) == 0 && len(deployments) == 0 {
+		say("no environments tracked yet, use 'release mark <tag> --env <env>'")
+		return
+	}
+
+	// Build a component x environment matrix
+	envs := []string{}
+	components := []string{}
+	matrix := map[string]map[string]string{}
+	for _, d := range deployed {
+		if !containsString(envs, d.Env) {
+			envs = append(envs, d.Env)
+		}
+		if _, ok := matrix[d.Component]; !ok {
+			matrix[d.Component] = map[string]string{}
+			components = append(components, d.Component)
+		}
+		version := d.Tag
+		if version == "" {
+			version = d.Hash[:8]
+		}
+		matrix[d.Component][d.Env] = version
+	}
+	// The last status reported for a component in an environment is shown
+	// unless it's the successful deployment of what's there
+	last := map[string]map[string]release.Deployment{}
+	for _, d := range deployments {
+		rel := rm.FindRelease(d.Tag)
+		if rel == nil {
+			continue
+		}
+		if !containsString(envs, d.Env) {
+			envs = append(envs, d.Env)
+		}
+		if _, ok := matrix[rel.Component()]; !ok {
+			matrix[rel.Component()] = map[string]string{}
+			components = append(components, rel.Component())
+		}
+		if last[rel.Component()] == nil {
+			last[rel.Component()] = map[string]release.Deployment{}
+		}
+		last[rel.Component()][d.Env] = d
+	}
+	for component, byEnv := range last {
+		for env, d := range byEnv {
+			if d.Status == "success" && matrix[component][env] == d.Tag {
+				continue
+			}
+			status := fmt.Sprintf("%s %s", d.Tag, d.Status)
+			if matrix[component][env] != "" {
+				status = fmt.Sprintf("%s (%s)", matrix[component][env], status)
+			}
+			matrix[component][env] = status
+		}
+	}
+	sort.Strings(components)
+
+	// The rollout of each component's newest release, for whoever rolls it
+	// out next
+	rollouts := map[string]string{}
+	for _, rel := range rm.Releases() {
+		if _, seen := rollouts[rel.Component()]; !seen {
+			rollouts[rel.Component()] = ""
+			if r := rel.Rollout(); r != nil {
+				rollouts[rel.Component()] = fmt.Sprintf("%s: %s", rel.Tag, r)
+			}
+		}
+	}
+	showRollouts := false
+	for _, component := range components {
+		showRollouts = showRollouts || rollouts[component] != ""
+	}
+
+	headers := []string{"COMPONENT"}
+	for _, env := range envs {
+		headers = append(headers, strings.ToUpper(env))
+	}
+	if showRollouts {
+		headers = append(headers, "ROLLOUT")
+	}
+	t := newTable(headers...).color(0, colorCyan)
+	for _, component := range components {
+		row := []string{component}
+		for _, env := range envs {
+			version := matrix[component][env]
+			if version == "" {
+				version = "-"
+			}
+			row = append(row, version)
+		}
+		if showRollouts {
+			row = append(row, rollouts[component])
+		}
+		t.row(row...)
+	}
+	t.render(os.Stdout)
+}
+
+func containsString(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func compareEnvsMain(args []string) {
+	var from, to, remote, sshKeyPath string
+	var verbose, fetch bool
+	fs := flag.NewFlagSet("compare-envs", flag.ExitOnError)
+	fs.StringVar(&from, "from", "staging", "the environment being promoted")
+	fs.StringVar(&to, "to", "production", "the environment being promoted to")
+	fs.BoolVar(&fetch, "fetch", false, "fetch the environment refs from the remote first")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to fetch from (if --fetch)")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	if fetch {
+		release.CheckIfError(rm.FetchEnvironments(remote, authForRemote(rm, remote, sshKeyPath)), fmt.Sprintf("failed to fetch environments from %s", remote))
+	}
+	drifts, err := rm.CompareEnvironments(from, to)
+	release.CheckIfError(err, fmt.Sprintf("failed to compare %s and %s", from, to))
+	if len(drifts) == 0 {
+		say(fmt.Sprintf("no components tracked in %s or %s", from, to), "from", from, "to", to)
+		return
+	}
+
+	for _, d := range drifts {
+		switch {
+		case d.From == nil:
+			say(fmt.Sprintf("%s: only in %s (%s)", d.Component, to, envVersion(d.To)), "component", d.Component, "status", "only_in_"+to, to, envVersion(d.To))
+		case d.To == nil:
+			say(fmt.Sprintf("%s: not in %s yet, %s has %s", d.Component, to, from, envVersion(d.From)), "component", d.Component, "status", "missing_in_"+to, from, envVersion(d.From))
+		case d.InSync():
+			say(fmt.Sprintf("%s: %s (%s)", colorize(colorCyan, d.Component), colorize(colorGreen, "in sync"), envVersion(d.From)), "component", d.Component, "status", "in_sync", from, envVersion(d.From), to, envVersion(d.To))
+		default:
+			line := fmt.Sprintf("%s: %s %s, %s %s (%s", colorize(colorCyan, d.Component), from, envVersion(d.From), to, envVersion(d.To), colorize(colorYellow, fmt.Sprintf("%d commit(s) to ship", len(d.Pending))))
+			if d.Behind > 0 {
+				line += fmt.Sprintf(", %s", colorize(colorRed, fmt.Sprintf("%s is missing %d commit(s) from %s", from, d.Behind, to)))
+			}
+			say(line+")", "component", d.Component, "status", "drift", from, envVersion(d.From), to, envVersion(d.To), "pending", len(d.Pending), "behind", d.Behind)
+			for _, c := range d.Pending {
+				say(fmt.Sprintf("  * %s %s", colorize(colorYellow, c.Hash[:8]), release.Subject(c.Message)), "component", d.Component, "commit", c.Hash[:8], "subject", release.Subject(c.Message))
+			}
+		}
+	}
+}
+
+// envVersion returns the tag, or the short hash if the commit isn't a release
+func envVersion(e *release.EnvRelease) string {
+	if e.Tag != "" {
+		return e.Tag
+	}
+	return e.Hash[:8]
+}
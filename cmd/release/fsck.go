@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func fsckTagsMain(args []string) {
+	var asJSON, verbose bool
+	fs := flag.NewFlagSet("fsck-tags", flag.ExitOnError)
+	fs.BoolVar(&asJSON, "json", false, "print the problems as json")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release fsck-tags [--json]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	problems, err := rm.CheckTags()
+	release.CheckIfError(err, "failed to check the tags")
+	if asJSON {
+		writeJSON(problems)
+	} else if len(problems) == 0 {
+		say("no problems found")
+	} else {
+		t := newTable("TAG", "TARGET", "PROBLEM").color(0, colorCyan).color(1, colorYellow)
+		for _, p := range problems {
+			t.row(p.Tag, p.Target[:8], p.Problem)
+		}
+		t.render(os.Stdout)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// gateOptions is how whoever releases answered the release gates up front
+type gateOptions struct {
+	by             string            // Who acknowledges, see releasedBy
+	overrideFreeze string            // Why releasing in a freeze window can't wait
+	overrideDCO    string            // Why commits that aren't signed off can be released
+	ackBreaking    bool              // Release breaking (or unchecked) changes
+	ackLint        bool              // Release commits breaking the lint rules
+	ackLeadTime    bool              // Don't ask about the lead time
+	allowSkew      bool              // Release even if clock_skew refuses
+	acks           []string          // Acknowledged checklist items
+	targets        map[string]string // By tag, the revision it tags, HEAD if missing
+	requireBranch  bool              // Refuse a detached HEAD
+	allowDetached  bool              // Even with require_branch
+	dryRun         bool              // Only report, don't ask
+	unattended     bool              // Nobody to ask, like the daemon
+}
+
+// gates is what the release gates record in the message of each release
+type gates struct {
+	by              string
+	freezeOverrides map[string]string   // By component
+	breaking        map[string]bool     // By tag, acknowledged
+	linted          map[string]bool     // By tag, with violations
+	lintBlocks      bool                // Violations had to be acknowledged
+	dcoOverride     string              // The reason of --override-dco
+	dcoOverridden   map[string]bool     // By tag
+	checklists      map[string][]string // By tag, the acknowledged items
+}
+
+// checkGates runs every check new releases have to pass: a detached HEAD,
+// clock skew, freeze windows, the lead time, breaking changes (including the Go API),
+// commit message lint, sign-offs and checklists. components and newReleases
+// go together. Refusing is fatal.
+func checkGates(rm *release.Manager, cfg *release.Config, components, newReleases []string, opts gateOptions) *gates {
+	g, err := runGates(rm, cfg, components, newReleases, opts)
+	if err != nil {
+		log.Fatal().Msg(err.Error())
+	}
+	return g
+}
+
+// runGates is checkGates returning why the releases are refused, nothing is
+// asked when unattended
+func runGates(rm *release.Manager, cfg *release.Config, components, newReleases []string, opts gateOptions) (*gates, error) {
+	detached, head, err := rm.DetachedHead()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if detached && !opts.allowDetached {
+		if opts.requireBranch || cfg.RequireBranch {
+			return nil, fmt.Errorf("HEAD is detached at %s, check out a branch or use --allow-detached", head[:8])
+		}
+		log.Info().Msgf("HEAD is detached at %s, the release won't be tied to a branch (use --require-branch to refuse this)", head[:8])
+	}
+
+	if err := checkClockSkew(rm, cfg.ClockSkew, opts.allowSkew || opts.dryRun); err != nil {
+		return nil, err
+	}
+
+	g := &gates{by: opts.by, freezeOverrides: map[string]string{}, dcoOverride: opts.overrideDCO, lintBlocks: cfg.Lint.Blocks()}
+	now := time.Now()
+	for _, component := range components {
+		window := cfg.ActiveFreeze(component, now)
+		if window == nil {
+			continue
+		}
+		if opts.overrideFreeze == "" {
+			return nil, fmt.Errorf("component %s is frozen by %s, use --override-freeze with a reason if this release can't wait", component, window)
+		}
+		log.Warn().Msgf("overriding freeze %s for component %s: %s", window.Name, component, opts.overrideFreeze)
+		g.freezeOverrides[component] = fmt.Sprintf("%s: %s", window.Name, opts.overrideFreeze)
+	}
+	ask := !opts.unattended && terminal.IsTerminal(int(os.Stdin.Fd()))
+	if err := checkLeadTime(cfg, components, now, opts.ackLeadTime || opts.dryRun, ask); err != nil {
+		return nil, err
+	}
+	if g.breaking, err = checkBreaking(rm, cfg, newReleases, opts.targets, opts.ackBreaking || opts.dryRun, ask); err != nil {
+		return nil, err
+	}
+	if g.linted, err = checkLint(rm, cfg, components, newReleases, opts.targets, opts.ackLint || opts.dryRun); err != nil {
+		return nil, err
+	}
+	if g.dcoOverridden, err = checkDCO(rm, cfg, components, newReleases, opts.targets, opts.overrideDCO, opts.dryRun); err != nil {
+		return nil, err
+	}
+	if g.checklists, err = checkChecklists(rm, cfg, newReleases, opts.acks, opts.dryRun, ask); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// checkClockSkew warns about releases dated after the local clock, the new
+// release would get a date before theirs. It's refused with clock_skew.refuse.
+func checkClockSkew(rm *release.Manager, cfg release.ClockSkewConfig, allow bool) error {
+	skew := rm.CheckClockSkew(time.Now(), cfg)
+	if skew == nil {
+		return nil
+	}
+	if cfg.Refuse && !allow {
+		return fmt.Errorf("clock skew: %s, fix the clock or use --allow-clock-skew", skew)
+	}
+	log.Warn().Str("tag", skew.Tag).Msgf("clock skew: %s, the new release will be dated before it and may look out of order", skew)
+	return nil
+}
+
+// annotate adds what the gates recorded about a release to its message, only
+// frozen components and releases with commits that aren't signed off record
+// the overrides
+func (g *gates) annotate(message, component, newRelease string) string {
+	if override, ok := g.freezeOverrides[component]; ok {
+		message = release.AppendTrailer(message, "Freeze-Override", override)
+	}
+	if g.breaking[newRelease] {
+		message = release.AppendTrailer(message, release.BreakingTrailer, "acknowledged by "+g.by)
+	}
+	if g.linted[newRelease] && g.lintBlocks {
+		message = release.AppendTrailer(message, release.LintTrailer, g.by)
+	}
+	if g.dcoOverridden[newRelease] {
+		message = release.AppendTrailer(message, release.DCOOverrideTrailer, fmt.Sprintf("%s (by %s)", g.dcoOverride, g.by))
+	}
+	for _, item := range g.checklists[newRelease] {
+		message = release.AppendTrailer(message, release.ChecklistTrailer, item)
+	}
+	return message
+}
+
+// checkTrust loads the trusted keys and warns about release tags that aren't
+// signed by one, they're an error with untrusted: fail
+func checkTrust(rm *release.Manager, cfg release.TrustConfig) error {
+	untrusted, err := rm.ApplyTrust(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted keys: %w", err)
+	}
+	return untrustedError(untrusted, cfg)
+}
+
+// untrustedError warns about the untrusted tags, they're an error with
+// untrusted: fail
+func untrustedError(untrusted map[string]error, cfg release.TrustConfig) error {
+	tags := []string{}
+	for tag := range untrusted {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		log.Warn().Msgf("ignoring untrusted tag %s: %s", tag, untrusted[tag])
+	}
+	if len(tags) > 0 && cfg.Untrusted == "fail" {
+		return fmt.Errorf("found %d untrusted release tag(s), remove them or sign them with a trusted key", len(tags))
+	}
+	return nil
+}
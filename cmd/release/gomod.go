@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// goModuleTags works out the Go module tag for every new release of a
+// component that is a Go module, returning release -> go tag and release ->
+// module path. Misconfigured modules are fatal so nothing gets tagged.
+func goModuleTags(rm *release.Manager, cfg release.GoConfig, newReleases []string) (map[string]string, map[string]string) {
+	tags := map[string]string{}
+	modules := map[string]string{}
+	for _, newRelease := range newReleases {
+		rel := rm.ParseRelease(newRelease)
+		mod, ok := cfg.Modules[rel.Component()]
+		if !ok {
+			continue
+		}
+		goTag, modulePath, err := rm.GoTag(newRelease, mod)
+		release.CheckIfError(err, fmt.Sprintf("can't tag go module for %s", newRelease))
+		tags[newRelease] = goTag
+		modules[newRelease] = modulePath
+	}
+	return tags, modules
+}
+
+// pushGoTag pushes the go module tag with push and optionally warms up the
+// module proxy, failures are only logged since the CalVer tag is already out
+func pushGoTag(goTag, modulePath string, push func(tag string) (string, error), warmup bool) {
+	msg, err := push(goTag)
+	if err != nil {
+		logError(err, msg)
+		return
+	}
+	say(msg, "tag", goTag)
+	if !warmup {
+		return
+	}
+	if err := release.WarmGoProxy(modulePath, goTag); err != nil {
+		log.Warn().Err(err).Msgf("failed to warm up the go module proxy for %s@%s", modulePath, goTag)
+		return
+	}
+	log.Info().Msgf("requested %s from the go module proxy", goTag)
+}
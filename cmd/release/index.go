@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func indexMain(args []string) {
+	var drop, verbose bool
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	fs.BoolVar(&drop, "drop", false, "remove the index, tags are read from git again")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release index [--drop]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	if drop {
+		release.CheckIfError(rm.DropIndex(), "failed to remove the index")
+		say(fmt.Sprintf("removed %s", rm.IndexPath()), "file", rm.IndexPath())
+		return
+	}
+	start := time.Now()
+	count, err := rm.BuildIndex()
+	release.CheckIfError(err, "failed to build the index")
+	took := time.Since(start).Round(time.Millisecond)
+	say(fmt.Sprintf("indexed %d release(s) in %s in %s", count, rm.IndexPath(), took), "count", count, "file", rm.IndexPath(), "took", took)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+func initMain(args []string) {
+	var yes, force, stdout, verbose bool
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.BoolVarP(&yes, "yes", "y", false, "accept what was detected without asking")
+	fs.BoolVar(&force, "force", false, fmt.Sprintf("overwrite an existing %s", release.ConfigFile))
+	fs.BoolVar(&stdout, "stdout", false, "print the config instead of writing it")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release init [--yes] [--force] [--stdout] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	output := filepath.Join(rm.RepoDir(), release.ConfigFile)
+	if _, err := os.Stat(output); err == nil && !force && !stdout {
+		log.Fatal().Msgf("%s already exists, use --force to overwrite it", release.ConfigFile)
+	}
+	d, err := rm.DetectConfig()
+	release.CheckIfError(err, "failed to inspect the repository")
+
+	log.Info().Msgf("found %d release(s) using %s", d.Schemes[d.Scheme], d.Scheme)
+	if len(d.Schemes) > 1 {
+		log.Warn().Msgf("the releases use %d schemes, the newest one is suggested", len(d.Schemes))
+	}
+	if len(d.Other) > 0 {
+		log.Info().Msgf("ignoring %d tag(s) that aren't CalVer, like %s", len(d.Other), d.Other[0])
+	}
+	if d.Forge != "" {
+		log.Info().Msgf("the remote is on %s", d.Forge)
+	}
+
+	if !yes && !stdout && terminal.IsTerminal(int(os.Stdin.Fd())) {
+		askDetection(d)
+	}
+
+	data := d.YAML()
+	if stdout {
+		fmt.Print(string(data))
+		return
+	}
+	release.CheckIfError(ioutil.WriteFile(output, data, 0644), fmt.Sprintf("failed to write %s", output))
+	log.Info().Msgf("wrote %s, check it with 'release config validate'", release.ConfigFile)
+}
+
+// askDetection lets the user correct what was detected, an empty answer keeps
+// the suggestion
+func askDetection(d *release.Detection) {
+	in := bufio.NewReader(os.Stdin)
+	ask := func(question, suggestion string) string {
+		fmt.Fprintf(os.Stderr, "%s [%s] ", question, suggestion)
+		answer, _ := in.ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "" {
+			return answer
+		}
+		return suggestion
+	}
+	for {
+		scheme := release.Scheme(ask(i18n.T("version scheme (YYYY.MM.RRR or YYYY.DDD.N)?"), string(d.Scheme)))
+		if err := (&release.Config{Scheme: scheme}).Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		d.Scheme = scheme
+		break
+	}
+	names := []string{}
+	for name := range d.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer := ask(i18n.T("paths of component %s (comma separated, - to leave it out)?", name), strings.Join(d.Components[name], ","))
+		if answer == "-" {
+			delete(d.Components, name)
+			continue
+		}
+		paths := []string{}
+		for _, p := range strings.Split(answer, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+		d.Components[name] = paths
+	}
+}
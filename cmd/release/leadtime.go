@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// checkLeadTime warns about releasing the components at now, right before a
+// freeze window or outside business hours. With lead_time.confirm the user is
+// asked unless acknowledged, when they can't be asked the release is refused.
+func checkLeadTime(cfg *release.Config, components []string, now time.Time, acknowledged, ask bool) error {
+	seen := map[string]bool{}
+	for _, component := range components {
+		for _, warning := range cfg.LeadTimeWarnings(component, now) {
+			if !seen[warning] {
+				log.Warn().Msg(colorize(colorBold+colorYellow, warning))
+			}
+			seen[warning] = true
+		}
+	}
+	if len(seen) == 0 || !cfg.LeadTime.Confirm || acknowledged {
+		return nil
+	}
+	if !ask {
+		return fmt.Errorf("refusing to release this close to a freeze or outside business hours, use --acknowledge-lead-time")
+	}
+	fmt.Fprint(os.Stderr, i18n.T("release anyway? [y/N] "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return fmt.Errorf("not releasing")
+	}
+	return nil
+}
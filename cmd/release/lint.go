@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// checkLint reports the commits of the new releases whose messages break the
+// lint rules and returns the releases that have any. With the block policy
+// they're refused unless acknowledged, releases that couldn't be checked too.
+func checkLint(rm *release.Manager, cfg *release.Config, components, newReleases []string, targets map[string]string, acknowledged bool) (map[string]bool, error) {
+	violating := map[string]bool{}
+	unchecked := 0
+	for idx, newRelease := range newReleases {
+		if !cfg.Lint.Enabled(components[idx]) {
+			continue
+		}
+		violations, err := rm.LintCommits(newRelease, targets[newRelease], cfg.Lint, cfg.Components[components[idx]])
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to lint the commits of %s", newRelease))
+			unchecked++
+			continue
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		violating[newRelease] = true
+		log.Warn().Msgf("%s contains %d commit(s) breaking the lint rules:", newRelease, len(violations))
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "  * %s %s (%s)\n", v.Commit.Hash[:8], release.Subject(v.Commit.Message), strings.Join(v.Problems, ", "))
+		}
+	}
+	if !cfg.Lint.Blocks() || (len(violating) == 0 && unchecked == 0) || acknowledged {
+		return violating, nil
+	}
+	return nil, fmt.Errorf("refusing to release commits breaking the lint rules, fix them or use --acknowledge-lint")
+}
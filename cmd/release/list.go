@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/internal/filter"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// listOptions are shared by list and export
+type listOptions struct {
+	filter     string
+	components []string
+	from       string
+	team       string
+	since      string
+	limit      int
+	changes    bool
+	touching   []string
+	verbose    bool
+}
+
+func (o *listOptions) register(fs *flag.FlagSet) {
+	fs.StringVarP(&o.filter, "filter", "f", "", `only show releases matching the expression, e.g. 'component == "api" && date > "2020-01-01"'`)
+	fs.StringArrayVarP(&o.components, "component", "c", []string{}, "only show releases of this component (can be repeated)")
+	fs.StringVar(&o.from, "released-from", "", "only show releases created by a CI job (ci) or by hand (human)")
+	fs.StringVar(&o.team, "team", "", "only show releases of components owned by this team (from .release.yaml or CODEOWNERS)")
+	fs.StringVar(&o.since, "since", "", "only show releases since this day (YYYY-MM-DD), year (YYYY) or age like 30d")
+	fs.IntVar(&o.limit, "limit", 0, "show at most this many releases (0 for all)")
+	fs.BoolVar(&o.changes, "changes", false, "include the files each release changed since the one before (slower)")
+	fs.StringArrayVar(&o.touching, "touching", []string{}, "only show releases that changed this file or directory (can be repeated, slower)")
+	fs.BoolVarP(&o.verbose, "verbose", "v", false, "enable more output")
+}
+
+// releases loads the releases matching the options, newest first. The
+// manager has component ownership loaded.
+func (o *listOptions) releases() (*release.Manager, []release.Release) {
+	var expr *filter.Expr
+	if o.filter != "" {
+		var err error
+		expr, err = filter.Parse(o.filter)
+		release.CheckIfError(err, "invalid --filter")
+	}
+	if o.from != "" && o.from != "ci" && o.from != "human" {
+		log.Fatal().Msgf("--released-from must be ci or human, not '%s'", o.from)
+	}
+	var since time.Time
+	if o.since != "" {
+		var err error
+		if since, err = time.ParseInLocation("2006", o.since, time.Local); err != nil {
+			since, err = parseDay(o.since, time.Now())
+			release.CheckIfError(err, "invalid --since")
+		}
+	}
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.LoadOwnership(repoCfg.Components), "failed to load CODEOWNERS")
+	release.CheckIfError(rm.UseMetadata(repoCfg.Metadata), "failed to open the metadata store")
+	matched := []release.Release{}
+	for _, rel := range rm.Releases() {
+		if len(o.components) > 0 && !containsString(o.components, rel.Component()) {
+			continue
+		}
+		if o.team != "" && !release.TeamMatches(rm.Teams(rel.Component()), o.team) {
+			continue
+		}
+		if o.from != "" && rel.ReleasedFrom() != o.from {
+			continue
+		}
+		if rel.ReleasedBy().When.Before(since) {
+			continue
+		}
+		if expr != nil {
+			ok, err := expr.Match(&rel)
+			release.CheckIfError(err, fmt.Sprintf("failed to evaluate --filter on %s", rel.Tag))
+			if !ok {
+				continue
+			}
+		}
+		if o.changes || len(o.touching) > 0 {
+			release.CheckIfError(rm.LoadChanges(&rel), fmt.Sprintf("failed to work out the files %s changed", rel.Tag))
+		}
+		if len(o.touching) > 0 && !touchesAny(&rel, o.touching) {
+			continue
+		}
+		matched = append(matched, rel)
+		if o.limit > 0 && len(matched) >= o.limit {
+			break
+		}
+	}
+	return rm, matched
+}
+
+func touchesAny(rel *release.Release, paths []string) bool {
+	for _, p := range paths {
+		if rel.Touches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	release.CheckIfError(enc.Encode(v), "failed to write json")
+}
+
+func exportReleases(rm *release.Manager, releases []release.Release) []release.Export {
+	deployments, err := rm.Deployments()
+	release.CheckIfError(err, "failed to load deployment statuses")
+	latest := release.LatestDeployments(deployments)
+	exports := []release.Export{}
+	for _, rel := range releases {
+		e := rel.Export()
+		e.Teams = rm.Teams(rel.Component())
+		for _, env := range sortedEnvs(latest[rel.Tag]) {
+			e.Deployments = append(e.Deployments, latest[rel.Tag][env])
+		}
+		exports = append(exports, e)
+	}
+	return exports
+}
+
+func sortedEnvs(byEnv map[string]release.Deployment) []string {
+	envs := []string{}
+	for env := range byEnv {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// deploymentSummary is the DEPLOYED column of a release, like
+// "prod:success staging:failure"
+func deploymentSummary(byEnv map[string]release.Deployment) string {
+	parts := []string{}
+	for _, env := range sortedEnvs(byEnv) {
+		parts = append(parts, fmt.Sprintf("%s:%s", env, byEnv[env].Status))
+	}
+	return strings.Join(parts, " ")
+}
+
+func listMain(args []string) {
+	opts := &listOptions{}
+	var asJSON bool
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	opts.register(fs)
+	fs.BoolVar(&asJSON, "json", false, "print the releases as json (same as export)")
+	parseFlags(fs, args)
+	setupLogging(opts.verbose)
+
+	rm, releases := opts.releases()
+	if asJSON {
+		writeJSON(exportReleases(rm, releases))
+		return
+	}
+	// The team column is only shown for repos with ownership set up
+	showTeams := false
+	for _, rel := range releases {
+		if len(rm.Teams(rel.Component())) > 0 {
+			showTeams = true
+			break
+		}
+	}
+	// So is the deployment column for repos with deployments recorded
+	deployments, err := rm.Deployments()
+	release.CheckIfError(err, "failed to load deployment statuses")
+	latest := release.LatestDeployments(deployments)
+	headers := []string{"TAG", "DATE", "RELEASED BY"}
+	if showTeams {
+		headers = append(headers, "TEAM")
+	}
+	if len(deployments) > 0 {
+		headers = append(headers, "DEPLOYED")
+	}
+	if opts.changes {
+		headers = append(headers, "FILES")
+	}
+	t := newTable(append(headers, "MESSAGE")...).color(0, colorCyan).color(1, colorGray)
+	for _, rel := range releases {
+		by := rel.ReleasedBy()
+		cells := []string{rel.Tag, by.When.Format("2006-01-02 15:04"), by.Name}
+		if showTeams {
+			cells = append(cells, strings.Join(rm.Teams(rel.Component()), ","))
+		}
+		if len(deployments) > 0 {
+			cells = append(cells, deploymentSummary(latest[rel.Tag]))
+		}
+		if opts.changes {
+			added, deleted := diffstatTotals(rel.Changes())
+			cells = append(cells, fmt.Sprintf("%d +%d -%d", len(rel.Changes()), added, deleted))
+		}
+		t.row(append(cells, release.Subject(rel.Message()))...)
+	}
+	t.render(os.Stdout)
+}
+
+func exportMain(args []string) {
+	opts := &listOptions{}
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	opts.register(fs)
+	parseFlags(fs, args)
+	setupLogging(opts.verbose)
+	writeJSON(exportReleases(opts.releases()))
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/user"
-	"release"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/fernferret/release/pkg/release/forge"
+	"github.com/fernferret/release/pkg/release/notify"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	go_git_ssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	flag "github.com/spf13/pflag"
@@ -20,10 +27,56 @@ import (
 
 const (
 	incrementFormat = "%03d"
+	dateFormat      = "%Y.%m."
 )
 
+// commands are the subcommands that can be given as the first argument,
+// anything else is treated as a component to release
+var commands = map[string]func(args []string){
+	"verify":         verifyMain,
+	"prune":          pruneMain,
+	"mark":           markMain,
+	"publish":        publishMain,
+	"status":         statusMain,
+	"compare-envs":   compareEnvsMain,
+	"list":           listMain,
+	"export":         exportMain,
+	"show":           showMain,
+	"search":         searchMain,
+	"bisect":         bisectMain,
+	"compare":        compareMain,
+	"sort":           sortMain,
+	"supersede":      supersedeMain,
+	"diff":           diffMain,
+	"tui":            tuiMain,
+	"checksums":      checksumsMain,
+	"build-matrix":   buildMatrixMain,
+	"verify-assets":  verifyAssetsMain,
+	"apply":          applyMain,
+	"plan":           planMain,
+	"schedule":       scheduleMain,
+	"daemon":         daemonMain,
+	"bundle":         bundleMain,
+	"config":         configMain,
+	"schema":         schemaMain,
+	"init":           initMain,
+	"migrate-scheme": migrateSchemeMain,
+	"auth":           authMain,
+	"changelog":      changelogMain,
+	"report":         reportMain,
+	"audit":          auditMain,
+	"index":          indexMain,
+	"fsck-tags":      fsckTagsMain,
+	"bench":          benchMain,
+	"deployed":       deployedMain,
+	"serve":          serveMain,
+}
+
 var version = "dev"
 
+// commit is the commit the binary was built from, set with ldflags
+var commit = ""
+
 func loadKeys(path string) transport.AuthMethod {
 	var auth transport.AuthMethod
 	sshKey, _ := ioutil.ReadFile(path)
@@ -32,6 +85,205 @@ func loadKeys(path string) transport.AuthMethod {
 	return auth
 }
 
+// ciCredential is the short-lived token of the CI job, see setupOIDC
+var (
+	ciCredential *release.CICredential
+	ciOIDC       release.OIDCConfig
+)
+
+// setupOIDC exchanges the CI job's OIDC ID token for short-lived credentials
+// when oidc is configured and we're running in CI
+func setupOIDC(rm *release.Manager, cfg *release.Config) {
+	if !cfg.OIDC.Enabled() || release.DetectCI() == nil || release.Offline() {
+		return
+	}
+	cred, err := release.OIDCCredential(cfg.OIDC)
+	release.CheckIfError(err, "failed to get OIDC credentials for the CI job")
+	if cfg.OIDC.TokenFile != "" {
+		log.Info().Msgf("wrote the CI job's ID token to %s", cfg.OIDC.TokenFile)
+	}
+	if cred.Token == "" {
+		return
+	}
+	cred.Host = cfg.OIDC.Host
+	if cred.Host == "" {
+		if url, err := rm.RemoteURL("origin"); err == nil {
+			if repo, err := forge.ParseRemoteURL(url); err == nil {
+				cred.Host = repo.Host
+			}
+		}
+	}
+	if cred.Host == "" {
+		log.Warn().Msg("can't tell which host the CI job's token is for, set oidc.host in the config, using the git credential helpers for pushes")
+	}
+	ciCredential, ciOIDC = cred, cfg.OIDC
+	rm.ForgeToken = cred.Token
+	if cred.Expires.IsZero() {
+		log.Info().Msg("using a short-lived token from the CI job's ID token")
+	} else {
+		log.Info().Msgf("using a short-lived token from the CI job's ID token, it expires at %s", cred.Expires.Format(time.RFC3339))
+	}
+}
+
+// filledCredentials are the credentials the git credential helpers gave us,
+// see settleCredentials
+var filledCredentials []*release.Credential
+
+// authForURL returns the ssh keys for ssh remotes. http(s) remotes get the CI
+// job's token when they're on its host, otherwise what the git credential
+// helpers have for them, or no auth so public repos can still be cloned.
+func authForURL(url, sshKeyPath string) transport.AuthMethod {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		if ciCredential != nil && ciCredential.IsFor(url) {
+			return ciCredential.Auth(ciOIDC, url)
+		}
+		cred, err := release.CredentialFill(url)
+		if err != nil {
+			log.Debug().Err(err).Msgf("failed to ask the git credential helpers about %s", url)
+		}
+		if cred == nil {
+			return nil
+		}
+		filledCredentials = append(filledCredentials, cred)
+		return cred.Auth()
+	}
+	return loadKeys(sshKeyPath)
+}
+
+// authForRemote is authForURL for a configured remote
+func authForRemote(rm *release.Manager, remote, sshKeyPath string) transport.AuthMethod {
+	url, err := rm.RemoteURL(remote)
+	if err != nil {
+		return loadKeys(sshKeyPath)
+	}
+	return authForURL(url, sshKeyPath)
+}
+
+// settleCredentials tells the git credential helpers whether the credentials
+// they gave us worked, like git does after a push
+func settleCredentials(err error) {
+	for _, cred := range filledCredentials {
+		var settleErr error
+		if err == nil {
+			settleErr = cred.Approve()
+		} else if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+			settleErr = cred.Reject()
+		}
+		if settleErr != nil {
+			log.Debug().Err(settleErr).Msgf("failed to update the git credential helpers for %s", cred.Host)
+		}
+	}
+}
+
+// releasedBy formats the user for notifications, leaving out whatever isn't
+// configured
+func releasedBy(user, email string) string {
+	switch {
+	case user != "" && email != "":
+		return fmt.Sprintf("%s <%s>", user, email)
+	case user != "":
+		return user
+	case email != "":
+		return email
+	}
+	return "unknown"
+}
+
+// useMetadata switches rm to the metadata store configured in
+// .release.yaml
+func useMetadata(rm *release.Manager) {
+	cfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.UseMetadata(cfg.Metadata), "failed to open the metadata store")
+}
+
+// audit records an event in the audit log. Whatever it records already
+// happened so failing to is only logged.
+func audit(rm *release.Manager, e release.AuditEvent, user, email string) {
+	if e.By == "" {
+		e.By = releasedBy(user, email)
+	}
+	sig := object.Signature{Name: user, Email: email, When: time.Now()}
+	if err := rm.Audit(e, sig); err != nil {
+		logError(err, fmt.Sprintf("failed to record %s of %s in the audit log", e.Action, e.Tag))
+	}
+}
+
+// logError logs a non-fatal error along with its remediation hint, if it has
+// one
+func logError(err error, msg string) {
+	event := log.Error().Err(err)
+	if hint := release.Hint(err); hint != "" {
+		event = event.Str("hint", hint)
+	}
+	event.Msg(msg)
+}
+
+// closeReleaseMilestone closes the milestone of a pushed release, the release
+// is out so failing to is only logged
+func closeReleaseMilestone(f forge.Forge, m forge.Milestone, tag string) {
+	moved, next, err := release.CloseMilestone(f, m)
+	if moved > 0 {
+		say(fmt.Sprintf("moved %d open issue(s) from milestone %s to %s", moved, m.Title, next.Title), "milestone", m.Title, "next", next.Title, "moved", moved)
+	}
+	if err != nil {
+		logError(err, fmt.Sprintf("failed to close milestone %s of %s", m.Title, tag))
+		return
+	}
+	say(fmt.Sprintf("closed milestone %s", m.Title), "milestone", m.Title, "tag", tag, "url", m.URL)
+}
+
+// createDraftRelease creates the draft forge release of a pushed tag, the tag
+// is out so failing to is only logged
+func createDraftRelease(rm *release.Manager, f forge.Forge, compCfg *release.Config, component release.ComponentConfig, remote, tag string) {
+	links, err := rm.NoteLinker(remote, compCfg.Forge)
+	if err != nil {
+		log.Debug().Err(err).Msgf("can't link the notes of %s to the forge", tag)
+	}
+	rm.Links = links
+	rel, err := rm.CreateDraftRelease(f, tag, component)
+	if err != nil {
+		logError(err, fmt.Sprintf("failed to create the draft release of %s", tag))
+		return
+	}
+	say(fmt.Sprintf("created draft release %s, publish it with 'release publish %s' once the notes are edited", rel.URL, tag), "tag", tag, "url", rel.URL, "draft", true)
+}
+
+// createViaAPI creates a tag through the forge API instead of pushing it, for
+// --via-api or when the remote rejected the push and a token may be allowed to
+// create tags the git credentials can't
+func createViaAPI(rm *release.Manager, cfg forge.Config, remote, tag string) (string, error) {
+	f, err := rm.Forge(remote, cfg)
+	if err != nil {
+		return fmt.Sprintf("can't retry tag %s through the forge API", tag), err
+	}
+	if err := rm.CreateTagViaForge(f, tag); err != nil {
+		return fmt.Sprintf("failed to create tag %s through the %s API", tag, f.Name()), err
+	}
+	return fmt.Sprintf("created tag %s on remote %s through the %s API, fetch it with 'git fetch --tags --force' if it's annotated", tag, remote, f.Name()), nil
+}
+
+// gitIdentity fills in the user and email from ~/.gitconfig unless they were
+// given on the command line
+func gitIdentity(user, email string) (string, string) {
+	cfg, err := config.LoadConfig(config.GlobalScope)
+	if err != nil {
+		// At this point, we might be in a CI environment and might not have gitconfig
+		// setup. If we're not using heavy tags, we don't even care about this error,
+		// so we'll log a warning (only visible at debug) and if the user tries to create
+		// an annotated tag, we'll deal with it then.
+		log.Debug().Err(err).Msg("unable to load git config, this is only a problem if you're using annotated tags")
+		return user, email
+	}
+	if user == "" {
+		user = cfg.User.Name
+	}
+	if email == "" {
+		email = cfg.User.Email
+	}
+	return user, email
+}
+
 func homeDir() string {
 	usr, err := user.Current()
 	if err != nil {
@@ -41,36 +293,329 @@ func homeDir() string {
 }
 
 func getVersionString() string {
+	if commit != "" {
+		return fmt.Sprintf("release %s (%s)", version, commit)
+	}
 	return fmt.Sprintf("release %s", version)
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: release [component] [options]\n\n")
+	fmt.Fprintf(os.Stderr, "usage: release [create] [component] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release list [--filter <expr>] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release export [--filter <expr>] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release show <tag> [options]\n")
+	fmt.Fprintf(os.Stderr, "       release search <words...> [--index] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release bisect --bad <tag> [--good <tag>] [--run <command>]\n")
+	fmt.Fprintf(os.Stderr, "       release compare <tag> <other-tag>\n")
+	fmt.Fprintf(os.Stderr, "       release sort [-r] < tags\n")
+	fmt.Fprintf(os.Stderr, "       release supersede <tag> [--target <rev>] [--push]\n")
+	fmt.Fprintf(os.Stderr, "       release publish <tag> [options]\n")
+	fmt.Fprintf(os.Stderr, "       release diff <from-tag> <to-tag> [options]\n")
+	fmt.Fprintf(os.Stderr, "       release plan [component...] [-o plan.yaml]\n")
+	fmt.Fprintf(os.Stderr, "       release schedule [component...] [-n 6] [--json]\n")
+	fmt.Fprintf(os.Stderr, "       release daemon [--cron <expr>] [--listen <addr>] [--once]\n")
+	fmt.Fprintf(os.Stderr, "       release apply <plan.yaml> [--push] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release bundle create -o <file> [tag...] [--since <tag>]\n")
+	fmt.Fprintf(os.Stderr, "       release bundle apply <file> [--push] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release init [--yes] [--stdout]\n")
+	fmt.Fprintf(os.Stderr, "       release config validate [--strict]\n")
+	fmt.Fprintf(os.Stderr, "       release config show [--effective] [-c <component>]\n")
+	fmt.Fprintf(os.Stderr, "       release schema [<document>] [--out <dir>]\n")
+	fmt.Fprintf(os.Stderr, "       release migrate-scheme [--alias|--retag] [--push]\n")
+	fmt.Fprintf(os.Stderr, "       release auth login|logout <forge|host|NAME>\n")
+	fmt.Fprintf(os.Stderr, "       release changelog --all|<component...> [-o <file>|--dir <dir>]\n")
+	fmt.Fprintf(os.Stderr, "       release deployed <tag> --env <env> --status <status> [--push]\n")
+	fmt.Fprintf(os.Stderr, "       release serve [--listen <addr>] [--push]\n")
+	fmt.Fprintf(os.Stderr, "       release report --since <date> [--until <date>] [--format markdown|html]\n")
+	fmt.Fprintf(os.Stderr, "       release audit [--tag <tag>] [--json]\n")
+	fmt.Fprintf(os.Stderr, "       release index [--drop]\n")
+	fmt.Fprintf(os.Stderr, "       release fsck-tags [--json]\n")
+	fmt.Fprintf(os.Stderr, "       release tui\n")
+	fmt.Fprintf(os.Stderr, "       release verify [options]\n")
+	fmt.Fprintf(os.Stderr, "       release checksums [--commit] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release build-matrix <tag> [-j N]\n")
+	fmt.Fprintf(os.Stderr, "       release verify-assets [-f SHA256SUMS] [options]\n")
+	fmt.Fprintf(os.Stderr, "       release prune [options]\n")
+	fmt.Fprintf(os.Stderr, "       release mark <tag> --env <env> [options]\n")
+	fmt.Fprintf(os.Stderr, "       release status [options]\n")
+	fmt.Fprintf(os.Stderr, "       release compare-envs [--from staging] [--to production] [options]\n\n")
 	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "      --no-color                 disable colors (also NO_COLOR), every command accepts this\n")
+	fmt.Fprintf(os.Stderr, "      --plain                    stable key=value lines without colors, alignment or progress, every command accepts this\n")
+	fmt.Fprintf(os.Stderr, "      --offline                  never use the network, fail anything that needs it, every command accepts this\n")
+	fmt.Fprintf(os.Stderr, "      --lang string              language of prompts, the TUI and reports (en or ja), default from LC_ALL, LC_MESSAGES or LANG\n")
+	fmt.Fprintf(os.Stderr, "      --proxy string             send https, ssh and API traffic through this http(s) or socks5 proxy, every command accepts this\n")
+	fmt.Fprintf(os.Stderr, "      --no-proxy string          comma separated hosts to reach without the proxy, like NO_PROXY\n")
+	fmt.Fprintf(os.Stderr, "      --ssh-proxy-command string connect to ssh remotes through this command like ssh's ProxyCommand (%%h and %%p are the host and port)\n")
+}
+
+func setupLogging(verbose bool) {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	if plain {
+		// level=info msg="..." key=value, without timestamps so the same run
+		// always logs the same lines
+		log.Logger = log.Output(zerolog.ConsoleWriter{
+			Out:        os.Stderr,
+			NoColor:    true,
+			PartsOrder: []string{zerolog.LevelFieldName, zerolog.MessageFieldName},
+			FormatLevel: func(i interface{}) string {
+				return fmt.Sprintf("level=%s", i)
+			},
+			FormatMessage: func(i interface{}) string {
+				if i == nil {
+					return ""
+				}
+				return fmt.Sprintf("msg=%s", plainValue(fmt.Sprint(i)))
+			},
+			FormatFieldName: func(i interface{}) string {
+				return fmt.Sprintf("%s=", i)
+			},
+			FormatErrFieldName: func(i interface{}) string {
+				return fmt.Sprintf("%s=", i)
+			},
+		})
+	}
+	// If we want UTC use this
+	// zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if verbose {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+}
+
+// openManager creates a release manager for the current directory, or for an
+// temporary clone if repoURL is set. With --verbose whatever remotes print
+// while pushing is shown as it arrives.
+func openManager(repoURL, sshKeyPath string) *release.Manager {
+	var rm *release.Manager
+	var err error
+	if repoURL != "" {
+		rm, err = release.CloneManager(repoURL, authForURL(repoURL, sshKeyPath), dateFormat, incrementFormat)
+		release.CheckIfError(err, fmt.Sprintf("failed to clone %s", repoURL))
+	} else {
+		cwd, err := os.Getwd()
+		release.CheckIfError(err, "failed to get current dir")
+		rm, err = release.NewManager(cwd, dateFormat, incrementFormat)
+		release.CheckIfError(err, "failed to load release manager")
+	}
+	// Fatal errors exit without running deferred calls, clones and worktrees
+	// shouldn't outlive them
+	log.Logger = log.Logger.Hook(zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, msg string) {
+		if level == zerolog.FatalLevel {
+			rm.Close()
+		}
+	}))
+	if zerolog.GlobalLevel() <= zerolog.DebugLevel && !plain {
+		rm.PushProgress = os.Stderr
+	}
+	return rm
+}
+
+// setupTLS applies the CA bundles and client certificates configured for git
+// to every https request. Broken settings only matter for https, so they're
+// a warning rather than failing commands that don't need the network.
+func setupTLS() {
+	if release.Offline() {
+		return
+	}
+	repoDir := ""
+	if cwd, err := os.Getwd(); err == nil {
+		if dir, err := release.FindRepoDir(cwd); err == nil {
+			repoDir = dir
+		}
+	}
+	global, hosts, err := release.GitTLSSettings(repoDir)
+	if err == nil {
+		err = release.UseTLS(global, hosts)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to apply the TLS settings from the git config, https requests use the system defaults")
+	}
+}
+
+// The global flags besides --no-color and --plain, see globalFlags
+var (
+	offline                               bool
+	lang, proxyURL, noProxy, proxyCommand string
+	globalsApplied                        bool
+)
+
+// globalFlags are accepted by every command, in front of it or among its own
+// flags. They're hidden from the commands' usage, usage lists them once.
+func globalFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("global", flag.ContinueOnError)
+	fs.BoolVar(&noColor, "no-color", noColor, "disable colors")
+	fs.BoolVar(&plain, "plain", plain, "stable key=value output")
+	fs.BoolVar(&offline, "offline", offline, "never use the network")
+	fs.StringVar(&lang, "lang", lang, "language of prompts, the TUI and reports")
+	fs.StringVar(&proxyURL, "proxy", proxyURL, "proxy for https, ssh and API traffic")
+	fs.StringVar(&noProxy, "no-proxy", noProxy, "hosts to reach without the proxy")
+	fs.StringVar(&proxyCommand, "ssh-proxy-command", proxyCommand, "connect to ssh remotes through this command")
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Hidden = true
+	})
+	return fs
+}
+
+// parseFlags parses the flags of a command along with the global flags, and
+// applies the global ones
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.AddFlagSet(globalFlags())
+	fs.Parse(args)
+	applyGlobalFlags()
+}
+
+// applyGlobalFlags switches to offline mode, the language and the proxy the
+// global flags asked for, once
+func applyGlobalFlags() {
+	if globalsApplied {
+		return
+	}
+	globalsApplied = true
+	if offline {
+		release.GoOffline()
+	}
+	if lang == "" {
+		lang = i18n.Detect()
+	}
+	release.CheckIfError(i18n.SetLanguage(lang), "invalid --lang")
+	if proxyURL != "" {
+		release.CheckIfError(release.UseProxy(proxyURL, noProxy), "failed to set up the proxy")
+	} else if noProxy != "" {
+		os.Setenv("NO_PROXY", noProxy)
+	}
+	if proxyCommand != "" {
+		release.UseSSHProxyCommand(proxyCommand)
+	}
+	setupTLS()
 }
 
 func main() {
+	// Global flags in front of the command are taken here, the ones after it
+	// are parsed along with the command's flags so their values and
+	// arguments after -- are left alone
+	args := os.Args[1:]
+	global := globalFlags()
+	for len(args) > 0 && args[0] != "--" {
+		name := strings.SplitN(strings.TrimPrefix(args[0], "--"), "=", 2)[0]
+		f := global.Lookup(name)
+		if !strings.HasPrefix(args[0], "--") || f == nil {
+			break
+		}
+		n := 1
+		if f.Value.Type() != "bool" && !strings.Contains(args[0], "=") {
+			n = 2
+		}
+		if len(args) < n {
+			log.Fatal().Msgf("--%s needs a value", name)
+		}
+		if err := global.Parse(args[:n]); err != nil {
+			log.Fatal().Err(err).Msgf("invalid --%s", name)
+		}
+		args = args[n:]
+	}
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			warnShadowedComponent(args[0])
+			cmd(args[1:])
+			return
+		}
+		// create is the default, it's only needed if a component has the same
+		// name as a command
+		if args[0] == "create" {
+			args = args[1:]
+		}
+	}
+	createMain(args)
+}
+
+// readOnlyCommands don't create or move anything, running one of them instead
+// of releasing a component of the same name is harmless
+var readOnlyCommands = map[string]bool{
+	"verify": true, "status": true, "compare-envs": true, "list": true, "export": true,
+	"show": true, "search": true, "bisect": true, "compare": true, "sort": true,
+	"diff": true, "tui": true, "verify-assets": true, "plan": true, "schedule": true,
+	"config": true, "schema": true, "report": true, "audit": true, "fsck-tags": true,
+	"bench": true,
+}
+
+// warnShadowedComponent warns when a component in the config is named like a
+// command that changes something, 'release <name>' used to release it
+func warnShadowedComponent(command string) {
+	if readOnlyCommands[command] {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	dir, err := release.FindRepoDir(cwd)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, release.ConfigFile))
+	if err != nil {
+		return
+	}
+	cfg, err := release.ParseConfig(data)
+	if err != nil {
+		return
+	}
+	if _, shadowed := cfg.Components[command]; shadowed {
+		setupLogging(false)
+		log.Warn().Msgf("running the %s command, there's also a component named %s: release it with 'release create %s'", command, command, command)
+	}
+}
 
+func createMain(args []string) {
 	modules := []string{}
 	var remote, message string
-	var verbose, dryRun, doPush bool
-	var user, email, sshKeyPath string
-	format := "%Y.%m."
+	var verbose, dryRun, doPush, force, noNotify, msgFromPR, ackBreaking, ackLint, ackLeadTime, allowSkew, explain, allowEmptyMessage, annotate, lightweight, jsonOutput, requireBranch, allowDetached, asBot, retryViaAPI, viaAPI, closeMilestone, draft bool
+	refSpecs := []string{}
+	var user, email, sshKeyPath, repoURL, fromBranch, overrideFreeze, overrideDCO, overrideScan, acks, rolloutSpec string
 	defaultRemote := "origin"
 	flag.StringArrayVarP(&modules, "component", "c", []string{}, "component to release, if not set will use 'release' which triggers all components to build and deploy, can also be specified as the first argument")
 	flag.StringVarP(&remote, "remote", "r", defaultRemote, "git remote to push to (if --push)")
 	flag.StringVarP(&message, "msg", "m", "", "optional release message, will create an annotated git tag")
+	flag.BoolVar(&msgFromPR, "msg-from-pr", false, "use the description (or its '## Release Notes' section) of the merged pull request HEAD came from as the release message")
+	flag.BoolVar(&allowEmptyMessage, "allow-empty-message", false, "tag without a message if --msg or --msg-from-pr turn out empty instead of failing")
+	flag.BoolVar(&annotate, "annotate", false, "always create annotated tags, even without a message (also annotate: true in .release.yaml)")
+	flag.BoolVar(&lightweight, "lightweight", false, "always create lightweight tags, a --msg is only used for notifications")
 	flag.StringVar(&user, "user", "", "override user in ~/.gitconfig")
 	flag.StringVar(&email, "email", "", "override email in ~/.gitconfig")
 	// flag.StringVarP(&format, "fmt", "f", "%Y.%m.", "date format to use")
 	flag.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
 	flag.BoolVar(&doPush, "push", false, "push tag to default remote (does 'git push')")
+	flag.BoolVar(&force, "force", false, "force push, this MOVES the tag on the remote if it already exists there, use 'release supersede' to fix a release instead")
+	flag.StringArrayVar(&refSpecs, "refspec", []string{}, "custom refspec to push instead of refs/tags/<tag>:refs/tags/<tag>, {tag} is replaced with the tag name")
+	flag.BoolVar(&retryViaAPI, "retry-via-api", false, "if the remote rejects the push, create the tag through the forge API with the configured token instead")
+	flag.BoolVar(&viaAPI, "via-api", false, "create the tag through the forge API with the configured token instead of pushing it, no push credentials needed (implies --push)")
+	flag.BoolVar(&noNotify, "no-notify", false, "don't send the notifications configured in .release.yaml after pushing")
+	flag.BoolVar(&closeMilestone, "close-milestone", false, "link the forge milestone of the release in its message and close it after pushing, open issues move to the next milestone (also milestones.close in .release.yaml)")
+	flag.BoolVar(&draft, "draft", false, "after pushing, create the forge release as a draft with generated notes to edit, publish it with 'release publish <tag>'")
+	flag.StringVar(&overrideFreeze, "override-freeze", "", "release during a freeze window, the reason given is recorded in the tag annotation")
+	flag.BoolVar(&ackLint, "acknowledge-lint", false, "release even though commit messages since the last release break the lint rules")
+	flag.StringVar(&rolloutSpec, "rollout", "", `how to roll the release out, like "canary=5 waves=25,50,100 bake=1h", replaces the configured rollout`)
+	flag.StringVar(&overrideScan, "override-scan", "", "release despite scanner findings that block it, the reason given is recorded in the tag annotation")
+	flag.StringVar(&overrideDCO, "override-dco", "", "release commits that aren't signed off by their author, the reason given is recorded in the tag annotation")
+	flag.BoolVar(&ackBreaking, "acknowledge-breaking", false, "release even though commits since the last release are marked as breaking changes")
+	flag.BoolVar(&ackLeadTime, "acknowledge-lead-time", false, "release right before a freeze window or outside business hours without being asked, see lead_time in .release.yaml")
+	flag.StringVar(&acks, "ack", "", "comma separated checklist items to acknowledge instead of being asked, see checklist in .release.yaml")
+	flag.BoolVar(&explain, "explain", false, "show which tags were considered for the version and why, combine with -n to only explain")
+	flag.BoolVar(&allowSkew, "allow-clock-skew", false, "release even though existing releases are dated after the local clock and clock_skew.refuse is set")
+	flag.BoolVar(&requireBranch, "require-branch", false, "refuse to release when HEAD is detached (also require_branch: true in .release.yaml)")
+	flag.BoolVar(&allowDetached, "allow-detached", false, "release a detached HEAD without a notice, even if a branch is required")
+	flag.BoolVar(&asBot, "bot", false, "tag as the bot configured in .release.yaml even outside of CI")
+	flag.BoolVar(&jsonOutput, "json", false, "with --dry-run, print the proposed releases as JSON")
 	flag.BoolVarP(&dryRun, "dry-run", "n", false, "don't create a release, just print what would be released")
 	defaultSSHKeyPath := fmt.Sprintf("%s/.ssh/id_rsa", homeDir())
 	flag.StringVar(&sshKeyPath, "ssh-key", defaultSSHKeyPath, "specify path to ssh key")
+	flag.StringVar(&fromBranch, "from-branch", "", "release the tip of this branch (fetched from the remote) instead of HEAD, without checking it out")
+	flag.StringVar(&repoURL, "repo-url", "", "clone this repository (blobless, without a checkout) and release it instead of the current directory (implies --push)")
 	showVersion := flag.Bool("version", false, "display the version and exit")
 	flag.Usage = usage
-	flag.Parse()
+	parseFlags(flag.CommandLine, args)
 
 	if *showVersion {
 		fmt.Fprintf(os.Stderr, "%s\n", getVersionString())
@@ -85,82 +630,501 @@ func main() {
 		modules = append(modules, "release")
 	}
 
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-	// If we want UTC use this
-	// zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	setupLogging(verbose)
 
-	zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	if verbose {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	user, email = gitIdentity(user, email)
+
+	// There's no local checkout to push from later when using --repo-url, so
+	// the tag is only useful if it's pushed
+	if repoURL != "" {
+		doPush = true
+	}
+	if viaAPI {
+		if force || len(refSpecs) > 0 {
+			log.Fatal().Msg("--force and --refspec need a git push, they can't be used with --via-api")
+		}
+		doPush = true
 	}
 
-	cfg, err := config.LoadConfig(config.GlobalScope)
-	if err == nil {
-		if user == "" {
-			user = cfg.User.Name
+	if release.Offline() {
+		switch {
+		case repoURL != "":
+			log.Fatal().Msg("--repo-url needs the network, it can't be used with --offline")
+		case viaAPI:
+			log.Fatal().Msg("--via-api needs the network, it can't be used with --offline")
+		case doPush:
+			log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+		case msgFromPR:
+			log.Fatal().Msg("--msg-from-pr needs the network, it can't be used with --offline")
+		case closeMilestone:
+			log.Fatal().Msg("--close-milestone needs the network, it can't be used with --offline")
+		case draft:
+			log.Fatal().Msg("--draft needs the network, it can't be used with --offline")
+		}
+		noNotify = true
+	}
+
+	// Create a new Release Manager
+	rm := openManager(repoURL, sshKeyPath)
+	defer rm.Close()
+
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.LoadOwnership(repoCfg.Components), "failed to load CODEOWNERS")
+	setupOIDC(rm, repoCfg)
+	release.CheckIfError(rm.UseMetadata(repoCfg.Metadata), "failed to open the metadata store")
+
+	// Components can have their own remote, notifications, ... on top of the
+	// repository's settings. An explicit --remote wins over the config.
+	remoteGiven := flag.CommandLine.Changed("remote")
+	if !remoteGiven && repoCfg.Remote != "" {
+		remote = repoCfg.Remote
+	}
+	if closeMilestone && !doPush {
+		log.Fatal().Msg("--close-milestone needs --push, the milestone is closed once the release is out")
+	}
+	if draft && !doPush {
+		log.Fatal().Msg("--draft needs --push, the forge release is created for the pushed tag")
+	}
+	compCfgs := map[string]*release.Config{}
+	remotes := map[string]string{}
+	notifiers := map[string][]notify.Notifier{}
+	milestoneForges := map[string]forge.Forge{}
+	draftForges := map[string]forge.Forge{}
+	for _, module := range modules {
+		compCfg := repoCfg.ForComponent(module)
+		compCfgs[module] = compCfg
+		remotes[module] = remote
+		if !remoteGiven && compCfg.Remote != "" {
+			remotes[module] = compCfg.Remote
 		}
-		if email == "" {
-			email = cfg.User.Email
+		if doPush {
+			err := rm.CheckRemote(remotes[module])
+			release.CheckIfError(err, fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remotes[module]))
+		}
+		if viaAPI {
+			f, err := rm.Forge(remotes[module], compCfg.Forge)
+			release.CheckIfError(err, fmt.Sprintf("failed to set up the forge for remote '%s'", remotes[module]))
+			if _, ok := f.(forge.TagCreator); !ok {
+				log.Fatal().Msgf("%s can't create tags through its API, push them instead", f.Name())
+			}
+		}
+		if doPush && !release.Offline() && (closeMilestone || compCfg.Milestones.Close) {
+			f, err := rm.Forge(remotes[module], compCfg.Forge)
+			release.CheckIfError(err, fmt.Sprintf("failed to set up the forge for remote '%s'", remotes[module]))
+			if _, ok := f.(forge.Milestones); !ok {
+				log.Fatal().Msgf("%s doesn't have milestones, omit --close-milestone", f.Name())
+			}
+			milestoneForges[module] = f
+		}
+		if draft {
+			f, err := rm.Forge(remotes[module], compCfg.Forge)
+			release.CheckIfError(err, fmt.Sprintf("failed to set up the forge for remote '%s'", remotes[module]))
+			if _, ok := f.(forge.Releases); !ok {
+				log.Fatal().Msgf("%s doesn't have releases, omit --draft", f.Name())
+			}
+			draftForges[module] = f
+		}
+		if !noNotify {
+			notifiers[module], err = notify.FromConfig(compCfg.Notify)
+			release.CheckIfError(err, fmt.Sprintf("failed to set up notifications for %s", module))
 		}
-	} else {
-		// At this point, we might be in a CI environment and might not have gitconfig
-		// setup. If we're not using heavy tags, we don't even care about this error,
-		// so we'll log a warning (only visible at debug) and if the user tries to create
-		// an annotated tag, we'll deal with it then.
-		log.Debug().Err(err).Msg("unable to load git config, this is only a problem if you're using annotated tags")
 	}
 
-	cwd, err := os.Getwd()
-	release.CheckIfError(err, "failed to get current dir")
+	// The branch is released as it is on the remote, hooks and scanners run in
+	// a temporary worktree of it
+	target := ""
+	if fromBranch != "" {
+		var auth transport.AuthMethod
+		if !release.Offline() {
+			auth = authForRemote(rm, remote, sshKeyPath)
+		}
+		target, err = rm.BranchTip(remote, fromBranch, auth)
+		release.CheckIfError(err, fmt.Sprintf("failed to resolve branch %s", fromBranch))
+		rm.UseTarget(target)
+		log.Info().Msgf("releasing %s at %s", fromBranch, target[:8])
+		for _, module := range modules {
+			if repoCfg.Components[module].Build != nil {
+				log.Fatal().Msgf("building %s needs a checkout of the release, check out %s instead of using --from-branch", module, fromBranch)
+			}
+		}
+	}
 
-	// Create a new Release Manager
-	rm, err := release.NewManager(cwd, format, incrementFormat)
+	if msgFromPR {
+		if message != "" {
+			log.Fatal().Msg("--msg and --msg-from-pr can't be used together")
+		}
+		f, err := rm.Forge(remote, repoCfg.Forge)
+		release.CheckIfError(err, fmt.Sprintf("failed to set up the forge for remote '%s'", remote))
+		notes, pr, err := rm.PullRequestNotes(f)
+		release.CheckIfError(err, "failed to look up the pull request for HEAD")
+		if pr == nil {
+			log.Fatal().Msg("HEAD isn't part of a merged pull request, use --msg instead")
+		}
+		log.Info().Msgf("using release notes from %s", pr.URL)
+		message = notes
+	}
 
-	if doPush {
-		err := rm.CheckRemote(remote)
-		release.CheckIfError(err, fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remote))
+	tagKind := release.TagAuto
+	switch {
+	case annotate && lightweight:
+		log.Fatal().Msg("--annotate and --lightweight can't be used together")
+	case lightweight:
+		tagKind = release.TagLightweight
+		if message != "" {
+			log.Info().Msg("creating lightweight tags, the message will only be used for notifications")
+		}
+	case annotate || repoCfg.Annotate:
+		tagKind = release.TagAnnotated
 	}
 
+	messageGiven := msgFromPR || flag.CommandLine.Changed("msg")
+	message, warnings := release.SanitizeMessage(message, repoCfg.Messages)
+	for _, warning := range warnings {
+		log.Warn().Msg(warning)
+	}
+	if messageGiven && message == "" {
+		if !allowEmptyMessage {
+			log.Fatal().Msg("the release message is empty, use --allow-empty-message to tag without one")
+		}
+		log.Info().Msg("the release message is empty, the tag won't have one")
+	}
+
+	detached, _, err := rm.DetachedHead()
+	release.CheckIfError(err, "failed to resolve HEAD")
+	ci := release.DetectCI()
+	if ci != nil && tagKind != release.TagLightweight {
+		// Audits need to tell automated releases from manual ones
+		message = ci.AppendTo(message)
+	}
+	by := releasedBy(user, email)
+	if asBot && !repoCfg.Bot.Configured() {
+		log.Fatal().Msgf("--bot needs a bot name and email in %s", release.ConfigFile)
+	}
+	if (asBot || repoCfg.Bot.Active(ci != nil)) && tagKind != release.TagLightweight {
+		// The human in the CI environment, or whoever runs us, is the one the
+		// release is really for
+		if human := release.InvokingHuman(); human != "" {
+			by = human
+		}
+		user, email = repoCfg.Bot.Name, repoCfg.Bot.Email
+		message = release.AppendTrailer(message, release.InitiatedByTrailer, by)
+		message = release.AppendTrailer(message, release.BotTrailer, repoCfg.Bot.String())
+	}
+	branch := fromBranch
+	if branch == "" && detached {
+		branch = release.CIBranch()
+	}
+	if branch != "" && (message != "" || tagKind == release.TagAnnotated) {
+		message = release.AppendTrailer(message, release.BranchTrailer, branch)
+	}
+
+	var rollout *release.Rollout
+	if rolloutSpec != "" {
+		rollout, err = release.ParseRollout(rolloutSpec)
+		release.CheckIfError(err, "invalid --rollout")
+		if tagKind == release.TagLightweight {
+			log.Fatal().Msg("lightweight tags can't record a rollout")
+		}
+	}
+
+	release.CheckIfError(checkTrust(rm, repoCfg.Trust), "refusing to release")
+
 	// This is customizable, but for now, we always want a release number
 	rm.AlwaysIncludeNumber = true
+	rm.Scheme = repoCfg.Scheme
 
-	release.CheckIfError(err, "failed to load release manager")
-	proposedDate := rm.GetProposedDate()
+	now := time.Now()
+	trainDate := repoCfg.Train.Date(now)
+	if repoCfg.Train.Cutoff != "" {
+		log.Debug().Msgf("releasing on the %s train", trainDate.Format("2006-01-02"))
+	}
 	newReleases := []string{}
+	proposals := []*release.ProposedRelease{}
 	for _, module := range modules {
-		newReleases = append(newReleases, fmt.Sprintf("%s-%s", proposedDate, module))
+		rm.Scheme = compCfgs[module].Scheme
+		proposal, err := rm.GetProposedReleaseAt(module, trainDate, repoCfg.Increments.ResetPolicy(module))
+		release.CheckIfError(err, fmt.Sprintf("failed to propose a release for %s", module))
+		if explain {
+			fmt.Fprintf(os.Stderr, "%s:\n%s\n", module, proposal.Explanation)
+		}
+		proposals = append(proposals, proposal)
+		newReleases = append(newReleases, proposal.TagName)
 	}
+	targets := map[string]string{}
+	for _, newRelease := range newReleases {
+		if target != "" {
+			targets[newRelease] = target
+		}
+	}
+	gates := checkGates(rm, repoCfg, modules, newReleases, gateOptions{
+		by:             by,
+		overrideFreeze: overrideFreeze,
+		overrideDCO:    overrideDCO,
+		ackBreaking:    ackBreaking,
+		ackLint:        ackLint,
+		ackLeadTime:    ackLeadTime,
+		allowSkew:      allowSkew,
+		acks:           strings.Split(acks, ","),
+		targets:        targets,
+		requireBranch:  requireBranch,
+		allowDetached:  allowDetached || fromBranch != "",
+		dryRun:         dryRun,
+	})
 	plural := ""
 	if len(newReleases) > 1 {
 		plural = "s"
 	}
+	goTags, goModules := goModuleTags(rm, repoCfg.Go, newReleases)
+	semvers := semverTags(rm, repoCfg, modules, newReleases, target)
+	packages := repoCfg.Packages
+	if fromBranch != "" && packages.Policy == "bump" {
+		// The bump would be committed to the checked out branch, not the
+		// released one
+		packages.Policy = "block"
+	}
+	checkPackages(rm, packages, newReleases, dryRun, user, email)
+	if dryRun && jsonOutput {
+		writeJSON(proposals)
+		rm.Close()
+		os.Exit(0)
+	}
 	if dryRun {
-		fmt.Printf("would create release%s:\n%s\n", plural, strings.Join(newReleases, ", "))
+		say(fmt.Sprintf("would create release%s:\n%s", plural, strings.Join(newReleases, ", ")), "tags", strings.Join(newReleases, ","))
+		for idx, newRelease := range newReleases {
+			if goTag, ok := goTags[newRelease]; ok {
+				say(fmt.Sprintf("would tag go module %s as %s", goModules[newRelease], goTag), "module", goModules[newRelease], "tag", goTag)
+			}
+			if semverTag, ok := semvers[newRelease]; ok {
+				say(fmt.Sprintf("would also tag %s as %s", newRelease, semverTag), "release", newRelease, "tag", semverTag)
+			}
+			if build := repoCfg.Components[modules[idx]].Build; build != nil {
+				say(fmt.Sprintf("would build %s with %s", newRelease, strings.Join(build.Commands, " && ")), "tag", newRelease)
+			}
+		}
+		rm.Close()
 		os.Exit(0)
 	}
 
+	summaries := map[string]string{}
+	if !messageGiven && tagKind != release.TagLightweight {
+		summaries = offerSummaries(rm, repoCfg, modules, newReleases, trainDate)
+	}
+	failedBuilds := buildReleases(rm, repoCfg, modules, newReleases)
+
+	// Components may push to different remotes, each gets its own auth
+	auths := map[string]transport.AuthMethod{}
+
 	failedCreate := false
-	for _, newRelease := range newReleases {
-		_, err = rm.CreateTag(newRelease, message, user, email)
+	movedFloating := map[string][]string{} // By remote
+	for idx, newRelease := range newReleases {
+		module := modules[idx]
+		compCfg := compCfgs[module]
+		relRemote := remotes[module]
+		if failedBuilds[newRelease] {
+			failedCreate = true
+			continue
+		}
+		if _, ok := auths[relRemote]; !ok && doPush && !viaAPI {
+			auths[relRemote] = authForRemote(rm, relRemote, sshKeyPath)
+		}
+		auth := auths[relRemote]
+		relMessage := message
+		if summary, ok := summaries[newRelease]; ok {
+			// Without --msg the message only has trailers so far
+			relMessage = strings.TrimSpace(summary + "\n\n" + message)
+		}
+		relMessage = gates.annotate(relMessage, module, newRelease)
+		if teams, err := rm.AffectedTeams(newRelease, ""); err != nil {
+			logError(err, fmt.Sprintf("failed to work out the teams %s affects", newRelease))
+		} else if len(teams) > 0 && tagKind != release.TagLightweight {
+			log.Info().Msgf("%s is cross-cutting, it changes files owned by %s", newRelease, strings.Join(teams, ", "))
+			relMessage = release.AppendTrailer(relMessage, release.AffectsTrailer, strings.Join(teams, ", "))
+		}
+		if r := compCfg.Rollout; (r != nil || rollout != nil) && tagKind != release.TagLightweight {
+			if rollout != nil {
+				r = rollout
+			}
+			relMessage = release.AppendTrailer(relMessage, release.RolloutTrailer, r.String())
+		}
+		if repoCfg.Risk.Enabled && tagKind != release.TagLightweight {
+			risk, err := rm.ScoreRisk(newRelease, "", repoCfg.Components[module], repoCfg.Risk, time.Now())
+			if err != nil {
+				logError(err, fmt.Sprintf("failed to score the risk of %s", newRelease))
+			} else {
+				log.Info().Msgf("risk of %s: %s", newRelease, risk)
+				relMessage = release.AppendTrailer(relMessage, release.RiskTrailer, risk.String())
+			}
+		}
+		var milestone *forge.Milestone
+		if f, ok := milestoneForges[module]; ok {
+			milestone, err = release.FindMilestone(f, compCfg.Milestones, rm.ParseRelease(newRelease))
+			if err != nil {
+				logError(err, fmt.Sprintf("failed to find the milestone of %s", newRelease))
+			} else if milestone == nil {
+				log.Info().Msgf("no open milestone for %s", newRelease)
+			} else {
+				relMessage = release.AppendTrailer(relMessage, release.MilestoneTrailer, milestone.URL)
+			}
+		}
+		if compCfg.Messages.Template != "" {
+			relMessage, err = rm.RenderReleaseMessage(compCfg.Messages.Template, newRelease, relMessage)
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to render the message template of %s", module)
+				failedCreate = true
+				continue
+			}
+		}
+		hookDir := rm.RepoDir()
+		if hooks := compCfg.Hooks; fromBranch != "" && len(hooks.PreRelease)+len(hooks.Scanners)+len(hooks.PostRelease) > 0 {
+			hookDir, err = rm.Worktree()
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to check out %s for the hooks, not releasing %s", fromBranch, newRelease)
+				failedCreate = true
+				continue
+			}
+		}
+		scans, relMessage, err := preRelease(compCfg.Hooks, hookDir, rm.ParseRelease(newRelease), relMessage, overrideScan, by)
+		if err != nil {
+			log.Error().Err(err).Msgf("not releasing %s", newRelease)
+			failedCreate = true
+			continue
+		}
+		fullMessage := relMessage
+		if compCfg.Messages.Compresses(relMessage) {
+			// Keeps 'git tag -n' readable, the full notes become an asset.
+			// The clone of --repo-url is removed when we're done, its notes
+			// go where release was run.
+			notesFile := compCfg.Messages.NotesFileFor(newRelease)
+			notesDir := rm.RepoDir()
+			if repoURL != "" {
+				notesDir, err = os.Getwd()
+				release.CheckIfError(err, "failed to get current dir")
+			}
+			pointer, err := release.WriteFullNotes(filepath.Join(notesDir, notesFile), relMessage)
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to write the full notes of %s", newRelease)
+				failedCreate = true
+				continue
+			}
+			relMessage = release.CompressMessage(relMessage, compCfg.Messages.CompressOver, pointer)
+			log.Info().Msgf("the message of %s is %d bytes, compressed it, the full notes are in %s", newRelease, len(fullMessage), notesFile)
+		}
+		_, err = rm.CreateTagOfKind(newRelease, relMessage, user, email, tagKind)
 		if err != nil {
 			log.Error().Msgf("failed to create tag %s: %s", newRelease, err.Error())
 			failedCreate = true
 			continue
 		}
+		semverTag, hasSemVer := semvers[newRelease]
+		if hasSemVer {
+			if err := rm.CreateSemVerTag(newRelease, semverTag, relMessage, user, email, tagKind); err != nil {
+				// CreateSemVerTag deletes the release tag again, like ApplyPlan
+				// neither tag is kept
+				log.Error().Msgf("failed to create SemVer tag %s, not releasing %s, its tag was deleted again: %s", semverTag, newRelease, err.Error())
+				failedCreate = true
+				continue
+			}
+		}
 		// Success!
-		fmt.Printf("created release: %s\n", newRelease)
+		say(fmt.Sprintf("created release: %s", newRelease), "tag", newRelease)
+		if hasSemVer {
+			say(fmt.Sprintf("created SemVer tag: %s", semverTag), "tag", semverTag, "release", newRelease)
+		}
+		audit(rm, release.AuditEvent{Action: "release", Tag: newRelease, By: by}, user, email)
+		for _, scan := range scans {
+			audit(rm, release.AuditEvent{Action: "scan", Tag: newRelease, By: by, Detail: scan.Summary()}, user, email)
+		}
+		if items := gates.checklists[newRelease]; len(items) > 0 {
+			audit(rm, release.AuditEvent{Action: "checklist", Tag: newRelease, By: by, Detail: strings.Join(items, ", ")}, user, email)
+		}
+		goTag, isGoModule := goTags[newRelease]
+		if isGoModule {
+			if _, err := rm.CreateTagOfKind(goTag, relMessage, user, email, tagKind); err != nil {
+				log.Error().Msgf("failed to create go module tag %s: %s", goTag, err.Error())
+				failedCreate = true
+				continue
+			}
+			say(fmt.Sprintf("created go module tag: %s", goTag), "tag", goTag, "release", newRelease)
+		}
+		floating := release.FloatingTagsFor(repoCfg.Floating, rm.ParseRelease(newRelease), relMessage)
+		if err := rm.MoveFloatingTags(floating, newRelease); err != nil {
+			log.Error().Err(err).Msgf("failed to move the floating tags of %s", newRelease)
+			failedCreate = true
+		} else if len(floating) > 0 {
+			say(fmt.Sprintf("moved floating tags: %s", strings.Join(floating, ", ")), "tags", strings.Join(floating, ","), "release", newRelease)
+			movedFloating[relRemote] = append(movedFloating[relRemote], floating...)
+		}
 
 		if doPush {
-			msg, err := rm.PushTagToRemote(newRelease, remote, loadKeys(sshKeyPath))
+			pushOpts := release.PushOptions{Force: force}
+			for _, rs := range refSpecs {
+				pushOpts.RefSpecs = append(pushOpts.RefSpecs, config.RefSpec(strings.ReplaceAll(rs, "{tag}", newRelease)))
+			}
+			if hasSemVer {
+				// Both tags go in the same push
+				if len(pushOpts.RefSpecs) == 0 {
+					pushOpts.RefSpecs = release.TagRefSpecs(newRelease)
+				}
+				pushOpts.RefSpecs = append(pushOpts.RefSpecs, release.TagRefSpecs(semverTag)...)
+			}
+			push := func(tag string) (string, error) {
+				return rm.PushTagToRemote(tag, relRemote, auth)
+			}
+			var msg string
+			if viaAPI {
+				push = func(tag string) (string, error) {
+					return createViaAPI(rm, compCfg.Forge, relRemote, tag)
+				}
+				msg, err = push(newRelease)
+			} else {
+				msg, err = rm.PushTagToRemoteWithOptions(newRelease, relRemote, auth, pushOpts)
+			}
+			if err != nil && retryViaAPI && !viaAPI && errors.Is(err, release.ErrPushRejected) {
+				logError(err, msg)
+				log.Info().Msgf("retrying tag %s through the forge API", newRelease)
+				msg, err = createViaAPI(rm, compCfg.Forge, relRemote, newRelease)
+			}
+			settleCredentials(err)
 			if err == nil {
 				// Great Success!
-				fmt.Println(msg)
+				say(msg, "tag", newRelease, "remote", relRemote)
+				if isGoModule {
+					pushGoTag(goTag, goModules[newRelease], push, repoCfg.Go.ProxyWarmup)
+				}
+				if hasSemVer && viaAPI {
+					if msg, err := push(semverTag); err != nil {
+						logError(err, msg)
+						failedCreate = true
+					}
+				}
+				if viaAPI && len(floating) > 0 {
+					log.Warn().Msgf("floating tags can't be moved through the forge API, push %s yourself", strings.Join(floating, ", "))
+				} else if err := rm.PushFloatingTags(floating, relRemote, auth); err != nil {
+					logError(err, fmt.Sprintf("failed to push the floating tags %s", strings.Join(floating, ", ")))
+					failedCreate = true
+				}
+				if milestone != nil {
+					closeReleaseMilestone(milestoneForges[module], *milestone, newRelease)
+				}
+				if f, ok := draftForges[module]; ok {
+					createDraftRelease(rm, f, compCfgs[module], repoCfg.Components[module], relRemote, newRelease)
+				}
+				event := rm.ReleaseEvent(newRelease, relRemote, by, fullMessage)
+				if notify.SendAll(notifiers[module], event) > 0 {
+					log.Warn().Msgf("tag %s was pushed but some notifications failed, see above", newRelease)
+				}
 			} else {
-				log.Error().Err(err).Msg(msg)
-				fmt.Printf("the tag will still be in the local repo you can delete it with `git tag -d %s` or push it with `git push <REMOTE> %s` once you have resolved the issue preventing push\n", newRelease, newRelease)
+				logError(err, msg)
+				say(fmt.Sprintf("the tag will still be in the local repo you can delete it with `git tag -d %s` or push it with `git push <REMOTE> %s` once you have resolved the issue preventing push", newRelease, newRelease), "tag", newRelease, "pushed", false)
 				failedCreate = true
+				continue
 			}
 		}
+		if err := release.RunHooks(compCfg.Hooks.PostRelease, hookDir, rm.ParseRelease(newRelease)); err != nil {
+			log.Error().Err(err).Msgf("post-release hook of %s failed", newRelease)
+			failedCreate = true
+		}
 	}
 	if failedCreate {
 		// We failed at least one create, exit
@@ -173,8 +1137,29 @@ func main() {
 	}
 
 	if !doPush {
-		fmt.Printf("tag%s (%s) not pushed (--push not set), push it with:\n", plural, strings.Join(newReleases, ", "))
-		fmt.Printf(" git push %s %s\n", remote, strings.Join(newReleases, " "))
+		say(fmt.Sprintf("tag%s (%s) not pushed (--push not set), push it with:", plural, strings.Join(newReleases, ", ")), "tags", strings.Join(newReleases, ","), "pushed", false)
+		// Components may push to different remotes, one command each
+		pushRemotes := []string{}
+		toPush := map[string][]string{}
+		for idx, newRelease := range newReleases {
+			relRemote := remotes[modules[idx]]
+			if _, ok := toPush[relRemote]; !ok {
+				pushRemotes = append(pushRemotes, relRemote)
+			}
+			toPush[relRemote] = append(toPush[relRemote], newRelease)
+			if goTag, ok := goTags[newRelease]; ok {
+				toPush[relRemote] = append(toPush[relRemote], goTag)
+			}
+			if semverTag, ok := semvers[newRelease]; ok {
+				toPush[relRemote] = append(toPush[relRemote], semverTag)
+			}
+		}
+		for _, relRemote := range pushRemotes {
+			say(fmt.Sprintf(" git push %s %s", relRemote, strings.Join(toPush[relRemote], " ")))
+			if len(movedFloating[relRemote]) > 0 {
+				say(fmt.Sprintf(" git push -f %s %s", relRemote, strings.Join(movedFloating[relRemote], " ")))
+			}
+		}
 	}
 
 }
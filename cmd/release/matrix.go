@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func buildMatrixMain(args []string) {
+	var workers int
+	var verbose bool
+	fs := flag.NewFlagSet("build-matrix", flag.ExitOnError)
+	fs.IntVarP(&workers, "jobs", "j", runtime.NumCPU(), "how many targets to build at once")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release build-matrix <tag> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Cross-compiles the release for the build_matrix targets of its component.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	tag := fs.Arg(0)
+
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	rel := rm.FindRelease(tag)
+	if rel == nil {
+		log.Fatal().Msgf("there's no release %s", tag)
+	}
+	matrix := repoCfg.Components[rel.Component()].BuildMatrix
+	if matrix == nil {
+		log.Fatal().Msgf("component %s has no build_matrix in %s", rel.Component(), release.ConfigFile)
+	}
+	// The working tree is built, it should be what was released
+	if head, err := rm.ResolveCommit("HEAD"); err == nil && head != rel.Hash {
+		log.Warn().Msgf("HEAD isn't %s, the binaries are built from the working tree", tag)
+	}
+
+	log.Info().Msgf("building %s for %d target(s)", tag, len(matrix.Targets))
+	manifest, err := release.RunBuildMatrix(*matrix, rm.RepoDir(), rel, rel.Hash, workers)
+	release.CheckIfError(err, fmt.Sprintf("failed to build %s", tag))
+	manifestFile := repoCfg.Build.ManifestFile(tag)
+	err = release.WriteBuildManifest(filepath.Join(rm.RepoDir(), manifestFile), manifest)
+	release.CheckIfError(err, fmt.Sprintf("failed to write %s", manifestFile))
+	for _, a := range manifest.Artifacts {
+		say(fmt.Sprintf("%s  %s", a.SHA256, a.Path), "path", a.Path, "sha256", a.SHA256)
+	}
+	say(fmt.Sprintf("built %s into %s, listed in %s", tag, matrix.OutputDir(), manifestFile), "tag", tag, "output", matrix.OutputDir(), "manifest", manifestFile)
+}
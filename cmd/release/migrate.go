@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func migrateSchemeMain(args []string) {
+	var remote, user, email, sshKeyPath string
+	var alias, retag, doPush, verbose bool
+	fs := flag.NewFlagSet("migrate-scheme", flag.ExitOnError)
+	fs.BoolVar(&alias, "alias", false, "tag every release that doesn't use the configured scheme again in that scheme, keeping the old tag")
+	fs.BoolVar(&retag, "retag", false, "like --alias but delete the old tags afterwards")
+	fs.BoolVar(&doPush, "push", false, "push the new tags (and with --retag delete the old ones from the remote)")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release migrate-scheme [--alias|--retag] [--push] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if alias && retag {
+		log.Fatal().Msg("--alias and --retag can't be used together")
+	}
+	if doPush && !alias && !retag {
+		log.Fatal().Msg("--push needs --alias or --retag")
+	}
+	if doPush && release.Offline() {
+		log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+	}
+
+	rm := openManager("", sshKeyPath)
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+
+	t := newTable("COMPONENT", "SCHEME", "RELEASES", "LATEST").color(1, colorCyan)
+	for _, u := range rm.SchemeUsage() {
+		component := u.Component
+		if component == "" {
+			component = "(none)"
+		}
+		for _, scheme := range []release.Scheme{release.SchemeMonthly, release.SchemeOrdinal} {
+			if u.Releases[scheme] > 0 {
+				t.row(component, string(scheme), fmt.Sprint(u.Releases[scheme]), u.Latest[scheme])
+			}
+		}
+	}
+	t.render(os.Stdout)
+
+	mappings := rm.MigrationPlan(repoCfg)
+	if len(mappings) == 0 {
+		say("\nevery release uses the configured scheme")
+		return
+	}
+	say(fmt.Sprintf("\n%d release(s) don't use the configured scheme:", len(mappings)), "count", len(mappings))
+	m := newTable("FROM", "TO", "COMMIT").color(1, colorGreen).color(2, colorYellow)
+	for _, mapping := range mappings {
+		m.row(mapping.From, mapping.To, mapping.Hash[:8])
+	}
+	m.render(os.Stdout)
+	if !alias && !retag {
+		say("\ncreate the new tags with --alias, or replace the old ones with --retag")
+		return
+	}
+
+	if doPush {
+		release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remote))
+	}
+	user, email = gitIdentity(user, email)
+	release.CheckIfError(rm.MigrateTags(mappings, user, email, retag), "failed to migrate the tags, no new tags were kept")
+	created, old := []string{}, []string{}
+	for _, mapping := range mappings {
+		created = append(created, mapping.To)
+		old = append(old, mapping.From)
+	}
+	log.Info().Msgf("created %s", strings.Join(created, ", "))
+	if retag {
+		log.Info().Msgf("deleted %s", strings.Join(old, ", "))
+	}
+	if !doPush {
+		return
+	}
+	auth := authForRemote(rm, remote, sshKeyPath)
+	pushTags(rm, created, remote, auth)
+	if retag {
+		release.CheckIfError(rm.DeleteRemoteTags(old, remote, auth), fmt.Sprintf("failed to delete the old tags from %s", remote))
+	}
+	log.Info().Msgf("pushed %d tag(s) to %s", len(created), remote)
+}
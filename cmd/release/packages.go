@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// checkPackages compares the package manifests of the new releases with their
+// versions and applies the packages policy: warn, block or bump (commit the
+// new versions before tagging). Nothing is written on a dry run.
+func checkPackages(rm *release.Manager, cfg release.PackagesConfig, newReleases []string, dryRun bool, user, email string) {
+	outOfSync := []*release.PackageVersion{}
+	for _, newRelease := range newReleases {
+		versions, err := rm.PackageVersions(cfg, newRelease)
+		release.CheckIfError(err, fmt.Sprintf("failed to check package versions for %s", newRelease))
+		for _, p := range versions {
+			if !p.InSync() {
+				outOfSync = append(outOfSync, p)
+			}
+		}
+	}
+	if len(outOfSync) == 0 {
+		return
+	}
+	bumps := []string{}
+	for _, p := range outOfSync {
+		log.Warn().Msgf("%s has version %s, the release needs %s", p.File.Path, p.Found, p.Expected)
+		bumps = append(bumps, fmt.Sprintf("%s to %s", p.File.Path, p.Expected))
+	}
+	switch {
+	case cfg.Policy == "block":
+		log.Fatal().Msg("package versions don't match the release, update them or change the packages policy")
+	case cfg.Policy != "bump":
+		return
+	case dryRun:
+		log.Info().Msgf("would bump %s", strings.Join(bumps, ", "))
+		return
+	}
+	message := fmt.Sprintf("Bump package versions for %s\n\n* %s\n", strings.Join(newReleases, ", "), strings.Join(bumps, "\n* "))
+	err := rm.BumpPackages(outOfSync, message, user, email)
+	release.CheckIfError(err, "failed to bump package versions")
+	say(fmt.Sprintf("bumped %s", strings.Join(bumps, ", ")), "packages", strings.Join(bumps, ","))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+const planHeader = `# Proposed by 'release plan' on %s, edit the messages, drop the
+# releases you don't want and run 'release apply' with this file.
+`
+
+func planMain(args []string) {
+	var output, sshKeyPath string
+	var verbose, noCascade bool
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	fs.StringVarP(&output, "output", "o", "", "write the plan to this file instead of stdout")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVar(&noCascade, "no-cascade", false, "don't add the components depending on a changed one (depends_on or go.mod requirements)")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release plan [component...] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", sshKeyPath)
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	rm.AlwaysIncludeNumber = true
+	rm.Scheme = repoCfg.Scheme
+	_, err = rm.ApplyTrust(repoCfg.Trust)
+	release.CheckIfError(err, "failed to load trusted keys")
+
+	now := time.Now()
+	plan, err := rm.DraftPlan(release.PlanOptions{
+		Now:        repoCfg.Train.Date(now),
+		Increments: repoCfg.Increments,
+		Components: repoCfg.Components,
+		Only:       fs.Args(),
+		NoCascade:  noCascade,
+	})
+	release.CheckIfError(err, "failed to draft a release plan")
+	if len(plan.Releases) == 0 {
+		log.Info().Msg("no component changed since its last release, nothing to plan")
+		return
+	}
+	for idx := range plan.Releases {
+		p := &plan.Releases[idx]
+		cfg := repoCfg.ForComponent(p.Component)
+		if cfg.Messages.Summarize == "" {
+			continue
+		}
+		// The notes stay in the plan as they are, the summary is only a draft
+		summary, err := release.Summarize(cfg.Messages.Summarize, rm.RepoDir(), rm.ParseRelease(p.Tag), p.Notes)
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to summarize the changes of %s, keeping the list", p.Tag))
+			continue
+		}
+		p.Message = summary
+	}
+	data, err := plan.Marshal()
+	release.CheckIfError(err, "failed to render the plan")
+	data = append([]byte(fmt.Sprintf(planHeader, now.Format("2006-01-02"))), data...)
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	release.CheckIfError(ioutil.WriteFile(output, data, 0644), fmt.Sprintf("failed to write %s", output))
+	log.Info().Msgf("planned %d release(s) in %s", len(plan.Releases), output)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	flag "github.com/spf13/pflag"
+)
+
+// pruneReport is what --json prints
+type pruneReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Remote   string         `json:"remote,omitempty"`
+	Pruned   []prunedTag    `json:"pruned"`
+	Branches []prunedBranch `json:"branches,omitempty"`
+	Failed   []string       `json:"failed,omitempty"`
+	Cutoff   time.Time      `json:"cutoff"`
+}
+
+type prunedTag struct {
+	Tag        string    `json:"tag"`
+	Component  string    `json:"component"`
+	PreRelease string    `json:"pre_release"`
+	Date       time.Time `json:"date"`
+}
+
+type prunedBranch struct {
+	Branch string    `json:"branch"`
+	Remote string    `json:"remote,omitempty"`
+	Tags   []string  `json:"tags"`
+	Newest time.Time `json:"newest"`
+}
+
+func pruneMain(args []string) {
+	var remote, sshKeyPath, olderThan, into string
+	var verbose, preReleases, branches, localOnly, dryRun, asJSON bool
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	fs.BoolVar(&preReleases, "pre-releases", false, "prune superseded rc/beta/alpha/pre tags (final releases are never touched)")
+	fs.BoolVar(&branches, "branches", false, "prune release branches that are fully merged and whose tags are old enough")
+	fs.StringVar(&into, "into", "", "the branch release branches are merged into (default from .release.yaml or main)")
+	fs.StringVar(&olderThan, "older-than", "90d", "only prune tags older than this (e.g. 90d, 2w, 12h)")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to also delete the tags and branches from")
+	fs.BoolVar(&localOnly, "local-only", false, "only delete the local tags and branches")
+	fs.BoolVarP(&dryRun, "dry-run", "n", false, "only report what would be pruned")
+	fs.BoolVar(&asJSON, "json", false, "print a JSON report")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release prune [--pre-releases] [--branches] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	if !preReleases && !branches {
+		fs.Usage()
+		os.Exit(2)
+	}
+	age, err := release.ParseAge(olderThan)
+	release.CheckIfError(err, "invalid --older-than")
+
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	if localOnly {
+		remote = ""
+	} else {
+		release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s', use --local-only to skip it", remote))
+	}
+	auth := authForRemote(rm, remote, sshKeyPath)
+
+	now := time.Now()
+	report := pruneReport{DryRun: dryRun, Remote: remote, Cutoff: now.Add(-age), Pruned: []prunedTag{}}
+	if preReleases {
+		pruneTags(rm, &report, age, now, auth)
+	}
+	if branches {
+		cfg, err := rm.LoadConfig()
+		release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+		if into != "" {
+			cfg.Branches.Into = into
+		}
+		pruneBranches(rm, &report, cfg.Branches, age, now, auth)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		verb := "pruned"
+		if dryRun {
+			verb = "would prune"
+		}
+		for _, p := range report.Pruned {
+			say(fmt.Sprintf("%s %s (%s)", verb, p.Tag, p.Date.Format("2006-01-02")), "action", verb, "tag", p.Tag, "date", p.Date.Format("2006-01-02"))
+		}
+		for _, b := range report.Branches {
+			name := b.Branch
+			if b.Remote != "" {
+				name = fmt.Sprintf("%s/%s", b.Remote, b.Branch)
+			}
+			say(fmt.Sprintf("%s branch %s (newest tag %s)", verb, name, b.Newest.Format("2006-01-02")), "action", verb, "branch", name, "newest", b.Newest.Format("2006-01-02"))
+		}
+		say(fmt.Sprintf("%s %d pre-release tag(s) and %d branch(es) older than %s", verb, len(report.Pruned), len(report.Branches), report.Cutoff.Format("2006-01-02")), "action", verb, "tags", len(report.Pruned), "branches", len(report.Branches), "cutoff", report.Cutoff.Format("2006-01-02"))
+		for _, failed := range report.Failed {
+			say(fmt.Sprintf("failed to delete %s", failed), "action", "failed", "ref", failed)
+		}
+	}
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func pruneTags(rm *release.Manager, report *pruneReport, age time.Duration, now time.Time, auth transport.AuthMethod) {
+	tags := []string{}
+	for _, rel := range rm.PrunablePreReleases(age, now) {
+		report.Pruned = append(report.Pruned, prunedTag{
+			Tag:        rel.Tag,
+			Component:  rel.Component(),
+			PreRelease: rel.PreRelease(),
+			Date:       rel.ReleasedBy().When,
+		})
+		tags = append(tags, rel.Tag)
+	}
+	if report.DryRun {
+		return
+	}
+	if report.Remote != "" {
+		err := rm.DeleteRemoteTags(tags, report.Remote, auth)
+		release.CheckIfError(err, fmt.Sprintf("failed to delete tags from remote %s, no local tags were deleted", report.Remote))
+	}
+	user, email := gitIdentity("", "")
+	for _, tag := range tags {
+		if err := rm.DeleteTag(tag); err != nil {
+			report.Failed = append(report.Failed, tag)
+			continue
+		}
+		audit(rm, release.AuditEvent{Action: "prune", Tag: tag, Detail: report.Remote}, user, email)
+	}
+}
+
+func pruneBranches(rm *release.Manager, report *pruneReport, cfg release.BranchConfig, age time.Duration, now time.Time, auth transport.AuthMethod) {
+	branches, err := rm.PrunableBranches(cfg, report.Remote, age, now)
+	release.CheckIfError(err, "failed to find release branches")
+	for _, b := range branches {
+		report.Branches = append(report.Branches, prunedBranch{Branch: b.Name, Remote: b.Remote, Tags: b.Tags, Newest: b.Newest})
+		if report.DryRun {
+			continue
+		}
+		if err := rm.DeleteBranch(b, auth); err != nil {
+			logError(err, fmt.Sprintf("failed to delete branch %s", b))
+			report.Failed = append(report.Failed, b.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func publishMain(args []string) {
+	var remote string
+	var verbose bool
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote of the forge the release is on")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release publish <tag> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Publishes the draft forge release 'release --draft' created.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if release.Offline() {
+		log.Fatal().Msg("publish needs the network, it can't be used with --offline")
+	}
+	tag := fs.Arg(0)
+
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	rel := rm.FindRelease(tag)
+	if rel == nil {
+		log.Fatal().Msgf("there's no release %s", tag)
+	}
+	compCfg := repoCfg.ForComponent(rel.Component())
+	if !fs.Changed("remote") && compCfg.Remote != "" {
+		remote = compCfg.Remote
+	}
+	f, err := rm.Forge(remote, compCfg.Forge)
+	release.CheckIfError(err, fmt.Sprintf("failed to set up the forge for remote '%s'", remote))
+	published, err := release.PublishRelease(f, tag)
+	release.CheckIfError(err, fmt.Sprintf("failed to publish the release of %s", tag))
+	say(fmt.Sprintf("published release %s", published.URL), "tag", tag, "url", published.URL)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// parseDay parses a YYYY-MM-DD date in local time, or an age like 30d or 2w
+// counted back from now
+func parseDay(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	age, err := release.ParseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither a YYYY-MM-DD date nor an age like 30d", value)
+	}
+	return now.Add(-age), nil
+}
+
+func reportMain(args []string) {
+	var since, until, format, templateFile, output string
+	var preReleases, verbose bool
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.StringVar(&since, "since", "", "first day of the period (YYYY-MM-DD) or an age like 30d (required)")
+	fs.StringVar(&until, "until", "", "last day of the period (YYYY-MM-DD), default today")
+	fs.StringVarP(&format, "format", "f", "markdown", "markdown or html")
+	fs.StringVarP(&templateFile, "template", "t", "", "render with this Go template instead of the default one")
+	fs.StringVarP(&output, "output", "o", "", "write the report to this file instead of stdout")
+	fs.BoolVar(&preReleases, "pre-releases", false, "include pre-releases")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release report --since <date> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if since == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if format != "markdown" && format != "html" {
+		log.Fatal().Msgf("unknown format %s, must be markdown or html", format)
+	}
+
+	now := time.Now()
+	from, err := parseDay(since, now)
+	release.CheckIfError(err, "invalid --since")
+	to := now
+	if until != "" {
+		day, err := time.ParseInLocation("2006-01-02", until, time.Local)
+		release.CheckIfError(err, "invalid --until, use YYYY-MM-DD")
+		// The whole last day is part of the period
+		to = day.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		log.Fatal().Msg("--since must be before --until")
+	}
+
+	rm := openManager("", "")
+	digest, err := rm.Digest(from, to, preReleases)
+	release.CheckIfError(err, "failed to build the report")
+	report, err := digest.Render(format, templateFile)
+	release.CheckIfError(err, "failed to render the report")
+	if output == "" {
+		fmt.Print(report)
+		return
+	}
+	release.CheckIfError(ioutil.WriteFile(output, []byte(report), 0644), fmt.Sprintf("failed to write %s", output))
+	log.Info().Msgf("wrote the report of %d release(s) to %s", digest.Releases, output)
+}
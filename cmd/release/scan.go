@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// runScanners runs the scanners of a release and adds what they found to its
+// message. Findings that block the release refuse it unless overridden with a
+// reason, which is recorded too.
+func runScanners(scanners []release.ScannerHook, dir string, rel *release.Release, message, override, by string) ([]*release.ScanResult, string, error) {
+	tag := rel.Tag
+	results := []*release.ScanResult{}
+	blocked := false
+	for _, s := range scanners {
+		result, err := release.RunScanner(s, dir, rel)
+		if err != nil {
+			return nil, message, err
+		}
+		results = append(results, result)
+		message = release.AppendTrailer(message, release.ScanTrailer, result.Summary())
+		if len(result.Blocking) == 0 {
+			continue
+		}
+		blocked = true
+		log.Warn().Msgf("%s found %d finding(s) blocking %s:", s.Name, len(result.Blocking), tag)
+		for _, f := range result.Blocking {
+			fmt.Fprintf(os.Stderr, "  * %s %s %s %s\n", f.Severity, f.ID, f.Package, f.Title)
+		}
+	}
+	if !blocked {
+		return results, message, nil
+	}
+	if override == "" {
+		return nil, message, fmt.Errorf("scanners found blocking problems, fix them or use --override-scan with a reason")
+	}
+	log.Warn().Msgf("overriding the scanners of %s: %s", tag, override)
+	message = release.AppendTrailer(message, release.ScanOverrideTrailer, fmt.Sprintf("%s (by %s)", override, by))
+	return results, message, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+// scheduledRelease is a row of release schedule --json
+type scheduledRelease struct {
+	Date      string `json:"date"`
+	Component string `json:"component,omitempty"`
+	Tag       string `json:"tag"`
+}
+
+func scheduleMain(args []string) {
+	var count int
+	var asJSON, verbose bool
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	fs.IntVarP(&count, "count", "n", 6, "how many upcoming releases to show")
+	fs.BoolVar(&asJSON, "json", false, "print the schedule as json")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release schedule [component...] [-n 6] [--json]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	rm := openManager("", "")
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	if !repoCfg.Schedule.Configured() {
+		log.Fatal().Msgf("no schedule in %s, set schedule.every and schedule.start", release.ConfigFile)
+	}
+	rm.AlwaysIncludeNumber = true
+	_, err = rm.ApplyTrust(repoCfg.Trust)
+	release.CheckIfError(err, "failed to load trusted keys")
+
+	components := fs.Args()
+	if len(components) == 0 {
+		for name := range repoCfg.Components {
+			components = append(components, name)
+		}
+		sort.Strings(components)
+	}
+	if len(components) == 0 {
+		// Without components the bare versions are shown
+		components = []string{""}
+	}
+
+	// Releases are dated by their train, like they would be when created
+	dates := []time.Time{}
+	for _, date := range repoCfg.Schedule.Upcoming(time.Now(), count) {
+		dates = append(dates, repoCfg.Train.Date(date))
+	}
+	rows := []scheduledRelease{}
+	for _, component := range components {
+		rm.Scheme = repoCfg.ForComponent(component).Scheme
+		tags := rm.PlanVersions(component, dates, repoCfg.Increments.ResetPolicy(component))
+		for idx, tag := range tags {
+			rows = append(rows, scheduledRelease{Date: dates[idx].Format("2006-01-02"), Component: component, Tag: tag})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Date < rows[j].Date
+	})
+	if asJSON {
+		writeJSON(rows)
+		return
+	}
+	t := newTable("DATE", "DAY", "COMPONENT", "TAG").color(0, colorCyan).color(3, colorGreen)
+	for _, row := range rows {
+		date, _ := time.Parse("2006-01-02", row.Date)
+		t.row(row.Date, date.Format("Mon"), row.Component, row.Tag)
+	}
+	t.render(os.Stdout)
+}
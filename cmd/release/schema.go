@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func schemaMain(args []string) {
+	var outDir string
+	var verbose bool
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.StringVarP(&outDir, "out", "o", "", "write the schema of every document to <document>.schema.json in this directory")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release schema [<document>] [--out <dir>]\n\n")
+		fmt.Fprintf(os.Stderr, "Prints the JSON Schema of a document release reads or writes, for editors\n")
+		fmt.Fprintf(os.Stderr, "and other tools to validate them. Lists the documents without one.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() > 1 || (outDir != "" && fs.NArg() != 0) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if outDir != "" {
+		release.CheckIfError(os.MkdirAll(outDir, 0755), fmt.Sprintf("failed to create %s", outDir))
+		for _, name := range release.SchemaNames() {
+			data, err := release.Schema(name)
+			release.CheckIfError(err, fmt.Sprintf("failed to generate the %s schema", name))
+			path := filepath.Join(outDir, name+".schema.json")
+			release.CheckIfError(ioutil.WriteFile(path, data, 0644), fmt.Sprintf("failed to write %s", path))
+			say(fmt.Sprintf("wrote %s", path), "document", name, "path", path)
+		}
+		return
+	}
+	if fs.NArg() == 0 {
+		t := newTable("DOCUMENT", "DESCRIPTION").color(0, colorCyan)
+		for _, name := range release.SchemaNames() {
+			t.row(name, release.SchemaTitle(name))
+		}
+		t.render(os.Stdout)
+		return
+	}
+	data, err := release.Schema(fs.Arg(0))
+	release.CheckIfError(err, "failed to generate the schema")
+	os.Stdout.Write(data)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func searchMain(args []string) {
+	opts := &listOptions{}
+	var asJSON, useIndex bool
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	opts.register(fs)
+	fs.BoolVar(&useIndex, "index", false, "only search tag and released commit messages, in the index of releases (fast)")
+	fs.BoolVar(&asJSON, "json", false, "print the matches as json")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release search <words...> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Searches the tag messages, commit messages and notes of releases for\nreleases with every word.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(opts.verbose)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// --limit is for the hits, not the releases searched
+	limit := opts.limit
+	opts.limit = 0
+	rm, releases := opts.releases()
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	hits, err := rm.Search(strings.Join(fs.Args(), " "), releases, release.SearchOptions{Index: useIndex, Components: repoCfg.Components})
+	release.CheckIfError(err, "failed to search the releases")
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	if asJSON {
+		writeJSON(hits)
+		return
+	}
+	if len(hits) == 0 {
+		fmt.Fprintf(os.Stderr, "no release matches '%s'\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+	for _, hit := range hits {
+		if plain {
+			for _, m := range hit.Matches {
+				fmt.Println(kv("tag", hit.Tag, "source", m.Source, "line", m.Line))
+			}
+			continue
+		}
+		rel := rm.FindRelease(hit.Tag)
+		by := rel.ReleasedBy()
+		fmt.Printf("%s %s %s\n", colorize(colorCyan, hit.Tag), colorize(colorGray, by.When.Format("2006-01-02")), by.Name)
+		for _, m := range hit.Matches {
+			fmt.Printf("  %s %s\n", colorize(colorYellow, fmt.Sprintf("%-15s", m.Source)), m.Line)
+		}
+	}
+}
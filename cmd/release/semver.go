@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fernferret/release/pkg/release"
+)
+
+// semverTags works out the SemVer tag of every new release of a component
+// configured with semver, by release. The releases tag target, HEAD if empty.
+// Failing to is fatal so nothing gets tagged.
+func semverTags(rm *release.Manager, repoCfg *release.Config, modules, newReleases []string, target string) map[string]string {
+	if target == "" {
+		target = "HEAD"
+	}
+	tags := map[string]string{}
+	for idx, newRelease := range newReleases {
+		c := repoCfg.Components[modules[idx]]
+		if c.SemVer == nil {
+			continue
+		}
+		head, err := rm.ResolveCommit(target)
+		release.CheckIfError(err, fmt.Sprintf("failed to resolve %s", target))
+		tag, err := rm.NextSemVerTag(c, head)
+		release.CheckIfError(err, fmt.Sprintf("failed to work out the SemVer tag of %s", newRelease))
+		tags[newRelease] = tag
+	}
+	return tags
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+// commitTable renders commits as a table, newest first
+func commitTable(commits []*release.Commit) *table {
+	t := newTable("COMMIT", "DATE", "AUTHOR", "SUBJECT").color(0, colorYellow).color(1, colorGray)
+	for _, c := range commits {
+		t.row(c.Hash[:8], c.Author.When.Format("2006-01-02"), c.Author.Name, release.Subject(c.Message))
+	}
+	return t
+}
+
+func showMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release show <tag> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rm := openManager("", "")
+	rel := rm.FindRelease(fs.Arg(0))
+	if rel == nil {
+		fmt.Fprintf(os.Stderr, "no release tag %s found\n", fs.Arg(0))
+		os.Exit(1)
+	}
+	field := func(name, value string) {
+		if plain {
+			fmt.Println(kv(strings.ToLower(strings.Replace(name, " ", "_", -1)), value))
+			return
+		}
+		fmt.Printf("%s %s\n", colorize(colorBold, fmt.Sprintf("%-12s", name+":")), value)
+	}
+	field("Tag", colorize(colorCyan, rel.Tag))
+	field("Component", rel.Component())
+	if rel.IsPreRelease() {
+		field("Pre-release", rel.PreRelease())
+	}
+	field("Version", rel.Version())
+	field("Commit", rel.Hash)
+	field("Released by", rel.ReleasedByString(true))
+	if prev := rm.PreviousRelease(rel.Tag); prev != nil {
+		field("Previous", prev.Tag)
+	}
+	if old := rel.Supersedes(); old != "" {
+		field("Supersedes", old)
+	}
+	if newer := rm.SupersededBy(rel.Tag); newer != nil {
+		field("Superseded", colorize(colorYellow, "by "+newer.Tag))
+	}
+	if plain {
+		fmt.Println(kv("message", strings.TrimSpace(rel.Message())))
+	} else {
+		fmt.Printf("\n%s\n", strings.TrimSpace(rel.Message()))
+	}
+
+	commits, err := rm.Changelog(rel.Tag)
+	release.CheckIfError(err, "failed to build changelog")
+	say("\n"+colorize(colorBold, fmt.Sprintf("Changes (%d):", len(commits))), "changes", len(commits))
+	commitTable(commits).render(os.Stdout)
+
+	release.CheckIfError(rm.LoadChanges(rel), "failed to work out the changed files")
+	added, deleted := diffstatTotals(rel.Changes())
+	say("\n"+colorize(colorBold, fmt.Sprintf("Files (%d, +%d -%d):", len(rel.Changes()), added, deleted)), "files", len(rel.Changes()), "added", added, "deleted", deleted)
+	t := newTable("FILE", "ADDED", "DELETED").color(1, colorGreen).color(2, colorRed)
+	for _, c := range rel.Changes() {
+		t.row(c.Path, fmt.Sprintf("+%d", c.Added), fmt.Sprintf("-%d", c.Deleted))
+	}
+	t.render(os.Stdout)
+}
+
+// diffstatTotals adds up the added and deleted lines of changed files
+func diffstatTotals(changes []release.PathChange) (int, int) {
+	added, deleted := 0, 0
+	for _, c := range changes {
+		added += c.Added
+		deleted += c.Deleted
+	}
+	return added, deleted
+}
+
+func diffMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release diff <from-tag> <to-tag> [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rm := openManager("", "")
+	commits, err := rm.Diff(fs.Arg(0), fs.Arg(1))
+	release.CheckIfError(err, "failed to diff releases")
+	commitTable(commits).render(os.Stdout)
+}
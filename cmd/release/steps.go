@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+)
+
+// preRelease runs the pre_release hooks and scanners of a release in dir and
+// adds what the scanners found to its message
+func preRelease(hooks release.HooksConfig, dir string, rel *release.Release, message, overrideScan, by string) ([]*release.ScanResult, string, error) {
+	if err := release.RunHooks(hooks.PreRelease, dir, rel); err != nil {
+		return nil, message, fmt.Errorf("pre-release hook failed: %w", err)
+	}
+	return runScanners(hooks.Scanners, dir, rel, message, overrideScan, by)
+}
+
+// planSteps are the steps of planned releases besides the gates: the build
+// phase, pre_release hooks and scanners run before ApplyPlan creates any tag
+// and the post_release hooks after. create runs the same steps release by
+// release.
+type planSteps struct {
+	dirs  map[string]string                // By tag, where the hooks run
+	hooks map[string]release.HooksConfig   // By tag
+	scans map[string][]*release.ScanResult // By tag
+}
+
+// runPlanSteps builds the planned releases (newReleases go with
+// plan.Releases) and runs their pre_release hooks and scanners, adding the
+// findings to their messages. Hooks and scanners of a target that isn't
+// checked out run in a worktree of it, builds need the target checked out.
+// The releases whose steps failed are returned with the reason, they mustn't
+// be created.
+func runPlanSteps(rm *release.Manager, cfg *release.Config, plan *release.Plan, newReleases []string, overrideScan, by string) (*planSteps, map[string]error) {
+	steps := &planSteps{dirs: map[string]string{}, hooks: map[string]release.HooksConfig{}, scans: map[string][]*release.ScanResult{}}
+	failed := map[string]error{}
+	head, err := rm.ResolveCommit("HEAD")
+	if err != nil {
+		for _, newRelease := range newReleases {
+			failed[newRelease] = fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		return steps, failed
+	}
+	targets := map[string]string{}
+	builds, built := []string{}, []string{}
+	for idx, p := range plan.Releases {
+		newRelease := newReleases[idx]
+		target := p.Target
+		if target == "" {
+			target = "HEAD"
+		}
+		hash, err := rm.ResolveCommit(target)
+		if err != nil {
+			failed[newRelease] = fmt.Errorf("failed to resolve target %s: %w", target, err)
+			continue
+		}
+		targets[newRelease] = hash
+		if cfg.Components[p.Component].Build == nil {
+			continue
+		}
+		if hash != head {
+			failed[newRelease] = fmt.Errorf("%s has a build, check out its target %s to release it", p.Component, target)
+			continue
+		}
+		builds, built = append(builds, p.Component), append(built, newRelease)
+	}
+	for newRelease := range buildReleases(rm, cfg, builds, built) {
+		failed[newRelease] = fmt.Errorf("the build failed")
+	}
+
+	for idx, p := range plan.Releases {
+		newRelease := newReleases[idx]
+		if failed[newRelease] != nil {
+			continue
+		}
+		hooks := cfg.ForComponent(p.Component).Hooks
+		if len(hooks.PreRelease)+len(hooks.Scanners)+len(hooks.PostRelease) == 0 {
+			continue
+		}
+		dir := rm.RepoDir()
+		if targets[newRelease] != head {
+			if dir, err = rm.WorktreeAt(targets[newRelease]); err != nil {
+				failed[newRelease] = fmt.Errorf("failed to check out %s for the hooks: %w", p.Target, err)
+				continue
+			}
+		}
+		scans, message, err := preRelease(hooks, dir, rm.ParseRelease(newRelease), p.Message, overrideScan, by)
+		if err != nil {
+			failed[newRelease] = err
+			continue
+		}
+		plan.Releases[idx].Message = message
+		steps.dirs[newRelease], steps.hooks[newRelease], steps.scans[newRelease] = dir, hooks, scans
+	}
+	return steps, failed
+}
+
+// finish audits the scans of the created releases and runs their
+// post_release hooks, it returns whether any hook failed
+func (s *planSteps) finish(rm *release.Manager, applied []*release.AppliedRelease, user, email, by string) bool {
+	failed := false
+	for _, a := range applied {
+		for _, scan := range s.scans[a.Tag] {
+			audit(rm, release.AuditEvent{Action: "scan", Tag: a.Tag, By: by, Detail: scan.Summary()}, user, email)
+		}
+		if err := release.RunHooks(s.hooks[a.Tag].PostRelease, s.dirs[a.Tag], rm.ParseRelease(a.Tag)); err != nil {
+			log.Error().Err(err).Msgf("post-release hook of %s failed", a.Tag)
+			failed = true
+		}
+	}
+	return failed
+}
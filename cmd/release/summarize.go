@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// offerSummaries runs the unreleased changes of each new release through the
+// configured summarizer and asks whether to use the result as its message.
+// Only done on a terminal, returns the accepted messages by release.
+func offerSummaries(rm *release.Manager, repoCfg *release.Config, modules, newReleases []string, now time.Time) map[string]string {
+	accepted := map[string]string{}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return accepted
+	}
+	in := bufio.NewReader(os.Stdin)
+	for idx, newRelease := range newReleases {
+		cfg := repoCfg.ForComponent(modules[idx])
+		if cfg.Messages.Summarize == "" {
+			continue
+		}
+		changelogs, err := rm.UnreleasedChangelogs(release.PlanOptions{
+			Now:        now,
+			Increments: repoCfg.Increments,
+			Components: repoCfg.Components,
+			Only:       []string{modules[idx]},
+		}, 1)
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to list the changes of %s, not summarizing them", newRelease))
+			continue
+		}
+		if len(changelogs) == 0 {
+			continue
+		}
+		summary, err := release.Summarize(cfg.Messages.Summarize, rm.RepoDir(), rm.ParseRelease(newRelease), changelogs[0].Notes)
+		if err != nil {
+			logError(err, fmt.Sprintf("failed to summarize the changes of %s", newRelease))
+			continue
+		}
+		summary, warnings := release.SanitizeMessage(summary, cfg.Messages)
+		for _, warning := range warnings {
+			log.Warn().Msg(warning)
+		}
+		fmt.Fprintf(os.Stderr, "\n%s\n\n", summary)
+		fmt.Fprintf(os.Stderr, i18n.T("use this draft as the message of %s? [y/N] "), newRelease)
+		answer, _ := in.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) == "y" {
+			accepted[newRelease] = summary
+		}
+	}
+	return accepted
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/pkg/release"
+	"github.com/fernferret/release/pkg/release/notify"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+)
+
+func supersedeMain(args []string) {
+	var target, message, remote, user, email, sshKeyPath, overrideFreeze, overrideDCO, overrideScan, acks string
+	var doPush, noNotify, ackBreaking, ackLint, ackLeadTime, allowSkew, dryRun, verbose bool
+	fs := flag.NewFlagSet("supersede", flag.ExitOnError)
+	fs.StringVar(&target, "target", "", "the corrected commit or ref to release, HEAD by default")
+	fs.StringVarP(&message, "msg", "m", "", "release message, says which release is replaced by default")
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to push to (if --push)")
+	fs.BoolVar(&doPush, "push", false, "push the new tag")
+	fs.StringVar(&user, "user", "", "override user in ~/.gitconfig")
+	fs.StringVar(&email, "email", "", "override email in ~/.gitconfig")
+	fs.BoolVar(&noNotify, "no-notify", false, "don't send the notifications configured in .release.yaml after pushing")
+	fs.StringVar(&overrideFreeze, "override-freeze", "", "release during a freeze window, the reason given is recorded in the tag annotation")
+	fs.StringVar(&overrideDCO, "override-dco", "", "release commits that aren't signed off by their author, the reason given is recorded in the tag annotation")
+	fs.StringVar(&overrideScan, "override-scan", "", "release despite scanner findings that block it, the reason given is recorded in the tag annotation")
+	fs.BoolVar(&ackBreaking, "acknowledge-breaking", false, "release even though commits since the last release are marked as breaking changes")
+	fs.BoolVar(&ackLint, "acknowledge-lint", false, "release even though commit messages since the last release break the lint rules")
+	fs.BoolVar(&ackLeadTime, "acknowledge-lead-time", false, "release right before a freeze window or outside business hours without being asked")
+	fs.StringVar(&acks, "ack", "", "comma separated checklist items to acknowledge instead of being asked")
+	fs.BoolVar(&allowSkew, "allow-clock-skew", false, "release even though existing releases are dated after the local clock and clock_skew.refuse is set")
+	fs.BoolVarP(&dryRun, "dry-run", "n", false, "show the release that would supersede the tag")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release supersede <tag> [--target <rev>] [-m <msg>] [--push]\n\n")
+		fmt.Fprintf(os.Stderr, "Fixes a release without moving its tag: the next version of the component is\n")
+		fmt.Fprintf(os.Stderr, "released at the corrected commit with a Supersedes trailer naming the tag.\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if doPush && release.Offline() {
+		log.Fatal().Msg("--push needs the network, it can't be used with --offline")
+	}
+
+	tag := fs.Arg(0)
+	user, email = gitIdentity(user, email)
+	by := releasedBy(user, email)
+	rm := openManager("", sshKeyPath)
+	defer rm.Close()
+	repoCfg, err := rm.LoadConfig()
+	release.CheckIfError(err, fmt.Sprintf("failed to load %s", release.ConfigFile))
+	release.CheckIfError(rm.LoadOwnership(repoCfg.Components), "failed to load CODEOWNERS")
+	setupOIDC(rm, repoCfg)
+	release.CheckIfError(checkTrust(rm, repoCfg.Trust), "refusing to release")
+
+	component := rm.ParseRelease(tag).Component()
+	compCfg := repoCfg.ForComponent(component)
+	if !fs.Changed("remote") && compCfg.Remote != "" {
+		remote = compCfg.Remote
+	}
+	rm.AlwaysIncludeNumber = true
+	rm.Scheme = compCfg.Scheme
+	proposal, err := rm.ProposeSupersede(tag, target, repoCfg.Train.Date(time.Now()), repoCfg.Increments.ResetPolicy(component))
+	release.CheckIfError(err, fmt.Sprintf("can't supersede %s", tag))
+	newRelease := proposal.TagName
+
+	gates := checkGates(rm, repoCfg, []string{component}, []string{newRelease}, gateOptions{
+		by:             by,
+		overrideFreeze: overrideFreeze,
+		overrideDCO:    overrideDCO,
+		ackBreaking:    ackBreaking,
+		ackLint:        ackLint,
+		ackLeadTime:    ackLeadTime,
+		allowSkew:      allowSkew,
+		acks:           strings.Split(acks, ","),
+		targets:        map[string]string{newRelease: proposal.TargetCommit},
+		allowDetached:  true,
+		dryRun:         dryRun,
+	})
+	semverTag, hasSemVer := semverTags(rm, repoCfg, []string{component}, []string{newRelease}, proposal.TargetCommit)[newRelease]
+	if dryRun {
+		say(fmt.Sprintf("would supersede %s with %s at %s", tag, newRelease, proposal.TargetCommit[:8]), "tag", tag, "release", newRelease, "commit", proposal.TargetCommit)
+		if hasSemVer {
+			say(fmt.Sprintf("would also tag %s as %s", newRelease, semverTag), "release", newRelease, "tag", semverTag)
+		}
+		return
+	}
+	if doPush {
+		release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s', cannot push, omit --push or fix the remote", remote))
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Supersedes %s", tag)
+	}
+	message = gates.annotate(message, component, newRelease)
+	message = release.AppendTrailer(message, release.SupersedesTrailer, tag)
+
+	// The build, hooks and scanners of the component run like for any other
+	// release
+	plan := &release.Plan{Releases: []release.PlannedRelease{{Component: component, Target: proposal.TargetCommit, Message: message}}}
+	steps, failed := runPlanSteps(rm, repoCfg, plan, []string{newRelease}, overrideScan, by)
+	if err, ok := failed[newRelease]; ok {
+		release.CheckIfError(err, fmt.Sprintf("not releasing %s", newRelease))
+	}
+	message = plan.Releases[0].Message
+
+	_, err = rm.CreateTagAt(newRelease, proposal.TargetCommit, message, user, email, release.TagAnnotated)
+	release.CheckIfError(err, fmt.Sprintf("failed to create tag %s", newRelease))
+	tags := []string{newRelease}
+	if hasSemVer {
+		err := rm.CreateSemVerTag(newRelease, semverTag, message, user, email, release.TagAnnotated)
+		release.CheckIfError(err, fmt.Sprintf("failed to create SemVer tag %s, not releasing %s, its tag was deleted again", semverTag, newRelease))
+		tags = append(tags, semverTag)
+	}
+	say(fmt.Sprintf("created release %s, superseding %s", newRelease, tag), "tag", newRelease, "supersedes", tag)
+	if hasSemVer {
+		say(fmt.Sprintf("created SemVer tag: %s", semverTag), "tag", semverTag, "release", newRelease)
+	}
+	audit(rm, release.AuditEvent{Action: "supersede", Tag: newRelease, By: by, Detail: tag}, user, email)
+	applied := []*release.AppliedRelease{{Component: component, Tag: newRelease, Target: proposal.TargetCommit, Message: message, SemVerTag: semverTag}}
+	if !doPush {
+		say(fmt.Sprintf("tag (%s) not pushed (--push not set), push it with:\n git push %s %s", newRelease, remote, strings.Join(tags, " ")), "tag", newRelease, "pushed", false)
+		finishSupersede(rm, steps, applied, user, email, by)
+		return
+	}
+
+	// Both tags go in the same push
+	msg, err := rm.PushTagToRemoteWithOptions(newRelease, remote, authForRemote(rm, remote, sshKeyPath), release.PushOptions{RefSpecs: release.TagRefSpecs(tags...)})
+	settleCredentials(err)
+	if err != nil {
+		logError(err, msg)
+		log.Fatal().Msgf("the tags are still in the local repo, push them with 'git push %s %s' once the problem is resolved", remote, strings.Join(tags, " "))
+	}
+	say(msg, "tag", newRelease, "remote", remote)
+	if !noNotify {
+		notifiers, err := notify.FromConfig(compCfg.Notify)
+		if err != nil {
+			logError(err, "failed to set up notifications, none were sent")
+		} else if notify.SendAll(notifiers, rm.ReleaseEvent(newRelease, remote, by, message)) > 0 {
+			log.Warn().Msgf("tag %s was pushed but some notifications failed, see above", newRelease)
+		}
+	}
+	finishSupersede(rm, steps, applied, user, email, by)
+}
+
+// finishSupersede runs the post_release hooks of the superseding release
+func finishSupersede(rm *release.Manager, steps *planSteps, applied []*release.AppliedRelease, user, email, by string) {
+	if steps.finish(rm, applied, user, email, by) {
+		log.Fatal().Msg("the post-release hook failed, see above")
+	}
+}
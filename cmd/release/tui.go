@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fernferret/release/internal/i18n"
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// ANSI sequences only the TUI needs
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	cursorHide   = "\x1b[?25l"
+	cursorShow   = "\x1b[?25h"
+	clearScreen  = "\x1b[2J\x1b[H"
+	reverseVideo = "\x1b[7m"
+)
+
+type tuiMode int
+
+const (
+	modeList tuiMode = iota
+	modeChanges
+	modeConfirm
+)
+
+// tui is a small full-screen browser for components and their releases
+type tui struct {
+	rm         *release.Manager
+	components []string // "" is every component
+	component  int
+	releases   []release.Release
+	selected   int
+	offset     int
+	mode       tuiMode
+	status     string
+	proposed   string
+	out        *strings.Builder
+}
+
+func tuiMain(args []string) {
+	var verbose bool
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	if plain {
+		fmt.Fprintln(os.Stderr, i18n.T("release tui can't be used with --plain, use release list"))
+		os.Exit(1)
+	}
+	stdin := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(stdin) || !terminal.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprintln(os.Stderr, i18n.T("release tui needs a terminal"))
+		os.Exit(1)
+	}
+
+	t := &tui{rm: openManager("", "")}
+	t.load()
+
+	state, err := terminal.MakeRaw(stdin)
+	release.CheckIfError(err, "failed to put the terminal in raw mode")
+	fmt.Print(altScreenOn + cursorHide)
+	defer func() {
+		fmt.Print(cursorShow + altScreenOff)
+		terminal.Restore(stdin, state)
+	}()
+
+	buf := make([]byte, 8)
+	for {
+		t.draw()
+		n, err := os.Stdin.Read(buf)
+		if err != nil || !t.handle(string(buf[:n])) {
+			return
+		}
+	}
+}
+
+// load (re)loads the component list and the releases of the current one
+func (t *tui) load() {
+	seen := map[string]bool{}
+	t.components = []string{""}
+	for _, rel := range t.rm.Releases() {
+		if c := rel.Component(); c != "" && !seen[c] {
+			seen[c] = true
+			t.components = append(t.components, c)
+		}
+	}
+	sort.Strings(t.components[1:])
+	if t.component >= len(t.components) {
+		t.component = 0
+	}
+	t.releases = []release.Release{}
+	for _, rel := range t.rm.Releases() {
+		if t.components[t.component] == "" || rel.Component() == t.components[t.component] {
+			t.releases = append(t.releases, rel)
+		}
+	}
+	t.selected, t.offset = 0, 0
+}
+
+// handle processes a key press, it returns false to quit
+func (t *tui) handle(key string) bool {
+	if t.mode == modeConfirm {
+		if key == "y" || key == "Y" {
+			if _, err := t.rm.CreateTag(t.proposed, "", "", ""); err != nil {
+				t.status = i18n.T("failed to create %s: %s", t.proposed, err)
+			} else {
+				t.status = i18n.T("created release %s, push it with: git push origin %s", t.proposed, t.proposed)
+				t.rm.Reload()
+				t.load()
+			}
+		} else {
+			t.status = i18n.T("cancelled")
+		}
+		t.mode = modeList
+		return true
+	}
+
+	switch key {
+	case "q", "\x03":
+		return false
+	case "\x1b", "\x7f":
+		t.mode = modeList
+	case "j", "\x1b[B":
+		if t.selected < len(t.releases)-1 {
+			t.selected++
+		}
+	case "k", "\x1b[A":
+		if t.selected > 0 {
+			t.selected--
+		}
+	case "l", "\t", "\x1b[C":
+		t.component = (t.component + 1) % len(t.components)
+		t.load()
+		t.mode = modeList
+	case "h", "\x1b[D":
+		t.component = (t.component + len(t.components) - 1) % len(t.components)
+		t.load()
+		t.mode = modeList
+	case "\r", "\n":
+		if len(t.releases) > 0 {
+			t.mode = modeChanges
+		}
+	case "n":
+		component := t.components[t.component]
+		if component == "" {
+			component = "release"
+		}
+		t.proposed = t.rm.GetProposedName(component)
+		t.mode = modeConfirm
+	}
+	t.status = ""
+	return true
+}
+
+func (t *tui) line(format string, args ...interface{}) {
+	// Raw mode needs explicit carriage returns
+	fmt.Fprintf(t.out, format+"\r\n", args...)
+}
+
+func (t *tui) draw() {
+	width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 20 || height < 8 {
+		width, height = 80, 24
+	}
+	t.out = &strings.Builder{}
+	t.out.WriteString(clearScreen)
+
+	// Component bar
+	tabs := []string{}
+	for idx, c := range t.components {
+		if c == "" {
+			c = i18n.T("all")
+		}
+		if idx == t.component {
+			c = reverseVideo + " " + c + " " + colorReset
+		} else {
+			c = " " + c + " "
+		}
+		tabs = append(tabs, c)
+	}
+	t.line("%s %s", colorBold+"release"+colorReset, strings.Join(tabs, ""))
+	t.line("%s", strings.Repeat("─", width))
+
+	body := height - 4
+	switch t.mode {
+	case modeChanges:
+		t.drawChanges(body)
+	case modeConfirm:
+		t.line("")
+		t.line("  %s", i18n.T("Create release %s at HEAD? [y/N]", colorCyan+t.proposed+colorReset))
+	default:
+		t.drawList(body)
+	}
+
+	// Footer
+	t.out.WriteString(fmt.Sprintf("\x1b[%d;1H", height-1))
+	t.line("%s", colorGray+truncate(t.status, width)+colorReset)
+	t.out.WriteString(colorGray + truncate(i18n.T("↑/↓ select  ←/→ component  enter changes  esc back  n new release  q quit"), width) + colorReset)
+	fmt.Print(t.out.String())
+}
+
+func (t *tui) drawList(rows int) {
+	if len(t.releases) == 0 {
+		t.line("  %s", i18n.T("no releases yet, press n to create one"))
+		return
+	}
+	if t.selected < t.offset {
+		t.offset = t.selected
+	} else if t.selected >= t.offset+rows {
+		t.offset = t.selected - rows + 1
+	}
+	for idx := t.offset; idx < len(t.releases) && idx < t.offset+rows; idx++ {
+		rel := t.releases[idx]
+		by := rel.ReleasedBy()
+		text := fmt.Sprintf(" %-24s %s  %-20s %s", rel.Tag, by.When.Format("2006-01-02 15:04"), by.Name, release.Subject(rel.Message()))
+		if idx == t.selected {
+			text = reverseVideo + text + colorReset
+		}
+		t.line("%s", text)
+	}
+}
+
+func (t *tui) drawChanges(rows int) {
+	rel := t.releases[t.selected]
+	commits, err := t.rm.Changelog(rel.Tag)
+	if err != nil {
+		t.line("  %s", i18n.T("failed to load changes: %s", err))
+		return
+	}
+	prev := i18n.T("the beginning")
+	if p := t.rm.PreviousRelease(rel.Tag); p != nil {
+		prev = p.Tag
+	}
+	t.line(" %s", i18n.T("%s since %s (%d commits)", colorCyan+rel.Tag+colorReset, prev, len(commits)))
+	t.line("")
+	for idx, c := range commits {
+		if idx >= rows-2 {
+			t.line(" %s", i18n.T("... %d more", len(commits)-idx))
+			break
+		}
+		t.line(" %s %s %s", colorYellow+c.Hash[:8]+colorReset, c.Author.When.Format("2006-01-02"), release.Subject(c.Message))
+	}
+}
+
+func truncate(s string, width int) string {
+	if len([]rune(s)) > width {
+		return string([]rune(s)[:width])
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// ANSI colors used by the table renderer and friends
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+	colorGray   = "\x1b[90m"
+)
+
+// noColor is set by --no-color, which every command accepts
+var noColor bool
+
+// plain is set by --plain, which every command accepts: no colors, no
+// alignment and no progress, tables and results are key=value lines
+var plain bool
+
+// useColor returns true if stdout is a terminal and nobody asked for plain
+// output (--no-color, --plain, NO_COLOR or TERM=dumb)
+func useColor() bool {
+	if noColor || plain || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorize wraps s in the color if colors are enabled
+func colorize(color, s string) string {
+	if !useColor() || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// plainValue quotes values that wouldn't survive splitting on spaces, the
+// way zerolog does
+func plainValue(v string) string {
+	if v == "" {
+		return `""`
+	}
+	for _, r := range v {
+		if r < 0x21 || r == 0x7f || r == '"' || r == '\\' || r == '=' {
+			return strconv.Quote(v)
+		}
+	}
+	return v
+}
+
+// kv formats key, value pairs as key=value
+func kv(fields ...interface{}) string {
+	parts := []string{}
+	for idx := 0; idx+1 < len(fields); idx += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%s", fields[idx], plainValue(fmt.Sprint(fields[idx+1]))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// say prints a result line. With --plain it's msg="..." followed by the
+// fields as key=value, so scripts don't have to parse sentences, and the
+// indentation and blank lines that are only there for people are dropped.
+func say(msg string, fields ...interface{}) {
+	if !plain {
+		fmt.Println(msg)
+		return
+	}
+	msg = strings.TrimSpace(msg)
+	if msg == "" && len(fields) == 0 {
+		return
+	}
+	fmt.Println(kv(append([]interface{}{"msg", msg}, fields...)...))
+}
+
+// table renders aligned columns, with a bold header and per column colors on
+// terminals and plain aligned text everywhere else
+type table struct {
+	headers []string
+	rows    [][]string
+	colors  map[int]string
+}
+
+func newTable(headers ...string) *table {
+	return &table{headers: headers, colors: map[int]string{}}
+}
+
+// color sets the color of every cell in the column
+func (t *table) color(col int, color string) *table {
+	t.colors[col] = color
+	return t
+}
+
+func (t *table) row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) render(w io.Writer) {
+	if plain {
+		// Every row on its own, keyed by the snake cased headers
+		keys := []string{}
+		for _, h := range t.headers {
+			keys = append(keys, strings.ToLower(strings.Replace(h, " ", "_", -1)))
+		}
+		for _, row := range t.rows {
+			fields := []interface{}{}
+			for col, cell := range row {
+				if col < len(keys) {
+					fields = append(fields, keys[col], cell)
+				}
+			}
+			fmt.Fprintln(w, kv(fields...))
+		}
+		return
+	}
+	widths := make([]int, len(t.headers))
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for col, cell := range row {
+			if col < len(widths) && utf8.RuneCountInString(cell) > widths[col] {
+				widths[col] = utf8.RuneCountInString(cell)
+			}
+		}
+	}
+	line := func(cells []string, header bool) {
+		parts := []string{}
+		for col, cell := range cells {
+			padded := cell
+			// The last column isn't padded so there's no trailing space
+			if col < len(cells)-1 {
+				padded += strings.Repeat(" ", widths[col]-utf8.RuneCountInString(cell))
+			}
+			switch {
+			case header:
+				padded = colorize(colorBold, padded)
+			case t.colors[col] != "":
+				padded = colorize(t.colors[col], padded)
+			}
+			parts = append(parts, padded)
+		}
+		fmt.Fprintln(w, strings.Join(parts, "  "))
+	}
+	line(t.headers, true)
+	for _, row := range t.rows {
+		line(row, false)
+	}
+}
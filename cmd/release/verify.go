@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fernferret/release/pkg/release"
+	flag "github.com/spf13/pflag"
+)
+
+func verifyMain(args []string) {
+	var remote, sshKeyPath string
+	var verbose, immutable, includeMissing bool
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.StringVarP(&remote, "remote", "r", "origin", "git remote to compare against")
+	fs.BoolVar(&immutable, "immutable", false, "check that no release tag was moved on the remote")
+	fs.BoolVar(&includeMissing, "include-missing", false, "also report release tags that don't exist on the remote")
+	fs.StringVar(&sshKeyPath, "ssh-key", fmt.Sprintf("%s/.ssh/id_rsa", homeDir()), "specify path to ssh key")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "enable more output")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: release verify --immutable [options]\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	setupLogging(verbose)
+
+	if !immutable {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rm := openManager("", sshKeyPath)
+	useMetadata(rm)
+	release.CheckIfError(rm.CheckRemote(remote), fmt.Sprintf("problem with remote '%s'", remote))
+	mismatches, err := rm.VerifyRemoteTags(remote, authForRemote(rm, remote, sshKeyPath), includeMissing)
+	release.CheckIfError(err, fmt.Sprintf("failed to compare tags with remote %s", remote))
+
+	if len(mismatches) == 0 {
+		say(fmt.Sprintf("all release tags match remote %s", remote), "status", "ok", "remote", remote)
+		return
+	}
+	for _, m := range mismatches {
+		recorded := "released at"
+		if !m.FromAuditLog {
+			recorded = "not in the audit log, local tag at"
+		}
+		switch {
+		case m.MovedLocally():
+			say(fmt.Sprintf("MOVED   %s: %s %s, local tag now at %s", m.Tag, recorded, m.Recorded, m.Local), "status", "moved-locally", "tag", m.Tag, "recorded", m.Recorded, "local", m.Local)
+		case m.Missing():
+			say(fmt.Sprintf("MISSING %s: %s %s, not on remote %s", m.Tag, recorded, m.Recorded, remote), "status", "missing", "tag", m.Tag, "recorded", m.Recorded, "remote", remote)
+		default:
+			say(fmt.Sprintf("MOVED   %s: %s %s, remote %s has %s", m.Tag, recorded, m.Recorded, remote, m.Remote), "status", "moved", "tag", m.Tag, "recorded", m.Recorded, "remote", remote, "remote_hash", m.Remote)
+		}
+	}
+	os.Exit(1)
+}
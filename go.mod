@@ -1,15 +1,18 @@
-module release
+module github.com/fernferret/release
 
 go 1.14
 
 require (
 	github.com/cactus/gostrftime v0.0.0-20190922123236-884915fd58c8
+	github.com/go-git/go-billy/v5 v5.0.0
 	github.com/go-git/go-git/v5 v5.1.0
 	github.com/imdario/mergo v0.3.10 // indirect
+	github.com/mattn/go-sqlite3 v1.14.4
 	github.com/rs/zerolog v1.19.0
 	github.com/spf13/pflag v1.0.5
 	github.com/zenazn/goji v0.9.0 // indirect
 	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899
-	golang.org/x/net v0.0.0-20200707034311-ab3426394381 // indirect
+	golang.org/x/net v0.0.0-20200707034311-ab3426394381
 	golang.org/x/sys v0.0.0-20200625212154-ddb9806d33ae // indirect
+	gopkg.in/yaml.v2 v2.3.0
 )
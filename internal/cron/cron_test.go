@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"* * * * MON-FOO",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%s should be invalid", expr)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2020-07-06 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2020, time.July, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", at(6, 12, 34), true},
+		{"*/15 * * * *", at(6, 12, 45), true},
+		{"*/15 * * * *", at(6, 12, 46), false},
+		{"10-20/5 * * * *", at(6, 12, 15), true},
+		{"10-20/5 * * * *", at(6, 12, 25), false},
+		{"0 9-17 * * *", at(6, 17, 0), true},
+		{"0 9-17 * * *", at(6, 18, 0), false},
+		{"0 0,12 * * *", at(6, 12, 0), true},
+		{"* * * JUL *", at(6, 0, 0), true},
+		{"* * * jan-jun *", at(6, 0, 0), false},
+		{"* * * * MON-FRI", at(6, 0, 0), true},
+		{"* * * * SAT,SUN", at(6, 0, 0), false},
+		{"* * * * 7", at(5, 0, 0), true}, // 7 is Sunday too
+		{"* * * * 0", at(5, 0, 0), true},
+		// Both day fields restricted, either one matching is enough
+		{"* * 1 * MON", at(6, 0, 0), true},
+		{"* * 1 * MON", at(1, 0, 0), true},
+		{"* * 1 * MON", at(7, 0, 0), false},
+		// */N in the day of the month restricts it, it's ORed with the day of
+		// the week (the 6th is even, the 7th a Tuesday)
+		{"* * */2 * TUE", at(7, 0, 0), true},
+		{"* * */2 * MON", at(7, 0, 0), true},
+		{"* * */2 * WED", at(6, 0, 0), false},
+		// Only one restricted, it has to match
+		{"* * 1 * *", at(6, 0, 0), false},
+		{"* * * * MON", at(7, 0, 0), false},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Matches(test.t); got != test.want {
+			t.Errorf("%s at %s: got %v, want %v", test.expr, test.t.Format("Mon Jan 2 15:04"), got, test.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2020, time.July, 6, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2020, time.July, 6, 12, 35, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.July, 6, 12, 45, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2020, time.July, 7, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON", time.Date(2020, time.July, 13, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * FRI", time.Date(2020, time.July, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 FEB *", time.Time{}},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Next(from); !got.Equal(test.want) {
+			t.Errorf("%s: got %s, want %s", test.expr, got, test.want)
+		}
+	}
+	// Strictly after, a matching minute isn't returned again
+	s, _ := Parse("34 12 * * *")
+	if got := s.Next(time.Date(2020, time.July, 6, 12, 34, 0, 0, time.UTC)); !got.Equal(time.Date(2020, time.July, 7, 12, 34, 0, 0, time.UTC)) {
+		t.Errorf("got %s", got)
+	}
+}
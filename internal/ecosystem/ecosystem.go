@@ -0,0 +1,143 @@
+// Package ecosystem reads and writes the version of language packages
+// (package.json, pyproject.toml) so published package versions can be kept in
+// line with the git tags.
+package ecosystem
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Version is the CalVer version being released
+type Version struct {
+	Year       int
+	Month      int
+	Day        int // Day of the year for YYYY.DDD.N versions, 0 otherwise
+	Release    int
+	PreRelease string // rc.1, beta2, empty for final releases
+}
+
+// Ecosystem knows how a package manager spells versions and where it keeps
+// them in its manifest
+type Ecosystem interface {
+	// Format returns the version the way the package manager expects it
+	Format(v Version) string
+	// Version returns the version in the manifest
+	Version(manifest []byte) (string, error)
+	// SetVersion returns the manifest with its version replaced
+	SetVersion(manifest []byte, version string) ([]byte, error)
+}
+
+// numbers returns the version as the three numbers package managers expect
+func (v Version) numbers() string {
+	if v.Day != 0 {
+		return fmt.Sprintf("%d.%d.%d", v.Year, v.Day, v.Release)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Year, v.Month, v.Release)
+}
+
+var ecosystems = map[string]Ecosystem{}
+
+// Register makes an ecosystem available by name, later registrations replace
+// earlier ones
+func Register(name string, e Ecosystem) {
+	ecosystems[name] = e
+}
+
+// Get returns the named ecosystem
+func Get(name string) (Ecosystem, error) {
+	e, ok := ecosystems[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown package type '%s', must be one of %s", name, strings.Join(Names(), ", "))
+	}
+	return e, nil
+}
+
+// Names returns the registered ecosystems, sorted
+func Names() []string {
+	names := []string{}
+	for name := range ecosystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("npm", npm{})
+	Register("pypi", pypi{})
+}
+
+// replaceFirst replaces the first submatch (group 1) of pat in data
+func replaceFirst(pat *regexp.Regexp, data []byte, value string) ([]byte, error) {
+	loc := pat.FindSubmatchIndex(data)
+	if loc == nil {
+		return nil, fmt.Errorf("no version found")
+	}
+	out := append([]byte{}, data[:loc[2]]...)
+	out = append(out, value...)
+	return append(out, data[loc[3]:]...), nil
+}
+
+// npm is package.json, versions are semver so the leading zeros go
+type npm struct{}
+
+// The first "version" key is the package's own, dependencies come later in
+// any package.json written by npm
+var npmVersion = regexp.MustCompile(`"version"\s*:\s*"([^"]*)"`)
+
+func (npm) Format(v Version) string {
+	version := v.numbers()
+	if v.PreRelease != "" {
+		version += "-" + v.PreRelease
+	}
+	return version
+}
+
+func (npm) Version(manifest []byte) (string, error) {
+	m := npmVersion.FindSubmatch(manifest)
+	if m == nil {
+		return "", fmt.Errorf("no version found")
+	}
+	return string(m[1]), nil
+}
+
+func (npm) SetVersion(manifest []byte, version string) ([]byte, error) {
+	return replaceFirst(npmVersion, manifest, version)
+}
+
+// pypi is pyproject.toml, with the version in [project] or [tool.poetry]
+type pypi struct{}
+
+var (
+	pypiVersion = regexp.MustCompile(`(?m)^\[(?:project|tool\.poetry)\][^\[]*?^version\s*=\s*["']([^"']*)["']`)
+	pypiPre     = regexp.MustCompile(`^(alpha|beta|rc|pre)[.-]?(\d*)$`)
+)
+
+// Format follows PEP 440, rc.1 becomes rc1, alpha and beta become a and b
+func (pypi) Format(v Version) string {
+	version := v.numbers()
+	if m := pypiPre.FindStringSubmatch(v.PreRelease); m != nil {
+		kind := map[string]string{"alpha": "a", "beta": "b", "rc": "rc", "pre": "rc"}[m[1]]
+		num := m[2]
+		if num == "" {
+			num = "0"
+		}
+		version += kind + num
+	}
+	return version
+}
+
+func (pypi) Version(manifest []byte) (string, error) {
+	m := pypiVersion.FindSubmatch(manifest)
+	if m == nil {
+		return "", fmt.Errorf("no version found in [project] or [tool.poetry]")
+	}
+	return string(m[1]), nil
+}
+
+func (pypi) SetVersion(manifest []byte, version string) ([]byte, error) {
+	return replaceFirst(pypiVersion, manifest, version)
+}
@@ -0,0 +1,327 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type node interface {
+	eval(f Fields) (interface{}, error)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	if p.done() || p.peek().kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if p.peek().text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("!") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner}, nil
+	}
+	if !p.done() && p.peek().kind == tokLParen {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseOperand() (node, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.peek()
+	p.pos++
+	switch tok.kind {
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		}
+		return fieldNode(tok.text), nil
+	case tokString:
+		return literalNode{tok.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", tok.text, tok.pos)
+		}
+		return literalNode{n}, nil
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("==", "!=", "<", "<=", ">", ">=", "contains", "startswith", "endswith", "matches") {
+		return left, nil
+	}
+	op := p.peek().text
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	cmp := &compareNode{op: op, left: left, right: right}
+	if op == "matches" {
+		lit, ok := right.(literalNode)
+		pattern, isString := lit.value.(string)
+		if !ok || !isString {
+			return nil, fmt.Errorf("matches needs a string pattern")
+		}
+		if cmp.re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return cmp, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(f Fields) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode string
+
+func (n fieldNode) eval(f Fields) (interface{}, error) {
+	v, ok := f.Field(string(n))
+	if !ok {
+		return nil, fmt.Errorf("unknown field '%s'", string(n))
+	}
+	return v, nil
+}
+
+type notNode struct {
+	inner node
+}
+
+func (n *notNode) eval(f Fields) (interface{}, error) {
+	v, err := n.inner.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	or          bool
+	left, right node
+}
+
+func (n *logicalNode) eval(f Fields) (interface{}, error) {
+	left, err := n.left.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	// Short circuit
+	if truthy(left) == n.or {
+		return n.or, nil
+	}
+	right, err := n.right.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// coerce makes both values the same type, strings are converted to dates or
+// numbers if the other side is one
+func coerce(left, right interface{}) (interface{}, interface{}, error) {
+	switch l := left.(type) {
+	case time.Time:
+		if s, ok := right.(string); ok {
+			t, err := parseDate(s)
+			return l, t, err
+		}
+	case float64:
+		if s, ok := right.(string); ok {
+			n, err := strconv.ParseFloat(s, 64)
+			return l, n, err
+		}
+	case string:
+		switch right.(type) {
+		case time.Time, float64:
+			r, l2, err := coerce(right, left)
+			return l2, r, err
+		}
+	}
+	return left, right, nil
+}
+
+func (n *compareNode) eval(f Fields) (interface{}, error) {
+	left, err := n.left.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(f)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "contains", "startswith", "endswith", "matches":
+		l, ok := left.(string)
+		r, rok := right.(string)
+		if !ok || !rok {
+			return nil, fmt.Errorf("%s only works on strings", n.op)
+		}
+		switch n.op {
+		case "contains":
+			return strings.Contains(l, r), nil
+		case "startswith":
+			return strings.HasPrefix(l, r), nil
+		case "endswith":
+			return strings.HasSuffix(l, r), nil
+		}
+		return n.re.MatchString(l), nil
+	}
+
+	left, right, err = coerce(left, right)
+	if err != nil {
+		return nil, err
+	}
+	var c int
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("can't compare a string with %v", right)
+		}
+		c = strings.Compare(l, r)
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("can't compare a number with %v", right)
+		}
+		if l < r {
+			c = -1
+		} else if l > r {
+			c = 1
+		}
+	case time.Time:
+		r, ok := right.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("can't compare a date with %v", right)
+		}
+		if l.Before(r) {
+			c = -1
+		} else if l.After(r) {
+			c = 1
+		}
+	case bool:
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("can't compare a boolean with %v", right)
+		}
+		if n.op != "==" && n.op != "!=" {
+			return nil, fmt.Errorf("booleans can only be compared with == and !=")
+		}
+		if l != r {
+			c = 1
+		}
+	default:
+		return nil, fmt.Errorf("can't compare %v", left)
+	}
+
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
@@ -0,0 +1,174 @@
+// Package filter implements the small expression language used by --filter,
+// for example:
+//
+//	component == "api" && date > "2020-01-01" && tagger.email endswith "@corp.com"
+//
+// Expressions compare fields against literals (or other fields) with ==, !=,
+// <, <=, >, >=, contains, startswith, endswith and matches (a regular
+// expression), and combine them with &&, ||, ! (or and, or, not) and
+// parentheses. A field on its own is true if it's a true boolean or a non-empty
+// string. Strings compared against dates are parsed as 2006-01-02 or RFC3339.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields is something expressions can be evaluated against
+type Fields interface {
+	// Field returns the value of the named field, the value must be a string,
+	// bool, float64 or time.Time. The second value is false for unknown fields.
+	Field(name string) (interface{}, bool)
+}
+
+// Expr is a parsed expression
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses an expression
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.peek().text, p.peek().pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the expression as it was given
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match evaluates the expression against the fields
+func (e *Expr) Match(f Fields) (bool, error) {
+	v, err := e.root.eval(f)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case float64:
+		return val != 0
+	case time.Time:
+		return !val.IsZero()
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var wordOps = map[string]string{
+	"and": "&&", "or": "||", "not": "!",
+	"contains": "contains", "startswith": "startswith", "endswith": "endswith", "matches": "matches",
+}
+
+func isIdentChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && (c == '.' || (c >= '0' && c <= '9')))
+}
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			text := src[i+1 : end]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(src[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+				}
+				text = unquoted
+			}
+			tokens = append(tokens, token{tokString, text, i})
+			i = end + 1
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:end], i})
+			i = end
+		case isIdentChar(c, true):
+			end := i
+			for end < len(src) && isIdentChar(src[end], false) {
+				end++
+			}
+			word := src[i:end]
+			if op, ok := wordOps[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{tokOp, op, i})
+			} else {
+				tokens = append(tokens, token{tokIdent, word, i})
+			}
+			i = end
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
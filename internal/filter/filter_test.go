@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+// fields is a plain map of fields
+type fields map[string]interface{}
+
+func (f fields) Field(name string) (interface{}, bool) {
+	v, ok := f[name]
+	return v, ok
+}
+
+var release = fields{
+	"tag":          "2020.07.003-api",
+	"component":    "api",
+	"prerelease":   "",
+	"increment":    float64(3),
+	"breaking":     true,
+	"date":         time.Date(2020, time.July, 14, 9, 30, 0, 0, time.UTC),
+	"tagger.email": "dev@corp.com",
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]bool{
+		`component == "api"`:                              true,
+		`component == 'web'`:                              false,
+		`component != "web"`:                              true,
+		`increment > 2 && increment <= 3`:                 true,
+		`increment >= "4"`:                                false,
+		`breaking`:                                        true,
+		`!breaking`:                                       false,
+		`prerelease`:                                      false,
+		`not prerelease and breaking`:                     true,
+		`component == "web" || breaking`:                  true,
+		`component == "web" or increment < 3`:             false,
+		`!(component == "web" || increment < 3)`:          true,
+		`date > "2020-07-01" && date < "2020-08-01"`:      true,
+		`date >= "2020-07-14T09:30:00Z"`:                  true,
+		`tagger.email endswith "@corp.com"`:               true,
+		`tag startswith "2020.07" && tag contains "-api"`: true,
+		`tag matches "^2020\\.07\\.\\d{3}-api$"`:          true,
+		`tag matches "-web$"`:                             false,
+		`component == component`:                          true,
+	}
+	for src, want := range tests {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		if expr.String() != src {
+			t.Errorf("%s: String() is %s", src, expr.String())
+		}
+		got, err := expr.Match(release)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+		} else if got != want {
+			t.Errorf("%s: got %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`component ==`,
+		`component == "api`,
+		`(component == "api"`,
+		`component == "api")`,
+		`component == "api" &&`,
+		`tag matches "["`,
+		`component = "api"`,
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("%q parsed", src)
+		}
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	for _, src := range []string{
+		`nosuchfield == "x"`,
+		`increment contains "3"`,
+		`date > "last tuesday"`,
+		`increment > "three"`,
+	} {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		if _, err := expr.Match(release); err == nil {
+			t.Errorf("%s matched without an error", src)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package i18n translates what release shows people: the interactive prompts,
+// the TUI and reports. Logs and errors stay in English so they can be searched
+// for. Messages are looked up by their English text, anything missing from a
+// catalog is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// English is the language messages are written in
+const English = "en"
+
+// catalogs maps languages to their translations of the English messages
+var catalogs = map[string]map[string]string{
+	"ja": ja,
+}
+
+var current = English
+
+// Languages returns the languages output can be shown in
+func Languages() []string {
+	langs := []string{English}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// normalize turns locales like ja_JP.UTF-8 or ja-JP into the language, ja
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(locale, "_-.@"); idx >= 0 {
+		locale = locale[:idx]
+	}
+	return locale
+}
+
+// Detect returns the language of the environment, from the first of LC_ALL,
+// LC_MESSAGES and LANG that is set. Locales without a catalog (C, POSIX,
+// fr_FR, ...) are English.
+func Detect() string {
+	lang := ""
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang = os.Getenv(env); lang != "" {
+			break
+		}
+	}
+	lang = normalize(lang)
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return English
+}
+
+// SetLanguage switches the output to lang, an error if there's no catalog
+// for it
+func SetLanguage(lang string) error {
+	lang = normalize(lang)
+	if _, ok := catalogs[lang]; !ok && lang != English {
+		return fmt.Errorf("unknown language '%s', must be one of %s", lang, strings.Join(Languages(), ", "))
+	}
+	current = lang
+	return nil
+}
+
+// Language returns the language output is shown in
+func Language() string {
+	return current
+}
+
+// T translates msg, with args it's a format for fmt.Sprintf
+func T(msg string, args ...interface{}) string {
+	if translated, ok := catalogs[current][msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,34 @@
+package i18n
+
+// ja is the Japanese catalog
+var ja = map[string]string{
+	// Reports
+	"Releases %s to %s":                 "リリース %s 〜 %s",
+	"%d release(s) of %d component(s).": "%d 件のリリース（%d コンポーネント）。",
+	"Released:":                         "リリース:",
+	"(breaking)":                        "（破壊的変更）",
+	"Contributors:":                     "貢献者:",
+
+	// Prompts
+	"release anyway? [y/N] ":                                      "このままリリースしますか？ [y/N] ",
+	"version scheme (YYYY.MM.RRR or YYYY.DDD.N)?":                 "バージョン形式（YYYY.MM.RRR または YYYY.DDD.N）は？",
+	"paths of component %s (comma separated, - to leave it out)?": "コンポーネント %s のパス（カンマ区切り、- で除外）は？",
+	"token for %s: ": "%s のトークン: ",
+	"%s: %s? [y/N] ": "%s: %s は完了していますか？ [y/N] ",
+	"use this draft as the message of %s? [y/N] ": "この下書きを %s のメッセージにしますか？ [y/N] ",
+
+	// TUI
+	"release tui needs a terminal":                             "release tui は端末で実行してください",
+	"release tui can't be used with --plain, use release list": "release tui は --plain と併用できません。release list を使ってください",
+	"all":                     "すべて",
+	"failed to create %s: %s": "%s を作成できませんでした: %s",
+	"created release %s, push it with: git push origin %s": "リリース %s を作成しました。プッシュするには: git push origin %s",
+	"cancelled":                        "キャンセルしました",
+	"Create release %s at HEAD? [y/N]": "HEAD にリリース %s を作成しますか？ [y/N]",
+	"↑/↓ select  ←/→ component  enter changes  esc back  n new release  q quit": "↑/↓ 選択  ←/→ コンポーネント  enter 変更履歴  esc 戻る  n 新規リリース  q 終了",
+	"no releases yet, press n to create one":                                    "リリースはまだありません。n で作成できます",
+	"failed to load changes: %s":                                                "変更履歴を読み込めませんでした: %s",
+	"the beginning":                                                             "最初",
+	"%s since %s (%d commits)":                                                  "%s（%s 以降、%d コミット）",
+	"... %d more":                                                               "... 他 %d 件",
+}
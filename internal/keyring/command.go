@@ -0,0 +1,95 @@
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychain is the macOS keychain, through the security tool
+type keychain struct{}
+
+func (keychain) String() string {
+	return "macOS keychain"
+}
+
+func (keychain) get(name string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", Service, "-a", name, "-w").Output()
+	if err != nil {
+		if exitCode(err) == 44 {
+			return "", ErrNotFound
+		}
+		return "", commandError("security find-generic-password", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (keychain) set(name, secret string) error {
+	// -U updates the item if it exists. -w has to come last without a value
+	// so the secret is read from stdin, where it doesn't show up in the
+	// process list. It's asked for twice, the second time to confirm it.
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", Service, "-a", name, "-w")
+	cmd.Stdin = strings.NewReader(secret + "\n" + secret + "\n")
+	return commandError("security add-generic-password", cmd.Run())
+}
+
+func (keychain) delete(name string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", Service, "-a", name).Run()
+	if exitCode(err) == 44 {
+		return ErrNotFound
+	}
+	return commandError("security delete-generic-password", err)
+}
+
+// secretService is the freedesktop secret service (GNOME keyring, KWallet)
+// through libsecret's secret-tool
+type secretService struct{}
+
+func (secretService) String() string {
+	return "secret service"
+}
+
+func (secretService) get(name string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", Service, "account", name).Output()
+	if err != nil {
+		// secret-tool exits 1 without output when nothing matched
+		if exitCode(err) == 1 {
+			return "", ErrNotFound
+		}
+		return "", commandError("secret-tool lookup", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (secretService) set(name, secret string) error {
+	// The secret goes in on stdin so it never shows up in the process list
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%s: %s", Service, name), "service", Service, "account", name)
+	cmd.Stdin = strings.NewReader(secret)
+	return commandError("secret-tool store", cmd.Run())
+}
+
+func (s secretService) delete(name string) error {
+	if _, err := s.get(name); err != nil {
+		return err
+	}
+	return commandError("secret-tool clear", exec.Command("secret-tool", "clear", "service", Service, "account", name).Run())
+}
+
+func exitCode(err error) int {
+	if exit, ok := err.(*exec.ExitError); ok {
+		return exit.ExitCode()
+	}
+	return -1
+}
+
+// commandError adds what the tool printed to stderr to the error
+func commandError(what string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if exit, ok := err.(*exec.ExitError); ok && len(bytes.TrimSpace(exit.Stderr)) > 0 {
+		return fmt.Errorf("%s failed: %s", what, bytes.TrimSpace(exit.Stderr))
+	}
+	return fmt.Errorf("%s failed: %s", what, err)
+}
@@ -0,0 +1,189 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PasswordEnv is the environment variable with the password of the encrypted
+// file, it's asked for on a terminal when it isn't set
+const PasswordEnv = "RELEASE_KEYRING_PASSWORD"
+
+// fileStore keeps the secrets in a file encrypted with AES-GCM, the key is
+// derived from a password with scrypt
+type fileStore struct {
+	path     string
+	password []byte
+	secrets  map[string]string // nil until the file was read
+}
+
+type encryptedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func newFileStore() *fileStore {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return &fileStore{path: filepath.Join(dir, "release", "keyring")}
+}
+
+func (f *fileStore) String() string {
+	return fmt.Sprintf("encrypted file %s", f.path)
+}
+
+// getPassword returns the password from the environment or the terminal
+func (f *fileStore) getPassword() ([]byte, error) {
+	if f.password != nil {
+		return f.password, nil
+	}
+	if p := os.Getenv(PasswordEnv); p != "" {
+		f.password = []byte(p)
+		return f.password, nil
+	}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("set %s to use the keyring file %s", PasswordEnv, f.path)
+	}
+	fmt.Fprintf(os.Stderr, "password for %s: ", f.path)
+	p, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, errors.New("the keyring password can't be empty")
+	}
+	f.password = p
+	return p, nil
+}
+
+func gcm(password, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load reads and decrypts the file once, a missing file is an empty keyring
+// and doesn't need the password
+func (f *fileStore) load() error {
+	if f.secrets != nil {
+		return nil
+	}
+	raw, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		f.secrets = map[string]string{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var enc encryptedFile
+	if err := json.Unmarshal(raw, &enc); err != nil {
+		return fmt.Errorf("keyring file %s is corrupt: %s", f.path, err)
+	}
+	password, err := f.getPassword()
+	if err != nil {
+		return err
+	}
+	aead, err := gcm(password, enc.Salt)
+	if err != nil {
+		return err
+	}
+	plain, err := aead.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		f.password = nil
+		return fmt.Errorf("wrong password for the keyring file %s", f.path)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("keyring file %s is corrupt: %s", f.path, err)
+	}
+	f.secrets = secrets
+	return nil
+}
+
+// save encrypts the secrets with a new salt and nonce and replaces the file
+func (f *fileStore) save() error {
+	password, err := f.getPassword()
+	if err != nil {
+		return err
+	}
+	enc := encryptedFile{Salt: make([]byte, 16)}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return err
+	}
+	aead, err := gcm(password, enc.Salt)
+	if err != nil {
+		return err
+	}
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return err
+	}
+	plain, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+	enc.Data = aead.Seal(nil, enc.Nonce, plain, nil)
+	raw, err := json.Marshal(enc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func (f *fileStore) get(name string) (string, error) {
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	secret, ok := f.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *fileStore) set(name, secret string) error {
+	if err := f.load(); err != nil {
+		return err
+	}
+	f.secrets[name] = secret
+	return f.save()
+}
+
+func (f *fileStore) delete(name string) error {
+	if err := f.load(); err != nil {
+		return err
+	}
+	if _, ok := f.secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(f.secrets, name)
+	return f.save()
+}
@@ -0,0 +1,97 @@
+// Package keyring stores the secrets release needs (forge tokens, webhook
+// urls, passwords) in the OS keyring, or in an encrypted file where there is
+// none, so they stay out of shell history and CI logs.
+package keyring
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+)
+
+// Service is what the secrets are stored under in the OS keyring
+const Service = "release"
+
+// ErrNotFound is returned when the keyring has no secret with the name
+var ErrNotFound = errors.New("secret not found in the keyring")
+
+type backend interface {
+	get(name string) (string, error)
+	set(name, secret string) error
+	delete(name string) error
+	String() string
+}
+
+var (
+	chooseOnce sync.Once
+	chosen     backend
+)
+
+// store picks the backend the first time it's needed: RELEASE_KEYRING
+// (keychain, secret-service or file) if it's set, the OS keyring if its tool
+// is installed, the encrypted file otherwise
+func store() backend {
+	chooseOnce.Do(func() {
+		switch os.Getenv("RELEASE_KEYRING") {
+		case "keychain":
+			chosen = keychain{}
+		case "secret-service":
+			chosen = secretService{}
+		case "file":
+			chosen = newFileStore()
+		default:
+			chosen = detect()
+		}
+	})
+	return chosen
+}
+
+func detect() backend {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return keychain{}
+		}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := exec.LookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			return secretService{}
+		}
+	}
+	return newFileStore()
+}
+
+// Backend describes where secrets are stored
+func Backend() string {
+	return store().String()
+}
+
+// Get returns the secret with the name, ErrNotFound if there is none
+func Get(name string) (string, error) {
+	return store().get(name)
+}
+
+// Set stores the secret under the name, replacing what was there
+func Set(name, secret string) error {
+	return store().set(name, secret)
+}
+
+// Delete removes the secret with the name, ErrNotFound if there is none
+func Delete(name string) error {
+	return store().delete(name)
+}
+
+// Getenv returns the environment variable if it's set, otherwise the keyring's
+// secret with the same name. Empty if neither has it, a keyring that can't be
+// read counts as not having it.
+func Getenv(name string) string {
+	if name == "" {
+		return ""
+	}
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	v, _ := Get(name)
+	return v
+}
@@ -0,0 +1,77 @@
+package release
+
+import (
+	"sort"
+	"strings"
+)
+
+// AffectsTrailer lists the other teams owning files a release changed, see
+// Manager.AffectedTeams
+const AffectsTrailer = "Affects-Teams"
+
+// AffectedTeams returns the teams other than the component's owners that own
+// (per CODEOWNERS) files changed by the commits of a release, see
+// releaseCommits. A release changing them is cross-cutting. It's empty when
+// LoadOwnership found no CODEOWNERS.
+func (r *Manager) AffectedTeams(tag, target string) ([]string, error) {
+	if r.codeOwners == nil {
+		return nil, nil
+	}
+	component := tagComponent(r.tagTemplate, tag)
+	commits, err := r.releaseCommits(tag, target, r.components[component])
+	if err != nil {
+		return nil, err
+	}
+	hashes := []string{}
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash)
+	}
+	changed, err := r.changedFiles(hashes)
+	if err != nil {
+		return nil, err
+	}
+	owners := map[string]bool{}
+	for _, team := range r.Teams(component) {
+		owners[team] = true
+	}
+	seen := map[string]bool{}
+	teams := []string{}
+	for _, files := range changed {
+		for _, name := range files {
+			for _, team := range r.codeOwners.Owners(name) {
+				if !owners[team] && !seen[team] {
+					seen[team] = true
+					teams = append(teams, team)
+				}
+			}
+		}
+	}
+	sort.Strings(teams)
+	return teams, nil
+}
+
+// AffectedTeams returns the teams recorded in the AffectsTrailer of the
+// release, empty unless it's cross-cutting
+func (r *Release) AffectedTeams() []string {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == AffectsTrailer {
+			return splitTeams(t.Value)
+		}
+	}
+	return nil
+}
+
+// IsCrossCutting reports whether the release changed files of other teams
+func (r *Release) IsCrossCutting() bool {
+	return len(r.AffectedTeams()) > 0
+}
+
+func splitTeams(value string) []string {
+	teams := []string{}
+	for _, team := range strings.Split(value, ",") {
+		if team = strings.TrimSpace(team); team != "" {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
@@ -0,0 +1,57 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestAffectedTeams(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"CODEOWNERS":  "/api/ @org/payments\n/shared/ @org/platform\n/web/ @org/web\n",
+		"api/main.go": "package main\n",
+	}
+	if _, err := repo.Commit("initial commit", files); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.001-api", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("change the web", map[string]string{"web/index.html": "hi\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("use the shared client", map[string]string{"api/main.go": "package main // v2\n", "shared/client.go": "package shared\n"}); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if teams, err := rm.AffectedTeams("2020.07.002-api", ""); err != nil || teams != nil {
+		t.Errorf("nothing is affected without ownership loaded, got %v, %v", teams, err)
+	}
+	if err := rm.LoadOwnership(map[string]ComponentConfig{"api": {Paths: []string{"api"}}}); err != nil {
+		t.Fatal(err)
+	}
+	// The web commit isn't part of the api release
+	teams, err := rm.AffectedTeams("2020.07.002-api", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"@org/platform"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("got %v, want %v", teams, want)
+	}
+
+	rel := &Release{Tag: "2020.07.002-api", ReleaseMessage: AppendTrailer("release", AffectsTrailer, "@org/platform, @org/web")}
+	if !rel.IsCrossCutting() || !reflect.DeepEqual(rel.AffectedTeams(), []string{"@org/platform", "@org/web"}) {
+		t.Errorf("got %v from %q", rel.AffectedTeams(), rel.ReleaseMessage)
+	}
+	if (&Release{Tag: "2020.07.001-api"}).IsCrossCutting() {
+		t.Error("releases without the trailer aren't cross-cutting")
+	}
+}
@@ -0,0 +1,128 @@
+package release
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fernferret/release/pkg/release/apidiff"
+)
+
+// APIDiffConfig configures the exported API check for components that are Go
+// modules
+type APIDiffConfig struct {
+	// Policy is warn (default) or block, blocked releases need
+	// --acknowledge-breaking
+	Policy string `yaml:"policy"`
+	// Modules maps components to their module directory in the repository
+	Modules map[string]string `yaml:"modules"`
+}
+
+// Blocks reports whether incompatible changes stop the release
+func (c APIDiffConfig) Blocks() bool {
+	return c.Policy == "block"
+}
+
+func (c APIDiffConfig) validate() error {
+	if c.Policy != "" && c.Policy != "warn" && c.Policy != "block" {
+		return fmt.Errorf("apidiff policy must be warn or block, not '%s'", c.Policy)
+	}
+	return nil
+}
+
+// APIChanges compares the exported API of the Go module in dir between the
+// previous release of the given (not yet created) tag and its target revision
+// (HEAD if empty). There are no changes if the component was never released.
+func (r *Manager) APIChanges(tag, dir, target string) ([]apidiff.Change, error) {
+	prev := r.PreviousRelease(tag)
+	if prev == nil {
+		return nil, nil
+	}
+	head, err := r.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	oldAPI, err := r.moduleAPI(prev.Hash, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the API of %s: %w", prev.Tag, err)
+	}
+	newAPI, err := r.moduleAPI(head, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the API of %s: %w", head[:8], err)
+	}
+	return apidiff.Compare(oldAPI, newAPI), nil
+}
+
+// moduleAPI reads the Go files of the module in dir at the given commit
+func (r *Manager) moduleAPI(hash, dir string) (apidiff.API, error) {
+	if h := r.history(); h != nil {
+		files, err := h.files(hash, dir, ".go")
+		if err != nil {
+			return nil, err
+		}
+		return apidiff.Exports(files)
+	}
+	commit, err := r.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ensureObjects(commit.TreeHash); err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	dir = strings.Trim(path.Clean(dir), "/")
+	if dir != "" && dir != "." {
+		tree, err = tree.Tree(dir)
+		if err == object.ErrDirectoryNotFound {
+			// The module didn't exist yet
+			return apidiff.API{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	// Only the Go files are read, so partial clones only fetch those
+	goFiles := map[string]plumbing.Hash{}
+	blobs := []plumbing.Hash{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if entry.Mode.IsFile() && strings.HasSuffix(name, ".go") {
+			goFiles[name] = entry.Hash
+			blobs = append(blobs, entry.Hash)
+		}
+	}
+	if err := r.ensureObjects(blobs...); err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for name, hash := range goFiles {
+		blob, err := r.repo.BlobObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		rd, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		files[name], err = ioutil.ReadAll(rd)
+		rd.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return apidiff.Exports(files)
+}
@@ -0,0 +1,237 @@
+// Package apidiff compares the exported API of two versions of a Go module.
+// It's a lot simpler than golang.org/x/exp/apidiff: declarations are compared
+// by their printed form, so any change to an exported signature counts as
+// incompatible, but it only needs the source files, not a type-checked build.
+package apidiff
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of API change
+type Kind string
+
+// The kinds of changes, only Added is compatible
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is a single change to the exported API
+type Change struct {
+	Name string // package/dir.Identifier, methods are Type.Method
+	Kind Kind
+	Old  string // The old declaration, empty when added
+	New  string // The new declaration, empty when removed
+}
+
+// Compatible reports whether the change can't break users of the module
+func (c Change) Compatible() bool {
+	return c.Kind == Added
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("added %s", c.New)
+	case Removed:
+		return fmt.Sprintf("removed %s", c.Old)
+	}
+	return fmt.Sprintf("changed %s\n    to %s", c.Old, c.New)
+}
+
+// API is the exported API of a module, name -> declaration
+type API map[string]string
+
+// Exports parses the Go files of a module (path relative to the module root
+// -> contents) and returns its exported API. Test files, internal packages,
+// testdata and vendor directories aren't part of the API.
+func Exports(files map[string][]byte) (API, error) {
+	api := API{}
+	fset := token.NewFileSet()
+	for name, src := range files {
+		if !isAPIFile(name) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		if f.Name.Name == "main" {
+			continue
+		}
+		pkg := path.Dir(name)
+		for _, decl := range f.Decls {
+			addDecl(api, fset, pkg, decl)
+		}
+	}
+	return api, nil
+}
+
+func isAPIFile(name string) bool {
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+	for _, part := range strings.Split(path.Dir(name), "/") {
+		if part == "internal" || part == "testdata" || part == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+func addDecl(api API, fset *token.FileSet, pkg string, decl ast.Decl) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() {
+			return
+		}
+		name := d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			recv := receiverName(d.Recv.List[0].Type)
+			if !ast.IsExported(recv) {
+				return
+			}
+			name = recv + "." + name
+		}
+		d.Body = nil
+		d.Doc = nil
+		d.Recv = unnamed(d.Recv)
+		d.Type.Params = unnamed(d.Type.Params)
+		d.Type.Results = unnamed(d.Type.Results)
+		api[pkg+"."+name] = format(fset, d)
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					api[pkg+"."+s.Name.Name] = "type " + format(fset, stripType(s))
+				}
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					if n.IsExported() {
+						api[pkg+"."+n.Name] = strings.TrimSpace(fmt.Sprintf("%s %s %s", d.Tok, n.Name, format(fset, s.Type)))
+					}
+				}
+			}
+		}
+	}
+}
+
+// unnamed drops the names from a parameter list, renaming a parameter doesn't
+// change the API
+func unnamed(fields *ast.FieldList) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+	list := []*ast.Field{}
+	for _, f := range fields.List {
+		for i := 0; i < len(f.Names) || i == 0; i++ {
+			list = append(list, &ast.Field{Type: f.Type})
+		}
+	}
+	return &ast.FieldList{List: list}
+}
+
+// receiverName returns the type name of a method receiver (T for *T)
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	}
+	return ""
+}
+
+// stripType drops comments and unexported struct fields and interface methods
+// from a type spec, they're not part of the API
+func stripType(s *ast.TypeSpec) *ast.TypeSpec {
+	s.Doc, s.Comment = nil, nil
+	var fields *ast.FieldList
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	}
+	if fields == nil {
+		return s
+	}
+	kept := []*ast.Field{}
+	for _, f := range fields.List {
+		f.Doc, f.Comment = nil, nil
+		if len(f.Names) == 0 {
+			kept = append(kept, f)
+			continue
+		}
+		names := []*ast.Ident{}
+		for _, n := range f.Names {
+			if n.IsExported() {
+				names = append(names, n)
+			}
+		}
+		if len(names) > 0 {
+			f.Names = names
+			kept = append(kept, f)
+		}
+	}
+	fields.List = kept
+	return s
+}
+
+func format(fset *token.FileSet, node interface{}) string {
+	if node == nil {
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	if err := printer.Fprint(buf, fset, node); err != nil {
+		return fmt.Sprintf("%v", node)
+	}
+	// Collapse multi-line declarations so they compare and print on one line
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// Compare returns the changes from old to new, sorted by name
+func Compare(old, new API) []Change {
+	changes := []Change{}
+	for name, decl := range old {
+		newDecl, ok := new[name]
+		if !ok {
+			changes = append(changes, Change{Name: name, Kind: Removed, Old: decl})
+		} else if newDecl != decl {
+			changes = append(changes, Change{Name: name, Kind: Changed, Old: decl, New: newDecl})
+		}
+	}
+	for name, decl := range new {
+		if _, ok := old[name]; !ok {
+			changes = append(changes, Change{Name: name, Kind: Added, New: decl})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// Incompatible filters the changes down to the ones that break users
+func Incompatible(changes []Change) []Change {
+	incompatible := []Change{}
+	for _, c := range changes {
+		if !c.Compatible() {
+			incompatible = append(incompatible, c)
+		}
+	}
+	return incompatible
+}
@@ -0,0 +1,217 @@
+package release
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultChecksumFile is where checksums are written unless configured
+const DefaultChecksumFile = "SHA256SUMS"
+
+// AssetsConfig lists the release artifacts to write checksums for
+type AssetsConfig struct {
+	Paths  []string `yaml:"paths"`  // Globs relative to the repository root
+	Output string   `yaml:"output"` // Defaults to SHA256SUMS
+	Sign   string   `yaml:"sign"`   // gpg or cosign, unsigned if empty
+	Key    string   `yaml:"key"`    // gpg key id or cosign key file
+}
+
+func (c AssetsConfig) validate() error {
+	if c.Sign != "" && c.Sign != "gpg" && c.Sign != "cosign" {
+		return fmt.Errorf("assets sign must be gpg or cosign, not '%s'", c.Sign)
+	}
+	if c.Sign == "cosign" && c.Key == "" {
+		return fmt.Errorf("assets signed with cosign need a key")
+	}
+	return nil
+}
+
+// OutputFile returns the checksum file name
+func (c AssetsConfig) OutputFile() string {
+	if c.Output == "" {
+		return DefaultChecksumFile
+	}
+	return c.Output
+}
+
+// SignatureFile returns the detached signature written next to the checksum
+// file, empty if it isn't signed
+func (c AssetsConfig) SignatureFile() string {
+	switch c.Sign {
+	case "gpg":
+		return c.OutputFile() + ".asc"
+	case "cosign":
+		return c.OutputFile() + ".sig"
+	}
+	return ""
+}
+
+// Checksum is a line of a SHA256SUMS file
+type Checksum struct {
+	Path string // Relative to the checksum file
+	Sum  string
+}
+
+func (c Checksum) String() string {
+	return fmt.Sprintf("%s  %s", c.Sum, c.Path)
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksums hashes every file matching the globs, paths are made relative to
+// relTo (the directory the checksum file is written to)
+func Checksums(globs []string, relTo string) ([]Checksum, error) {
+	seen := map[string]bool{}
+	sums := []Checksum{}
+	for _, glob := range globs {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no assets match %s", glob)
+		}
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+			sum, err := sha256File(match)
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(relTo, match)
+			if err != nil {
+				return nil, err
+			}
+			sums = append(sums, Checksum{Path: filepath.ToSlash(rel), Sum: sum})
+		}
+	}
+	sort.Slice(sums, func(i, j int) bool {
+		return sums[i].Path < sums[j].Path
+	})
+	return sums, nil
+}
+
+// WriteChecksums writes the checksums in sha256sum format
+func WriteChecksums(path string, sums []Checksum) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	for _, s := range sums {
+		if _, err := fmt.Fprintln(f, s); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// ReadChecksums parses a file in sha256sum format
+func ReadChecksums(path string) ([]Checksum, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sums := []Checksum{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 2)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s:%d: not a sha256sum line", path, line)
+		}
+		// sha256sum marks binary mode with a * before the name
+		name := strings.TrimPrefix(strings.TrimLeft(fields[1], " "), "*")
+		sums = append(sums, Checksum{Path: name, Sum: fields[0]})
+	}
+	return sums, scanner.Err()
+}
+
+// AssetMismatch is an asset whose checksum doesn't match, Actual is empty if
+// the file is missing
+type AssetMismatch struct {
+	Path     string
+	Expected string
+	Actual   string
+	Err      error
+}
+
+// VerifyChecksums checks every file listed in the checksum file, paths are
+// relative to dir
+func VerifyChecksums(sums []Checksum, dir string) []AssetMismatch {
+	mismatches := []AssetMismatch{}
+	for _, s := range sums {
+		actual, err := sha256File(filepath.Join(dir, filepath.FromSlash(s.Path)))
+		if err != nil || actual != s.Sum {
+			mismatches = append(mismatches, AssetMismatch{Path: s.Path, Expected: s.Sum, Actual: actual, Err: err})
+		}
+	}
+	return mismatches
+}
+
+// SignChecksums writes a detached signature of the checksum file with gpg or
+// cosign, both have to be installed
+func SignChecksums(cfg AssetsConfig) error {
+	var cmd *exec.Cmd
+	switch cfg.Sign {
+	case "":
+		return nil
+	case "gpg":
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", cfg.SignatureFile()}
+		if cfg.Key != "" {
+			args = append(args, "--local-user", cfg.Key)
+		}
+		cmd = exec.Command("gpg", append(args, cfg.OutputFile())...)
+	case "cosign":
+		if offline {
+			return fmt.Errorf("cosign signs through its transparency log: %w", ErrOffline)
+		}
+		cmd = exec.Command("cosign", "sign-blob", "--yes", "--key", cfg.Key, "--output-signature", cfg.SignatureFile(), cfg.OutputFile())
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	return cmd.Run()
+}
+
+// VerifySignature checks the detached signature of the checksum file. For
+// cosign key is the public key.
+func VerifySignature(sign, key, file, signature string) error {
+	var cmd *exec.Cmd
+	switch sign {
+	case "gpg":
+		cmd = exec.Command("gpg", "--batch", "--verify", signature, file)
+	case "cosign":
+		if offline {
+			return fmt.Errorf("cosign verifies against its transparency log: %w", ErrOffline)
+		}
+		cmd = exec.Command("cosign", "verify-blob", "--key", key, "--signature", signature, file)
+	default:
+		return fmt.Errorf("unknown signature type '%s'", sign)
+	}
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd.Run()
+}
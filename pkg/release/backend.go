@@ -0,0 +1,204 @@
+package release
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// Ref is a ref and the object it points to. Hashes are full hex names of any
+// length, go-git's plumbing.Hash only holds SHA-1 ones.
+type Ref struct {
+	Name plumbing.ReferenceName
+	Hash string
+}
+
+// GitBackend runs the git operations release does the most of: reading refs
+// and the objects they point to, creating tags, pushing them and listing the
+// refs of remotes. Hashes are full hex strings so SHA-256 repositories work
+// too, see historyBackend for the history.
+type GitBackend interface {
+	String() string
+	// Refs returns every ref whose name starts with prefix, like refs/tags/
+	Refs(prefix string) ([]Ref, error)
+	// Reference returns what a ref points to, symbolic refs are followed.
+	// plumbing.ErrReferenceNotFound if it doesn't exist.
+	Reference(name plumbing.ReferenceName) (string, error)
+	// ResolveRevision returns the commit a revision (a tag, branch, hash,
+	// HEAD~2, ...) names
+	ResolveRevision(rev string) (string, error)
+	// Object reads an object, plumbing.ErrObjectNotFound if it's missing
+	Object(hash string) (plumbing.EncodedObject, error)
+	// WriteObject stores an object and returns its hash
+	WriteObject(obj plumbing.EncodedObject) (string, error)
+	// CreateReference creates a ref that doesn't exist yet, atomically.
+	// storage.ErrReferenceHasChanged if it does.
+	CreateReference(name plumbing.ReferenceName, hash string) error
+	// SetReference points a ref at hash, whatever it pointed to
+	SetReference(name plumbing.ReferenceName, hash string) error
+	// DeleteReference removes a ref, it's not an error if it doesn't exist
+	DeleteReference(name plumbing.ReferenceName) error
+	// Push pushes to options.RemoteName, writing what the remote says to
+	// options.Progress
+	Push(options *git.PushOptions) error
+	// Fetch fetches options.RefSpecs from options.RemoteName, tags too with
+	// git.AllTags
+	Fetch(options *git.FetchOptions) error
+	// ListRemote returns the refs advertised by the remote
+	ListRemote(remote string, auth transport.AuthMethod) ([]Ref, error)
+}
+
+// releaseLoader is implemented by backends that read every release at once
+// rather than ref by ref, the index isn't used with them. Tags are read with
+// the tag_template t when there is one.
+type releaseLoader interface {
+	loadReleases(t *TagTemplate) ([]Release, []TagProblem, error)
+}
+
+// GitConfig selects the git backend
+type GitConfig struct {
+	// Backend is go-git (in process, the default) or exec (runs the git
+	// binary, for repos or transports go-git doesn't handle well)
+	Backend string `yaml:"backend"`
+}
+
+func (c *GitConfig) validate() error {
+	switch c.Backend {
+	case "", "go-git", "exec":
+	default:
+		return fmt.Errorf("unknown git.backend '%s', must be go-git or exec", c.Backend)
+	}
+	return nil
+}
+
+// UseBackend switches the manager to the configured git backend. Releases
+// that are already loaded are kept, Reload reads them through the new one.
+// Repositories that don't use SHA-1 always use the exec backend, go-git only
+// reads SHA-1 objects.
+func (r *Manager) UseBackend(cfg GitConfig) error {
+	if format := r.ObjectFormat(); format != ObjectFormatSHA1 && cfg.Backend != "exec" {
+		log.Debug().Msgf("the repository uses %s, using the exec backend", format)
+		cfg.Backend = "exec"
+	}
+	switch cfg.Backend {
+	case "exec":
+		if r.repoDir == "" {
+			return fmt.Errorf("the exec git backend needs a repository on disk")
+		}
+		if _, err := exec.LookPath("git"); err != nil {
+			return fmt.Errorf("the exec git backend needs git: %w", err)
+		}
+		r.backend = newExecBackend(r.repo, r.repoDir)
+	default:
+		r.backend = &goGitBackend{r.repo}
+	}
+	return nil
+}
+
+// gitBackend returns the git backend, go-git unless UseBackend picked another
+func (r *Manager) gitBackend() GitBackend {
+	if r.backend == nil {
+		r.backend = &goGitBackend{r.repo}
+	}
+	return r.backend
+}
+
+// GitBackend describes how git is accessed
+func (r *Manager) GitBackend() string {
+	return r.gitBackend().String()
+}
+
+// goGitBackend does everything in process with go-git. It's only used in
+// SHA-1 repositories (see NewManager) so plumbing.NewHash doesn't truncate.
+type goGitBackend struct {
+	repo *git.Repository
+}
+
+func (b *goGitBackend) String() string {
+	return "go-git"
+}
+
+func (b *goGitBackend) Refs(prefix string) ([]Ref, error) {
+	iter, err := b.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	refs := []Ref{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && strings.HasPrefix(ref.Name().String(), prefix) {
+			refs = append(refs, Ref{Name: ref.Name(), Hash: ref.Hash().String()})
+		}
+		return nil
+	})
+	return refs, err
+}
+
+func (b *goGitBackend) Reference(name plumbing.ReferenceName) (string, error) {
+	ref, err := b.repo.Reference(name, true)
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+func (b *goGitBackend) ResolveRevision(rev string) (string, error) {
+	hash, err := b.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (b *goGitBackend) Object(hash string) (plumbing.EncodedObject, error) {
+	return b.repo.Storer.EncodedObject(plumbing.AnyObject, plumbing.NewHash(hash))
+}
+
+func (b *goGitBackend) WriteObject(obj plumbing.EncodedObject) (string, error) {
+	hash, err := b.repo.Storer.SetEncodedObject(obj)
+	return hash.String(), err
+}
+
+func (b *goGitBackend) CreateReference(name plumbing.ReferenceName, hash string) error {
+	// An old ref with a zero hash only matches a ref that doesn't exist yet
+	ref := plumbing.NewHashReference(name, plumbing.NewHash(hash))
+	return b.repo.Storer.CheckAndSetReference(ref, plumbing.NewHashReference(name, plumbing.ZeroHash))
+}
+
+func (b *goGitBackend) SetReference(name plumbing.ReferenceName, hash string) error {
+	return b.repo.Storer.SetReference(plumbing.NewHashReference(name, plumbing.NewHash(hash)))
+}
+
+func (b *goGitBackend) DeleteReference(name plumbing.ReferenceName) error {
+	return b.repo.Storer.RemoveReference(name)
+}
+
+func (b *goGitBackend) Push(options *git.PushOptions) error {
+	return b.repo.Push(options)
+}
+
+func (b *goGitBackend) Fetch(options *git.FetchOptions) error {
+	return b.repo.Fetch(options)
+}
+
+func (b *goGitBackend) ListRemote(remote string, auth transport.AuthMethod) ([]Ref, error) {
+	rem, err := b.repo.Remote(remote)
+	if err != nil {
+		return nil, err
+	}
+	list, err := rem.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, err
+	}
+	refs := []Ref{}
+	for _, ref := range list {
+		if ref.Type() == plumbing.HashReference {
+			refs = append(refs, Ref{Name: ref.Name(), Hash: ref.Hash().String()})
+		}
+	}
+	return refs, nil
+}
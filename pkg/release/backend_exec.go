@@ -0,0 +1,727 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// execBackend runs the git binary in the repository. Objects are read
+// through a single 'git cat-file --batch' started on first use. git handles
+// authentication with its own credential helpers and ssh config, auth
+// methods are ignored.
+type execBackend struct {
+	repo *git.Repository // Only used to look up remotes in offline mode
+	dir  string
+
+	mu    sync.Mutex
+	batch *catFile
+}
+
+// catFile is a running 'git cat-file --batch'
+type catFile struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func newExecBackend(repo *git.Repository, dir string) *execBackend {
+	return &execBackend{repo: repo, dir: dir}
+}
+
+func (b *execBackend) String() string {
+	return "exec git"
+}
+
+// git runs git with args in the repository and returns its output, errors
+// carry what git printed
+func (b *execBackend) git(stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = b.dir
+	cmd.Stdin = stdin
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+func (b *execBackend) Refs(prefix string) ([]Ref, error) {
+	// for-each-ref matches whole path components, the rest of the prefix is
+	// matched here
+	args := []string{"for-each-ref", "--format=%(objectname) %(refname)"}
+	if dir := prefix[:strings.LastIndex(prefix, "/")+1]; dir != "" {
+		args = append(args, dir)
+	}
+	out, err := b.git(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	refs := []Ref{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], prefix) {
+			continue
+		}
+		refs = append(refs, Ref{Name: plumbing.ReferenceName(fields[1]), Hash: fields[0]})
+	}
+	return refs, nil
+}
+
+func (b *execBackend) Reference(name plumbing.ReferenceName) (string, error) {
+	out, err := b.git(nil, "rev-parse", "--verify", "--quiet", name.String())
+	if err != nil {
+		// --quiet leaves nothing to tell a missing ref from a broken one
+		return "", plumbing.ErrReferenceNotFound
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (b *execBackend) ResolveRevision(rev string) (string, error) {
+	out, err := b.git(nil, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (b *execBackend) Object(hash string) (plumbing.EncodedObject, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.batch == nil {
+		batch, err := b.startCatFile()
+		if err != nil {
+			return nil, err
+		}
+		b.batch = batch
+	}
+	obj, err := b.batch.read(hash)
+	if err != nil && err != plumbing.ErrObjectNotFound {
+		// Whatever is left of the object would be read as the next one
+		b.batch.close()
+		b.batch = nil
+	}
+	return obj, err
+}
+
+func (b *execBackend) startCatFile() (*catFile, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = b.dir
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+	}
+	return &catFile{cmd: cmd, in: in, out: bufio.NewReader(out)}, nil
+}
+
+// read asks for an object and reads it, the output is "<hash> <type>
+// <size>\n<content>\n" or "<hash> missing\n"
+func (c *catFile) read(hash string) (plumbing.EncodedObject, error) {
+	if _, err := fmt.Fprintf(c.in, "%s\n", hash); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	header, err := c.out.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, plumbing.ErrObjectNotFound
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: unexpected output %q", header)
+	}
+	typ, err := plumbing.ParseObjectType(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: unexpected output %q", header)
+	}
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(typ)
+	if _, err := io.CopyN(obj, c.out, size); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	if _, err := c.out.ReadByte(); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return obj, nil
+}
+
+func (c *catFile) close() {
+	c.in.Close()
+	if err := c.cmd.Wait(); err != nil {
+		log.Debug().Err(err).Msg("git cat-file exited")
+	}
+}
+
+func (b *execBackend) WriteObject(obj plumbing.EncodedObject) (string, error) {
+	rdr, err := obj.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer rdr.Close()
+	out, err := b.git(rdr, "hash-object", "-t", obj.Type().String(), "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (b *execBackend) CreateReference(name plumbing.ReferenceName, hash string) error {
+	// An empty old value only matches a ref that doesn't exist yet
+	_, err := b.git(nil, "update-ref", name.String(), hash, "")
+	if err == nil {
+		return nil
+	}
+	if _, verr := b.git(nil, "show-ref", "--verify", "--quiet", name.String()); verr == nil {
+		return storage.ErrReferenceHasChanged
+	}
+	return err
+}
+
+func (b *execBackend) SetReference(name plumbing.ReferenceName, hash string) error {
+	_, err := b.git(nil, "update-ref", name.String(), hash)
+	return err
+}
+
+func (b *execBackend) DeleteReference(name plumbing.ReferenceName) error {
+	_, err := b.git(nil, "update-ref", "-d", name.String())
+	return err
+}
+
+// tagFields are what loadReleases reads of every tag, the * ones are of the
+// object annotated tags point to
+var tagFields = []string{
+	"refname", "objecttype", "*objecttype", "*objectname", "objectname", "tag", "contents", "*contents",
+	"authorname", "authoremail", "authordate:iso-strict",
+	"committername", "committeremail", "committerdate:iso-strict",
+	"*authorname", "*authoremail", "*authordate:iso-strict",
+	"*committername", "*committeremail", "*committerdate:iso-strict",
+	"taggername", "taggeremail", "taggerdate:iso-strict",
+}
+
+// loadReleases reads every release tag with a single for-each-ref, which
+// names objects with full hashes of any length. Annotated tags of annotated
+// tags aren't followed, they're problems.
+func (b *execBackend) loadReleases(t *TagTemplate) ([]Release, []TagProblem, error) {
+	format := ""
+	for _, f := range tagFields {
+		format += "%(" + f + ")%00"
+	}
+	out, err := b.git(nil, "for-each-ref", "--format="+format, "refs/tags/")
+	if err != nil {
+		return nil, nil, err
+	}
+	releases := []Release{}
+	problems := []TagProblem{}
+	values := strings.Split(out, "\x00")
+	for i := 0; i+len(tagFields) <= len(values); i += len(tagFields) {
+		v := map[string]string{}
+		for j, f := range tagFields {
+			v[f] = values[i+j]
+		}
+		// Records are separated by a newline
+		name := strings.TrimPrefix(strings.TrimLeft(v["refname"], "\n"), "refs/tags/")
+		info := parseTagInfo(t, name)
+		if info.version == nil {
+			log.Debug().Msgf("skipping tag %s, it isn't a release", name)
+			continue
+		}
+		rel := Release{Tag: name, parsed: info}
+		prefix := ""
+		switch {
+		case v["objecttype"] == "commit":
+			rel.Hash = v["objectname"]
+			rel.CommitMessage = v["contents"]
+		case v["objecttype"] == "tag" && v["*objecttype"] == "commit":
+			prefix = "*"
+			rel.Hash = v["*objectname"]
+			rel.CommitMessage = v["*contents"]
+			rel.Tag = v["tag"]
+			if rel.Tag != name {
+				rel.ref = name
+			}
+			rel.ReleaseMessage = v["contents"]
+			// go-git leaves the signature out of the message
+			if idx := strings.Index(rel.ReleaseMessage, pgpSignatureStart); idx >= 0 {
+				rel.ReleaseMessage = rel.ReleaseMessage[:idx]
+			}
+			tagger := forEachRefSignature(v["taggername"], v["taggeremail"], v["taggerdate:iso-strict"])
+			rel.Tagger = &tagger
+			if rel.Tag != name {
+				rel.parsed = parseTagInfo(t, rel.Tag)
+			}
+		case v["objecttype"] == "tag":
+			problems = append(problems, TagProblem{Tag: name, Target: v["objectname"], Problem: fmt.Sprintf("tags a %s, which the exec backend doesn't follow", v["*objecttype"])})
+			continue
+		default:
+			problems = append(problems, TagProblem{Tag: name, Target: v["objectname"], Problem: fmt.Sprintf("points to a %s, not a commit", v["objecttype"])})
+			continue
+		}
+		rel.Author = forEachRefSignature(v[prefix+"authorname"], v[prefix+"authoremail"], v[prefix+"authordate:iso-strict"])
+		rel.Committer = forEachRefSignature(v[prefix+"committername"], v[prefix+"committeremail"], v[prefix+"committerdate:iso-strict"])
+		releases = append(releases, rel)
+	}
+	return releases, problems, nil
+}
+
+// forEachRefSignature builds a signature out of the name, <email> and strict
+// ISO 8601 date for-each-ref prints
+func forEachRefSignature(name, email, date string) object.Signature {
+	sig := object.Signature{Name: name, Email: strings.Trim(email, "<>")}
+	sig.When, _ = time.Parse(time.RFC3339, date)
+	return sig
+}
+
+// checkOffline refuses remotes that aren't local paths in offline mode, git
+// doesn't go through the transports GoOffline replaces
+func (b *execBackend) checkOffline(remote string) error {
+	if !offline {
+		return nil
+	}
+	rem, err := b.repo.Remote(remote)
+	if err != nil {
+		return err
+	}
+	for _, url := range rem.Config().URLs {
+		ep, err := transport.NewEndpoint(url)
+		if err != nil || ep.Protocol != "file" {
+			return fmt.Errorf("reaching remote %s: %w", remote, ErrOffline)
+		}
+	}
+	return nil
+}
+
+func (b *execBackend) Push(options *git.PushOptions) error {
+	if err := b.checkOffline(options.RemoteName); err != nil {
+		return err
+	}
+	args := []string{"push", "--porcelain", options.RemoteName}
+	for _, rs := range options.RefSpecs {
+		args = append(args, string(rs))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = b.dir
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	runErr := cmd.Run()
+	// Remote messages are passed on the way go-git's sideband does, without
+	// the prefix
+	other := []string{}
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if strings.HasPrefix(line, "remote: ") {
+			if options.Progress != nil {
+				fmt.Fprintln(options.Progress, strings.TrimPrefix(line, "remote: "))
+			}
+		} else if strings.TrimSpace(line) != "" {
+			other = append(other, strings.TrimSpace(line))
+		}
+	}
+	// Porcelain lines are "<flag>\t<from>:<to>\t<summary>", = is up to date
+	// and ! rejected
+	upToDate, rejected := true, []string{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		if fields[0] != "=" {
+			upToDate = false
+		}
+		if fields[0] == "!" {
+			rejected = append(rejected, fmt.Sprintf("%s %s", fields[1], fields[2]))
+		}
+	}
+	if runErr != nil {
+		if len(rejected) > 0 {
+			return fmt.Errorf("rejected: %s", strings.Join(rejected, "; "))
+		}
+		if len(other) > 0 {
+			return fmt.Errorf("git push: %s", strings.Join(other, "; "))
+		}
+		return fmt.Errorf("git push: %w", runErr)
+	}
+	if upToDate {
+		return git.NoErrAlreadyUpToDate
+	}
+	return nil
+}
+
+func (b *execBackend) Fetch(options *git.FetchOptions) error {
+	if err := b.checkOffline(options.RemoteName); err != nil {
+		return err
+	}
+	args := []string{"fetch", "--quiet"}
+	switch options.Tags {
+	case git.AllTags:
+		args = append(args, "--tags")
+	case git.NoTags:
+		args = append(args, "--no-tags")
+	}
+	args = append(args, options.RemoteName)
+	for _, rs := range options.RefSpecs {
+		args = append(args, string(rs))
+	}
+	_, err := b.git(nil, args...)
+	return err
+}
+
+func (b *execBackend) ListRemote(remote string, auth transport.AuthMethod) ([]Ref, error) {
+	if err := b.checkOffline(remote); err != nil {
+		return nil, err
+	}
+	out, err := b.git(nil, "ls-remote", remote)
+	if err != nil {
+		return nil, err
+	}
+	refs := []Ref{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		// Peeled tags (refs/tags/x^{}) aren't refs
+		if len(fields) != 2 || strings.HasSuffix(fields[1], "^{}") {
+			continue
+		}
+		refs = append(refs, Ref{Name: plumbing.ReferenceName(fields[1]), Hash: fields[0]})
+	}
+	return refs, nil
+}
+
+// commitFields are what commits reads of every commit, see git log --format
+var commitFields = []string{"%H", "%an", "%ae", "%aI", "%cn", "%ce", "%cI", "%B"}
+
+func (b *execBackend) commits(from, to string, limit int) ([]*Commit, error) {
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+	format := strings.Join(commitFields, "%x00") + "%x00"
+	out, err := b.git(nil, "log", "--format="+format, "-n", strconv.Itoa(limit), "--end-of-options", rev)
+	if err != nil {
+		return nil, err
+	}
+	commits := []*Commit{}
+	values := strings.Split(out, "\x00")
+	for i := 0; i+len(commitFields) <= len(values); i += len(commitFields) {
+		v := values[i : i+len(commitFields)]
+		commits = append(commits, &Commit{
+			// Records are separated by a newline
+			Hash:      strings.TrimLeft(v[0], "\n"),
+			Author:    forEachRefSignature(v[1], v[2], v[3]),
+			Committer: forEachRefSignature(v[4], v[5], v[6]),
+			Message:   v[7],
+		})
+	}
+	return commits, nil
+}
+
+func (b *execBackend) changedFiles(commits []string) (map[string][]string, error) {
+	files := map[string][]string{}
+	if len(commits) == 0 {
+		return files, nil
+	}
+	// diff-tree prints each commit followed by its files, merges are
+	// compared with their first parent like go-git does
+	out, err := b.git(strings.NewReader(strings.Join(commits, "\n")+"\n"),
+		"-c", "core.quotePath=false", "diff-tree", "--stdin", "-r", "--name-only", "--root", "-m", "--first-parent")
+	if err != nil {
+		return nil, err
+	}
+	wanted := map[string]bool{}
+	for _, c := range commits {
+		wanted[c] = true
+		files[c] = []string{}
+	}
+	current := ""
+	for _, line := range strings.Split(out, "\n") {
+		if wanted[line] {
+			current = line
+		} else if line != "" && current != "" {
+			files[current] = append(files[current], line)
+		}
+	}
+	return files, nil
+}
+
+func (b *execBackend) diffstat(from, to string) ([]PathChange, error) {
+	if from == "" {
+		// The empty tree, whatever the object format
+		tree, err := b.git(strings.NewReader(""), "hash-object", "-t", "tree", "--stdin")
+		if err != nil {
+			return nil, err
+		}
+		from = strings.TrimSpace(tree)
+	}
+	out, err := b.git(nil, "-c", "core.quotePath=false", "diff", "--numstat", "--no-renames", "-z", from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := []PathChange{}
+	for _, record := range strings.Split(out, "\x00") {
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Binary files are counted as -
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		stats = append(stats, PathChange{Path: fields[2], Added: added, Deleted: deleted})
+	}
+	return stats, nil
+}
+
+func (b *execBackend) isAncestor(ancestor, commit string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, commit)
+	cmd.Dir = b.dir
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("git merge-base: %w", err)
+	}
+	return true, nil
+}
+
+func (b *execBackend) file(commit, name string) ([]byte, error) {
+	obj, err := b.Object(commit + ":" + name)
+	if err == plumbing.ErrObjectNotFound {
+		return nil, os.ErrNotExist
+	} else if err != nil {
+		return nil, err
+	}
+	if obj.Type() != plumbing.BlobObject {
+		return nil, fmt.Errorf("%s is a %s, not a file", name, obj.Type())
+	}
+	return objectContent(obj)
+}
+
+func (b *execBackend) dirs(commit string) ([]string, error) {
+	out, err := b.git(nil, "ls-tree", "-r", "-d", "-z", "--name-only", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{}
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			dirs = append(dirs, name)
+		}
+	}
+	return dirs, nil
+}
+
+func (b *execBackend) files(commit, dir, suffix string) (map[string][]byte, error) {
+	args := []string{"ls-tree", "-r", "-z", "--full-tree", commit}
+	prefix := ""
+	if dir = strings.Trim(path.Clean(dir), "/"); dir != "" && dir != "." {
+		prefix = dir + "/"
+		args = append(args, "--", prefix)
+	}
+	out, err := b.git(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	// Entries are "<mode> <type> <hash>\t<path>"
+	for _, entry := range strings.Split(out, "\x00") {
+		fields := strings.SplitN(entry, "\t", 2)
+		if len(fields) != 2 || !strings.HasSuffix(fields[1], suffix) {
+			continue
+		}
+		info := strings.Fields(fields[0])
+		if len(info) != 3 || info[1] != "blob" {
+			continue
+		}
+		obj, err := b.Object(info[2])
+		if err != nil {
+			return nil, err
+		}
+		data, err := objectContent(obj)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(fields[1], prefix)] = data
+	}
+	return files, nil
+}
+
+func (b *execBackend) notes(ref plumbing.ReferenceName) (map[string]string, error) {
+	notes := map[string]string{}
+	blobs, err := b.noteBlobs(ref)
+	if err != nil {
+		return nil, err
+	}
+	for target, blob := range blobs {
+		obj, err := b.Object(blob)
+		if err != nil {
+			return nil, err
+		}
+		data, err := objectContent(obj)
+		if err != nil {
+			return nil, err
+		}
+		notes[target] = string(data)
+	}
+	return notes, nil
+}
+
+// noteBlobs returns the blobs of the notes in a ref by the object they
+// annotate, empty if the ref doesn't exist
+func (b *execBackend) noteBlobs(ref plumbing.ReferenceName) (map[string]string, error) {
+	blobs := map[string]string{}
+	if _, err := b.Reference(ref); err == plumbing.ErrReferenceNotFound {
+		return blobs, nil
+	}
+	out, err := b.git(nil, "notes", "--ref="+ref.String(), "list")
+	if err != nil {
+		return nil, err
+	}
+	// Lines are "<note blob> <annotated object>"
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			blobs[fields[1]] = fields[0]
+		}
+	}
+	return blobs, nil
+}
+
+func (b *execBackend) appendNote(ref plumbing.ReferenceName, target, content string, sig object.Signature, message string) error {
+	parent, err := b.Reference(ref)
+	if err == plumbing.ErrReferenceNotFound {
+		parent = ""
+	} else if err != nil {
+		return err
+	}
+	blobs, err := b.noteBlobs(ref)
+	if err != nil {
+		return err
+	}
+	if blob, ok := blobs[target]; ok {
+		obj, err := b.Object(blob)
+		if err != nil {
+			return err
+		}
+		existing, err := objectContent(obj)
+		if err != nil {
+			return err
+		}
+		content = string(existing) + content
+	}
+	blob, err := b.git(strings.NewReader(content), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	blobs[target] = strings.TrimSpace(blob)
+
+	// A flat tree, git reads it just like a fanned out one
+	entries := &strings.Builder{}
+	for name, hash := range blobs {
+		fmt.Fprintf(entries, "100644 blob %s\t%s\n", hash, name)
+	}
+	tree, err := b.git(strings.NewReader(entries.String()), "mktree")
+	if err != nil {
+		return err
+	}
+	args := []string{"commit-tree", strings.TrimSpace(tree), "-F", "-"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = b.dir
+	cmd.Env = signatureEnv(sig)
+	cmd.Stdin = strings.NewReader(message)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	commit, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git commit-tree: %s", strings.TrimSpace(stderr.String()))
+	}
+	// Only move the ref from the parent we read, like go-git's notes do
+	_, err = b.git(nil, "update-ref", ref.String(), strings.TrimSpace(string(commit)), parent)
+	return err
+}
+
+func (b *execBackend) commitFiles(paths []string, sig object.Signature, message string) error {
+	if _, err := b.git(nil, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "commit", "--quiet", "-F", "-")
+	cmd.Dir = b.dir
+	cmd.Env = signatureEnv(sig)
+	cmd.Stdin = strings.NewReader(message)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git commit: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// signatureEnv is the environment that makes git author and commit as sig
+func signatureEnv(sig object.Signature) []string {
+	when := fmt.Sprintf("%d %s", sig.When.Unix(), sig.When.Format("-0700"))
+	return append(os.Environ(),
+		"GIT_AUTHOR_NAME="+sig.Name, "GIT_AUTHOR_EMAIL="+sig.Email, "GIT_AUTHOR_DATE="+when,
+		"GIT_COMMITTER_NAME="+sig.Name, "GIT_COMMITTER_EMAIL="+sig.Email, "GIT_COMMITTER_DATE="+when,
+	)
+}
+
+func (b *execBackend) packObjects(roots, basis []string) ([]byte, error) {
+	revs := &strings.Builder{}
+	for _, root := range roots {
+		fmt.Fprintln(revs, root)
+	}
+	for _, hash := range basis {
+		fmt.Fprintln(revs, "^"+hash)
+	}
+	out, err := b.git(strings.NewReader(revs.String()), "pack-objects", "--revs", "--stdout", "-q")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func (b *execBackend) unpackObjects(pack []byte) error {
+	_, err := b.git(bytes.NewReader(pack), "index-pack", "--stdin")
+	return err
+}
+
+// objectContent reads all of an object
+func objectContent(obj plumbing.EncodedObject) ([]byte, error) {
+	rd, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return ioutil.ReadAll(rd)
+}
@@ -0,0 +1,93 @@
+package release
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+// benchSizes are the tag counts of the synthetic repositories, the same as
+// 'release bench'
+var benchSizes = []int{1000, 10000, 100000}
+
+// benchRepos are built once per size, the 100k one takes a while
+var benchRepos = map[int]*releasetest.Repo{}
+
+func benchRepo(b *testing.B, count int) *releasetest.Repo {
+	b.Helper()
+	if repo, ok := benchRepos[count]; ok {
+		return repo
+	}
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := repo.SyntheticTags(count); err != nil {
+		b.Fatal(err)
+	}
+	benchRepos[count] = repo
+	return repo
+}
+
+func benchManager(b *testing.B, count int) *Manager {
+	b.Helper()
+	rm, err := NewManagerFromRepo(benchRepo(b, count).Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rm
+}
+
+func BenchmarkLoadTags(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			repo := benchRepo(b, size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIncrement(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			rm := benchManager(b, size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, component := range releasetest.SyntheticComponents {
+					if _, err := rm.GetProposedRelease(component); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSortReleases(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			releases := benchManager(b, size).Releases()
+			shuffled := make([]Release, len(releases))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(shuffled, releases)
+				rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+					shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+				})
+				b.StartTimer()
+				SortReleases(shuffled)
+			}
+		})
+	}
+}
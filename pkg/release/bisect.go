@@ -0,0 +1,119 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// BisectOptions are the bounds and test of a bisect between releases
+type BisectOptions struct {
+	// Bad is the first release known to be broken
+	Bad string
+	// Good is the last release known to work, the release before Bad if
+	// empty
+	Good string
+	// Paths limits bisecting to the commits touching them, like the paths of
+	// the component
+	Paths []string
+	// Run is a shell command telling good (exit 0) from bad commits, bisecting
+	// is left to the user if it's empty
+	Run string
+}
+
+// BisectResult is what a bisect run found
+type BisectResult struct {
+	Hash    string
+	Subject string
+	// Release is the first release with the commit, between Good and Bad
+	Release string
+}
+
+// Bisect starts 'git bisect' between two releases of a component. With a Run
+// command the bisect is run to the end, reset and its first bad commit is
+// returned, otherwise it's left running for 'git bisect good/bad' and the
+// result is nil. The output of git goes to out.
+func (r *Manager) Bisect(opts BisectOptions, out io.Writer) (*BisectResult, error) {
+	if r.repoDir == "" || r.noWorktree {
+		return nil, fmt.Errorf("bisecting needs a checkout")
+	}
+	bad := r.FindRelease(opts.Bad)
+	if bad == nil {
+		return nil, fmt.Errorf("there's no release %s", opts.Bad)
+	}
+	var good *Release
+	if opts.Good == "" {
+		if good = r.PreviousRelease(bad.Tag); good == nil {
+			return nil, fmt.Errorf("%s is the first release of its component, give a good release", bad.Tag)
+		}
+	} else if good = r.FindRelease(opts.Good); good == nil {
+		return nil, fmt.Errorf("there's no release %s", opts.Good)
+	}
+	if good.Hash == bad.Hash {
+		return nil, fmt.Errorf("%s and %s are the same commit", good.Tag, bad.Tag)
+	}
+	if ok, err := r.isAncestor(good.Hash, bad.Hash); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%s isn't in the history of %s", good.Tag, bad.Tag)
+	}
+
+	args := []string{"bisect", "start", bad.Hash, good.Hash}
+	if len(opts.Paths) > 0 {
+		args = append(append(args, "--"), opts.Paths...)
+	}
+	if err := r.bisectGit(out, args...); err != nil {
+		return nil, err
+	}
+	if opts.Run == "" {
+		return nil, nil
+	}
+	// Whatever happens, the checkout goes back to where it was
+	defer r.bisectGit(out, "bisect", "reset")
+	if err := r.bisectGit(out, "bisect", "run", "sh", "-c", opts.Run); err != nil {
+		return nil, err
+	}
+	hash, err := r.bisectOutput("rev-parse", "refs/bisect/bad")
+	if err != nil {
+		return nil, err
+	}
+	subject, err := r.bisectOutput("log", "-1", "--format=%s", hash)
+	if err != nil {
+		return nil, err
+	}
+	result := &BisectResult{Hash: hash, Subject: subject, Release: bad.Tag}
+	// Walk back from the bad release to the first one with the commit
+	for prev := r.PreviousRelease(bad.Tag); prev != nil && prev.Tag != good.Tag; prev = r.PreviousRelease(prev.Tag) {
+		if ok, err := r.isAncestor(hash, prev.Hash); err != nil || !ok {
+			break
+		}
+		result.Release = prev.Tag
+	}
+	return result, nil
+}
+
+// bisectGit runs git in the repository with its output going to out
+func (r *Manager) bisectGit(out io.Writer, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.repoDir
+	cmd.Stdout, cmd.Stderr = out, out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w", strings.Join(args[:2], " "), err)
+	}
+	return nil
+}
+
+// bisectOutput runs git in the repository and returns its trimmed output
+func (r *Manager) bisectOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.repoDir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,89 @@
+package release
+
+import (
+	"fmt"
+	"os"
+)
+
+// The trailers of releases tagged by a bot
+const (
+	BotTrailer         = "Release-Bot"  // The bot identity the tag was created with
+	InitiatedByTrailer = "Initiated-By" // The person who started the release
+)
+
+// BotConfig is the identity automated releases are tagged with
+type BotConfig struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+	// When is "ci" (the default) to tag as the bot only when running in CI
+	// or "always"
+	When string `yaml:"when"`
+}
+
+func (c BotConfig) validate() error {
+	if c.Name == "" && c.Email == "" {
+		return nil
+	}
+	if c.Name == "" || c.Email == "" {
+		return fmt.Errorf("the bot needs both a name and an email")
+	}
+	switch c.When {
+	case "", "ci", "always":
+		return nil
+	}
+	return fmt.Errorf("bot when must be ci or always, not '%s'", c.When)
+}
+
+// Configured reports whether a bot identity is set up
+func (c BotConfig) Configured() bool {
+	return c.Name != "" && c.Email != ""
+}
+
+// Active reports whether releases should be tagged as the bot
+func (c BotConfig) Active(inCI bool) bool {
+	return c.Configured() && (inCI || c.When == "always")
+}
+
+// String is the bot's identity in "name <email>" form
+func (c BotConfig) String() string {
+	return fmt.Sprintf("%s <%s>", c.Name, c.Email)
+}
+
+// InvokingHuman returns the person CI says started the job, empty outside of
+// CI or if the CI system doesn't say. RELEASE_INITIATED_BY overrides it.
+func InvokingHuman() string {
+	if v := os.Getenv("RELEASE_INITIATED_BY"); v != "" {
+		return v
+	}
+	for _, vars := range [][2]string{
+		{"GITLAB_USER_NAME", "GITLAB_USER_EMAIL"},
+		{"BUILDKITE_BUILD_CREATOR", "BUILDKITE_BUILD_CREATOR_EMAIL"},
+		{"BUILD_USER", "BUILD_USER_EMAIL"}, // Jenkins build user vars plugin
+		{"GITHUB_ACTOR", ""},
+		{"CIRCLE_USERNAME", ""},
+		{"DRONE_BUILD_TRIGGER", ""},
+	} {
+		name, email := os.Getenv(vars[0]), ""
+		if vars[1] != "" {
+			email = os.Getenv(vars[1])
+		}
+		switch {
+		case name != "" && email != "":
+			return fmt.Sprintf("%s <%s>", name, email)
+		case name != "":
+			return name
+		}
+	}
+	return ""
+}
+
+// InitiatedBy returns who started a release that was tagged by a bot, empty
+// for releases tagged by people
+func (r *Release) InitiatedBy() string {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == InitiatedByTrailer {
+			return t.Value
+		}
+	}
+	return ""
+}
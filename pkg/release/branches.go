@@ -0,0 +1,154 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// BranchConfig configures branch-per-release mode
+type BranchConfig struct {
+	Prefix string `yaml:"prefix"` // Release branches start with this, defaults to release/
+	Into   string `yaml:"into"`   // The branch release branches get merged into, defaults to main
+}
+
+// DefaultBranchPrefix is the prefix used for release branches if none is
+// configured
+const DefaultBranchPrefix = "release/"
+
+// DefaultMergeTarget is the branch release branches are expected to be merged
+// into if none is configured
+const DefaultMergeTarget = "main"
+
+// ReleaseBranch is a release branch and the release tags that belong to it
+type ReleaseBranch struct {
+	Name   string    // The branch name, without refs/heads or the remote
+	Remote string    // The remote for remote-tracking branches, empty for local ones
+	Tags   []string  // Release tags at the tip or named by the branch
+	Newest time.Time // When the newest of the tags was released
+	Merged bool      // If the tip is fully merged into the target branch
+}
+
+// String gives the branch name as git would show it
+func (b ReleaseBranch) String() string {
+	if b.Remote != "" {
+		return fmt.Sprintf("%s/%s", b.Remote, b.Name)
+	}
+	return b.Name
+}
+
+// ReleaseBranches finds the local release branches, and the remote-tracking
+// ones of remote if it's not empty. A release's tags are the ones pointing at
+// the tip of the branch or, for branches named after a version
+// (release/2020.07 or release/2020.07.003-api), the matching releases. Remote
+// branches are read from the remote-tracking refs so fetch first.
+func (r *Manager) ReleaseBranches(cfg BranchConfig, remote string) ([]ReleaseBranch, error) {
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = DefaultBranchPrefix
+	}
+	target, err := r.mergeTarget(cfg.Into, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := r.gitBackend().Refs("refs/")
+	if err != nil {
+		return nil, err
+	}
+	branches := []ReleaseBranch{}
+	for _, ref := range refs {
+		name := ref.Name.String()
+		branch := ReleaseBranch{}
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"+prefix):
+			branch.Name = strings.TrimPrefix(name, "refs/heads/")
+		case remote != "" && strings.HasPrefix(name, fmt.Sprintf("refs/remotes/%s/%s", remote, prefix)):
+			branch.Name = strings.TrimPrefix(name, fmt.Sprintf("refs/remotes/%s/", remote))
+			branch.Remote = remote
+		default:
+			continue
+		}
+		if ref.Hash == target {
+			branch.Merged = true
+		} else if branch.Merged, err = r.isAncestor(ref.Hash, target); err != nil {
+			return nil, err
+		}
+		branchVersion, _, named := splitTag(r.tagTemplate, strings.TrimPrefix(branch.Name, prefix))
+		for _, rel := range r.releases {
+			matches := rel.Hash == ref.Hash
+			if version, ok := parseCalVer(r.tagTemplate, rel.Tag); ok && named && !matches {
+				if branchVersion.Release == 0 {
+					matches = version.IsSameMonth(branchVersion)
+				} else {
+					matches = version.Compare(branchVersion) == 0
+				}
+			}
+			if !matches {
+				continue
+			}
+			branch.Tags = append(branch.Tags, rel.Tag)
+			if when := rel.ReleasedBy().When; when.After(branch.Newest) {
+				branch.Newest = when
+			}
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// mergeTarget resolves the branch release branches are merged into, falling
+// back to the remote-tracking branch if there is no local one
+func (r *Manager) mergeTarget(into, remote string) (string, error) {
+	if into == "" {
+		into = DefaultMergeTarget
+	}
+	hash, err := r.gitBackend().Reference(plumbing.NewBranchReferenceName(into))
+	if err != nil && remote != "" {
+		hash, err = r.gitBackend().Reference(plumbing.NewRemoteReferenceName(remote, into))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge target branch %s: %w", into, err)
+	}
+	return hash, nil
+}
+
+// PrunableBranches returns the release branches that are fully merged and
+// whose newest tag was released more than olderThan before now. Branches
+// without any release tags are never pruned.
+func (r *Manager) PrunableBranches(cfg BranchConfig, remote string, olderThan time.Duration, now time.Time) ([]ReleaseBranch, error) {
+	branches, err := r.ReleaseBranches(cfg, remote)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-olderThan)
+	prunable := []ReleaseBranch{}
+	for _, b := range branches {
+		if b.Merged && len(b.Tags) > 0 && b.Newest.Before(cutoff) {
+			prunable = append(prunable, b)
+		}
+	}
+	return prunable, nil
+}
+
+// DeleteBranch deletes a local branch, or for remote-tracking branches deletes
+// the branch on the remote and then the remote-tracking ref
+func (r *Manager) DeleteBranch(b ReleaseBranch, auth transport.AuthMethod) error {
+	if b.Remote == "" {
+		return r.gitBackend().DeleteReference(plumbing.NewBranchReferenceName(b.Name))
+	}
+	_, err := r.push(&git.PushOptions{
+		RemoteName: b.Remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf(":refs/heads/%s", b.Name))},
+		Auth:       auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return Classify(fmt.Sprintf("deleting branch %s from %s", b.Name, b.Remote), err)
+	}
+	return r.gitBackend().DeleteReference(plumbing.NewRemoteReferenceName(b.Remote, b.Name))
+}
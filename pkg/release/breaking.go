@@ -0,0 +1,72 @@
+package release
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BreakingTrailer marks a tag annotation as a breaking release
+const BreakingTrailer = "Breaking-Change"
+
+// conventionalBreaking matches conventional commit subjects with a ! before
+// the colon (feat!: or fix(api)!:)
+var conventionalBreaking = regexp.MustCompile(`^[a-zA-Z]+(?:\([^)]*\))?!: `)
+
+// IsBreakingCommit reports whether a commit message announces a breaking
+// change, either with a conventional commit ! or a BREAKING CHANGE footer
+func IsBreakingCommit(message string) bool {
+	if conventionalBreaking.MatchString(Subject(message)) {
+		return true
+	}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE: ") || strings.HasPrefix(line, "BREAKING-CHANGE: ") {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakingChanges returns the breaking commits between the previous release of
+// the given (not yet created) tag and its target revision (HEAD if empty),
+// newest first
+func (r *Manager) BreakingChanges(tag, target string) ([]*Commit, error) {
+	head, err := r.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	stop := ""
+	if prev := r.PreviousRelease(tag); prev != nil {
+		stop = prev.Hash
+	}
+	commits, err := r.commitsBetween(stop, head)
+	if err != nil {
+		return nil, err
+	}
+	breaking := []*Commit{}
+	for _, c := range commits {
+		if IsBreakingCommit(c.Message) {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking, nil
+}
+
+// resolveTarget resolves the revision a release will tag, HEAD (or the
+// target of UseTarget) if empty
+func (r *Manager) resolveTarget(target string) (string, error) {
+	if target == "" {
+		return r.releaseHead()
+	}
+	return r.ResolveCommit(target)
+}
+
+// IsBreaking reports whether the release was acknowledged as breaking when it
+// was created
+func (r *Release) IsBreaking() bool {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == BreakingTrailer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultBuildManifest is where the artifacts of a build are listed unless
+// configured
+const DefaultBuildManifest = "dist/{tag}.json"
+
+// BuildConfig configures the build phase, which builds the components being
+// released after their version is known and before they're tagged
+type BuildConfig struct {
+	// Jobs is how many components are built at once, one at a time if 0
+	Jobs int `yaml:"jobs"`
+	// Manifest is the file the artifacts of a release are listed in,
+	// relative to the repository, DefaultBuildManifest if empty
+	Manifest string `yaml:"manifest"`
+}
+
+func (c BuildConfig) validate() error {
+	if c.Jobs < 0 {
+		return fmt.Errorf("build.jobs can't be negative")
+	}
+	if c.Manifest != "" && !strings.Contains(c.Manifest, "{tag}") {
+		return fmt.Errorf("build.manifest %s needs {tag} so releases don't overwrite each other's", c.Manifest)
+	}
+	return nil
+}
+
+// ManifestFile returns the manifest of the artifacts of the release
+func (c BuildConfig) ManifestFile(tag string) string {
+	manifest := c.Manifest
+	if manifest == "" {
+		manifest = DefaultBuildManifest
+	}
+	return strings.ReplaceAll(manifest, "{tag}", tag)
+}
+
+// ComponentBuild builds a component's artifacts
+type ComponentBuild struct {
+	// Commands run with sh in the repository with the environment of hooks,
+	// stopping at the first failure
+	Commands []string `yaml:"commands"`
+	// Artifacts are globs relative to the repository matching what the
+	// commands built, each has to match something
+	Artifacts []string `yaml:"artifacts"`
+}
+
+// BuildManifest lists the artifacts built for a release, for uploading them
+// later
+type BuildManifest struct {
+	Tag       string     `json:"tag"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is a file built for a release
+type Artifact struct {
+	Path   string `json:"path"` // Relative to the repository
+	SHA256 string `json:"sha256"`
+}
+
+// BuildJob is a release to build
+type BuildJob struct {
+	Release *Release // See Manager.ParseRelease
+	Build   ComponentBuild
+}
+
+// BuildResult is the outcome of building a release, Output is what the
+// commands printed
+type BuildResult struct {
+	Tag      string
+	Manifest *BuildManifest
+	Output   string
+	Err      error
+}
+
+// Build runs the build commands of a release in dir and collects its
+// artifacts
+func Build(job BuildJob, dir string) BuildResult {
+	result := BuildResult{Tag: job.Release.Tag}
+	out := &bytes.Buffer{}
+	for _, command := range job.Build.Commands {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = hookEnv(job.Release)
+		cmd.Stdout, cmd.Stderr = out, out
+		if err := cmd.Run(); err != nil {
+			result.Output = out.String()
+			result.Err = fmt.Errorf("build command '%s' failed: %w", command, err)
+			return result
+		}
+	}
+	result.Output = out.String()
+	globs := []string{}
+	for _, glob := range job.Build.Artifacts {
+		globs = append(globs, filepath.Join(dir, glob))
+	}
+	sums, err := Checksums(globs, dir)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to collect the artifacts: %w", err)
+		return result
+	}
+	result.Manifest = &BuildManifest{Tag: job.Release.Tag, Artifacts: []Artifact{}}
+	for _, sum := range sums {
+		result.Manifest.Artifacts = append(result.Manifest.Artifacts, Artifact{Path: sum.Path, SHA256: sum.Sum})
+	}
+	return result
+}
+
+// BuildAll builds the releases in dir, up to jobs at once, and returns the
+// results in the order of the jobs. Each build's output is kept apart so
+// parallel builds don't interleave.
+func BuildAll(jobs []BuildJob, dir string, workers int) []BuildResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]BuildResult, len(jobs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				results[idx] = Build(jobs[idx], dir)
+			}
+		}()
+	}
+	for idx := range jobs {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// WriteBuildManifest writes the manifest as JSON, creating its directory
+func WriteBuildManifest(path string, manifest *BuildManifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,38 @@
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestBuildAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jobs := []BuildJob{
+		{Release: &Release{Tag: "2020.07.001-api"}, Build: ComponentBuild{
+			Commands:  []string{"mkdir -p out", "printf $RELEASE_COMPONENT > out/$RELEASE_COMPONENT.bin"},
+			Artifacts: []string{"out/api.bin"},
+		}},
+		{Release: &Release{Tag: "2020.07.001-web"}, Build: ComponentBuild{Commands: []string{"echo broken", "false"}}},
+		{Release: &Release{Tag: "2020.07.001-cli"}, Build: ComponentBuild{Artifacts: []string{"missing/*"}}},
+	}
+	results := BuildAll(jobs, dir, 2)
+	api := results[0]
+	if api.Err != nil || len(api.Manifest.Artifacts) != 1 {
+		t.Fatalf("got %+v, want the api artifact", api)
+	}
+	// sha256 of "api"
+	if a := api.Manifest.Artifacts[0]; a.Path != "out/api.bin" || a.SHA256 != "14c2529eb4498c5d1ffd6915d05bf58a91bdda796af59f41d480d11c099d0479" {
+		t.Errorf("got artifact %+v", a)
+	}
+	if results[1].Err == nil || results[1].Output != "broken\n" {
+		t.Errorf("got %+v, want the failed build with its output", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("a build without its artifacts succeeded")
+	}
+}
@@ -0,0 +1,233 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+)
+
+// BundleVersion is the version of the bundle format written by CreateBundle
+const BundleVersion = 1
+
+// The files in a bundle archive
+const (
+	bundleManifestFile = "manifest.json"
+	bundlePackFile     = "objects.pack"
+)
+
+// BundleTag is a release tag carried by a bundle
+type BundleTag struct {
+	Name   string `json:"name"`
+	Hash   string `json:"hash"`   // What the tag ref points to, the tag object for annotated tags
+	Commit string `json:"commit"` // The released commit
+}
+
+// BundleManifest describes the contents of a bundle
+type BundleManifest struct {
+	Version int         `json:"version"`
+	Created time.Time   `json:"created"`
+	Tags    []BundleTag `json:"tags"`
+	// Basis are the commits the receiving repository must already have, the
+	// bundle only contains the objects that aren't reachable from them
+	Basis []string `json:"basis,omitempty"`
+	// PackSHA256 is the checksum of the packfile with the objects
+	PackSHA256 string `json:"pack_sha256"`
+}
+
+// Bundle is a read and verified bundle
+type Bundle struct {
+	Manifest BundleManifest
+	pack     []byte
+}
+
+// CreateBundle writes a bundle (a gzipped tar with a manifest and a packfile)
+// with the given release tags and every object they need that isn't
+// reachable from the basis commits (full hashes)
+func (r *Manager) CreateBundle(w io.Writer, tags []string, basis []string) (*BundleManifest, error) {
+	manifest := &BundleManifest{Version: BundleVersion, Created: time.Now().UTC(), Tags: []BundleTag{}, Basis: basis}
+	roots := []string{}
+	for _, name := range tags {
+		hash, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag %s: %w", name, err)
+		}
+		_, commit, err := r.resolveTag(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag %s: %w", name, err)
+		}
+		manifest.Tags = append(manifest.Tags, BundleTag{Name: name, Hash: hash, Commit: commit.Hash})
+		roots = append(roots, hash)
+	}
+	pack, err := r.packObjects(roots, basis)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(pack)
+	manifest.PackSHA256 = hex.EncodeToString(sum[:])
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{bundleManifestFile, manifestData}, {bundlePackFile, pack}} {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), ModTime: manifest.Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+// packObjects returns a packfile with the objects reachable from roots but
+// not from basis
+func (r *Manager) packObjects(roots, basis []string) ([]byte, error) {
+	if h := r.history(); h != nil {
+		pack, err := h.packObjects(roots, basis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write the packfile: %w", err)
+		}
+		return pack, nil
+	}
+	hashes, err := revlist.Objects(r.repo.Storer, toHashes(roots), toHashes(basis))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the objects to bundle: %w", err)
+	}
+	pack := &bytes.Buffer{}
+	if _, err := packfile.NewEncoder(pack, r.repo.Storer, false).Encode(hashes, 10); err != nil {
+		return nil, fmt.Errorf("failed to write the packfile: %w", err)
+	}
+	return pack.Bytes(), nil
+}
+
+// unpackObjects stores the objects of a packfile
+func (r *Manager) unpackObjects(pack []byte) error {
+	if h := r.history(); h != nil {
+		return h.unpackObjects(pack)
+	}
+	return packfile.UpdateObjectStorage(r.repo.Storer, bytes.NewReader(pack))
+}
+
+func toHashes(hashes []string) []plumbing.Hash {
+	converted := []plumbing.Hash{}
+	for _, h := range hashes {
+		converted = append(converted, plumbing.NewHash(h))
+	}
+	return converted
+}
+
+// ReadBundle reads a bundle and checks the packfile against the manifest
+func ReadBundle(rd io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		return nil, fmt.Errorf("not a release bundle: %w", err)
+	}
+	b := &Bundle{}
+	var manifestData []byte
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("not a release bundle: %w", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		switch hdr.Name {
+		case bundleManifestFile:
+			manifestData = data
+		case bundlePackFile:
+			b.pack = data
+		}
+	}
+	if manifestData == nil || b.pack == nil {
+		return nil, errors.New("not a release bundle: the manifest or packfile is missing")
+	}
+	if err := json.Unmarshal(manifestData, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if b.Manifest.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, this release supports version %d", b.Manifest.Version, BundleVersion)
+	}
+	sum := sha256.Sum256(b.pack)
+	if hex.EncodeToString(sum[:]) != b.Manifest.PackSHA256 {
+		return nil, errors.New("the bundle's packfile doesn't match its manifest, it was corrupted or tampered with")
+	}
+	return b, nil
+}
+
+// ImportBundle adds the bundle's objects to the repository and creates its
+// tags. The repository must have the basis commits. Tags that already exist
+// with the same hash are skipped, a tag that exists with a different hash
+// fails the import before any tag is created. The created tags are returned.
+func (r *Manager) ImportBundle(b *Bundle) ([]string, error) {
+	for _, basis := range b.Manifest.Basis {
+		if _, err := r.gitBackend().Object(basis); err != nil {
+			return nil, fmt.Errorf("the repository doesn't have the bundle's basis commit %s, fetch it first: %w", basis, err)
+		}
+	}
+	pending := []BundleTag{}
+	for _, t := range b.Manifest.Tags {
+		hash, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(t.Name))
+		if err == plumbing.ErrReferenceNotFound {
+			pending = append(pending, t)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if hash != t.Hash {
+			return nil, fmt.Errorf("the bundle's %s is %s: %w", t.Name, t.Hash, &AlreadyExistsError{Tag: t.Name, Hash: hash})
+		}
+	}
+	if err := r.unpackObjects(b.pack); err != nil {
+		return nil, fmt.Errorf("failed to import the bundle's objects: %w", err)
+	}
+	created := []string{}
+	for _, t := range pending {
+		if _, err := r.gitBackend().Object(t.Commit); err != nil {
+			return created, fmt.Errorf("tag %s: the bundle doesn't contain commit %s: %w", t.Name, t.Commit, err)
+		}
+		if _, err := r.createTagRef(t.Name, t.Hash); err != nil {
+			return created, err
+		}
+		resolved, err := r.tagCommit(t.Name)
+		if err != nil || resolved.Hash != t.Commit {
+			r.DeleteTag(t.Name)
+			return created, fmt.Errorf("tag %s doesn't point at commit %s as the manifest says", t.Name, t.Commit)
+		}
+		created = append(created, t.Name)
+	}
+	r.Reload()
+	return created, nil
+}
+
+// ResolveCommit resolves a revision (a tag, branch, hash, HEAD~2, ...) to the
+// full hash of the commit it names
+func (r *Manager) ResolveCommit(rev string) (string, error) {
+	return r.gitBackend().ResolveRevision(rev)
+}
@@ -0,0 +1,160 @@
+package release
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// goDirectives returns the arguments of every verb directive of a go.mod or
+// go.work file, single line or in a ( ) block
+func goDirectives(data []byte, verb string) [][]string {
+	found := [][]string{}
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			found = append(found, fields)
+		case fields[0] == verb && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == verb && len(fields) > 1:
+			found = append(found, fields[1:])
+		}
+	}
+	return found
+}
+
+// goModuleDirs returns the directories with a go.mod at HEAD: the ones the
+// go.work file uses and the top level paths of the components
+func (r *Manager) goModuleDirs(components map[string]ComponentConfig) ([]string, error) {
+	candidates := map[string]bool{}
+	work, err := r.headFile("go.work")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, use := range goDirectives(work, "use") {
+		candidates[path.Clean(strings.Trim(use[0], `"`))] = true
+	}
+	for _, component := range components {
+		for _, p := range component.Paths {
+			candidates[path.Clean(p)] = true
+		}
+	}
+	dirs := []string{}
+	for dir := range candidates {
+		if _, err := r.headFile(path.Join(dir, "go.mod")); err == nil {
+			dirs = append(dirs, dir)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Dependencies returns the components each component depends on, the ones it
+// names in depends_on and the ones whose Go modules its go.mod files require
+func (r *Manager) Dependencies(components map[string]ComponentConfig) (map[string][]string, error) {
+	deps := map[string]map[string]bool{}
+	add := func(from, to string) {
+		if from == to {
+			return
+		}
+		if deps[from] == nil {
+			deps[from] = map[string]bool{}
+		}
+		deps[from][to] = true
+	}
+	for name, component := range components {
+		for _, dep := range component.DependsOn {
+			add(name, dep)
+		}
+	}
+
+	dirs, err := r.goModuleDirs(components)
+	if err != nil {
+		return nil, err
+	}
+	owners := map[string]string{}     // module path to component
+	requires := map[string][]string{} // component to required module paths
+	for _, dir := range dirs {
+		name := path.Join(dir, "go.mod")
+		data, err := r.headFile(name)
+		if err != nil {
+			return nil, err
+		}
+		owner := ""
+		for _, c := range sortedKeys(components) {
+			if components[c].Owns(name) && len(components[c].Paths) > 0 {
+				owner = c
+				break
+			}
+		}
+		if owner == "" {
+			continue
+		}
+		for _, module := range goDirectives(data, "module") {
+			owners[strings.Trim(module[0], `"`)] = owner
+		}
+		for _, req := range goDirectives(data, "require") {
+			requires[owner] = append(requires[owner], strings.Trim(req[0], `"`))
+		}
+	}
+	for component, modules := range requires {
+		for _, module := range modules {
+			if owner, ok := owners[module]; ok {
+				add(component, owner)
+			}
+		}
+	}
+
+	graph := map[string][]string{}
+	for component, to := range deps {
+		for dep := range to {
+			graph[component] = append(graph[component], dep)
+		}
+		sort.Strings(graph[component])
+	}
+	return graph, nil
+}
+
+// Downstream returns the components that depend on any of the changed ones,
+// directly or through others, along with the component each depends on that
+// brought it in. The changed components themselves aren't included.
+func Downstream(deps map[string][]string, changed []string) map[string]string {
+	dependents := map[string][]string{}
+	for component, on := range deps {
+		for _, dep := range on {
+			dependents[dep] = append(dependents[dep], component)
+		}
+	}
+	seen := map[string]bool{}
+	for _, c := range changed {
+		seen[c] = true
+	}
+	reached := map[string]string{}
+	queue := append([]string{}, changed...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		next := dependents[current]
+		sort.Strings(next)
+		for _, d := range next {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			reached[d] = current
+			queue = append(queue, d)
+		}
+	}
+	return reached
+}
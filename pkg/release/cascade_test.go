@@ -0,0 +1,51 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoDirectives(t *testing.T) {
+	gomod := []byte(`module example.com/api // the API
+
+go 1.14
+
+require example.com/lib v1.0.0
+require (
+	example.com/util v0.1.0 // indirect
+
+	golang.org/x/mod v0.3.0
+)
+`)
+	got := goDirectives(gomod, "require")
+	want := [][]string{
+		{"example.com/lib", "v1.0.0"},
+		{"example.com/util", "v0.1.0"},
+		{"golang.org/x/mod", "v0.3.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := goDirectives(gomod, "module"); !reflect.DeepEqual(got, [][]string{{"example.com/api"}}) {
+		t.Errorf("got module %v", got)
+	}
+}
+
+func TestDownstream(t *testing.T) {
+	deps := map[string][]string{
+		"api":  {"lib"},
+		"web":  {"api"},
+		"cli":  {"lib", "web"},
+		"docs": nil,
+		// Cycles don't loop
+		"lib": {"cli"},
+	}
+	got := Downstream(deps, []string{"api"})
+	want := map[string]string{"web": "api", "cli": "web", "lib": "cli"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Downstream(deps, []string{"docs"}); len(got) != 0 {
+		t.Errorf("got %v, nothing depends on docs", got)
+	}
+}
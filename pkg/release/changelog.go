@@ -0,0 +1,166 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// maxChangelogCommits caps how far back we walk when a component has never
+// been released before
+const maxChangelogCommits = 500
+
+// PreviousRelease returns the release of the same component with the highest
+// version lower than the given tag's, nil if there is none. Pre-releases are
+// only considered for pre-release tags. The tag doesn't need to be loaded by
+// the manager yet, so this works for freshly created tags too.
+func (r *Manager) PreviousRelease(tag string) *Release {
+	version, ok := parseCalVer(r.tagTemplate, tag)
+	if !ok {
+		return nil
+	}
+	_, component, _ := splitTag(r.tagTemplate, tag)
+	component, pre := splitPreRelease(component)
+
+	var prev *Release
+	var prevVersion *calVerStandard
+	for idx, rel := range r.releases {
+		if rel.Tag == tag || rel.Component() != component || (pre == "" && rel.IsPreRelease()) {
+			continue
+		}
+		relVersion, _ := parseCalVer(r.tagTemplate, rel.Tag)
+		if relVersion.Compare(version) >= 0 {
+			continue
+		}
+		if prev == nil || relVersion.Compare(prevVersion) > 0 {
+			prev = &r.releases[idx]
+			prevVersion = relVersion
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+	relCopy := *prev
+	return &relCopy
+}
+
+// Changelog returns the commits that are part of the given release tag, that
+// is every commit since the previous release of the same component (newest
+// first)
+func (r *Manager) Changelog(tag string) ([]*Commit, error) {
+	commit, err := r.tagCommit(tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	stop := ""
+	if prev := r.PreviousRelease(tag); prev != nil {
+		stop = prev.Hash
+	}
+	return r.commitsBetween(stop, commit.Hash)
+}
+
+// releaseCommits returns the commits of the component between the previous
+// release of the given (not yet created) tag and its target revision (HEAD if
+// empty), newest first
+func (r *Manager) releaseCommits(tag, target string, component ComponentConfig) ([]*Commit, error) {
+	head, err := r.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	stop := ""
+	if prev := r.PreviousRelease(tag); prev != nil {
+		stop = prev.Hash
+	}
+	commits, err := r.commitsBetween(stop, head)
+	if err != nil {
+		return nil, err
+	}
+	return r.componentCommits(commits, component)
+}
+
+// Subject returns the first line of a commit message
+func Subject(msg string) string {
+	return strings.TrimSpace(strings.SplitN(msg, "\n", 2)[0])
+}
+
+// Diff returns the commits in toTag that aren't in fromTag (git log
+// fromTag..toTag), newest first
+func (r *Manager) Diff(fromTag, toTag string) ([]*Commit, error) {
+	from, err := r.tagCommit(fromTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tag %s: %w", fromTag, err)
+	}
+	to, err := r.tagCommit(toTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tag %s: %w", toTag, err)
+	}
+	return r.commitsBetween(from.Hash, to.Hash)
+}
+
+// ComponentChangelog is what changed in a component since its last release
+type ComponentChangelog struct {
+	Component string
+	Previous  string // The last release, empty if there is none
+	Next      string // The tag the next release would get
+	Commits   []*Commit
+	Notes     []string
+}
+
+// UnreleasedChangelogs returns the changelog of every component that changed
+// since its last release, the components DraftPlan would release. Up to
+// workers components are walked at once, each worker with its own handle on
+// the repository since go-git's storage isn't safe for concurrent use.
+func (r *Manager) UnreleasedChangelogs(opts PlanOptions, workers int) ([]*ComponentChangelog, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return nil, err
+	}
+	components := r.planComponents(opts)
+	if workers < 1 || r.repoDir == "" {
+		// An in-memory clone can't be opened again
+		workers = 1
+	}
+	if workers > len(components) {
+		workers = len(components)
+	}
+
+	results := make([]*ComponentChangelog, len(components))
+	errs := make([]error, len(components))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		worker := *r
+		if workers > 1 {
+			repo, err := git.PlainOpen(r.repoDir)
+			if err != nil {
+				return nil, err
+			}
+			worker.repo = repo
+		}
+		wg.Add(1)
+		go func(w *Manager) {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx], errs[idx] = w.unreleased(components[idx], opts, head)
+			}
+		}(&worker)
+	}
+	for idx := range components {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	changelogs := []*ComponentChangelog{}
+	for idx, c := range results {
+		if errs[idx] != nil {
+			return nil, fmt.Errorf("failed to build the changelog of %s: %w", components[idx], errs[idx])
+		}
+		if c != nil {
+			changelogs = append(changelogs, c)
+		}
+	}
+	return changelogs, nil
+}
@@ -0,0 +1,57 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChecklistTrailer records a checklist item that was acknowledged before
+// releasing, one trailer per item
+const ChecklistTrailer = "Checklist-Acknowledged"
+
+// validateChecklist checks that items can be given to --ack, which separates
+// them with commas
+func validateChecklist(items []string) error {
+	seen := map[string]bool{}
+	for _, item := range items {
+		key := checklistKey(item)
+		switch {
+		case key == "":
+			return fmt.Errorf("checklist items can't be empty")
+		case strings.Contains(item, ","):
+			return fmt.Errorf("checklist item '%s' can't contain a comma", item)
+		case seen[key]:
+			return fmt.Errorf("checklist item '%s' is listed twice", item)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// checklistKey is what items and acknowledgements are compared by
+func checklistKey(item string) string {
+	return strings.ToLower(strings.TrimSpace(item))
+}
+
+// Checklist returns the items that have to be acknowledged before releasing
+// the component, any pre-release marker is ignored
+func (c *Config) Checklist(component string) []string {
+	component, _ = splitPreRelease(component)
+	return c.Components[component].Checklist
+}
+
+// MissingAcks returns the items of checklist that acks doesn't acknowledge,
+// case and surrounding spaces don't matter
+func MissingAcks(checklist, acks []string) []string {
+	acked := map[string]bool{}
+	for _, ack := range acks {
+		acked[checklistKey(ack)] = true
+	}
+	missing := []string{}
+	for _, item := range checklist {
+		if !acked[checklistKey(item)] {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,102 @@
+package release
+
+import (
+	"fmt"
+	"os"
+)
+
+// The trailers a CI fingerprint is recorded with
+const (
+	CIProviderTrailer = "CI-Provider"
+	CIJobTrailer      = "CI-Job"
+	CIRunnerTrailer   = "CI-Runner"
+)
+
+// CIFingerprint identifies the CI job a release was created by
+type CIFingerprint struct {
+	Provider string `json:"provider"`          // github, gitlab, jenkins, ...
+	JobURL   string `json:"job_url,omitempty"` // Where the job's logs are
+	Runner   string `json:"runner,omitempty"`  // The machine or agent that ran it
+}
+
+// ciProviders detect CI systems from their environment, in order
+var ciProviders = []struct {
+	name   string
+	detect string
+	job    func() string
+	runner string
+}{
+	{"github", "GITHUB_ACTIONS", func() string {
+		if os.Getenv("GITHUB_RUN_ID") == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	}, "RUNNER_NAME"},
+	{"gitlab", "GITLAB_CI", envValue("CI_JOB_URL"), "CI_RUNNER_DESCRIPTION"},
+	{"buildkite", "BUILDKITE", envValue("BUILDKITE_BUILD_URL"), "BUILDKITE_AGENT_NAME"},
+	{"circleci", "CIRCLECI", envValue("CIRCLE_BUILD_URL"), "HOSTNAME"},
+	{"travis", "TRAVIS", envValue("TRAVIS_JOB_WEB_URL"), "HOSTNAME"},
+	{"drone", "DRONE", envValue("DRONE_BUILD_LINK"), "DRONE_RUNNER_HOSTNAME"},
+	{"bitbucket", "BITBUCKET_BUILD_NUMBER", func() string { return "" }, "BITBUCKET_STEP_RUN_NUMBER"},
+	{"jenkins", "JENKINS_URL", envValue("BUILD_URL"), "NODE_NAME"},
+	{"teamcity", "TEAMCITY_VERSION", func() string { return "" }, "HOSTNAME"},
+	// Most CI systems set CI, including ones we don't know about
+	{"ci", "CI", func() string { return "" }, "HOSTNAME"},
+}
+
+func envValue(name string) func() string {
+	return func() string { return os.Getenv(name) }
+}
+
+// DetectCI returns the fingerprint of the CI job we're running in, nil when
+// not running in CI
+func DetectCI() *CIFingerprint {
+	for _, p := range ciProviders {
+		if v := os.Getenv(p.detect); v == "" || v == "false" {
+			continue
+		}
+		return &CIFingerprint{Provider: p.name, JobURL: p.job(), Runner: os.Getenv(p.runner)}
+	}
+	return nil
+}
+
+// AppendTo records the fingerprint as trailers of a release message
+func (f *CIFingerprint) AppendTo(message string) string {
+	message = AppendTrailer(message, CIProviderTrailer, f.Provider)
+	if f.JobURL != "" {
+		message = AppendTrailer(message, CIJobTrailer, f.JobURL)
+	}
+	if f.Runner != "" {
+		message = AppendTrailer(message, CIRunnerTrailer, f.Runner)
+	}
+	return message
+}
+
+// CI returns the fingerprint of the CI job that created the release, nil for
+// releases made by hand (or lightweight tags, which can't record one)
+func (r *Release) CI() *CIFingerprint {
+	var f *CIFingerprint
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if f == nil && (t.Key == CIProviderTrailer || t.Key == CIJobTrailer || t.Key == CIRunnerTrailer) {
+			f = &CIFingerprint{}
+		}
+		switch t.Key {
+		case CIProviderTrailer:
+			f.Provider = t.Value
+		case CIJobTrailer:
+			f.JobURL = t.Value
+		case CIRunnerTrailer:
+			f.Runner = t.Value
+		}
+	}
+	return f
+}
+
+// ReleasedFrom is "ci" for releases created by a CI job and "human" for the
+// rest
+func (r *Release) ReleasedFrom() string {
+	if r.CI() != nil {
+		return "ci"
+	}
+	return "human"
+}
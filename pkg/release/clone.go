@@ -0,0 +1,99 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/rs/zerolog/log"
+)
+
+// CloneManager clones the repository at url and returns a manager for it, this
+// lets bots release repos they don't have checked out. With git installed it's
+// a blobless partial clone (--filter=blob:none) of the default branch and the
+// tags into a temporary directory with nothing checked out, blobs like the
+// config are fetched when they're read. git authenticates on its own then,
+// auth is only used by the fallback: without git the default branch and the
+// tags are cloned into memory, in full since go-git can't filter. Close
+// removes the temporary clone.
+func CloneManager(url string, auth transport.AuthMethod, timeFmt, incFmt string) (*Manager, error) {
+	if _, err := exec.LookPath("git"); err == nil {
+		return partialClone(url, timeFmt, incFmt)
+	}
+	log.Debug().Msgf("git isn't installed, cloning %s into memory", url)
+	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:          url,
+		Auth:         auth,
+		NoCheckout:   true,
+		SingleBranch: true,
+		Tags:         git.AllTags,
+	})
+	if err != nil {
+		return nil, Classify("cloning "+url, err)
+	}
+	return NewManagerFromRepo(r, timeFmt, incFmt)
+}
+
+// partialClone clones url into a temporary directory with git, fetching
+// commits, trees and tags but no blobs
+func partialClone(url, timeFmt, incFmt string) (*Manager, error) {
+	dir, err := ioutil.TempDir("", "release-clone-")
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("cloning %s into %s", url, dir)
+	b := newExecBackend(nil, dir)
+	// A single branch clone only brings along the tags in its history,
+	// release tags on other branches are fetched separately
+	_, err = b.git(nil, "clone", "--quiet", "--no-checkout", "--filter=blob:none", "--single-branch", url, dir)
+	if err == nil {
+		_, err = b.git(nil, "fetch", "--quiet", "--filter=blob:none", "origin", "+refs/tags/*:refs/tags/*")
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, Classify("cloning "+url, err)
+	}
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	b.repo = r
+	mgr := &Manager{
+		repoDir:    dir,
+		cwd:        dir,
+		repo:       r,
+		timeFmt:    timeFmt,
+		incFmt:     incFmt,
+		backend:    b,
+		noWorktree: true,
+		tempDir:    dir,
+	}
+	if err := mgr.useConfiguredTagTemplate(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	mgr.loadGitTags()
+	return mgr, nil
+}
+
+// Close removes the temporary clone of CloneManager and the worktree of
+// Worktree, it does nothing for other managers
+func (r *Manager) Close() error {
+	if err := r.RemoveWorktrees(); err != nil {
+		return err
+	}
+	if r.tempDir == "" {
+		return nil
+	}
+	dir := r.tempDir
+	r.tempDir = ""
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove the clone in %s: %w", dir, err)
+	}
+	return nil
+}
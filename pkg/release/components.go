@@ -0,0 +1,135 @@
+package release
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release/notify"
+)
+
+// ComponentConfig describes a component of the repository. Besides its paths
+// and owner a component can override some of the repository's settings,
+// anything it doesn't set is inherited.
+type ComponentConfig struct {
+	// Paths are the directories and files that belong to the component,
+	// relative to the root of the repository. Changes anywhere else don't
+	// need a release of the component. Empty means the whole repository.
+	Paths []string `yaml:"paths"`
+	// Team owns the component, CODEOWNERS is used if it isn't set
+	Team string `yaml:"team"`
+
+	Scheme Scheme `yaml:"scheme"`
+	// Message is the message template, see MessageConfig.Template
+	Message string `yaml:"message"`
+	// Remote is pushed to unless --remote is given
+	Remote string `yaml:"remote"`
+	// Notify replaces the repository's notifications when set
+	Notify *notify.Config `yaml:"notify"`
+	// Hooks replace the repository's pre or post release hooks when set
+	Hooks *HooksConfig `yaml:"hooks"`
+	// SemVer tags every release with a SemVer version too when set
+	SemVer *SemVerConfig `yaml:"semver"`
+	// DependsOn are the components this one is built against, a release of
+	// any of them cascades to this one in plans. Go modules that require
+	// another component's module depend on it without being listed.
+	DependsOn []string `yaml:"depends_on"`
+	// Checklist has to be acknowledged before the component is released,
+	// interactively or with --ack
+	Checklist []string `yaml:"checklist"`
+	// Build builds the component's artifacts before it's tagged
+	Build *ComponentBuild `yaml:"build"`
+	// BuildMatrix cross-compiles the component, see 'release build-matrix'
+	BuildMatrix *BuildMatrix `yaml:"build_matrix"`
+	// Rollout replaces the repository's rollout when set
+	Rollout *Rollout `yaml:"rollout"`
+	// DCO requires every commit of a release to be signed off by its author
+	// (Signed-off-by), releasing without needs --override-dco
+	DCO bool `yaml:"dco"`
+}
+
+func (c ComponentConfig) validate() error {
+	for _, p := range c.Paths {
+		if p == "" || path.IsAbs(p) || strings.HasPrefix(path.Clean(p), "..") {
+			return fmt.Errorf("component path '%s' must be relative to the root of the repository", p)
+		}
+	}
+	if err := c.Scheme.validate(); err != nil {
+		return err
+	}
+	if err := validateChecklist(c.Checklist); err != nil {
+		return err
+	}
+	if c.Hooks != nil {
+		if err := c.Hooks.validate(); err != nil {
+			return err
+		}
+	}
+	if c.BuildMatrix != nil {
+		if err := c.BuildMatrix.validate(); err != nil {
+			return err
+		}
+	}
+	if c.SemVer != nil {
+		if err := c.SemVer.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Rollout != nil {
+		if err := c.Rollout.validate(); err != nil {
+			return err
+		}
+	}
+	return MessageConfig{Template: c.Message}.validate()
+}
+
+// Owns reports whether the file at name (slash separated, relative to the
+// root of the repository) belongs to the component
+func (c ComponentConfig) Owns(name string) bool {
+	if len(c.Paths) == 0 {
+		return true
+	}
+	for _, p := range c.Paths {
+		p = path.Clean(p)
+		if p == "." || name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ForComponent returns the config with the overrides of a component (any
+// pre-release marker is ignored) applied
+func (c *Config) ForComponent(component string) *Config {
+	component, _ = splitPreRelease(component)
+	merged := *c
+	override, ok := c.Components[component]
+	if !ok {
+		return &merged
+	}
+	if override.Scheme != "" {
+		merged.Scheme = override.Scheme
+	}
+	if override.Message != "" {
+		merged.Messages.Template = override.Message
+	}
+	if override.Remote != "" {
+		merged.Remote = override.Remote
+	}
+	if override.Notify != nil {
+		merged.Notify = *override.Notify
+	}
+	if override.Hooks != nil && override.Hooks.PreRelease != nil {
+		merged.Hooks.PreRelease = override.Hooks.PreRelease
+	}
+	if override.Hooks != nil && override.Hooks.PostRelease != nil {
+		merged.Hooks.PostRelease = override.Hooks.PostRelease
+	}
+	if override.Hooks != nil && override.Hooks.Scanners != nil {
+		merged.Hooks.Scanners = override.Hooks.Scanners
+	}
+	if override.Rollout != nil {
+		merged.Rollout = override.Rollout
+	}
+	return &merged
+}
@@ -0,0 +1,75 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultNotesFile is where the full notes of compressed messages are written
+// unless configured
+const DefaultNotesFile = "dist/{tag}-notes.md"
+
+// FullNotesTrailer points from a compressed tag message to its full notes
+const FullNotesTrailer = "Full-Notes"
+
+// NotesFileFor returns where the full notes of tag are written when its
+// message is compressed, relative to the repository
+func (c MessageConfig) NotesFileFor(tag string) string {
+	file := c.NotesFile
+	if file == "" {
+		file = DefaultNotesFile
+	}
+	return strings.ReplaceAll(file, "{tag}", tag)
+}
+
+// Compresses reports whether a message is long enough to be compressed
+func (c MessageConfig) Compresses(message string) bool {
+	return c.CompressOver > 0 && len(message) > c.CompressOver
+}
+
+// CompressMessage shortens a message to about limit bytes for a tag
+// annotation. The body is cut at a line, a line saying how much was left out
+// is added and the trailers are kept, followed by a FullNotesTrailer with
+// pointer. Messages that fit are returned as they are.
+func CompressMessage(message string, limit int, pointer string) string {
+	if len(message) <= limit {
+		return message
+	}
+	body, trailers := splitTrailers(message)
+	budget := limit - len(strings.Join(trailers, "\n")) - len(FullNotesTrailer) - len(pointer) - 40
+	lines := strings.Split(body, "\n")
+	kept, size := 0, 0
+	for _, line := range lines {
+		if size+len(line)+1 > budget {
+			break
+		}
+		size += len(line) + 1
+		kept++
+	}
+	summary := strings.TrimRight(strings.Join(lines[:kept], "\n"), "\n")
+	summary += fmt.Sprintf("\n\n[%d more lines in the full notes]", len(lines)-kept)
+	summary = strings.TrimLeft(summary, "\n")
+	if len(trailers) > 0 {
+		summary += "\n\n" + strings.Join(trailers, "\n")
+	}
+	return AppendTrailer(summary, FullNotesTrailer, pointer)
+}
+
+// WriteFullNotes writes the full message of a release to path and returns
+// the pointer for its FullNotesTrailer, the file name and its SHA-256
+func WriteFullNotes(path, message string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, []byte(message+"\n"), 0644); err != nil {
+		return "", err
+	}
+	sum, err := sha256File(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s sha256:%s", filepath.Base(path), sum), nil
+}
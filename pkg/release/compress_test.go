@@ -0,0 +1,58 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompressMessage(t *testing.T) {
+	short := "fix the thing\n\nReviewed-by: ann"
+	if got := CompressMessage(short, 100, "notes.md"); got != short {
+		t.Errorf("short messages shouldn't change, got %q", got)
+	}
+
+	lines := []string{}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("* change number %d", i))
+	}
+	message := strings.Join(lines, "\n") + "\n\nReviewed-by: ann"
+	got := CompressMessage(message, 300, "2020.07.001-notes.md sha256:abc")
+	if len(got) > 300 {
+		t.Errorf("compressed message is %d bytes:\n%s", len(got), got)
+	}
+	if !strings.HasPrefix(got, "* change number 0\n") {
+		t.Errorf("the start of the message should be kept:\n%s", got)
+	}
+	trailers := ParseTrailers(got)
+	if len(trailers) != 2 || trailers[0].Key != "Reviewed-by" || trailers[1] != (Trailer{Key: FullNotesTrailer, Value: "2020.07.001-notes.md sha256:abc"}) {
+		t.Errorf("unexpected trailers %v in:\n%s", trailers, got)
+	}
+	if !strings.Contains(got, "more lines in the full notes]") {
+		t.Errorf("the message should say lines were left out:\n%s", got)
+	}
+}
+
+func TestWriteFullNotes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := MessageConfig{CompressOver: 5}
+	if !cfg.Compresses("too long") || (MessageConfig{}).Compresses("too long") {
+		t.Error("only messages over compress_over should be compressed")
+	}
+	path := filepath.Join(dir, cfg.NotesFileFor("2020.07.001"))
+	pointer, err := WriteFullNotes(path, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256 of "notes\n"
+	if pointer != "2020.07.001-notes.md sha256:444e0fffbd825e9610ff5b199485707a0c895339ae80c15cc8a8aee41b106fda" {
+		t.Errorf("got pointer %s", pointer)
+	}
+}
@@ -0,0 +1,195 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+
+	"github.com/fernferret/release/pkg/release/forge"
+	"github.com/fernferret/release/pkg/release/notify"
+)
+
+// ConfigFile is the name of the per-repository config file, it lives in the
+// root of the repository
+const ConfigFile = ".release.yaml"
+
+// Config is the per-repository configuration read from ConfigFile
+type Config struct {
+	// Annotate makes every tag annotated, like --annotate
+	Annotate bool `yaml:"annotate"`
+	// RequireBranch refuses to release a detached HEAD, like --require-branch
+	RequireBranch bool            `yaml:"require_branch"`
+	Scheme        Scheme          `yaml:"scheme"`
+	Notify        notify.Config   `yaml:"notify"`
+	Freeze        []FreezeWindow  `yaml:"freeze"`
+	LeadTime      LeadTimeConfig  `yaml:"lead_time"`
+	Branches      BranchConfig    `yaml:"branches"`
+	Forge         forge.Config    `yaml:"forge"`
+	Milestones    MilestoneConfig `yaml:"milestones"`
+	OIDC          OIDCConfig      `yaml:"oidc"`
+	Metadata      MetadataConfig  `yaml:"metadata"`
+	Git           GitConfig       `yaml:"git"`
+	APIDiff       APIDiffConfig   `yaml:"apidiff"`
+	Lint          LintConfig      `yaml:"lint"`
+	Go            GoConfig        `yaml:"go"`
+	Packages      PackagesConfig  `yaml:"packages"`
+	Assets        AssetsConfig    `yaml:"assets"`
+	Build         BuildConfig     `yaml:"build"`
+	Increments    IncrementConfig `yaml:"increments"`
+	Train         TrainConfig     `yaml:"train"`
+	Schedule      ScheduleConfig  `yaml:"schedule"`
+	Daemon        DaemonConfig    `yaml:"daemon"`
+	Trust         TrustConfig     `yaml:"trust"`
+	Messages      MessageConfig   `yaml:"messages"`
+	Bot           BotConfig       `yaml:"bot"`
+	Risk          RiskConfig      `yaml:"risk"`
+	// TagTemplate names new tags instead of YYYY.MM.RRR-component, see
+	// ParseTagTemplate
+	TagTemplate string `yaml:"tag_template"`
+	// ClockSkew checks the local clock against the dates of releases
+	ClockSkew ClockSkewConfig `yaml:"clock_skew"`
+	// Rollout is recorded in every release, see Rollout
+	Rollout *Rollout `yaml:"rollout"`
+	// Remote is pushed to unless --remote is given, origin if empty
+	Remote string      `yaml:"remote"`
+	Hooks  HooksConfig `yaml:"hooks"`
+	// Floating tags are moved to every new release of their channel
+	Floating []FloatingTag `yaml:"floating"`
+	// Components maps component names to the parts of the repository they
+	// are built from, their owners and their overrides of these settings
+	Components map[string]ComponentConfig `yaml:"components"`
+}
+
+// ParseConfig parses and validates the yaml configuration
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the config for mistakes that yaml parsing can't catch
+func (c *Config) Validate() error {
+	if err := c.Scheme.validate(); err != nil {
+		return err
+	}
+	if c.TagTemplate != "" {
+		t, err := ParseTagTemplate(c.TagTemplate)
+		if err != nil {
+			return err
+		}
+		if t.ordinal != (c.Scheme == SchemeOrdinal) {
+			return fmt.Errorf("tag_template has to use .Day for the %s scheme and .Month otherwise", SchemeOrdinal)
+		}
+	}
+	for idx := range c.Freeze {
+		if err := c.Freeze[idx].validate(); err != nil {
+			return err
+		}
+	}
+	if err := c.APIDiff.validate(); err != nil {
+		return err
+	}
+	if err := c.Hooks.validate(); err != nil {
+		return err
+	}
+	if err := c.Lint.validate(); err != nil {
+		return err
+	}
+	if err := c.Packages.validate(); err != nil {
+		return err
+	}
+	if err := c.Build.validate(); err != nil {
+		return err
+	}
+	if err := c.Assets.validate(); err != nil {
+		return err
+	}
+	if err := c.Increments.validate(); err != nil {
+		return err
+	}
+	if err := c.OIDC.validate(); err != nil {
+		return err
+	}
+	if err := c.Metadata.validate(); err != nil {
+		return err
+	}
+	if err := c.Git.validate(); err != nil {
+		return err
+	}
+	if err := c.Train.validate(); err != nil {
+		return err
+	}
+	if err := c.ClockSkew.validate(); err != nil {
+		return err
+	}
+	if err := c.LeadTime.validate(); err != nil {
+		return err
+	}
+	if err := c.Schedule.validate(); err != nil {
+		return err
+	}
+	if err := c.Daemon.validate(); err != nil {
+		return err
+	}
+	if err := c.Milestones.validate(); err != nil {
+		return err
+	}
+	if err := c.Trust.validate(); err != nil {
+		return err
+	}
+	if c.Rollout != nil {
+		if err := c.Rollout.validate(); err != nil {
+			return err
+		}
+	}
+	if err := c.Messages.validate(); err != nil {
+		return err
+	}
+	if err := c.Bot.validate(); err != nil {
+		return err
+	}
+	for _, f := range c.Floating {
+		if err := f.validate(); err != nil {
+			return err
+		}
+	}
+	for name, component := range c.Components {
+		if err := component.validate(); err != nil {
+			return fmt.Errorf("component %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LoadConfig loads ConfigFile from the repository. For repos on disk it's read
+// from the working directory, otherwise (clones without one) it's read from the
+// HEAD commit. A missing file gives an empty config.
+func (r *Manager) LoadConfig() (*Config, error) {
+	data, err := r.readRepoFile(ConfigFile)
+	if os.IsNotExist(err) {
+		log.Debug().Msgf("no %s found, using defaults", ConfigFile)
+		return &Config{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// readRepoFile reads a file from the working directory, or from the HEAD
+// commit for clones without one (the target of UseTarget if it's set).
+// Missing files give an os.IsNotExist error.
+func (r *Manager) readRepoFile(name string) ([]byte, error) {
+	if r.repoDir != "" && !r.noWorktree && r.target == "" {
+		return ioutil.ReadFile(filepath.Join(r.repoDir, name))
+	}
+	return r.headFile(name)
+}
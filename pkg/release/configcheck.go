@@ -0,0 +1,193 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// WithDefaults returns a copy of the config with every unset setting filled
+// in with the value the tool uses, for showing the effective config
+func (c *Config) WithDefaults() *Config {
+	e := *c
+	if e.Scheme == "" {
+		e.Scheme = SchemeMonthly
+	}
+	if e.Remote == "" {
+		e.Remote = "origin"
+	}
+	if e.Branches.Prefix == "" {
+		e.Branches.Prefix = DefaultBranchPrefix
+	}
+	if e.Branches.Into == "" {
+		e.Branches.Into = DefaultMergeTarget
+	}
+	if e.Increments.Reset == "" {
+		e.Increments.Reset = ResetMonthly
+	}
+	if e.Messages.Encoding == "" {
+		e.Messages.Encoding = "utf-8"
+	}
+	if e.Messages.MaxLength == 0 {
+		e.Messages.MaxLength = DefaultMaxMessageLength
+	}
+	if e.APIDiff.Policy == "" {
+		e.APIDiff.Policy = "warn"
+	}
+	if e.Packages.Policy == "" {
+		e.Packages.Policy = "warn"
+	}
+	if e.Assets.Output == "" {
+		e.Assets.Output = DefaultChecksumFile
+	}
+	if e.Git.Backend == "" {
+		e.Git.Backend = "go-git"
+	}
+	if e.Trust.Untrusted == "" {
+		e.Trust.Untrusted = "ignore"
+	}
+	if e.Bot.Configured() && e.Bot.When == "" {
+		e.Bot.When = "ci"
+	}
+	return &e
+}
+
+// Check looks for settings that are valid on their own but don't make sense
+// together, Validate has to pass first. Every problem is described in a
+// warning.
+func (c *Config) Check() []string {
+	warnings := []string{}
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Settings naming components should name configured ones, a typo would
+	// otherwise silently do nothing
+	if len(c.Components) > 0 {
+		unknown := func(where, component string) {
+			component, _ = splitPreRelease(component)
+			if _, ok := c.Components[component]; !ok {
+				warn("%s names component %s, which isn't in components", where, component)
+			}
+		}
+		for _, component := range sortedKeys(c.Increments.Components) {
+			unknown("increments.components", component)
+		}
+		for _, f := range c.Packages.Files {
+			unknown("packages.files", f.Component)
+		}
+		for component := range c.Go.Modules {
+			unknown("go.modules", component)
+		}
+		for component := range c.APIDiff.Modules {
+			unknown("apidiff.modules", component)
+		}
+		for _, name := range sortedKeys(c.Components) {
+			for _, dep := range c.Components[name].DependsOn {
+				unknown(fmt.Sprintf("components.%s.depends_on", name), dep)
+			}
+		}
+		for _, component := range c.Lint.Components {
+			unknown("lint.components", component)
+		}
+		for _, w := range c.Freeze {
+			for _, component := range w.Components {
+				unknown(fmt.Sprintf("freeze window %s", w.Name), component)
+			}
+		}
+	}
+
+	// The ordinal scheme counts per day, an explicit monthly reset suggests
+	// the scheme was changed without looking at the increments
+	for _, name := range append([]string{""}, sortedKeys(c.Components)...) {
+		cfg, where := c, "scheme"
+		if name != "" {
+			cfg, where = c.ForComponent(name), fmt.Sprintf("component %s", name)
+		}
+		reset := c.Increments.Components[name]
+		if name != "" && reset == "" && cfg.Scheme == c.Scheme {
+			continue // Same as the top level, already warned about
+		}
+		if reset == "" {
+			reset = c.Increments.Reset
+		}
+		if cfg.Scheme == SchemeOrdinal && reset == ResetMonthly {
+			warn("%s uses %s, which resets the increment daily, increments.reset monthly has no effect", where, SchemeOrdinal)
+		}
+	}
+
+	if c.Trust.Keyring == "" && c.Trust.Untrusted == "fail" {
+		warn("trust.untrusted is fail but there's no trust.keyring, no tag is checked")
+	}
+	if c.Trust.Keyring == "" && c.Trust.SigningKey != "" {
+		warn("trust.signing_key signs tags but without a trust.keyring nobody checks the signatures")
+	}
+	if c.Packages.Policy != "" && len(c.Packages.Files) == 0 {
+		warn("packages.policy is %s but there are no packages.files", c.Packages.Policy)
+	}
+	if c.Assets.Sign != "" && len(c.Assets.Paths) == 0 {
+		warn("assets.sign is %s but there are no assets.paths to checksum", c.Assets.Sign)
+	}
+	if c.Forge.TokenEnv != "" && keyring.Getenv(c.Forge.TokenEnv) == "" {
+		warn("forge.token_env is %s but it isn't set in this environment or the keyring", c.Forge.TokenEnv)
+	}
+	return warnings
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch m := m.(type) {
+	case map[string]ResetPolicy:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]ComponentConfig:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CheckConfig is Config.Check plus the warnings that need the repository:
+// components whose latest release uses a different scheme than configured,
+// the next release would start a new sequence.
+func (r *Manager) CheckConfig(c *Config) []string {
+	warnings := c.Check()
+	latest := map[string]*calVerStandard{}
+	tags := map[string]string{}
+	for _, release := range r.releases {
+		component := release.Component()
+		version, ok := parseCalVer(r.tagTemplate, release.Tag)
+		if !ok || (latest[component] != nil && version.Compare(latest[component]) <= 0) {
+			continue
+		}
+		latest[component], tags[component] = version, release.Tag
+	}
+	components := []string{}
+	for component := range latest {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		if len(c.Components) > 0 && component != "" {
+			if _, ok := c.Components[component]; !ok {
+				continue
+			}
+		}
+		scheme := c.ForComponent(component).Scheme
+		if scheme == "" {
+			scheme = SchemeMonthly
+		}
+		if latest[component].IsOrdinal() != (scheme == SchemeOrdinal) {
+			warnings = append(warnings, fmt.Sprintf("the latest release %s doesn't use the configured scheme %s", tags[component], scheme))
+		}
+	}
+	return warnings
+}
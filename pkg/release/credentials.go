@@ -0,0 +1,104 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Credential is a username and password (usually a token) for an https url
+// from the git credential helpers (osxkeychain, manager-core, libsecret,
+// store, ...)
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// CredentialFill asks the configured git credential helpers for the
+// credentials of an http(s) url with 'git credential fill'. It never prompts,
+// nil is returned when no helper has credentials for the url.
+func CredentialFill(rawURL string) (*Credential, error) {
+	if offline {
+		return nil, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("credential helpers only handle http(s) urls, not %s", rawURL)
+	}
+	c := &Credential{Protocol: u.Scheme, Host: u.Host, Path: strings.TrimPrefix(u.Path, "/")}
+	if u.User != nil {
+		c.Username = u.User.Username()
+	}
+	out, err := c.run("fill")
+	if err != nil {
+		// No helper had anything and git wanted to prompt
+		return nil, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "username":
+			c.Username = kv[1]
+		case "password":
+			c.Password = kv[1]
+		}
+	}
+	if c.Password == "" {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Approve tells the helpers the credential worked so they keep it
+func (c *Credential) Approve() error {
+	_, err := c.run("approve")
+	return err
+}
+
+// Reject tells the helpers the credential was refused so they drop it
+func (c *Credential) Reject() error {
+	_, err := c.run("reject")
+	return err
+}
+
+// Auth returns the credential as go-git http auth
+func (c *Credential) Auth() transport.AuthMethod {
+	return &githttp.BasicAuth{Username: c.Username, Password: c.Password}
+}
+
+func (c *Credential) run(action string) ([]byte, error) {
+	input := &bytes.Buffer{}
+	fmt.Fprintf(input, "protocol=%s\nhost=%s\n", c.Protocol, c.Host)
+	if c.Path != "" {
+		fmt.Fprintf(input, "path=%s\n", c.Path)
+	}
+	if c.Username != "" {
+		fmt.Fprintf(input, "username=%s\n", c.Username)
+	}
+	if c.Password != "" {
+		fmt.Fprintf(input, "password=%s\n", c.Password)
+	}
+	input.WriteString("\n")
+	cmd := exec.Command("git", "credential", action)
+	cmd.Stdin = input
+	// Helpers may be asked, the user never is
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	return cmd.Output()
+}
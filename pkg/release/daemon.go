@@ -0,0 +1,67 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/fernferret/release/internal/cron"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// DaemonConfig is what release daemon releases and when
+type DaemonConfig struct {
+	// Cron is when to release, like "0 9 * * TUE"
+	Cron string `yaml:"cron"`
+	// Branch of the remote that is released, the current branch if empty
+	Branch string `yaml:"branch"`
+	// Components limits the daemon to these components, every component
+	// that changed is released if empty
+	Components []string `yaml:"components"`
+}
+
+func (c *DaemonConfig) validate() error {
+	if c.Cron == "" {
+		return nil
+	}
+	if _, err := cron.Parse(c.Cron); err != nil {
+		return fmt.Errorf("invalid daemon.cron: %w", err)
+	}
+	return nil
+}
+
+// FetchBranch fetches the tags and a branch from the remote, reloads the
+// releases and returns the full hash of the commit the branch is at on the
+// remote
+func (r *Manager) FetchBranch(remote, branch string, auth transport.AuthMethod) (string, error) {
+	tracking := plumbing.NewRemoteReferenceName(remote, branch)
+	err := r.gitBackend().Fetch(&git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", branch, tracking))},
+		Tags:       git.AllTags,
+		Auth:       auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return "", Classify(fmt.Sprintf("fetching %s from %s", branch, remote), err)
+	}
+	r.Reload()
+	hash, err := r.gitBackend().Reference(tracking)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", tracking.Short(), err)
+	}
+	return hash, nil
+}
+
+// CurrentBranch returns the branch HEAD is on, an error if it's detached
+func (r *Manager) CurrentBranch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is detached, there's no current branch")
+	}
+	return head.Name().Short(), nil
+}
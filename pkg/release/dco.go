@@ -0,0 +1,56 @@
+package release
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SignOffTrailer certifies the Developer Certificate of Origin for a commit
+const SignOffTrailer = "Signed-off-by"
+
+// DCOOverrideTrailer records why a release was made with commits that aren't
+// signed off
+const DCOOverrideTrailer = "DCO-Override"
+
+// signOffPerson parses the Name <email> of a sign-off
+func signOffPerson(value string) (object.Signature, bool) {
+	start := strings.LastIndex(value, "<")
+	end := strings.LastIndex(value, ">")
+	if start < 0 || end < start {
+		return object.Signature{}, false
+	}
+	return object.Signature{Name: strings.TrimSpace(value[:start]), Email: strings.TrimSpace(value[start+1 : end])}, true
+}
+
+// signedOff reports whether the author of the commit signed it off, with any
+// of the emails the .mailmap maps to theirs
+func (r *Manager) signedOff(c *Commit) bool {
+	for _, t := range ParseTrailers(c.Message) {
+		if !strings.EqualFold(t.Key, SignOffTrailer) {
+			continue
+		}
+		person, ok := signOffPerson(t.Value)
+		if ok && strings.EqualFold(r.mailmap.Map(person).Email, c.Author.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingSignOffs returns the commits of the component between the previous
+// release of the given (not yet created) tag and its target revision (HEAD if
+// empty) that their author didn't sign off, newest first
+func (r *Manager) MissingSignOffs(tag, target string, component ComponentConfig) ([]*Commit, error) {
+	commits, err := r.releaseCommits(tag, target, component)
+	if err != nil {
+		return nil, err
+	}
+	missing := []*Commit{}
+	for _, c := range commits {
+		if !r.signedOff(c) {
+			missing = append(missing, c)
+		}
+	}
+	return missing, nil
+}
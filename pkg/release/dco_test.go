@@ -0,0 +1,27 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestSignedOff(t *testing.T) {
+	jane := object.Signature{Name: "Jane Doe", Email: "jane@example.com"}
+	r := &Manager{mailmap: ParseMailmap([]byte("<jane@example.com> <jane@old.example.com>"))}
+	for _, tc := range []struct {
+		message string
+		want    bool
+	}{
+		{"fix\n\nSigned-off-by: Jane Doe <jane@example.com>", true},
+		{"fix\n\nsigned-off-by: Jane <JANE@example.com>", true},
+		{"fix\n\nSigned-off-by: Jane Doe <jane@old.example.com>", true},
+		{"fix\n\nSigned-off-by: Someone Else <else@example.com>", false},
+		{"fix\n\nSigned-off-by: Jane Doe", false},
+		{"Signed-off-by: Jane Doe <jane@example.com> isn't a trailer", false},
+	} {
+		if got := r.signedOff(&Commit{Author: jane, Message: tc.message}); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.message, got, tc.want)
+		}
+	}
+}
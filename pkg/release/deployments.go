@@ -0,0 +1,89 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DeploymentNotesRef is the git notes ref deployment statuses are recorded
+// in, on the commit of the release. 'git log --notes=release/deployments'
+// shows them.
+const DeploymentNotesRef = "refs/notes/release/deployments"
+
+// DeploymentStatuses are the statuses a deployment can report, the same ones
+// GitHub deployments use
+var DeploymentStatuses = []string{"queued", "in_progress", "success", "failure", "error", "inactive"}
+
+// Deployment is a status reported by a deployment system for a release in an
+// environment
+type Deployment struct {
+	Tag         string    `json:"tag"`
+	Env         string    `json:"env"`
+	Status      string    `json:"status"`
+	Date        time.Time `json:"date"`
+	By          string    `json:"by,omitempty"`
+	URL         string    `json:"url,omitempty"` // The deployment's logs or dashboard
+	Description string    `json:"description,omitempty"`
+}
+
+func (d *Deployment) validate() error {
+	if d.Env == "" || strings.ContainsAny(d.Env, "/ ") {
+		return fmt.Errorf("invalid environment name '%s'", d.Env)
+	}
+	if !contains(DeploymentStatuses, d.Status) {
+		return fmt.Errorf("invalid deployment status '%s', must be one of %s", d.Status, strings.Join(DeploymentStatuses, ", "))
+	}
+	return nil
+}
+
+// RecordDeployment records the deployment status of a release in the
+// metadata store, the notes of its commit by default. A successful deployment also moves the environment's ref to
+// the release, like MarkEnvironment.
+func (r *Manager) RecordDeployment(d Deployment, sig object.Signature) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+	rel := r.FindRelease(d.Tag)
+	if rel == nil {
+		return fmt.Errorf("no release tag %s found", d.Tag)
+	}
+	if d.Date.IsZero() {
+		d.Date = sig.When
+	}
+	if err := r.metadata().AddDeployment(d, rel.Hash, sig); err != nil {
+		return err
+	}
+	if d.Status == "success" {
+		return r.MarkEnvironment(d.Tag, d.Env)
+	}
+	return nil
+}
+
+// Deployments returns every recorded deployment status, oldest first
+func (r *Manager) Deployments() ([]Deployment, error) {
+	deployments, err := r.metadata().Deployments()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].Date.Before(deployments[j].Date)
+	})
+	return deployments, nil
+}
+
+// LatestDeployments returns the last status reported for each tag and
+// environment, keyed by tag and then environment
+func LatestDeployments(deployments []Deployment) map[string]map[string]Deployment {
+	latest := map[string]map[string]Deployment{}
+	for _, d := range deployments {
+		if latest[d.Tag] == nil {
+			latest[d.Tag] = map[string]Deployment{}
+		}
+		latest[d.Tag][d.Env] = d
+	}
+	return latest
+}
@@ -0,0 +1,56 @@
+package release
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BranchTrailer records the branch a release was made from when HEAD was
+// detached, as it usually is in CI
+const BranchTrailer = "Release-Branch"
+
+// ciBranchVars are the environment variables CI systems put the branch name
+// of a build in, in order of preference. GitHub's GITHUB_REF is handled by
+// CIBranch since it's a full ref.
+var ciBranchVars = []string{
+	"GITHUB_HEAD_REF",    // GitHub, pull request builds
+	"CI_COMMIT_BRANCH",   // GitLab
+	"CI_COMMIT_REF_NAME", // GitLab, also set for tags and merge requests
+	"BUILDKITE_BRANCH",
+	"CIRCLE_BRANCH",
+	"TRAVIS_BRANCH",
+	"DRONE_BRANCH",
+	"BITBUCKET_BRANCH",
+	"BRANCH_NAME", // Jenkins multibranch
+	"GIT_BRANCH",  // Jenkins git plugin, origin/main
+}
+
+// CIBranch returns the branch the CI system says is being built, empty if
+// there's no CI system we know about
+func CIBranch() string {
+	for _, name := range ciBranchVars {
+		if branch := os.Getenv(name); branch != "" {
+			return strings.TrimPrefix(branch, "origin/")
+		}
+	}
+	if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/heads/") {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	return ""
+}
+
+// DetachedHead reports whether HEAD points at a commit instead of a branch,
+// the full hash of HEAD is returned either way
+func (r *Manager) DetachedHead() (bool, string, error) {
+	head, err := r.repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return false, "", err
+	}
+	hash, err := r.gitBackend().ResolveRevision("HEAD")
+	if err != nil {
+		return false, "", err
+	}
+	return head.Type() == plumbing.HashReference, hash, nil
+}
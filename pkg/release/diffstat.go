@@ -0,0 +1,113 @@
+package release
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PathChange is a file changed by a release, with its added and deleted lines
+// (0 for binary files)
+type PathChange struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+}
+
+// Changes returns the files the release changed, nil until
+// Manager.LoadChanges loaded them
+func (r *Release) Changes() []PathChange {
+	return r.changes
+}
+
+// ChangedPaths returns the paths of the files the release changed, see
+// Changes
+func (r *Release) ChangedPaths() []string {
+	if r.changes == nil {
+		return nil
+	}
+	paths := []string{}
+	for _, c := range r.changes {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
+
+// Touches reports whether the release changed the file at name or anything
+// under it, see Changes
+func (r *Release) Touches(name string) bool {
+	for _, c := range r.changes {
+		if underPath(c.Path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// underPath reports whether the file is dir itself or anything under it
+func underPath(file, dir string) bool {
+	dir = strings.TrimSuffix(path.Clean(dir), "/")
+	return file == dir || dir == "." || strings.HasPrefix(file, dir+"/")
+}
+
+// LoadChanges works out the files a release changed since the release of its
+// component before it, every file for the first one, along with their
+// diffstat. They're kept with the release, see Release.Changes.
+func (r *Manager) LoadChanges(rel *Release) error {
+	if rel.changes != nil {
+		return nil
+	}
+	from := ""
+	if prev := r.PreviousRelease(rel.Tag); prev != nil {
+		from = prev.Hash
+	}
+	changes, err := r.diffstat(from, rel.Hash)
+	if err != nil {
+		return err
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	rel.changes = changes
+	return nil
+}
+
+// diffstat compares the trees of two commits, from may be empty to compare
+// with an empty tree
+func (r *Manager) diffstat(from, to string) ([]PathChange, error) {
+	if h := r.history(); h != nil {
+		return h.diffstat(from, to)
+	}
+	toCommit, err := r.repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var fromTree *object.Tree
+	if from != "" {
+		fromCommit, err := r.repo.CommitObject(plumbing.NewHash(from))
+		if err != nil {
+			return nil, err
+		}
+		if fromTree, err = fromCommit.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+	stats := []PathChange{}
+	for _, stat := range patch.Stats() {
+		stats = append(stats, PathChange{Path: stat.Name, Added: stat.Addition, Deleted: stat.Deletion})
+	}
+	return stats, nil
+}
@@ -0,0 +1,59 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestLoadChanges(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("initial commit", map[string]string{"README": "test\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.001", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("configure", map[string]string{"config/app.yaml": "a: 1\nb: 2\n", "README": "changed\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.002", ""); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := rm.FindRelease("2020.07.001")
+	if first.ChangedPaths() != nil {
+		t.Error("changes shouldn't be there before they're loaded")
+	}
+	if err := rm.LoadChanges(first); err != nil {
+		t.Fatal(err)
+	}
+	if want := []PathChange{{Path: "README", Added: 1}}; !reflect.DeepEqual(first.Changes(), want) {
+		t.Errorf("first release: got %+v, want %+v", first.Changes(), want)
+	}
+
+	second := rm.FindRelease("2020.07.002")
+	if err := rm.LoadChanges(second); err != nil {
+		t.Fatal(err)
+	}
+	want := []PathChange{{Path: "README", Added: 1, Deleted: 1}, {Path: "config/app.yaml", Added: 2}}
+	if !reflect.DeepEqual(second.Changes(), want) {
+		t.Errorf("second release: got %+v, want %+v", second.Changes(), want)
+	}
+	if !reflect.DeepEqual(second.ChangedPaths(), []string{"README", "config/app.yaml"}) {
+		t.Errorf("got paths %v", second.ChangedPaths())
+	}
+	for name, want := range map[string]bool{"config": true, "config/": true, "config/app.yaml": true, "conf": false, "api": false} {
+		if second.Touches(name) != want {
+			t.Errorf("Touches(%q) should be %v", name, want)
+		}
+	}
+}
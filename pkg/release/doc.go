@@ -0,0 +1,19 @@
+// Package release creates and reads CalVer release tags. It's what the release
+// command is built on, for programs that want to release from their own code:
+//
+//	rm, err := release.NewManager(".", "%Y.%m.", "%03d")
+//	if err != nil {
+//		return err
+//	}
+//	tag := rm.GetProposedName("api")
+//	_, err = rm.CreateTag(tag, "Release notes", "Jane Doe", "jane@example.com")
+//
+// Tools reading tags should use ParseTag rather than their own patterns so
+// every scheme is understood, and the ParseTag of the manager's TagTemplate
+// for a tag_template:
+//
+//	version, component, channel, err := release.ParseTag("2020.07.001-api-rc.1")
+//	version, component, channel, err = rm.TagTemplate().ParseTag(tag)
+//
+// Forges and notifiers are in the forge and notify packages below this one.
+package release
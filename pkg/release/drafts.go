@@ -0,0 +1,66 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+// ForgeReleaseNotes generates the notes of the forge release of a tag, the
+// changelog since the previous release of the component with links made by
+// Links, for humans to edit before the release is published
+func (r *Manager) ForgeReleaseNotes(tag string, component ComponentConfig) (string, error) {
+	commits, err := r.Changelog(tag)
+	if err != nil {
+		return "", err
+	}
+	if commits, err = r.componentCommits(commits, component); err != nil {
+		return "", err
+	}
+	b := &strings.Builder{}
+	if prev := r.PreviousRelease(tag); prev != nil {
+		fmt.Fprintf(b, "Changes since %s:\n\n", r.Links.Compare(prev.Tag, tag))
+	} else {
+		b.WriteString("First release.\n\n")
+	}
+	notes := ReleaseNotes(commits)
+	if r.Links != nil {
+		notes = r.Links.Notes(commits)
+	}
+	for _, note := range notes {
+		fmt.Fprintf(b, "- %s\n", note)
+	}
+	return b.String(), nil
+}
+
+// CreateDraftRelease creates the forge release of a pushed tag as a draft with
+// generated notes
+func (r *Manager) CreateDraftRelease(f forge.Forge, tag string, component ComponentConfig) (*forge.Release, error) {
+	releases, ok := f.(forge.Releases)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't have releases", f.Name())
+	}
+	notes, err := r.ForgeReleaseNotes(tag, component)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the notes of %s: %w", tag, err)
+	}
+	rel, err := releases.CreateRelease(forge.Release{Tag: tag, Name: tag, Body: notes, Draft: true})
+	if err != nil {
+		return nil, Classify(fmt.Sprintf("creating the draft release of %s", tag), err)
+	}
+	return rel, nil
+}
+
+// PublishRelease publishes the draft forge release of a tag
+func PublishRelease(f forge.Forge, tag string) (*forge.Release, error) {
+	releases, ok := f.(forge.Releases)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't have releases", f.Name())
+	}
+	rel, err := releases.PublishRelease(tag)
+	if err != nil {
+		return nil, Classify(fmt.Sprintf("publishing the release of %s", tag), err)
+	}
+	return rel, nil
+}
@@ -0,0 +1,201 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// EnvRefPrefix is where environment tracking refs live, the full ref is
+// refs/releases/envs/<env>/<component> and points at the commit that's deployed
+const EnvRefPrefix = "refs/releases/envs/"
+
+// EnvRelease is what's deployed to an environment for a component
+type EnvRelease struct {
+	Env       string
+	Component string
+	Tag       string // Empty if the commit doesn't match a known release anymore
+	Hash      string
+}
+
+// FindRelease returns the loaded release with the given tag, nil if there is
+// none
+func (r *Manager) FindRelease(tag string) *Release {
+	for idx := range r.releases {
+		if r.releases[idx].Tag == tag {
+			return &r.releases[idx]
+		}
+	}
+	return nil
+}
+
+func envRefName(env, component string) plumbing.ReferenceName {
+	return plumbing.ReferenceName(fmt.Sprintf("%s%s/%s", EnvRefPrefix, env, component))
+}
+
+// MarkEnvironment records that the release tag is now in the environment
+func (r *Manager) MarkEnvironment(tag, env string) error {
+	rel := r.FindRelease(tag)
+	if rel == nil {
+		return fmt.Errorf("no release tag %s found", tag)
+	}
+	component := rel.Component()
+	if component == "" {
+		return fmt.Errorf("tag %s has no component, can't track it per environment", tag)
+	}
+	return r.SetEnvironment(env, component, rel.Hash)
+}
+
+// SetEnvironment records that the commit of the component is in the
+// environment, MarkEnvironment without a tag for metadata servers that may
+// not have fetched it yet. commit is a full hash.
+func (r *Manager) SetEnvironment(env, component, commit string) error {
+	if env == "" || strings.ContainsAny(env, "/ ") {
+		return fmt.Errorf("invalid environment name '%s'", env)
+	}
+	if component == "" {
+		return fmt.Errorf("no component given for %s", env)
+	}
+	return r.metadata().SetEnvironment(env, component, commit)
+}
+
+// Environments returns what's deployed where, sorted by environment and then
+// component
+func (r *Manager) Environments() ([]EnvRelease, error) {
+	envs, err := r.metadata().Environments()
+	if err != nil {
+		return nil, err
+	}
+	for idx := range envs {
+		envs[idx].Tag = r.tagForCommit(envs[idx].Component, envs[idx].Hash)
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		if envs[i].Env == envs[j].Env {
+			return envs[i].Component < envs[j].Component
+		}
+		return envs[i].Env < envs[j].Env
+	})
+	return envs, nil
+}
+
+// tagForCommit finds the release of the component at the commit. The refs only
+// record the commit, so if it was tagged more than once final releases win
+// over pre-releases and then the highest version wins.
+func (r *Manager) tagForCommit(component, hash string) string {
+	var best *Release
+	var bestVersion *calVerStandard
+	for idx, rel := range r.releases {
+		if rel.Component() != component || rel.Hash != hash {
+			continue
+		}
+		version, _ := parseCalVer(r.tagTemplate, rel.Tag)
+		if best == nil ||
+			(best.IsPreRelease() && !rel.IsPreRelease()) ||
+			(best.IsPreRelease() == rel.IsPreRelease() && version.Compare(bestVersion) > 0) {
+			best = &r.releases[idx]
+			bestVersion = version
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Tag
+}
+
+var envRefSpec = config.RefSpec(fmt.Sprintf("+%s*:%s*", EnvRefPrefix, EnvRefPrefix))
+
+// notesRefSpec covers the notes release records things in, like deployment
+// statuses. Notes only grow so pushing them doesn't force.
+var notesRefSpec = config.RefSpec("refs/notes/release/*:refs/notes/release/*")
+
+// PushEnvironments pushes all environment refs and the deployment statuses to
+// the remote, the refs are expected to move so they're always forced. There's
+// nothing to push when the metadata isn't kept in git.
+func (r *Manager) PushEnvironments(remote string, auth transport.AuthMethod) error {
+	if !r.MetadataInGit() {
+		return nil
+	}
+	_, err := r.push(&git.PushOptions{RemoteName: remote, RefSpecs: []config.RefSpec{envRefSpec, notesRefSpec}, Auth: auth})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return Classify("pushing environments to "+remote, err)
+}
+
+// FetchEnvironments fetches the environment refs and deployment statuses from
+// the remote, replacing the local ones
+func (r *Manager) FetchEnvironments(remote string, auth transport.AuthMethod) error {
+	if !r.MetadataInGit() {
+		return nil
+	}
+	err := r.gitBackend().Fetch(&git.FetchOptions{RemoteName: remote, RefSpecs: []config.RefSpec{envRefSpec, "+" + notesRefSpec}, Auth: auth})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return Classify("fetching environments from "+remote, err)
+}
+
+// EnvDrift is the difference between two environments for one component
+type EnvDrift struct {
+	Component string
+	From      *EnvRelease // nil if the component isn't in the from environment
+	To        *EnvRelease // nil if the component isn't in the to environment
+	Pending   []*Commit   // Commits in From but not in To, what promoting would ship
+	Behind    int         // Commits in To that From doesn't have (hotfixes)
+}
+
+// InSync returns true if both environments run the same commit
+func (d EnvDrift) InSync() bool {
+	return d.From != nil && d.To != nil && d.From.Hash == d.To.Hash
+}
+
+// CompareEnvironments diffs every component tracked in either environment,
+// sorted by component
+func (r *Manager) CompareEnvironments(from, to string) ([]EnvDrift, error) {
+	envs, err := r.Environments()
+	if err != nil {
+		return nil, err
+	}
+	drifts := map[string]*EnvDrift{}
+	components := []string{}
+	for idx := range envs {
+		env := &envs[idx]
+		if env.Env != from && env.Env != to {
+			continue
+		}
+		drift, ok := drifts[env.Component]
+		if !ok {
+			drift = &EnvDrift{Component: env.Component}
+			drifts[env.Component] = drift
+			components = append(components, env.Component)
+		}
+		if env.Env == from {
+			drift.From = env
+		} else {
+			drift.To = env
+		}
+	}
+	sort.Strings(components)
+
+	result := []EnvDrift{}
+	for _, component := range components {
+		drift := drifts[component]
+		if drift.From != nil && drift.To != nil && !drift.InSync() {
+			if drift.Pending, err = r.commitsBetween(drift.To.Hash, drift.From.Hash); err != nil {
+				return nil, err
+			}
+			behind, err := r.commitsBetween(drift.From.Hash, drift.To.Hash)
+			if err != nil {
+				return nil, err
+			}
+			drift.Behind = len(behind)
+		}
+		result = append(result, *drift)
+	}
+	return result, nil
+}
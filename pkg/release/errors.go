@@ -0,0 +1,142 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Category groups errors by what the user has to do about them
+type Category string
+
+// The error categories
+const (
+	CategoryAuth       Category = "auth"
+	CategoryNetwork    Category = "network"
+	CategoryNotFound   Category = "not-found"
+	CategoryConflict   Category = "conflict"
+	CategoryRepository Category = "repository"
+	CategoryRejected   Category = "rejected"
+	CategoryUnknown    Category = "unknown"
+)
+
+// Error wraps an error with what the tool was doing when it happened, its
+// category and a hint on how to fix it
+type Error struct {
+	Op       string // What was being done, "pushing tag 2020.07.001-api"
+	Category Category
+	Hint     string // A suggestion for the user, might be empty
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// classifier matches an error and says what it means
+type classifier struct {
+	match    func(err error, msg string) bool
+	category Category
+	hint     string
+}
+
+func is(target error) func(error, string) bool {
+	return func(err error, _ string) bool {
+		return errors.Is(err, target)
+	}
+}
+
+func mentions(substrings ...string) func(error, string) bool {
+	return func(_ error, msg string) bool {
+		for _, s := range substrings {
+			if strings.Contains(msg, s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// classifiers are checked in order, the first match wins
+var classifiers = []classifier{
+	{is(ErrOffline), CategoryNetwork,
+		"this needs the network, run it without --offline or move the tags with 'release bundle'"},
+	{mentions("valid known_hosts file"), CategoryAuth,
+		"there's no ~/.ssh/known_hosts, connect once with 'ssh -T git@<host>' to create it or set SSH_KNOWN_HOSTS"},
+	{mentions("knownhosts: key is unknown", "key mismatch"), CategoryAuth,
+		"the remote's host key isn't trusted, connect once with 'ssh -T git@<host>' to add it to ~/.ssh/known_hosts"},
+	{mentions("ssh: handshake failed", "unable to authenticate", "no supported methods remain"), CategoryAuth,
+		"the remote rejected the ssh key, check --ssh-key points to a key the remote accepts (try 'ssh -T git@<host>')"},
+	{is(transport.ErrAuthenticationRequired), CategoryAuth,
+		"the remote needs credentials, use an ssh remote with --ssh-key or configure https credentials"},
+	{is(transport.ErrAuthorizationFailed), CategoryAuth,
+		"the credentials were rejected or lack permission, check they can push to the repository"},
+	{is(transport.ErrRepositoryNotFound), CategoryNotFound,
+		"the repository doesn't exist or the credentials can't see it, check the remote url with 'git remote -v'"},
+	{is(git.ErrRemoteNotFound), CategoryNotFound,
+		"no such remote, list the configured ones with 'git remote -v' and pass one with --remote"},
+	{is(git.ErrRepositoryNotExists), CategoryRepository,
+		"run release inside a git repository"},
+	{is(git.ErrTagExists), CategoryConflict,
+		"the tag already exists, someone may have released at the same time, run 'git fetch --tags' and try again"},
+	{is(ErrSignatureRequired), CategoryRejected,
+		"the remote only accepts signed tags, set trust.signing_key in .release.yaml (see 'Trusted tags' in the README) and release again"},
+	{is(ErrTagNameBlocked), CategoryRejected,
+		"the remote's tag rules don't allow this name, check the allowed patterns in the repository's rulesets or push rules"},
+	{is(ErrProtectedTag), CategoryRejected,
+		"the tag is protected and these credentials may not create it, ask a maintainer for access, release from the job that has it or retry with --retry-via-api and a token that may"},
+	{is(ErrPushRejected), CategoryRejected,
+		"a hook on the remote rejected the push, its reason is in the error above"},
+	{is(ErrTagMoved), CategoryConflict,
+		"the remote has a different tag with this name, run 'git fetch --tags' and check which one is right, fix a broken release with 'release supersede' rather than moving it with --force"},
+	{is(plumbing.ErrObjectNotFound), CategoryRepository,
+		"an object is missing, the clone may be shallow, run 'git fetch --unshallow --tags'"},
+	{mentions("object not found"), CategoryRepository,
+		"an object is missing, the clone may be shallow, run 'git fetch --unshallow --tags'"},
+	{is(plumbing.ErrReferenceNotFound), CategoryNotFound,
+		"the ref doesn't exist locally, run 'git fetch --tags' if it was created elsewhere"},
+	{func(err error, _ string) bool {
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}, CategoryNetwork, "the remote couldn't be reached, check the network connection and the remote url"},
+	{mentions("connection refused", "no such host", "i/o timeout", "network is unreachable", "connection reset"), CategoryNetwork,
+		"the remote couldn't be reached, check the network connection and the remote url"},
+}
+
+// Classify wraps err with the operation, a category and a hint. Errors that
+// are already classified and nil errors are returned as they are.
+func Classify(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	msg := err.Error()
+	for _, c := range classifiers {
+		if c.match(err, msg) {
+			return &Error{Op: op, Category: c.category, Hint: c.hint, Err: err}
+		}
+	}
+	return &Error{Op: op, Category: CategoryUnknown, Err: err}
+}
+
+// Hint returns the remediation hint of a classified error, empty if there is
+// none
+func Hint(err error) string {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Hint
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package release
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fernferret/release/pkg/release/notify"
+)
+
+// ReleaseEvent builds the notification event for a freshly created tag, the
+// changelog is best effort and left empty if it can't be computed
+func (r *Manager) ReleaseEvent(tag, remote, releasedBy, message string) *notify.Event {
+	e := &notify.Event{
+		Tag:        tag,
+		Component:  tagComponent(r.tagTemplate, tag),
+		Remote:     remote,
+		ReleasedBy: releasedBy,
+		Message:    message,
+		Teams:      r.Teams(tagComponent(r.tagTemplate, tag)),
+		Date:       time.Now(),
+	}
+	for _, t := range annotationTrailers(message) {
+		if t.Key == BreakingTrailer {
+			e.Breaking = true
+		}
+		if t.Key == RiskTrailer {
+			e.Risk = t.Value
+		}
+	}
+	affected, err := r.AffectedTeams(tag, tag)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to work out the teams %s affects, notifications won't include them", tag)
+	}
+	e.AffectedTeams = affected
+	commits, err := r.Changelog(tag)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to build changelog for %s, notifications won't include it", tag)
+		return e
+	}
+	e.Changelog = ReleaseNotes(commits)
+	return e
+}
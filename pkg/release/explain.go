@@ -0,0 +1,119 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Candidate is a tag looked at while proposing the next version
+type Candidate struct {
+	Tag     string
+	Skipped string // Why the tag was skipped, empty if it was considered
+	Latest  bool   // The tag the increment continues from
+}
+
+// Explanation describes how the next version was computed, for --explain
+type Explanation struct {
+	Date       time.Time
+	Scheme     Scheme
+	Policy     ResetPolicy
+	Candidates []Candidate
+	Latest     string // The tag the increment continued from, empty if none
+	Version    string // The proposed version
+	next       *calVerStandard
+}
+
+// ExplainProposedDateAt is GetProposedDateAt but also says how the version was
+// arrived at
+func (r *Manager) ExplainProposedDateAt(t time.Time, policy ResetPolicy) *Explanation {
+	return r.explainNext("", t, policy)
+}
+
+// comparePeriod compares the year, month and day of two versions ignoring the
+// increment, the result is like Compare
+func comparePeriod(a, b *calVerStandard) int {
+	x, y := *a, *b
+	x.Release, y.Release = 0, 0
+	return x.Compare(&y)
+}
+
+// explainNext proposes the next version. The increment continues from the
+// highest trusted release of the same scheme in the policy's scope (the
+// month, or the day for the ordinal scheme, unless the policy says yearly or
+// never).
+func (r *Manager) explainNext(name string, now time.Time, policy ResetPolicy) *Explanation {
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = SchemeMonthly
+	}
+	e := &Explanation{Date: now, Scheme: scheme, Policy: policy}
+	ordinal := scheme == SchemeOrdinal
+	// Start from release 0 so we can blindly call .Increase() at the end, the
+	// first release of a period is 001
+	latest := newCalVerStandard(uint64(now.Year()), uint64(now.Month()), 0)
+	if ordinal {
+		latest = newOrdinalCalVer(uint64(now.Year()), uint64(now.YearDay()), 0)
+	}
+	period := *latest
+	latestIdx := -1
+	for idx := range r.releases {
+		release := &r.releases[idx]
+		c := Candidate{Tag: release.Tag}
+		rev := release.info().version
+		ok := rev != nil
+		inScope := ok && policy.sameScope(rev, &period)
+		if ok && ordinal && policy != ResetYearly && policy != ResetNever {
+			// The ordinal scheme counts per day
+			inScope = rev.Year == period.Year && rev.Day == period.Day
+		}
+		switch {
+		case !ok:
+			c.Skipped = "not a CalVer tag"
+		case rev.IsOrdinal() != ordinal:
+			c.Skipped = "different version scheme"
+		case comparePeriod(rev, &period) > 0:
+			c.Skipped = "in the future"
+		case !inScope:
+			c.Skipped = fmt.Sprintf("before the %s increment reset", policy)
+		case rev.Release <= latest.Release:
+		case !r.isTrusted(release):
+			// Only tags that would raise the increment have to be verified
+			c.Skipped = fmt.Sprintf("untrusted (%s)", r.trustError(release))
+		default:
+			latest.Release = rev.Release
+			latestIdx = len(e.Candidates)
+		}
+		e.Candidates = append(e.Candidates, c)
+	}
+	if latestIdx >= 0 {
+		e.Candidates[latestIdx].Latest = true
+		e.Latest = e.Candidates[latestIdx].Tag
+	}
+	// Always increase the release before returning, this way we always get a
+	// unique one.
+	e.next = latest.Increase()
+	e.Version = e.next.FormatRelease(r.tagTemplate, name)
+	return e
+}
+
+// String renders the explanation for humans
+func (e *Explanation) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "date: %s, scheme: %s, increment resets: %s\n", e.Date.Format("2006-01-02"), e.Scheme, e.Policy)
+	for _, c := range e.Candidates {
+		switch {
+		case c.Latest:
+			fmt.Fprintf(b, "  %-28s latest, the increment continues from here\n", c.Tag)
+		case c.Skipped != "":
+			fmt.Fprintf(b, "  %-28s skipped: %s\n", c.Tag, c.Skipped)
+		default:
+			fmt.Fprintf(b, "  %-28s considered, lower increment\n", c.Tag)
+		}
+	}
+	if e.Latest == "" {
+		fmt.Fprintf(b, "no release in scope, starting over at 1\n")
+	}
+	fmt.Fprintf(b, "next version: %s\n", e.Version)
+	return b.String()
+}
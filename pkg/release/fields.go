@@ -0,0 +1,188 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Person is a signature in exported releases
+type Person struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+func newPerson(sig object.Signature) Person {
+	return Person{Name: sig.Name, Email: sig.Email, Date: sig.When}
+}
+
+// Export is the JSON representation of a release used by list --json and
+// export
+type Export struct {
+	Tag         string         `json:"tag"`
+	Component   string         `json:"component"`
+	PreRelease  string         `json:"pre_release,omitempty"`
+	Version     string         `json:"version"`
+	Hash        string         `json:"hash"`
+	Message     string         `json:"message"`
+	Annotated   bool           `json:"annotated"`
+	Breaking    bool           `json:"breaking"`
+	CI          *CIFingerprint `json:"ci,omitempty"`
+	InitiatedBy string         `json:"initiated_by,omitempty"` // Who started a release tagged by a bot
+	Teams       []string       `json:"teams,omitempty"`        // The teams owning the component, see Manager.Teams
+	Deployments []Deployment   `json:"deployments,omitempty"`  // The last status in each environment, see Manager.Deployments
+	Changes     []PathChange   `json:"changes,omitempty"`      // The files the release changed, see Manager.LoadChanges
+	ReleasedBy  Person         `json:"released_by"`
+	Author      Person         `json:"author"`
+	Committer   Person         `json:"committer"`
+	Tagger      *Person        `json:"tagger,omitempty"`
+
+	// CrossCutting releases changed files owned by the AffectedTeams
+	CrossCutting  bool     `json:"cross_cutting,omitempty"`
+	AffectedTeams []string `json:"affected_teams,omitempty"`
+	// Risk is the score recorded when the release was created
+	Risk *Risk `json:"risk,omitempty"`
+	// Rollout is how the release should be rolled out
+	Rollout *Rollout `json:"rollout,omitempty"`
+	// Supersedes is the release this one replaces
+	Supersedes string `json:"supersedes,omitempty"`
+}
+
+// Version returns the version part of the tag (2020.07.001 for
+// 2020.07.001-api), empty if it isn't a CalVer tag
+func (r *Release) Version() string {
+	info := r.info()
+	if info.version == nil {
+		return ""
+	}
+	return info.version.FormatRelease(info.template, "")
+}
+
+// Export returns the release in its exported form
+func (r *Release) Export() Export {
+	e := Export{
+		Tag:         r.Tag,
+		Component:   r.Component(),
+		PreRelease:  r.PreRelease(),
+		Version:     r.Version(),
+		Hash:        r.Hash,
+		Message:     strings.TrimSpace(r.Message()),
+		Annotated:   r.Tagger != nil,
+		Breaking:    r.IsBreaking(),
+		CI:          r.CI(),
+		InitiatedBy: r.InitiatedBy(),
+		ReleasedBy:  newPerson(r.ReleasedBy()),
+		Author:      newPerson(r.Author),
+		Committer:   newPerson(r.Committer),
+		Changes:     r.Changes(),
+	}
+	if teams := r.AffectedTeams(); len(teams) > 0 {
+		e.CrossCutting = true
+		e.AffectedTeams = teams
+	}
+	e.Risk = r.Risk()
+	e.Rollout = r.Rollout()
+	e.Supersedes = r.Supersedes()
+	if r.Tagger != nil {
+		tagger := newPerson(*r.Tagger)
+		e.Tagger = &tagger
+	}
+	return e
+}
+
+// Field implements filter.Fields so releases can be matched against --filter
+// expressions. People (released_by, author, committer, tagger) have .name,
+// .email and .date sub fields.
+func (r *Release) Field(name string) (interface{}, bool) {
+	version := r.info().version
+	if version == nil {
+		version = &calVerStandard{}
+	}
+	switch name {
+	case "tag":
+		return r.Tag, true
+	case "component":
+		return r.Component(), true
+	case "prerelease", "pre_release":
+		return r.PreRelease(), true
+	case "version":
+		return r.Version(), true
+	case "year":
+		return float64(version.Year), true
+	case "month":
+		return float64(version.Month), true
+	case "day":
+		return float64(version.Day), true
+	case "increment":
+		return float64(version.Release), true
+	case "hash":
+		return r.Hash, true
+	case "message":
+		return r.Message(), true
+	case "subject":
+		return Subject(r.CommitMessage), true
+	case "annotated":
+		return r.Tagger != nil, true
+	case "breaking":
+		return r.IsBreaking(), true
+	case "cross_cutting":
+		return r.IsCrossCutting(), true
+	case "affected_teams":
+		return strings.Join(r.AffectedTeams(), ","), true
+	case "risk", "risk.level":
+		if risk := r.Risk(); risk != nil {
+			if name == "risk" {
+				return float64(risk.Score), true
+			}
+			return risk.Level, true
+		}
+		if name == "risk" {
+			return float64(0), true
+		}
+		return "", true
+	case "date":
+		return r.Date(), true
+	case "initiated_by":
+		return r.InitiatedBy(), true
+	case "supersedes":
+		return r.Supersedes(), true
+	case "released_from":
+		return r.ReleasedFrom(), true
+	}
+	ci := r.CI()
+	if ci == nil {
+		ci = &CIFingerprint{}
+	}
+	switch name {
+	case "ci.provider":
+		return ci.Provider, true
+	case "ci.job_url":
+		return ci.JobURL, true
+	case "ci.runner":
+		return ci.Runner, true
+	}
+	people := map[string]object.Signature{
+		"released_by": r.ReleasedBy(),
+		"author":      r.Author,
+		"committer":   r.Committer,
+	}
+	if r.Tagger != nil {
+		people["tagger"] = *r.Tagger
+	} else {
+		people["tagger"] = object.Signature{}
+	}
+	for prefix, sig := range people {
+		switch name {
+		case fmt.Sprintf("%s.name", prefix):
+			return sig.Name, true
+		case fmt.Sprintf("%s.email", prefix):
+			return sig.Email, true
+		case fmt.Sprintf("%s.date", prefix):
+			return sig.When, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/fernferret/release/internal/filter"
+)
+
+func TestReleaseFilter(t *testing.T) {
+	rm, _ := newTestManager(t, "2020.06.001-api", "2020.07.001-api", "2020.07.002-web", "2020.07.003-api-rc.1")
+	tests := map[string][]string{
+		`component == "api"`:                                                        {"2020.07.003-api-rc.1", "2020.07.001-api", "2020.06.001-api"},
+		`component == "api" && !prerelease`:                                         {"2020.07.001-api", "2020.06.001-api"},
+		`month == 7 and increment >= 2`:                                             {"2020.07.003-api-rc.1", "2020.07.002-web"},
+		`tag startswith "2020.06" || component == "web"`:                            {"2020.07.002-web", "2020.06.001-api"},
+		`author.email endswith "@example.com" && tag matches "^2020\\.07\\.00[12]"`: {"2020.07.002-web", "2020.07.001-api"},
+		`date >= "2020-07-01T12:02:00Z"`:                                            {"2020.07.003-api-rc.1", "2020.07.002-web"},
+	}
+	for src, want := range tests {
+		expr, err := filter.Parse(src)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		got := []string{}
+		for _, rel := range rm.Releases() {
+			rel := rel
+			ok, err := expr.Match(&rel)
+			if err != nil {
+				t.Errorf("%s: %s", src, err)
+			}
+			if ok {
+				got = append(got, rel.Tag)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", src, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v, want %v", src, got, want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// FloatingTag is a tag like api-latest or api-stable that is moved to every
+// new release of its channel, so consumers can follow a stream of releases
+// without parsing versions
+type FloatingTag struct {
+	// Name of the tag, {component} is replaced with the released component
+	Name string `yaml:"name"`
+	// Channel of the releases the tag follows: empty for final releases, a
+	// pre-release marker (rc, beta, ...) or a planned release's channel
+	Channel string `yaml:"channel"`
+	// Components limits the tag to some components, all if empty
+	Components []string `yaml:"components"`
+}
+
+func (f FloatingTag) validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("floating tags need a name")
+	}
+	if name := strings.ReplaceAll(f.Name, "{component}", "x"); strings.HasPrefix(name, "-") || strings.Contains(name, "..") || strings.ContainsAny(name, " ~^:?*[\\") {
+		return fmt.Errorf("floating tag '%s' isn't a valid tag name", f.Name)
+	}
+	if _, ok := parseCalVer(nil, strings.ReplaceAll(f.Name, "{component}", "x")); ok {
+		return fmt.Errorf("floating tag '%s' looks like a release", f.Name)
+	}
+	return nil
+}
+
+// TagFor returns the name of the floating tag for a component
+func (f FloatingTag) TagFor(component string) string {
+	return strings.Trim(strings.ReplaceAll(f.Name, "{component}", component), "-.")
+}
+
+// ReleaseChannel returns the channel of a release: the pre-release marker
+// without its number (rc for api-rc.2), the Release-Channel trailer of the
+// message or empty for final releases
+func ReleaseChannel(rel *Release, message string) string {
+	if pre := rel.PreRelease(); pre != "" {
+		return preReleaseChannel(pre)
+	}
+	for _, t := range annotationTrailers(message) {
+		if t.Key == ChannelTrailer {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// FloatingTagsFor returns the floating tags a new release moves, see
+// Manager.ParseRelease for releases that weren't loaded
+func FloatingTagsFor(floating []FloatingTag, rel *Release, message string) []string {
+	component := rel.Component()
+	channel := ReleaseChannel(rel, message)
+	names := []string{}
+	for _, f := range floating {
+		if f.Channel != channel {
+			continue
+		}
+		if len(f.Components) > 0 && !contains(f.Components, component) {
+			continue
+		}
+		names = append(names, f.TagFor(component))
+	}
+	return names
+}
+
+// MoveFloatingTags points the floating tags at the commit of a release. They
+// are always lightweight, whatever the release is.
+func (r *Manager) MoveFloatingTags(names []string, release string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	hash, err := r.ResolveCommit(release)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := r.gitBackend().SetReference(plumbing.NewTagReferenceName(name), hash); err != nil {
+			return fmt.Errorf("moving %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// PushFloatingTags force pushes just the floating tags, they are expected to
+// move so unlike releases they are never checked against the remote
+func (r *Manager) PushFloatingTags(names []string, remote string, auth transport.AuthMethod) error {
+	if len(names) == 0 {
+		return nil
+	}
+	refSpecs := []config.RefSpec{}
+	for _, name := range names {
+		refSpecs = append(refSpecs, config.RefSpec("+"+string(tagToRefspec(name))))
+	}
+	_, err := r.push(&git.PushOptions{RemoteName: remote, RefSpecs: refSpecs, Auth: auth})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	if err != nil {
+		return Classify(fmt.Sprintf("pushing floating tags to remote %s", remote), err)
+	}
+	return nil
+}
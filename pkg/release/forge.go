@@ -0,0 +1,96 @@
+package release
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+// ReleaseNotesHeading is the pull request section used as the release message
+// when a pull request description has one
+const ReleaseNotesHeading = "Release Notes"
+
+// RemoteURL returns the (first) url of a remote
+func (r *Manager) RemoteURL(remote string) (string, error) {
+	rem, err := r.repo.Remote(remote)
+	if err != nil {
+		return "", err
+	}
+	urls := rem.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no url", remote)
+	}
+	return urls[0], nil
+}
+
+// Forge returns a forge client for the repository the remote points to. Without
+// a token in the environment the git credential helpers are asked for the
+// forge's host, the password git uses for https is usually a token the API
+// accepts too.
+func (r *Manager) Forge(remote string, cfg forge.Config) (forge.Forge, error) {
+	remoteURL, err := r.RemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := forge.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		cfg.Token = r.ForgeToken
+	}
+	if cfg.Token == "" && os.Getenv(forge.TokenEnv(cfg, repo)) == "" {
+		if cred, err := CredentialFill("https://" + repo.Host); err == nil && cred != nil {
+			log.Debug().Msgf("using the token for %s from the git credential helpers", repo.Host)
+			cfg.Token = cred.Password
+		}
+	}
+	return forge.New(repo, cfg)
+}
+
+// PullRequestNotes finds the merged pull request HEAD belongs to and returns
+// the release notes from its description: the "## Release Notes" section if
+// there is one, otherwise the whole description. The pull request is nil if
+// HEAD didn't come from one.
+func (r *Manager) PullRequestNotes(f forge.Forge) (string, *forge.PullRequest, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return "", nil, err
+	}
+	pr, err := f.PullRequestForCommit(head)
+	if err != nil || pr == nil {
+		return "", nil, err
+	}
+	if notes, ok := forge.Section(pr.Body, ReleaseNotesHeading); ok {
+		return notes, pr, nil
+	}
+	return pr.Body, pr, nil
+}
+
+// CreateTagViaForge creates a local tag on the forge through its API instead
+// of pushing it. Annotated tags get a new tag object with the same message
+// and tagger, signatures can't be carried over.
+func (r *Manager) CreateTagViaForge(f forge.Forge, tag string) error {
+	creator, ok := f.(forge.TagCreator)
+	if !ok {
+		return fmt.Errorf("%s can't create tags through its API", f.Name())
+	}
+	hash, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(tag))
+	if err != nil {
+		return err
+	}
+	obj, commit, err := r.resolveTag(hash)
+	if err != nil {
+		return err
+	}
+	t := forge.Tag{Name: tag, Commit: commit.Hash}
+	if obj != nil {
+		t.Message = obj.Message
+		t.TaggerName, t.TaggerEmail, t.TaggerDate = obj.Tagger.Name, obj.Tagger.Email, obj.Tagger.When
+	}
+	return Classify(fmt.Sprintf("creating tag %s through the %s API", tag, f.Name()), creator.CreateTag(t))
+}
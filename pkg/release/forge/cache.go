@@ -0,0 +1,91 @@
+package forge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cachedResponse is a GET response kept to revalidate with the forge, a 304
+// doesn't count against GitHub's rate limit
+type cachedResponse struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+	Next         string `json:"next,omitempty"` // The next page of a list
+}
+
+// responseCache keeps responses in memory and, when it has a directory, on
+// disk so they're reused by the next run
+type responseCache struct {
+	dir     string
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+func newResponseCache(dir string) *responseCache {
+	return &responseCache{dir: dir, entries: map[string]*cachedResponse{}}
+}
+
+// defaultCacheDir is release/forge in the user's cache directory, empty if
+// there is none
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "release", "forge")
+}
+
+// cacheKey hashes the url with the credentials, responses for one token are
+// never served to another
+func cacheKey(url string, headers map[string]string) string {
+	h := sha256.New()
+	h.Write([]byte(url))
+	for _, k := range []string{"Authorization", "PRIVATE-TOKEN"} {
+		h.Write([]byte{0})
+		h.Write([]byte(headers[k]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) get(key string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		return e
+	}
+	if c.dir == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	e := &cachedResponse{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil
+	}
+	c.entries[key] = e
+	return e
+}
+
+// put stores the response, failing to write it to disk only costs a full
+// request next time
+func (c *responseCache) put(key string, e *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil || os.MkdirAll(c.dir, 0700) != nil {
+		return
+	}
+	ioutil.WriteFile(filepath.Join(c.dir, key+".json"), data, 0600)
+}
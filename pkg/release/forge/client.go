@@ -0,0 +1,293 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultMaxWait is how long a rate limited request waits for the limit to
+// reset before giving up
+const DefaultMaxWait = 5 * time.Minute
+
+// maxAttempts is how often a request is sent when it's rate limited or the
+// forge has a hiccup (a 502, 503 or 504)
+const maxAttempts = 5
+
+// client is the small JSON over HTTP client the forges share. GET responses
+// are cached and revalidated with ETags, and requests wait out the rate limits
+// GitHub and GitLab announce in their headers.
+type client struct {
+	baseURL string
+	headers map[string]string
+	http    *http.Client
+	cache   *responseCache // nil when caching is off
+	maxWait time.Duration
+
+	mu        sync.Mutex
+	remaining int // Requests left in the window, -1 if unknown
+	reset     time.Time
+}
+
+// clients are shared by every forge talking to the same API with the same
+// credentials, so bulk operations see one rate limit and one cache
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*client{}
+)
+
+func newClient(baseURL string, headers map[string]string, cfg Config) (*client, error) {
+	shared := fmt.Sprintf("%s %s %v %s", cacheKey(baseURL, headers), cfg.MaxWait, cfg.Cache == nil || *cfg.Cache, cfg.CacheDir)
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if c, ok := clients[shared]; ok {
+		return c, nil
+	}
+	c := &client{
+		baseURL:   baseURL,
+		headers:   headers,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		maxWait:   DefaultMaxWait,
+		remaining: -1,
+	}
+	if cfg.MaxWait != "" {
+		d, err := time.ParseDuration(cfg.MaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("forge.max_wait %s isn't a duration like 90s or 5m", cfg.MaxWait)
+		}
+		c.maxWait = d
+	}
+	if cfg.Cache == nil || *cfg.Cache {
+		dir := cfg.CacheDir
+		if dir == "" {
+			dir = defaultCacheDir()
+		}
+		c.cache = newResponseCache(dir)
+	}
+	clients[shared] = c
+	return c, nil
+}
+
+// get fetches path (relative to the base URL) and decodes the JSON response
+// into out
+func (c *client) get(path string, out interface{}) error {
+	return c.do("GET", path, nil, out)
+}
+
+// getPage is get for paginated lists, it returns the URL of the next page or
+// "" on the last one. path can be a URL returned by an earlier call.
+func (c *client) getPage(path string, out interface{}) (string, error) {
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = c.baseURL + path
+	}
+	return c.request("GET", url, nil, out)
+}
+
+// post sends in as JSON to path and decodes the JSON response into out
+func (c *client) post(path string, in, out interface{}) error {
+	return c.send("POST", path, in, out)
+}
+
+// send is post with another method, like PATCH or PUT
+func (c *client) send(method, path string, in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(method, path, data, out)
+}
+
+func (c *client) do(method, path string, body []byte, out interface{}) error {
+	_, err := c.request(method, c.baseURL+path, body, out)
+	return err
+}
+
+// request sends the request and decodes the JSON response into out, returning
+// the URL of the next page if the response is paginated
+func (c *client) request(method, url string, body []byte, out interface{}) (string, error) {
+	var key string
+	var cached *cachedResponse
+	if method == "GET" && c.cache != nil {
+		key = cacheKey(url, c.headers)
+		cached = c.cache.get(key)
+	}
+	for attempt := 1; ; attempt++ {
+		if err := c.waitForLimit(); err != nil {
+			return "", err
+		}
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, url, reader)
+		if err != nil {
+			return "", err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range c.headers {
+			if v != "" {
+				req.Header.Set(k, v)
+			}
+		}
+		if cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return "", err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		c.updateLimit(resp.Header)
+		next := nextPage(url, resp.Header)
+
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			log.Debug().Msgf("%s %s not modified, using the cached response", method, req.URL.Path)
+			data, next = cached.Body, cached.Next
+		} else if wait, retry := c.retryAfter(method, resp, attempt); retry {
+			if wait > c.maxWait {
+				return "", fmt.Errorf("%s %s is rate limited for %s, longer than forge.max_wait (%s)", method, req.URL.Path, wait.Round(time.Second), c.maxWait)
+			}
+			log.Warn().Msgf("%s %s returned %s, retrying in %s", method, req.URL.Path, resp.Status, wait.Round(time.Second))
+			time.Sleep(wait)
+			continue
+		} else if resp.StatusCode >= 300 {
+			return "", fmt.Errorf("%s %s returned %s: %s", method, req.URL.Path, resp.Status, data)
+		} else if key != "" {
+			etag, modified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+			if etag != "" || modified != "" {
+				c.cache.put(key, &cachedResponse{ETag: etag, LastModified: modified, Body: data, Next: next})
+			}
+		}
+		if out == nil {
+			return next, nil
+		}
+		return next, json.Unmarshal(data, out)
+	}
+}
+
+// nextPage returns the URL of the next page from the Link header both forges
+// send, or from GitLab's X-Next-Page
+func nextPage(current string, h http.Header) string {
+	for _, link := range strings.Split(h.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` && target != "" {
+				return target
+			}
+		}
+	}
+	page := h.Get("X-Next-Page")
+	if page == "" {
+		return ""
+	}
+	u, err := neturl.Parse(current)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("page", page)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// updateLimit remembers the rate limit the forge announced, GitHub sends
+// X-RateLimit-*, GitLab RateLimit-*
+func (c *client) updateLimit(h http.Header) {
+	remaining := headerInt(h, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	reset := headerInt(h, "X-RateLimit-Reset", "RateLimit-Reset")
+	if remaining < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remaining = remaining
+	if reset > 0 {
+		c.reset = time.Unix(int64(reset), 0)
+	}
+}
+
+// waitForLimit sleeps until the rate limit resets when it's used up, rather
+// than sending requests that are sure to be refused
+func (c *client) waitForLimit() error {
+	c.mu.Lock()
+	remaining, reset := c.remaining, c.reset
+	c.mu.Unlock()
+	if remaining != 0 {
+		return nil
+	}
+	wait := time.Until(reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > c.maxWait {
+		return fmt.Errorf("the %s rate limit is used up until %s, longer than forge.max_wait (%s)", c.baseURL, reset.Format(time.Kitchen), c.maxWait)
+	}
+	log.Warn().Msgf("the %s rate limit is used up, waiting %s for it to reset", c.baseURL, wait.Round(time.Second))
+	time.Sleep(wait)
+	return nil
+}
+
+// retryAfter says whether and when to send the request again: when it was
+// rate limited (a 429, or a 403 with no requests left or a Retry-After) or,
+// for GETs, when the forge had a temporary failure
+func (c *client) retryAfter(method string, resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+	// Exponential backoff when the forge doesn't say how long to wait
+	backoff := time.Duration(1<<uint(attempt)) * time.Second
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (resp.Header.Get("Retry-After") != "" || headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining") == 0))
+	switch {
+	case limited:
+		if secs := headerInt(resp.Header, "Retry-After"); secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		c.mu.Lock()
+		reset := c.reset
+		c.mu.Unlock()
+		if wait := time.Until(reset); wait > 0 {
+			return wait, true
+		}
+		return backoff, true
+	case method == "GET" && (resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout):
+		return backoff, true
+	}
+	return 0, false
+}
+
+// headerInt returns the first of the headers that is set as a number, -1 if
+// none is
+func headerInt(h http.Header, names ...string) int {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,226 @@
+// Package forge talks to the REST APIs of code forges (GitHub, GitLab) for the
+// things git itself can't do, like finding the pull request a commit came
+// from.
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// Config configures the forge client, everything is optional and detected
+// from the remote URL when possible
+type Config struct {
+	Type     string `yaml:"type"`      // github or gitlab, detected from the host if empty
+	APIURL   string `yaml:"api_url"`   // For self-hosted forges, defaults to the public API
+	TokenEnv string `yaml:"token_env"` // Defaults to GITHUB_TOKEN or GITLAB_TOKEN
+	// Cache keeps GET responses in the user's cache directory (or CacheDir)
+	// and revalidates them with ETags, on unless false
+	Cache    *bool  `yaml:"cache"`
+	CacheDir string `yaml:"cache_dir"`
+	// MaxWait is how long a rate limited request waits for the limit to
+	// reset before failing, like 90s, 5m if empty
+	MaxWait string `yaml:"max_wait"`
+	// Links are the URL templates of links in generated release notes
+	Links Links `yaml:"links"`
+	// Token is used when the token environment variable isn't set, filled in
+	// from the git credential helpers
+	Token string `yaml:"-"`
+}
+
+// Repo identifies a repository on a forge
+type Repo struct {
+	Host  string
+	Owner string // For GitLab this is the full group path
+	Name  string
+}
+
+// Path returns owner/name
+func (r *Repo) Path() string {
+	return fmt.Sprintf("%s/%s", r.Owner, r.Name)
+}
+
+// PullRequest is a pull request (or GitLab merge request)
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	URL    string
+	Merged bool
+}
+
+// Forge is a code forge API
+type Forge interface {
+	// Name is the type of forge (github, gitlab)
+	Name() string
+	// PullRequestForCommit returns the merged pull request the commit belongs
+	// to, nil if there is none
+	PullRequestForCommit(sha string) (*PullRequest, error)
+}
+
+// Tag is a tag to create through a forge's API
+type Tag struct {
+	Name   string
+	Commit string // The sha of the commit to tag
+	// Message makes an annotated tag, lightweight if empty
+	Message     string
+	TaggerName  string
+	TaggerEmail string
+	TaggerDate  time.Time
+}
+
+// TagCreator is implemented by forges that can create tags through their API,
+// for when a push is rejected but a token may create the tag
+type TagCreator interface {
+	CreateTag(tag Tag) error
+}
+
+// Milestone is a milestone issues (and pull requests) are planned for
+type Milestone struct {
+	ID    int // The number on GitHub, the id on GitLab
+	Title string
+	URL   string
+	Due   time.Time // Zero if it has no due date
+}
+
+// Milestones is implemented by forges that have milestones
+type Milestones interface {
+	// OpenMilestones returns the milestones that aren't closed
+	OpenMilestones() ([]Milestone, error)
+	// OpenIssues returns the numbers of the open issues in the milestone
+	OpenIssues(m Milestone) ([]int, error)
+	// MoveIssue puts the issue in another milestone
+	MoveIssue(issue int, to Milestone) error
+	// CloseMilestone closes the milestone
+	CloseMilestone(m Milestone) error
+}
+
+// Release is the release page of a tag on a forge
+type Release struct {
+	Tag   string
+	Name  string
+	Body  string // Markdown
+	Draft bool   // Only visible to maintainers until it's published
+	URL   string
+}
+
+// Releases is implemented by forges that have release pages
+type Releases interface {
+	// CreateRelease creates the release page of an existing tag
+	CreateRelease(rel Release) (*Release, error)
+	// PublishRelease publishes the draft release of the tag
+	PublishRelease(tag string) (*Release, error)
+}
+
+var scpLike = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemoteURL parses ssh (git@host:owner/repo.git), ssh:// and https
+// remote URLs
+func ParseRemoteURL(remote string) (*Repo, error) {
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return nil, err
+		}
+		host, path = u.Hostname(), u.Path
+	} else if m := scpLike.FindStringSubmatch(remote); m != nil {
+		host, path = m[1], m[2]
+	} else {
+		return nil, fmt.Errorf("can't parse remote url %s", remote)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	idx := strings.LastIndex(path, "/")
+	if idx <= 0 {
+		return nil, fmt.Errorf("remote url %s has no owner/repo path", remote)
+	}
+	return &Repo{Host: host, Owner: path[:idx], Name: path[idx+1:]}, nil
+}
+
+// New creates a forge client for the repository
+func New(repo *Repo, cfg Config) (Forge, error) {
+	kind := cfg.Type
+	if kind == "" {
+		switch {
+		case strings.Contains(repo.Host, "github"):
+			kind = "github"
+		case strings.Contains(repo.Host, "gitlab"):
+			kind = "gitlab"
+		default:
+			return nil, fmt.Errorf("can't tell which forge %s is, set forge.type in the config", repo.Host)
+		}
+	}
+	switch kind {
+	case "github":
+		return newGitHub(repo, cfg)
+	case "gitlab":
+		return newGitLab(repo, cfg)
+	}
+	return nil, fmt.Errorf("unknown forge type '%s', must be github or gitlab", kind)
+}
+
+// token returns the token from the environment, the keyring (stored under
+// the host by 'release auth login', or under the variable's name) or the git
+// credential helpers, in that order
+func token(cfg Config, def, host string) string {
+	env := def
+	if cfg.TokenEnv != "" {
+		env = cfg.TokenEnv
+	}
+	if t := os.Getenv(env); t != "" {
+		return t
+	}
+	if t, err := keyring.Get(host); err == nil {
+		return t
+	}
+	if t, err := keyring.Get(env); err == nil {
+		return t
+	}
+	return cfg.Token
+}
+
+// TokenEnv returns the environment variable the token of the forge is read
+// from
+func TokenEnv(cfg Config, repo *Repo) string {
+	if cfg.TokenEnv != "" {
+		return cfg.TokenEnv
+	}
+	if cfg.Type == "gitlab" || (cfg.Type == "" && strings.Contains(repo.Host, "gitlab")) {
+		return "GITLAB_TOKEN"
+	}
+	return "GITHUB_TOKEN"
+}
+
+var headingPat = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Section returns the content under the markdown heading with the given title
+// (case insensitive, any level) up to the next heading of the same or a higher
+// level. The second value is false if there is no such heading.
+func Section(body, title string) (string, bool) {
+	lines := strings.Split(strings.Replace(body, "\r\n", "\n", -1), "\n")
+	level := 0
+	start := -1
+	for idx, line := range lines {
+		m := headingPat.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if start >= 0 && len(m[1]) <= level {
+			return strings.TrimSpace(strings.Join(lines[start:idx], "\n")), true
+		}
+		if start < 0 && strings.EqualFold(m[2], title) {
+			level = len(m[1])
+			start = idx + 1
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
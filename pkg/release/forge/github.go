@@ -0,0 +1,231 @@
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GitHubAPIURL is the public GitHub API
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHub is the GitHub (or GitHub Enterprise) REST API
+type GitHub struct {
+	repo   *Repo
+	client *client
+}
+
+func newGitHub(repo *Repo, cfg Config) (*GitHub, error) {
+	base := cfg.APIURL
+	if base == "" {
+		base = GitHubAPIURL
+	}
+	headers := map[string]string{"Accept": "application/vnd.github.v3+json"}
+	if t := token(cfg, "GITHUB_TOKEN", repo.Host); t != "" {
+		headers["Authorization"] = "token " + t
+	}
+	c, err := newClient(strings.TrimRight(base, "/"), headers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHub{repo: repo, client: c}, nil
+}
+
+// Name returns github
+func (g *GitHub) Name() string {
+	return "github"
+}
+
+type githubPull struct {
+	Number   int     `json:"number"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	HTMLURL  string  `json:"html_url"`
+	MergedAt *string `json:"merged_at"`
+}
+
+// PullRequestForCommit finds the merged pull request containing the commit
+func (g *GitHub) PullRequestForCommit(sha string) (*PullRequest, error) {
+	pulls := []githubPull{}
+	// This endpoint needed a preview media type for a long time, it's harmless
+	// to keep asking for it
+	g.client.headers["Accept"] = "application/vnd.github.groot-preview+json"
+	err := g.client.get(fmt.Sprintf("/repos/%s/commits/%s/pulls", g.repo.Path(), sha), &pulls)
+	g.client.headers["Accept"] = "application/vnd.github.v3+json"
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pulls {
+		if p.MergedAt != nil {
+			return &PullRequest{Number: p.Number, Title: p.Title, Body: p.Body, URL: p.HTMLURL, Merged: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+type githubTagger struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+}
+
+type githubTag struct {
+	Tag     string       `json:"tag"`
+	Message string       `json:"message"`
+	Object  string       `json:"object"`
+	Type    string       `json:"type"`
+	Tagger  githubTagger `json:"tagger"`
+}
+
+type githubRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+type githubObject struct {
+	SHA string `json:"sha"`
+}
+
+// CreateTag creates the tag through the git data API, annotated tags are a tag
+// object and a ref. It needs a token allowed to create the tag.
+func (g *GitHub) CreateTag(tag Tag) error {
+	if g.client.headers["Authorization"] == "" {
+		return errors.New("creating tags through the GitHub API needs a token, set GITHUB_TOKEN or forge.token_env")
+	}
+	sha := tag.Commit
+	if tag.Message != "" {
+		obj := githubObject{}
+		err := g.client.post(fmt.Sprintf("/repos/%s/git/tags", g.repo.Path()), githubTag{
+			Tag:     tag.Name,
+			Message: tag.Message,
+			Object:  tag.Commit,
+			Type:    "commit",
+			Tagger:  githubTagger{Name: tag.TaggerName, Email: tag.TaggerEmail, Date: tag.TaggerDate.Format(time.RFC3339)},
+		}, &obj)
+		if err != nil {
+			return err
+		}
+		sha = obj.SHA
+	}
+	return g.client.post(fmt.Sprintf("/repos/%s/git/refs", g.repo.Path()), githubRef{Ref: "refs/tags/" + tag.Name, SHA: sha}, nil)
+}
+
+type githubMilestone struct {
+	Number  int     `json:"number"`
+	Title   string  `json:"title"`
+	HTMLURL string  `json:"html_url"`
+	DueOn   *string `json:"due_on"`
+}
+
+type githubIssue struct {
+	Number int `json:"number"`
+}
+
+// OpenMilestones returns the open milestones
+func (g *GitHub) OpenMilestones() ([]Milestone, error) {
+	found := []githubMilestone{}
+	for next := fmt.Sprintf("/repos/%s/milestones?state=open&per_page=100", g.repo.Path()); next != ""; {
+		page := []githubMilestone{}
+		var err error
+		if next, err = g.client.getPage(next, &page); err != nil {
+			return nil, err
+		}
+		found = append(found, page...)
+	}
+	milestones := []Milestone{}
+	for _, m := range found {
+		milestone := Milestone{ID: m.Number, Title: m.Title, URL: m.HTMLURL}
+		if m.DueOn != nil {
+			milestone.Due, _ = time.Parse(time.RFC3339, *m.DueOn)
+		}
+		milestones = append(milestones, milestone)
+	}
+	return milestones, nil
+}
+
+// OpenIssues returns the open issues and pull requests of the milestone
+func (g *GitHub) OpenIssues(m Milestone) ([]int, error) {
+	found := []githubIssue{}
+	for next := fmt.Sprintf("/repos/%s/issues?milestone=%d&state=open&per_page=100", g.repo.Path(), m.ID); next != ""; {
+		page := []githubIssue{}
+		var err error
+		if next, err = g.client.getPage(next, &page); err != nil {
+			return nil, err
+		}
+		found = append(found, page...)
+	}
+	issues := []int{}
+	for _, issue := range found {
+		issues = append(issues, issue.Number)
+	}
+	return issues, nil
+}
+
+// MoveIssue sets the milestone of the issue
+func (g *GitHub) MoveIssue(issue int, to Milestone) error {
+	return g.client.send("PATCH", fmt.Sprintf("/repos/%s/issues/%d", g.repo.Path(), issue), map[string]int{"milestone": to.ID}, nil)
+}
+
+// CloseMilestone closes the milestone
+func (g *GitHub) CloseMilestone(m Milestone) error {
+	return g.client.send("PATCH", fmt.Sprintf("/repos/%s/milestones/%d", g.repo.Path(), m.ID), map[string]string{"state": "closed"}, nil)
+}
+
+type githubRelease struct {
+	ID      int    `json:"id,omitempty"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+func (r githubRelease) release() *Release {
+	return &Release{Tag: r.TagName, Name: r.Name, Body: r.Body, Draft: r.Draft, URL: r.HTMLURL}
+}
+
+// CreateRelease creates the release of the tag
+func (g *GitHub) CreateRelease(rel Release) (*Release, error) {
+	if g.client.headers["Authorization"] == "" {
+		return nil, errors.New("creating releases through the GitHub API needs a token, set GITHUB_TOKEN or forge.token_env")
+	}
+	created := githubRelease{}
+	err := g.client.post(fmt.Sprintf("/repos/%s/releases", g.repo.Path()), githubRelease{
+		TagName: rel.Tag,
+		Name:    rel.Name,
+		Body:    rel.Body,
+		Draft:   rel.Draft,
+	}, &created)
+	if err != nil {
+		return nil, err
+	}
+	return created.release(), nil
+}
+
+// PublishRelease publishes the draft release of the tag. Drafts aren't found
+// by tag, only by listing the releases.
+func (g *GitHub) PublishRelease(tag string) (*Release, error) {
+	for next := fmt.Sprintf("/repos/%s/releases?per_page=100", g.repo.Path()); next != ""; {
+		page := []githubRelease{}
+		var err error
+		if next, err = g.client.getPage(next, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			if r.TagName != tag {
+				continue
+			}
+			if !r.Draft {
+				return nil, fmt.Errorf("the release of %s is already published", tag)
+			}
+			published := githubRelease{}
+			err := g.client.send("PATCH", fmt.Sprintf("/repos/%s/releases/%d", g.repo.Path(), r.ID), map[string]bool{"draft": false}, &published)
+			if err != nil {
+				return nil, err
+			}
+			return published.release(), nil
+		}
+	}
+	return nil, fmt.Errorf("there's no draft release of %s", tag)
+}
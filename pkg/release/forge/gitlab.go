@@ -0,0 +1,197 @@
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLabAPIURL is the public GitLab API
+const GitLabAPIURL = "https://gitlab.com/api/v4"
+
+// GitLab is the GitLab REST API
+type GitLab struct {
+	repo   *Repo
+	client *client
+}
+
+func newGitLab(repo *Repo, cfg Config) (*GitLab, error) {
+	base := cfg.APIURL
+	if base == "" {
+		base = GitLabAPIURL
+		if repo.Host != "gitlab.com" {
+			base = fmt.Sprintf("https://%s/api/v4", repo.Host)
+		}
+	}
+	headers := map[string]string{"PRIVATE-TOKEN": token(cfg, "GITLAB_TOKEN", repo.Host)}
+	c, err := newClient(strings.TrimRight(base, "/"), headers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &GitLab{repo: repo, client: c}, nil
+}
+
+// Name returns gitlab
+func (g *GitLab) Name() string {
+	return "gitlab"
+}
+
+// project is the url encoded project path GitLab uses as an id
+func (g *GitLab) project() string {
+	return url.PathEscape(g.repo.Path())
+}
+
+type gitlabMergeRequest struct {
+	IID         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	WebURL      string `json:"web_url"`
+	State       string `json:"state"`
+}
+
+// PullRequestForCommit finds the merged merge request containing the commit
+func (g *GitLab) PullRequestForCommit(sha string) (*PullRequest, error) {
+	mrs := []gitlabMergeRequest{}
+	err := g.client.get(fmt.Sprintf("/projects/%s/repository/commits/%s/merge_requests", g.project(), sha), &mrs)
+	if err != nil {
+		return nil, err
+	}
+	for _, mr := range mrs {
+		if mr.State == "merged" {
+			return &PullRequest{Number: mr.IID, Title: mr.Title, Body: mr.Description, URL: mr.WebURL, Merged: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+type gitlabTag struct {
+	TagName string `json:"tag_name"`
+	Ref     string `json:"ref"`
+	Message string `json:"message,omitempty"`
+}
+
+// CreateTag creates the tag through the tags API. GitLab records the owner of
+// the token as the tagger, the tagger of the tag is ignored.
+func (g *GitLab) CreateTag(tag Tag) error {
+	if g.client.headers["PRIVATE-TOKEN"] == "" {
+		return errors.New("creating tags through the GitLab API needs a token, set GITLAB_TOKEN or forge.token_env")
+	}
+	return g.client.post(fmt.Sprintf("/projects/%s/repository/tags", g.project()), gitlabTag{TagName: tag.Name, Ref: tag.Commit, Message: tag.Message}, nil)
+}
+
+type gitlabMilestone struct {
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	WebURL  string  `json:"web_url"`
+	DueDate *string `json:"due_date"`
+}
+
+type gitlabIssue struct {
+	IID int `json:"iid"`
+}
+
+// OpenMilestones returns the active project milestones
+func (g *GitLab) OpenMilestones() ([]Milestone, error) {
+	found := []gitlabMilestone{}
+	for next := fmt.Sprintf("/projects/%s/milestones?state=active&per_page=100", g.project()); next != ""; {
+		page := []gitlabMilestone{}
+		var err error
+		if next, err = g.client.getPage(next, &page); err != nil {
+			return nil, err
+		}
+		found = append(found, page...)
+	}
+	milestones := []Milestone{}
+	for _, m := range found {
+		milestone := Milestone{ID: m.ID, Title: m.Title, URL: m.WebURL}
+		if m.DueDate != nil {
+			milestone.Due, _ = time.Parse("2006-01-02", *m.DueDate)
+		}
+		milestones = append(milestones, milestone)
+	}
+	return milestones, nil
+}
+
+// OpenIssues returns the open issues of the milestone
+func (g *GitLab) OpenIssues(m Milestone) ([]int, error) {
+	found := []gitlabIssue{}
+	for next := fmt.Sprintf("/projects/%s/issues?milestone=%s&state=opened&per_page=100", g.project(), url.QueryEscape(m.Title)); next != ""; {
+		page := []gitlabIssue{}
+		var err error
+		if next, err = g.client.getPage(next, &page); err != nil {
+			return nil, err
+		}
+		found = append(found, page...)
+	}
+	issues := []int{}
+	for _, issue := range found {
+		issues = append(issues, issue.IID)
+	}
+	return issues, nil
+}
+
+// MoveIssue sets the milestone of the issue
+func (g *GitLab) MoveIssue(issue int, to Milestone) error {
+	return g.client.send("PUT", fmt.Sprintf("/projects/%s/issues/%d", g.project(), issue), map[string]int{"milestone_id": to.ID}, nil)
+}
+
+// CloseMilestone closes the milestone
+func (g *GitLab) CloseMilestone(m Milestone) error {
+	return g.client.send("PUT", fmt.Sprintf("/projects/%s/milestones/%d", g.project(), m.ID), map[string]string{"state_event": "close"}, nil)
+}
+
+// gitlabDraftDate is when GitLab releases created as drafts are due. GitLab
+// has no drafts, a release due in the future is shown as an upcoming release
+// until it's published.
+var gitlabDraftDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type gitlabRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ReleasedAt  string `json:"released_at,omitempty"`
+	Upcoming    bool   `json:"upcoming_release,omitempty"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links,omitempty"`
+}
+
+func (r gitlabRelease) release() *Release {
+	return &Release{Tag: r.TagName, Name: r.Name, Body: r.Description, Draft: r.Upcoming, URL: r.Links.Self}
+}
+
+// CreateRelease creates the release of the tag, drafts as upcoming releases
+func (g *GitLab) CreateRelease(rel Release) (*Release, error) {
+	if g.client.headers["PRIVATE-TOKEN"] == "" {
+		return nil, errors.New("creating releases through the GitLab API needs a token, set GITLAB_TOKEN or forge.token_env")
+	}
+	in := gitlabRelease{TagName: rel.Tag, Name: rel.Name, Description: rel.Body}
+	if rel.Draft {
+		in.ReleasedAt = gitlabDraftDate.Format(time.RFC3339)
+	}
+	created := gitlabRelease{}
+	if err := g.client.post(fmt.Sprintf("/projects/%s/releases", g.project()), in, &created); err != nil {
+		return nil, err
+	}
+	return created.release(), nil
+}
+
+// PublishRelease makes the upcoming release of the tag released now
+func (g *GitLab) PublishRelease(tag string) (*Release, error) {
+	path := fmt.Sprintf("/projects/%s/releases/%s", g.project(), url.PathEscape(tag))
+	existing := gitlabRelease{}
+	if err := g.client.get(path, &existing); err != nil {
+		return nil, err
+	}
+	if !existing.Upcoming {
+		return nil, fmt.Errorf("the release of %s is already published", tag)
+	}
+	published := gitlabRelease{}
+	err := g.client.send("PUT", path, map[string]string{"released_at": time.Now().UTC().Format(time.RFC3339)}, &published)
+	if err != nil {
+		return nil, err
+	}
+	return published.release(), nil
+}
@@ -0,0 +1,61 @@
+package forge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Links are the URL templates of the links in generated release notes. {repo}
+// is the web URL of the repository, {hash} and {short} the full and
+// abbreviated commit hash, {issue} the issue number and {from} and {to} the
+// tags being compared. Empty templates get the default of the forge, for
+// self-hosted forges with a different URL layout set them all.
+type Links struct {
+	Commit  string `yaml:"commit"`
+	Issue   string `yaml:"issue"`
+	Compare string `yaml:"compare"`
+}
+
+var defaultLinks = map[string]Links{
+	"github": {
+		Commit:  "{repo}/commit/{hash}",
+		Issue:   "{repo}/issues/{issue}",
+		Compare: "{repo}/compare/{from}...{to}",
+	},
+	"gitlab": {
+		Commit:  "{repo}/-/commit/{hash}",
+		Issue:   "{repo}/-/issues/{issue}",
+		Compare: "{repo}/-/compare/{from}...{to}",
+	},
+}
+
+// LinksFor returns the link templates of the repository with {repo} filled in,
+// the configured ones falling back to the defaults of the forge. Templates of
+// a forge that isn't known stay empty unless configured.
+func LinksFor(repo *Repo, cfg Config) Links {
+	kind := cfg.Type
+	if kind == "" {
+		switch {
+		case strings.Contains(repo.Host, "github"):
+			kind = "github"
+		case strings.Contains(repo.Host, "gitlab"):
+			kind = "gitlab"
+		}
+	}
+	links := cfg.Links
+	def := defaultLinks[kind]
+	if links.Commit == "" {
+		links.Commit = def.Commit
+	}
+	if links.Issue == "" {
+		links.Issue = def.Issue
+	}
+	if links.Compare == "" {
+		links.Compare = def.Compare
+	}
+	web := strings.NewReplacer("{repo}", fmt.Sprintf("https://%s/%s", repo.Host, repo.Path()))
+	links.Commit = web.Replace(links.Commit)
+	links.Issue = web.Replace(links.Issue)
+	links.Compare = web.Replace(links.Compare)
+	return links
+}
@@ -0,0 +1,116 @@
+package release
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fernferret/release/internal/cron"
+)
+
+// FreezeWindow is a period of time during which releases are refused unless
+// explicitly overridden. A window is either a date range (Start/End) or a cron
+// expression that matches every minute the freeze is active (e.g.
+// "* * * * SAT,SUN" for weekends).
+type FreezeWindow struct {
+	Name       string   `yaml:"name"`
+	Start      string   `yaml:"start"` // 2020-12-20 or RFC3339
+	End        string   `yaml:"end"`   // Inclusive, a date means until the end of that day
+	Cron       string   `yaml:"cron"`
+	Components []string `yaml:"components"` // Empty means every component
+	Reason     string   `yaml:"reason"`     // Shown to whoever tries to release
+
+	start, end time.Time
+	schedule   *cron.Schedule
+}
+
+// parseFreezeTime accepts a plain date or a full RFC3339 timestamp, dates are
+// in local time. If end is set a plain date is moved to the end of the day.
+func parseFreezeTime(value string, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if end {
+			return t.AddDate(0, 0, 1), nil
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (f *FreezeWindow) validate() error {
+	if f.Cron != "" {
+		if f.Start != "" || f.End != "" {
+			return fmt.Errorf("freeze window '%s' must use either cron or start/end, not both", f.Name)
+		}
+		schedule, err := cron.Parse(f.Cron)
+		if err != nil {
+			return fmt.Errorf("freeze window '%s': %w", f.Name, err)
+		}
+		f.schedule = schedule
+		return nil
+	}
+	if f.Start == "" || f.End == "" {
+		return fmt.Errorf("freeze window '%s' needs a cron or both start and end", f.Name)
+	}
+	var err error
+	if f.start, err = parseFreezeTime(f.Start, false); err != nil {
+		return fmt.Errorf("freeze window '%s' has an invalid start: %w", f.Name, err)
+	}
+	if f.end, err = parseFreezeTime(f.End, true); err != nil {
+		return fmt.Errorf("freeze window '%s' has an invalid end: %w", f.Name, err)
+	}
+	if !f.end.After(f.start) {
+		return fmt.Errorf("freeze window '%s' ends before it starts", f.Name)
+	}
+	return nil
+}
+
+// covers returns true if the window applies to the component, pre-releases
+// (api-rc.1) are covered by the windows of their component
+func (f *FreezeWindow) covers(component string) bool {
+	component, _ = splitPreRelease(component)
+	return len(f.Components) == 0 || contains(f.Components, component)
+}
+
+// Active returns true if the window covers the given component at time t
+func (f *FreezeWindow) Active(component string, t time.Time) bool {
+	if !f.covers(component) {
+		return false
+	}
+	if f.schedule != nil {
+		return f.schedule.Matches(t)
+	}
+	return !t.Before(f.start) && t.Before(f.end)
+}
+
+// String describes the window for error messages
+func (f *FreezeWindow) String() string {
+	desc := f.Name
+	if f.schedule != nil {
+		desc = fmt.Sprintf("%s (%s)", desc, f.Cron)
+	} else {
+		desc = fmt.Sprintf("%s (%s to %s)", desc, f.Start, f.End)
+	}
+	if f.Reason != "" {
+		desc = fmt.Sprintf("%s: %s", desc, f.Reason)
+	}
+	return desc
+}
+
+// ActiveFreeze returns the first freeze window covering the component at time
+// t, or nil if releasing is allowed
+func (c *Config) ActiveFreeze(component string, t time.Time) *FreezeWindow {
+	for idx := range c.Freeze {
+		if c.Freeze[idx].Active(component, t) {
+			return &c.Freeze[idx]
+		}
+	}
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// UseTarget makes the manager release the given commit instead of HEAD: new
+// tags, proposals, release notes and the files read for a release (package
+// manifests, go.mod) come from it. Hooks and builds need it checked out, see
+// Worktree.
+func (r *Manager) UseTarget(hash string) {
+	r.target = hash
+}
+
+// releaseHead resolves the commit releases are made from, HEAD unless
+// UseTarget was called
+func (r *Manager) releaseHead() (string, error) {
+	if r.target != "" {
+		return r.target, nil
+	}
+	return r.gitBackend().ResolveRevision("HEAD")
+}
+
+// BranchTip fetches branch from the remote and returns the commit it's at
+// there. Offline, or if the fetch fails, the local branch is used instead
+// when there is one.
+func (r *Manager) BranchTip(remote, branch string, auth transport.AuthMethod) (string, error) {
+	var fetchErr error
+	if !Offline() {
+		hash, err := r.FetchBranch(remote, branch, auth)
+		if err == nil {
+			return hash, nil
+		}
+		fetchErr = err
+	}
+	hash, err := r.gitBackend().Reference(plumbing.NewBranchReferenceName(branch))
+	if err != nil {
+		if fetchErr != nil {
+			return "", fetchErr
+		}
+		return "", fmt.Errorf("there's no local branch %s to release offline: %w", branch, err)
+	}
+	if fetchErr != nil {
+		log.Warn().Err(fetchErr).Msgf("failed to fetch %s, releasing the local branch", branch)
+	}
+	return hash, nil
+}
+
+// Worktree checks the release target out into a temporary git worktree and
+// returns its directory, hooks, scanners and builds run there so the
+// working directory can stay on any branch. Close removes it.
+func (r *Manager) Worktree() (string, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return "", err
+	}
+	return r.WorktreeAt(head)
+}
+
+// WorktreeAt is Worktree for any commit (a full hash), each commit is only
+// checked out once
+func (r *Manager) WorktreeAt(head string) (string, error) {
+	if dir, ok := r.worktrees[head]; ok {
+		return dir, nil
+	}
+	if r.repoDir == "" {
+		return "", fmt.Errorf("a worktree needs a repository on disk")
+	}
+	dir, err := ioutil.TempDir("", "release-worktree-")
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "worktree", "add", "--detach", dir, head)
+	cmd.Dir = r.repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("git worktree add failed: %s", strings.TrimSpace(string(output)))
+	}
+	if r.worktrees == nil {
+		r.worktrees = map[string]string{}
+	}
+	r.worktrees[head] = dir
+	return dir, nil
+}
+
+// RemoveWorktrees removes the worktrees made by WorktreeAt, Close does too
+func (r *Manager) RemoveWorktrees() error {
+	var failed error
+	for head, dir := range r.worktrees {
+		delete(r.worktrees, head)
+		cmd := exec.Command("git", "worktree", "remove", "--force", dir)
+		cmd.Dir = r.repoDir
+		if output, err := cmd.CombinedOutput(); err != nil && failed == nil {
+			failed = fmt.Errorf("failed to remove the worktree in %s: %s", dir, strings.TrimSpace(string(output)))
+		}
+	}
+	return failed
+}
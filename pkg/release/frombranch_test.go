@@ -0,0 +1,45 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestUseTarget(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := repo.Commit("on the release branch", map[string]string{"go.mod": "module example.com/branch\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("on main", map[string]string{"go.mod": "module example.com/main\n"}); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.UseTarget(branch.String())
+
+	if path, err := rm.GoModulePath(""); err != nil || path != "example.com/branch" {
+		t.Errorf("go.mod should be read from the target, got %s %v", path, err)
+	}
+	proposal, err := rm.GetProposedReleaseAt("api", time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC), ResetMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.TargetCommit != branch.String() {
+		t.Errorf("got target %s, want %s", proposal.TargetCommit, branch)
+	}
+	if _, err := rm.CreateTagOfKind(proposal.TagName, "", "Jane Doe", "jane@example.com", TagLightweight); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+	if rel := rm.FindRelease(proposal.TagName); rel == nil || rel.Hash != branch.String() {
+		t.Errorf("the release should tag the target, got %+v", rel)
+	}
+}
@@ -0,0 +1,116 @@
+package release
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxTagDepth is how many annotated tags of annotated tags are followed
+// before giving up on finding the commit
+const maxTagDepth = 10
+
+// TagProblem is a release tag that can't be loaded, it's skipped
+type TagProblem struct {
+	Tag     string `json:"tag"`
+	Target  string `json:"target"` // What the ref points to
+	Problem string `json:"problem"`
+}
+
+// TagProblems returns the release tags skipped by the last load because
+// their objects are broken, see CheckTags for a full check
+func (r *Manager) TagProblems() []TagProblem {
+	return append([]TagProblem{}, r.tagProblems...)
+}
+
+// CheckTags checks every tag without the index: tags that look like releases
+// but don't parse as one and release tags whose objects are missing, corrupt
+// or not commits. Problems are sorted by tag.
+func (r *Manager) CheckTags() ([]TagProblem, error) {
+	tagrefs, err := r.gitBackend().Refs("refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+	problems := []TagProblem{}
+	for _, t := range tagrefs {
+		name := t.Name.Short()
+		if _, ok := parseCalVer(r.tagTemplate, name); !ok {
+			if looksLikeCalVer(name) {
+				problems = append(problems, TagProblem{Tag: name, Target: t.Hash, Problem: "looks like a release but isn't a valid version"})
+			}
+			continue
+		}
+		if _, _, err := r.resolveTag(t.Hash); err != nil {
+			problems = append(problems, TagProblem{Tag: name, Target: t.Hash, Problem: err.Error()})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Tag < problems[j].Tag })
+	return problems, nil
+}
+
+// resolveTag finds the commit a tag ref points to, and the outermost tag
+// object for annotated tags (nil for lightweight ones). Annotated tags of
+// annotated tags are followed, trees, blobs and missing objects are errors.
+// The tag's Target is only right in SHA-1 repositories, go-git truncates
+// longer hashes.
+func (r *Manager) resolveTag(hash string) (*object.Tag, *Commit, error) {
+	var outer *object.Tag
+	for depth := 0; depth <= maxTagDepth; depth++ {
+		obj, err := r.gitBackend().Object(hash)
+		if err == plumbing.ErrObjectNotFound {
+			if depth == 0 {
+				return nil, nil, fmt.Errorf("points to %s which doesn't exist", hash)
+			}
+			return nil, nil, fmt.Errorf("tags %s which doesn't exist", hash)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't read %s: %w", hash, err)
+		}
+		switch obj.Type() {
+		case plumbing.CommitObject:
+			commit, err := object.DecodeCommit(r.repo.Storer, obj)
+			if err != nil {
+				return nil, nil, fmt.Errorf("commit %s is corrupt: %w", hash, err)
+			}
+			return outer, &Commit{Hash: hash, Author: commit.Author, Committer: commit.Committer, Message: commit.Message}, nil
+		case plumbing.TagObject:
+			tag, err := object.DecodeTag(r.repo.Storer, obj)
+			if err != nil {
+				return nil, nil, fmt.Errorf("tag object %s is corrupt: %w", hash, err)
+			}
+			if outer == nil {
+				outer = tag
+			}
+			target, err := tagTarget(obj)
+			if err != nil {
+				return nil, nil, fmt.Errorf("tag object %s is corrupt: %w", hash, err)
+			}
+			hash = target
+		default:
+			if depth == 0 {
+				return nil, nil, fmt.Errorf("points to a %s, not a commit", obj.Type())
+			}
+			return nil, nil, fmt.Errorf("tags a %s, not a commit", obj.Type())
+		}
+	}
+	return nil, nil, fmt.Errorf("more than %d annotated tags deep", maxTagDepth)
+}
+
+// tagTarget reads the full hash of the object a tag object tags from its
+// first header, "object <hash>"
+func tagTarget(obj plumbing.EncodedObject) (string, error) {
+	rd, err := obj.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer rd.Close()
+	header, err := bufio.NewReader(rd).ReadString('\n')
+	if err != nil || !strings.HasPrefix(header, "object ") {
+		return "", fmt.Errorf("no object header")
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "object ")), nil
+}
@@ -0,0 +1,126 @@
+package release
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// readNotes returns the notes in a git notes ref (refs/notes/...) by the
+// object they annotate, empty if the ref doesn't exist. Fanned out trees
+// (ab/cdef...) written by git itself are read too.
+func (r *Manager) readNotes(ref plumbing.ReferenceName) (map[string]string, error) {
+	if h := r.history(); h != nil {
+		return h.notes(ref)
+	}
+	notes := map[string]string{}
+	commit, err := r.notesCommit(ref)
+	if err != nil || commit == nil {
+		return notes, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		name := strings.Replace(f.Name, "/", "", -1)
+		if len(name) != 40 {
+			return nil
+		}
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		notes[name] = string(data)
+		return nil
+	})
+	return notes, err
+}
+
+// notesCommit returns the commit a notes ref points to, nil if it doesn't
+// exist
+func (r *Manager) notesCommit(ref plumbing.ReferenceName) (*object.Commit, error) {
+	current, err := r.repo.Reference(ref, true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.repo.CommitObject(current.Hash())
+}
+
+// appendNote adds content to the note of the target object, like 'git notes
+// append' without the blank line, and commits the new notes tree to the ref
+func (r *Manager) appendNote(ref plumbing.ReferenceName, target, content string, sig object.Signature, message string) error {
+	if h := r.history(); h != nil {
+		return h.appendNote(ref, target, content, sig, message)
+	}
+	notes, err := r.readNotes(ref)
+	if err != nil {
+		return err
+	}
+	parent, err := r.notesCommit(ref)
+	if err != nil {
+		return err
+	}
+	notes[target] += content
+
+	// A flat tree, git reads it just like a fanned out one
+	entries := []object.TreeEntry{}
+	for hash, note := range notes {
+		blob, err := r.storeBlob([]byte(note))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, object.TreeEntry{Name: hash, Mode: filemode.Regular, Hash: blob})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	treeObj := r.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(treeObj); err != nil {
+		return err
+	}
+	treeHash, err := r.repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		return err
+	}
+
+	commit := &object.Commit{Author: sig, Committer: sig, Message: message, TreeHash: treeHash}
+	if parent != nil {
+		commit.ParentHashes = []plumbing.Hash{parent.Hash}
+	}
+	commitObj := r.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObj); err != nil {
+		return err
+	}
+	commitHash, err := r.repo.Storer.SetEncodedObject(commitObj)
+	if err != nil {
+		return err
+	}
+	return r.repo.Storer.SetReference(plumbing.NewHashReference(ref, commitHash))
+}
+
+func (r *Manager) storeBlob(data []byte) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
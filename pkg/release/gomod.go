@@ -0,0 +1,130 @@
+package release
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoProxyURL is where freshly pushed module versions are requested so the
+// proxy and pkg.go.dev pick them up
+const GoProxyURL = "https://proxy.golang.org"
+
+// GoConfig configures the Go module tags created next to the CalVer tags
+type GoConfig struct {
+	// Modules maps components to the Go module they release
+	Modules map[string]GoModule `yaml:"modules"`
+	// ProxyWarmup requests each new version from the Go module proxy after
+	// pushing
+	ProxyWarmup bool `yaml:"proxy_warmup"`
+}
+
+// GoModule is a Go module released as a component. Go tooling only understands
+// semver tags, prefixed with the module directory when the module isn't at the
+// root of the repository, so 2020.07.003-lib in pkg/lib is also tagged as
+// pkg/lib/v0.202007.3.
+type GoModule struct {
+	Dir string `yaml:"dir"`
+	// Major is the major version to tag, it has to match the module path
+	// (/v2 and up). Defaults to the module path's major version, 0 without one.
+	Major *int `yaml:"major"`
+}
+
+var majorSuffix = regexp.MustCompile(`/v(\d+)$`)
+
+// pathMajor returns the major version implied by a module path, 0 when there
+// is no /vN suffix
+func pathMajor(modulePath string) int {
+	if m := majorSuffix.FindStringSubmatch(modulePath); m != nil {
+		major, _ := strconv.Atoi(m[1])
+		return major
+	}
+	return 0
+}
+
+// GoModulePath reads the module path from dir/go.mod at HEAD
+func (r *Manager) GoModulePath(dir string) (string, error) {
+	file := path.Join(strings.Trim(dir, "/"), "go.mod")
+	contents, err := r.headFile(file)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s doesn't exist at HEAD", file)
+	} else if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no module directive", file)
+}
+
+// GoTag returns the Go module tag for a CalVer tag along with the module path,
+// checking that the configured major version matches the module path
+func (r *Manager) GoTag(tag string, mod GoModule) (string, string, error) {
+	version, ok := parseCalVer(r.tagTemplate, tag)
+	if !ok {
+		return "", "", fmt.Errorf("%s isn't a CalVer tag", tag)
+	}
+	modulePath, err := r.GoModulePath(mod.Dir)
+	if err != nil {
+		return "", "", err
+	}
+	major := pathMajor(modulePath)
+	if mod.Major != nil {
+		// v0 and v1 both live at the bare module path
+		if *mod.Major != major && !(*mod.Major <= 1 && major == 0) {
+			return "", "", fmt.Errorf("major version %d doesn't match module path %s", *mod.Major, modulePath)
+		}
+		major = *mod.Major
+	}
+	semver := fmt.Sprintf("v%d.%d%02d.%d", major, version.Year, version.Month, version.Release)
+	if version.IsOrdinal() {
+		semver = fmt.Sprintf("v%d.%d%03d.%d", major, version.Year, version.Day, version.Release)
+	}
+	if pre := r.ParseRelease(tag).PreRelease(); pre != "" {
+		semver += "-" + pre
+	}
+	dir := strings.Trim(path.Clean("/"+mod.Dir), "/")
+	if dir == "" {
+		return semver, modulePath, nil
+	}
+	return dir + "/" + semver, modulePath, nil
+}
+
+// escapeModulePath escapes upper case letters the way the module proxy
+// protocol requires (!lower)
+func escapeModulePath(p string) string {
+	b := &strings.Builder{}
+	for _, c := range p {
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('!')
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// WarmGoProxy asks the Go module proxy for the version so it's fetched (and
+// shows up on pkg.go.dev) before anyone needs it
+func WarmGoProxy(modulePath, goTag string) error {
+	version := goTag[strings.LastIndex(goTag, "/")+1:]
+	url := fmt.Sprintf("%s/%s/@v/%s.info", GoProxyURL, escapeModulePath(modulePath), version)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
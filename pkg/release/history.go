@@ -0,0 +1,224 @@
+package release
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Commit is a commit read from the history. Hash is the full hex name, of
+// any length.
+type Commit struct {
+	Hash      string
+	Author    object.Signature
+	Committer object.Signature
+	Message   string
+}
+
+// IsHash reports whether s is a full SHA-1 or SHA-256 object name
+func IsHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func newCommit(c *object.Commit) *Commit {
+	return &Commit{Hash: c.Hash.String(), Author: c.Author, Committer: c.Committer, Message: c.Message}
+}
+
+// historyBackend is implemented by backends that read the history, trees and
+// notes themselves. Without one they're read with go-git, which only reads
+// SHA-1 repositories.
+type historyBackend interface {
+	// commits returns what git log from..to does, newest first and at most
+	// limit. An empty from walks back from to.
+	commits(from, to string, limit int) ([]*Commit, error)
+	// changedFiles returns the files each commit changed compared to its
+	// first parent, by commit
+	changedFiles(commits []string) (map[string][]string, error)
+	// diffstat compares the trees of two commits, see Manager.diffstat
+	diffstat(from, to string) ([]PathChange, error)
+	// isAncestor reports whether ancestor is reachable from commit
+	isAncestor(ancestor, commit string) (bool, error)
+	// file reads a file at commit, os.ErrNotExist if it's missing
+	file(commit, name string) ([]byte, error)
+	// files returns the files under dir at commit whose name ends with
+	// suffix, named relative to dir. Empty if dir doesn't exist.
+	files(commit, dir, suffix string) (map[string][]byte, error)
+	// dirs returns every directory at commit
+	dirs(commit string) ([]string, error)
+	// notes returns the notes in a notes ref by the object they annotate
+	notes(ref plumbing.ReferenceName) (map[string]string, error)
+	// appendNote adds content to the note of target, see Manager.appendNote
+	appendNote(ref plumbing.ReferenceName, target, content string, sig object.Signature, message string) error
+	// commitFiles commits paths as they are in the working directory
+	commitFiles(paths []string, sig object.Signature, message string) error
+	// packObjects returns a packfile with the objects reachable from roots
+	// but not from basis
+	packObjects(roots, basis []string) ([]byte, error)
+	// unpackObjects stores the objects of a packfile
+	unpackObjects(pack []byte) error
+}
+
+// history returns the backend's history reader, nil when go-git reads it
+func (r *Manager) history() historyBackend {
+	h, _ := r.gitBackend().(historyBackend)
+	return h
+}
+
+// commitsBetween returns the commits reachable from 'to' but not from 'from'
+// (git log from..to), capped at maxChangelogCommits, with the identities the
+// .mailmap prefers. An empty 'from' just walks back from 'to'.
+func (r *Manager) commitsBetween(from, to string) ([]*Commit, error) {
+	commits, err := r.walkCommits(from, to)
+	r.mailmap.mapCommits(commits)
+	return commits, err
+}
+
+func (r *Manager) walkCommits(from, to string) ([]*Commit, error) {
+	if h := r.history(); h != nil {
+		return h.commits(from, to, maxChangelogCommits)
+	}
+	seen := map[plumbing.Hash]bool{}
+	if from != "" {
+		fromCommit, err := r.repo.CommitObject(plumbing.NewHash(from))
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(fromCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	toCommit, err := r.repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, err
+	}
+	commits := []*Commit{}
+	err = object.NewCommitPreorderIter(toCommit, seen, nil).ForEach(func(c *object.Commit) error {
+		if len(commits) >= maxChangelogCommits {
+			return storer.ErrStop
+		}
+		commits = append(commits, newCommit(c))
+		return nil
+	})
+	return commits, err
+}
+
+// tagCommit resolves a tag (lightweight or annotated) to the commit it points
+// to
+func (r *Manager) tagCommit(tag string) (*Commit, error) {
+	hash, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(tag))
+	if err != nil {
+		return nil, err
+	}
+	_, commit, err := r.resolveTag(hash)
+	return commit, err
+}
+
+// isAncestor reports whether ancestor is reachable from commit
+func (r *Manager) isAncestor(ancestor, commit string) (bool, error) {
+	if h := r.history(); h != nil {
+		return h.isAncestor(ancestor, commit)
+	}
+	a, err := r.repo.CommitObject(plumbing.NewHash(ancestor))
+	if err != nil {
+		return false, err
+	}
+	c, err := r.repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return false, err
+	}
+	return a.IsAncestor(c)
+}
+
+// headFile reads a file from the HEAD commit (or the target of UseTarget),
+// os.ErrNotExist if it's missing
+func (r *Manager) headFile(name string) ([]byte, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return nil, err
+	}
+	if h := r.history(); h != nil {
+		return h.file(head, name)
+	}
+	commit, err := r.repo.CommitObject(plumbing.NewHash(head))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ensureObjects(commit.TreeHash); err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if entry, err := tree.FindEntry(name); err == nil {
+		// Partial clones may not have the blob yet
+		if err := r.ensureObjects(entry.Hash); err != nil {
+			return nil, err
+		}
+	}
+	f, err := tree.File(name)
+	if err == object.ErrFileNotFound {
+		return nil, os.ErrNotExist
+	} else if err != nil {
+		return nil, err
+	}
+	contents, err := f.Contents()
+	return []byte(contents), err
+}
+
+// headDirs returns every directory of the HEAD commit, none in a repository
+// without commits
+func (r *Manager) headDirs() (map[string]bool, error) {
+	dirs := map[string]bool{}
+	if _, err := r.gitBackend().Reference(plumbing.HEAD); err == plumbing.ErrReferenceNotFound {
+		return dirs, nil
+	}
+	head, err := r.gitBackend().ResolveRevision("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if h := r.history(); h != nil {
+		names, err := h.dirs(head)
+		for _, name := range names {
+			dirs[name] = true
+		}
+		return dirs, err
+	}
+	commit, err := r.repo.CommitObject(plumbing.NewHash(head))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ensureObjects(commit.TreeHash); err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			return dirs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if entry.Mode == filemode.Dir {
+			dirs[name] = true
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package release
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+// TestExecHistory checks that the exec backend reads the history like go-git
+func TestExecHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	repo, err := releasetest.NewTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Cleanup()
+	first, err := repo.Commit("initial commit", map[string]string{"README": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, files := range []map[string]string{
+		{"api/main.go": "package main"},
+		{"api/main.go": "package main\n", "web/index.html": "<p>"},
+	} {
+		repo.Advance(time.Hour)
+		if _, err := repo.Commit("change", files); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goGit, err := NewManager(repo.Dir, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	execGit, err := NewManager(repo.Dir, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := execGit.UseBackend(GitConfig{Backend: "exec"}); err != nil {
+		t.Fatal(err)
+	}
+	if execGit.history() == nil {
+		t.Fatal("the exec backend doesn't read the history")
+	}
+	head, err := goGit.gitBackend().ResolveRevision("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := goGit.commitsBetween(first.String(), head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := execGit.commitsBetween(first.String(), head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(want) != 2 {
+		t.Fatalf("got %d and %d commits, want 2", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Hash != want[i].Hash || got[i].Message != want[i].Message || !got[i].Author.When.Equal(want[i].Author.When) {
+			t.Errorf("commit %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	hashes := []string{got[0].Hash, got[1].Hash}
+	wantFiles, err := goGit.changedFiles(hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotFiles, err := execGit.changedFiles(hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotFiles, wantFiles) {
+		t.Errorf("got changed files %v, want %v", gotFiles, wantFiles)
+	}
+
+	if ok, err := execGit.isAncestor(first.String(), head); err != nil || !ok {
+		t.Errorf("got %v, %v, want the first commit to be an ancestor of HEAD", ok, err)
+	}
+	if ok, err := execGit.isAncestor(head, first.String()); err != nil || ok {
+		t.Errorf("got %v, %v, want HEAD not to be an ancestor of the first commit", ok, err)
+	}
+	if data, err := execGit.headFile("api/main.go"); err != nil || string(data) != "package main\n" {
+		t.Errorf("got %q, %v, want the file at HEAD", data, err)
+	}
+	if _, err := execGit.headFile("missing"); !os.IsNotExist(err) {
+		t.Errorf("got %v, want a not exist error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// HooksConfig are shell commands run around each release
+type HooksConfig struct {
+	// PreRelease runs before the tag is created, a failing command skips the
+	// release
+	PreRelease []string `yaml:"pre_release"`
+	// PostRelease runs after the tag is created (and pushed, when pushing)
+	PostRelease []string `yaml:"post_release"`
+	// Scanners run after the pre-release hooks, see ScannerHook
+	Scanners []ScannerHook `yaml:"scanners"`
+}
+
+func (c HooksConfig) validate() error {
+	for _, s := range c.Scanners {
+		if err := s.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hookEnv is the environment of hooks, see RunHooks
+func hookEnv(rel *Release) []string {
+	return append(os.Environ(),
+		"RELEASE_TAG="+rel.Tag,
+		"RELEASE_COMPONENT="+rel.Component(),
+		"RELEASE_VERSION="+rel.Version(),
+	)
+}
+
+// RunHooks runs the commands with sh in dir, stopping at the first failure.
+// The tag, its component and version are passed as RELEASE_TAG,
+// RELEASE_COMPONENT and RELEASE_VERSION, see Manager.ParseRelease for
+// releases that weren't loaded.
+func RunHooks(commands []string, dir string, rel *Release) error {
+	env := hookEnv(rel)
+	for _, command := range commands {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook '%s' failed: %w", command, err)
+		}
+	}
+	return nil
+}
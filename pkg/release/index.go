@@ -0,0 +1,210 @@
+package release
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// IndexFile is the SQLite index of releases, in the .git directory. Once
+// 'release index' created it every command reads tags through it and only
+// loads the objects of tags that are new or moved.
+const IndexFile = "release-index.db"
+
+// indexVersion is bumped whenever the schema changes, older indexes are
+// rebuilt
+const indexVersion = 1
+
+const indexSchema = `
+CREATE TABLE IF NOT EXISTS releases (
+	ref TEXT PRIMARY KEY,
+	target TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	release_message TEXT NOT NULL,
+	commit_message TEXT NOT NULL,
+	author_name TEXT NOT NULL,
+	author_email TEXT NOT NULL,
+	author_when TEXT NOT NULL,
+	committer_name TEXT NOT NULL,
+	committer_email TEXT NOT NULL,
+	committer_when TEXT NOT NULL,
+	tagger_name TEXT,
+	tagger_email TEXT,
+	tagger_when TEXT
+);
+`
+
+// indexedRelease is a release along with the ref it was loaded from
+type indexedRelease struct {
+	ref    string
+	target string // What the ref pointed to, the tag object for annotated tags
+	rel    Release
+}
+
+type releaseIndex struct {
+	db *sql.DB
+}
+
+// IndexPath returns where the index of releases is (or would be), empty for
+// repositories that aren't on disk
+func (r *Manager) IndexPath() string {
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return filepath.Join(storage.Filesystem().Root(), IndexFile)
+}
+
+// openIndex opens the index of releases, nil if there is none and create
+// isn't set
+func (r *Manager) openIndex(create bool) (*releaseIndex, error) {
+	path := r.IndexPath()
+	if path == "" {
+		if create {
+			return nil, fmt.Errorf("only repositories on disk can be indexed")
+		}
+		return nil, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) && !create {
+		return nil, nil
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if version != indexVersion {
+		_, err = db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS releases; %s PRAGMA user_version = %d;`, indexSchema, indexVersion))
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set up %s: %w", path, err)
+		}
+	}
+	return &releaseIndex{db: db}, nil
+}
+
+// BuildIndex creates the index of releases, or brings it up to date, and
+// returns how many releases are in it
+func (r *Manager) BuildIndex() (int, error) {
+	if _, ok := r.gitBackend().(releaseLoader); ok {
+		return 0, fmt.Errorf("the %s backend reads every tag at once, it doesn't use the index", r.gitBackend())
+	}
+	index, err := r.openIndex(true)
+	if err != nil {
+		return 0, err
+	}
+	index.db.Close()
+	r.loadGitTags()
+	return len(r.releases), nil
+}
+
+// DropIndex removes the index of releases, tags are read from git again
+func (r *Manager) DropIndex() error {
+	path := r.IndexPath()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// load returns the indexed releases by ref, their tags read with the
+// tag_template t
+func (i *releaseIndex) load(t *TagTemplate) (map[string]indexedRelease, error) {
+	rows, err := i.db.Query(`SELECT ref, target, tag, hash, release_message, commit_message,
+		author_name, author_email, author_when, committer_name, committer_email, committer_when,
+		tagger_name, tagger_email, tagger_when FROM releases`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexed := map[string]indexedRelease{}
+	for rows.Next() {
+		var ir indexedRelease
+		var target, authorWhen, committerWhen string
+		var taggerName, taggerEmail, taggerWhen sql.NullString
+		err := rows.Scan(&ir.ref, &target, &ir.rel.Tag, &ir.rel.Hash, &ir.rel.ReleaseMessage, &ir.rel.CommitMessage,
+			&ir.rel.Author.Name, &ir.rel.Author.Email, &authorWhen,
+			&ir.rel.Committer.Name, &ir.rel.Committer.Email, &committerWhen,
+			&taggerName, &taggerEmail, &taggerWhen)
+		if err != nil {
+			return nil, err
+		}
+		ir.target = target
+		ir.rel.parsed = parseTagInfo(t, ir.rel.Tag)
+		if name := strings.TrimPrefix(ir.ref, "refs/tags/"); name != ir.rel.Tag {
+			ir.rel.ref = name
+		}
+		ir.rel.Author.When, _ = time.Parse(time.RFC3339Nano, authorWhen)
+		ir.rel.Committer.When, _ = time.Parse(time.RFC3339Nano, committerWhen)
+		if taggerWhen.Valid {
+			tagger := &object.Signature{Name: taggerName.String, Email: taggerEmail.String}
+			tagger.When, _ = time.Parse(time.RFC3339Nano, taggerWhen.String)
+			ir.rel.Tagger = tagger
+		}
+		indexed[ir.ref] = ir
+	}
+	return indexed, rows.Err()
+}
+
+// update stores the releases that were loaded from git and removes those
+// whose refs are gone, in one transaction
+func (i *releaseIndex) update(loaded []indexedRelease, gone []string) error {
+	if len(loaded) == 0 && len(gone) == 0 {
+		return nil
+	}
+	tx, err := i.db.Begin()
+	if err != nil {
+		return err
+	}
+	insert, err := tx.Prepare(`INSERT OR REPLACE INTO releases VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer insert.Close()
+	for _, ir := range loaded {
+		rel := ir.rel
+		var taggerName, taggerEmail, taggerWhen sql.NullString
+		if rel.Tagger != nil {
+			taggerName = sql.NullString{String: rel.Tagger.Name, Valid: true}
+			taggerEmail = sql.NullString{String: rel.Tagger.Email, Valid: true}
+			taggerWhen = sql.NullString{String: rel.Tagger.When.Format(time.RFC3339Nano), Valid: true}
+		}
+		_, err := insert.Exec(ir.ref, ir.target, rel.Tag, rel.Hash, rel.ReleaseMessage, rel.CommitMessage,
+			rel.Author.Name, rel.Author.Email, rel.Author.When.Format(time.RFC3339Nano),
+			rel.Committer.Name, rel.Committer.Email, rel.Committer.When.Format(time.RFC3339Nano),
+			taggerName, taggerEmail, taggerWhen)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, ref := range gone {
+		if _, err := tx.Exec(`DELETE FROM releases WHERE ref = ?`, ref); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (i *releaseIndex) close() {
+	if err := i.db.Close(); err != nil {
+		log.Debug().Err(err).Msg("failed to close the release index")
+	}
+}
@@ -0,0 +1,83 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fernferret/release/internal/cron"
+)
+
+// LeadTimeConfig warns about releases that leave little time to react before
+// a freeze window starts, or that go out when nobody is around
+type LeadTimeConfig struct {
+	// BeforeFreeze is how long before a freeze window releases are warned
+	// about, like 24h or 2d
+	BeforeFreeze string `yaml:"before_freeze"`
+	// BusinessHours is a cron expression matching every minute of business
+	// hours, like "* 9-16 * * MON-FRI" for 9:00 to 17:00 on weekdays
+	BusinessHours string `yaml:"business_hours"`
+	// Confirm asks before releasing anyway, without a terminal the release
+	// needs --acknowledge-lead-time
+	Confirm bool `yaml:"confirm"`
+
+	beforeFreeze time.Duration
+	hours        *cron.Schedule
+}
+
+func (c *LeadTimeConfig) validate() error {
+	if c.BeforeFreeze != "" {
+		d, err := ParseAge(c.BeforeFreeze)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid lead_time.before_freeze '%s', must be a duration like 24h or 2d", c.BeforeFreeze)
+		}
+		c.beforeFreeze = d
+	}
+	if c.BusinessHours != "" {
+		hours, err := cron.Parse(c.BusinessHours)
+		if err != nil {
+			return fmt.Errorf("invalid lead_time.business_hours: %w", err)
+		}
+		c.hours = hours
+	}
+	return nil
+}
+
+// NextStart returns when the window next starts covering the component after
+// t, the zero time if it doesn't
+func (f *FreezeWindow) NextStart(component string, t time.Time) time.Time {
+	if !f.covers(component) {
+		return time.Time{}
+	}
+	if f.schedule != nil {
+		return f.schedule.Next(t)
+	}
+	if f.start.After(t) {
+		return f.start
+	}
+	return time.Time{}
+}
+
+// LeadTimeWarnings returns why releasing the component at t is risky: a
+// freeze window starting soon or being outside business hours
+func (c *Config) LeadTimeWarnings(component string, t time.Time) []string {
+	warnings := []string{}
+	if c.LeadTime.beforeFreeze > 0 {
+		for idx := range c.Freeze {
+			if c.Freeze[idx].Active(component, t) {
+				// Already frozen, releasing at all needs an override
+				continue
+			}
+			start := c.Freeze[idx].NextStart(component, t)
+			if start.IsZero() || start.Sub(t) > c.LeadTime.beforeFreeze {
+				continue
+			}
+			in := strings.TrimSuffix(start.Sub(t).Round(time.Minute).String(), "0s")
+			warnings = append(warnings, fmt.Sprintf("freeze %s starts in %s, there's little time to fix problems with this release", c.Freeze[idx].Name, in))
+		}
+	}
+	if c.LeadTime.hours != nil && !c.LeadTime.hours.Matches(t) {
+		warnings = append(warnings, fmt.Sprintf("it's outside of business hours (%s), there may be nobody around if this release breaks", c.LeadTime.BusinessHours))
+	}
+	return warnings
+}
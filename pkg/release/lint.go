@@ -0,0 +1,132 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LintTrailer marks a tag annotation as released with commit messages that
+// break the lint rules
+const LintTrailer = "Lint-Acknowledged"
+
+// DefaultConventionalTypes are the conventional commit types allowed unless
+// configured
+var DefaultConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// LintConfig checks the messages of the commits going into a release, as a
+// softer alternative to rejecting them when they're merged
+type LintConfig struct {
+	// Policy is warn (default) or block, blocked releases need
+	// --acknowledge-lint
+	Policy string `yaml:"policy"`
+	// Conventional requires conventional commit subjects (type(scope): text)
+	Conventional bool `yaml:"conventional"`
+	// Types are the conventional commit types allowed, see
+	// DefaultConventionalTypes
+	Types []string   `yaml:"types"`
+	Rules []LintRule `yaml:"rules"`
+	// Components are linted, all of them if empty
+	Components []string `yaml:"components"`
+}
+
+// LintRule is a regular expression commit messages have to match (Match) or
+// mustn't match (Reject)
+type LintRule struct {
+	Name   string `yaml:"name"`
+	Match  string `yaml:"match"`
+	Reject string `yaml:"reject"`
+}
+
+// conventionalSubject matches type(scope)!: description
+var conventionalSubject = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?!?: \S`)
+
+func (c LintConfig) validate() error {
+	if c.Policy != "" && c.Policy != "warn" && c.Policy != "block" {
+		return fmt.Errorf("lint policy must be warn or block, not '%s'", c.Policy)
+	}
+	for _, rule := range c.Rules {
+		if rule.Name == "" || (rule.Match == "" && rule.Reject == "") {
+			return fmt.Errorf("lint rules need a name and a match or reject pattern")
+		}
+		for _, pattern := range []string{rule.Match, rule.Reject} {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("lint rule %s: %w", rule.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Blocks reports whether violations stop the release
+func (c LintConfig) Blocks() bool {
+	return c.Policy == "block"
+}
+
+// Enabled reports whether there's anything to check for the component
+func (c LintConfig) Enabled(component string) bool {
+	if !c.Conventional && len(c.Rules) == 0 {
+		return false
+	}
+	if len(c.Components) == 0 {
+		return true
+	}
+	for _, name := range c.Components {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// Problems returns the rules a commit message breaks, merge commits git made
+// are left alone
+func (c LintConfig) Problems(message string) []string {
+	subject := Subject(message)
+	if strings.HasPrefix(subject, "Merge ") {
+		return nil
+	}
+	problems := []string{}
+	if c.Conventional {
+		types := c.Types
+		if len(types) == 0 {
+			types = DefaultConventionalTypes
+		}
+		if m := conventionalSubject.FindStringSubmatch(subject); m == nil {
+			problems = append(problems, "not a conventional commit")
+		} else if !contains(types, m[1]) {
+			problems = append(problems, fmt.Sprintf("unknown type %s", m[1]))
+		}
+	}
+	for _, rule := range c.Rules {
+		if rule.Match != "" && !regexp.MustCompile(rule.Match).MatchString(message) {
+			problems = append(problems, rule.Name)
+		} else if rule.Reject != "" && regexp.MustCompile(rule.Reject).MatchString(message) {
+			problems = append(problems, rule.Name)
+		}
+	}
+	return problems
+}
+
+// LintViolation is a commit whose message breaks lint rules
+type LintViolation struct {
+	Commit   *Commit
+	Problems []string
+}
+
+// LintCommits checks the commits of the component between the previous release
+// of the given (not yet created) tag and its target revision (HEAD if empty),
+// newest first
+func (r *Manager) LintCommits(tag, target string, cfg LintConfig, component ComponentConfig) ([]LintViolation, error) {
+	commits, err := r.releaseCommits(tag, target, component)
+	if err != nil {
+		return nil, err
+	}
+	violations := []LintViolation{}
+	for _, c := range commits {
+		if problems := cfg.Problems(c.Message); len(problems) > 0 {
+			violations = append(violations, LintViolation{Commit: c, Problems: problems})
+		}
+	}
+	return violations, nil
+}
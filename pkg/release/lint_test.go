@@ -0,0 +1,35 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintProblems(t *testing.T) {
+	cfg := LintConfig{
+		Conventional: true,
+		Types:        []string{"feat", "fix"},
+		Rules: []LintRule{
+			{Name: "ticket", Match: `(?m)^Refs: [A-Z]+-\d+$`},
+			{Name: "no WIP", Reject: `(?i)\bwip\b`},
+		},
+	}
+	for _, tc := range []struct {
+		message string
+		want    []string
+	}{
+		{"feat(api): add Get\n\nRefs: APP-12", []string{}},
+		{"fix!: drop Get\n\nRefs: APP-12", []string{}},
+		{"chore: bump\n\nRefs: APP-12", []string{"unknown type chore"}},
+		{"Add Get", []string{"not a conventional commit", "ticket"}},
+		{"fix: wip\n\nRefs: APP-12", []string{"no WIP"}},
+		{"Merge branch 'main'", nil},
+	} {
+		if got := cfg.Problems(tc.message); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %q, want %q", tc.message, got, tc.want)
+		}
+	}
+	if err := (LintConfig{Rules: []LintRule{{Name: "bad", Match: "("}}}).validate(); err == nil {
+		t.Error("a rule with a broken pattern is valid")
+	}
+}
@@ -0,0 +1,136 @@
+package release
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog/log"
+)
+
+// MailmapFile maps the names and emails people used in commits and tags to
+// the ones they prefer, like git log does
+const MailmapFile = ".mailmap"
+
+type mailmapEntry struct {
+	name, email string // What to show, empty to keep the one used
+	commitName  string // Only matches this name too if not empty
+}
+
+// Mailmap canonicalizes identities with the entries of a .mailmap file
+type Mailmap struct {
+	entries map[string][]mailmapEntry // By lower case commit email
+}
+
+// ParseMailmap parses the lines of a .mailmap file, which name a proper name,
+// a proper email or both for a commit email, optionally only with a commit
+// name:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func ParseMailmap(data []byte) *Mailmap {
+	m := &Mailmap{entries: map[string][]mailmapEntry{}}
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		names, emails := []string{}, []string{}
+		for {
+			start := strings.Index(line, "<")
+			end := strings.Index(line, ">")
+			if start < 0 || end < start {
+				break
+			}
+			names = append(names, strings.TrimSpace(line[:start]))
+			emails = append(emails, strings.TrimSpace(line[start+1:end]))
+			line = line[end+1:]
+		}
+		var e mailmapEntry
+		var commitEmail string
+		switch len(emails) {
+		case 1:
+			e.name, commitEmail = names[0], emails[0]
+		case 2:
+			e.name, e.email = names[0], emails[0]
+			e.commitName, commitEmail = names[1], emails[1]
+		default:
+			continue
+		}
+		key := strings.ToLower(commitEmail)
+		m.entries[key] = append(m.entries[key], e)
+	}
+	return m
+}
+
+// Map returns the canonical identity of a signature, entries naming the
+// commit name win over the ones that only name the email
+func (m *Mailmap) Map(sig object.Signature) object.Signature {
+	if m == nil {
+		return sig
+	}
+	entries := m.entries[strings.ToLower(sig.Email)]
+	var match *mailmapEntry
+	for idx := range entries {
+		e := &entries[idx]
+		if e.commitName != "" && strings.EqualFold(e.commitName, sig.Name) {
+			match = e
+			break
+		}
+		if e.commitName == "" && match == nil {
+			match = e
+		}
+	}
+	if match == nil {
+		return sig
+	}
+	if match.name != "" {
+		sig.Name = match.name
+	}
+	if match.email != "" {
+		sig.Email = match.email
+	}
+	return sig
+}
+
+// loadMailmap reads the .mailmap at HEAD, there's none if the repository
+// doesn't have one
+func (r *Manager) loadMailmap() {
+	r.mailmap = nil
+	data, err := r.headFile(MailmapFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debug().Err(err).Msgf("not using %s", MailmapFile)
+		}
+		return
+	}
+	r.mailmap = ParseMailmap(data)
+}
+
+// mapReleases canonicalizes who made and released every loaded release
+func (r *Manager) mapReleases() {
+	if r.mailmap == nil {
+		return
+	}
+	for idx := range r.releases {
+		rel := &r.releases[idx]
+		rel.Author = r.mailmap.Map(rel.Author)
+		rel.Committer = r.mailmap.Map(rel.Committer)
+		if rel.Tagger != nil {
+			tagger := r.mailmap.Map(*rel.Tagger)
+			rel.Tagger = &tagger
+		}
+	}
+}
+
+// mapCommits canonicalizes the authors and committers of the commits
+func (m *Mailmap) mapCommits(commits []*Commit) {
+	if m == nil {
+		return
+	}
+	for _, c := range commits {
+		c.Author = m.Map(c.Author)
+		c.Committer = m.Map(c.Committer)
+	}
+}
@@ -0,0 +1,33 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestMailmap(t *testing.T) {
+	m := ParseMailmap([]byte(`# Jane changed jobs
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@old.example.com>
+Jane Doe <jane@example.com> <JDOE@example.net> # any name
+Bot <bot@example.com> ci <ci@example.com>
+`))
+	for _, tc := range []struct {
+		name, email string
+		wantName    string
+		wantEmail   string
+	}{
+		{"jane", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"Jane D", "jane@old.example.com", "Jane D", "jane@example.com"},
+		{"jd", "jdoe@example.net", "Jane Doe", "jane@example.com"},
+		{"CI", "ci@example.com", "Bot", "bot@example.com"},
+		{"someone", "ci@example.com", "someone", "ci@example.com"},
+		{"Other", "other@example.com", "Other", "other@example.com"},
+	} {
+		got := m.Map(object.Signature{Name: tc.name, Email: tc.email})
+		if got.Name != tc.wantName || got.Email != tc.wantEmail {
+			t.Errorf("%s <%s>: got %s <%s>, want %s <%s>", tc.name, tc.email, got.Name, got.Email, tc.wantName, tc.wantEmail)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultMatrixOutput is where cross-compiled binaries go unless configured
+const DefaultMatrixOutput = "dist"
+
+// BuildMatrix cross-compiles the binary of a Go component for several
+// platforms, see RunBuildMatrix
+type BuildMatrix struct {
+	// Package is the main package, like ./cmd/release
+	Package string `yaml:"package"`
+	// Binary is the name of the binaries, the package's directory if empty
+	Binary string `yaml:"binary"`
+	// Targets are GOOS/GOARCH pairs, like linux/amd64
+	Targets []string `yaml:"targets"`
+	// LDFlags are passed to go build with {tag}, {version} and {commit} of
+	// the release replaced, like -X main.version={version}
+	LDFlags string `yaml:"ldflags"`
+	// Output is the directory the binaries and their SHA256SUMS are written
+	// to, relative to the repository, DefaultMatrixOutput if empty
+	Output string `yaml:"output"`
+	// CGO are the targets built with CGO_ENABLED=1, the others are built
+	// without cgo. Packages like go-sqlite3 only work with cgo.
+	CGO []string `yaml:"cgo"`
+	// CC is the C compiler of each cgo target, cc if it isn't listed
+	CC map[string]string `yaml:"cc"`
+}
+
+func (m BuildMatrix) validate() error {
+	if m.Package == "" || len(m.Targets) == 0 {
+		return fmt.Errorf("build_matrix needs a package and targets")
+	}
+	for _, target := range m.Targets {
+		if parts := strings.Split(target, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("build_matrix target %s isn't GOOS/GOARCH", target)
+		}
+	}
+	for _, target := range m.CGO {
+		if !contains(m.Targets, target) {
+			return fmt.Errorf("build_matrix cgo target %s isn't one of the targets", target)
+		}
+	}
+	for target := range m.CC {
+		if !contains(m.CGO, target) {
+			return fmt.Errorf("build_matrix has a cc for %s, which isn't built with cgo", target)
+		}
+	}
+	return nil
+}
+
+// env returns the environment target is built with
+func (m BuildMatrix) env(target string) []string {
+	parts := strings.SplitN(target, "/", 2)
+	env := append(os.Environ(), "GOOS="+parts[0], "GOARCH="+parts[1])
+	if !contains(m.CGO, target) {
+		return append(env, "CGO_ENABLED=0")
+	}
+	env = append(env, "CGO_ENABLED=1")
+	if cc := m.CC[target]; cc != "" {
+		env = append(env, "CC="+cc)
+	}
+	return env
+}
+
+// OutputDir returns the directory the binaries are written to
+func (m BuildMatrix) OutputDir() string {
+	if m.Output == "" {
+		return DefaultMatrixOutput
+	}
+	return m.Output
+}
+
+// binaryName names the binary of a release for a target, like
+// release-2020.07.001-linux-amd64
+func (m BuildMatrix) binaryName(rel *Release, target string) string {
+	binary := m.Binary
+	if binary == "" {
+		binary = path.Base(m.Package)
+	}
+	goos := strings.SplitN(target, "/", 2)[0]
+	name := fmt.Sprintf("%s-%s-%s", binary, rel.Version(), strings.ReplaceAll(target, "/", "-"))
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// RunBuildMatrix builds the binaries of the release at commit in dir for every
+// target, up to workers at once, and writes their SHA256SUMS next to them. The
+// manifest lists the binaries and the checksum file.
+func RunBuildMatrix(m BuildMatrix, dir string, rel *Release, commit string, workers int) (*BuildManifest, error) {
+	out := filepath.Join(dir, m.OutputDir())
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return nil, err
+	}
+	ldflags := strings.NewReplacer("{tag}", rel.Tag, "{version}", rel.Version(), "{commit}", commit).Replace(m.LDFlags)
+	if workers < 1 {
+		workers = 1
+	}
+	errs := make([]error, len(m.Targets))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				target := m.Targets[idx]
+				cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", filepath.Join(out, m.binaryName(rel, target)), m.Package)
+				cmd.Dir = dir
+				cmd.Env = m.env(target)
+				output := &bytes.Buffer{}
+				cmd.Stdout, cmd.Stderr = output, output
+				if err := cmd.Run(); err != nil {
+					errs[idx] = fmt.Errorf("building %s failed: %w\n%s", target, err, output.String())
+				}
+			}
+		}()
+	}
+	for idx := range m.Targets {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	binaries := []string{}
+	for _, target := range m.Targets {
+		binaries = append(binaries, filepath.Join(out, m.binaryName(rel, target)))
+	}
+	sums, err := Checksums(binaries, out)
+	if err != nil {
+		return nil, err
+	}
+	sumsFile := filepath.Join(out, DefaultChecksumFile)
+	if err := WriteChecksums(sumsFile, sums); err != nil {
+		return nil, err
+	}
+	manifest := &BuildManifest{Tag: rel.Tag, Artifacts: []Artifact{}}
+	for _, sum := range sums {
+		manifest.Artifacts = append(manifest.Artifacts, Artifact{Path: path.Join(filepath.ToSlash(m.OutputDir()), sum.Path), SHA256: sum.Sum})
+	}
+	sumsSum, err := sha256File(sumsFile)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Artifacts = append(manifest.Artifacts, Artifact{Path: path.Join(filepath.ToSlash(m.OutputDir()), DefaultChecksumFile), SHA256: sumsSum})
+	return manifest, nil
+}
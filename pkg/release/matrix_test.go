@@ -0,0 +1,59 @@
+package release
+
+import "testing"
+
+func TestBuildMatrixBinaryName(t *testing.T) {
+	m := BuildMatrix{Package: "./cmd/release", Targets: []string{"linux/amd64", "windows/amd64"}}
+	if err := m.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.binaryName(&Release{Tag: "2020.07.001-release"}, "linux/amd64"); got != "release-2020.07.001-linux-amd64" {
+		t.Errorf("got %s", got)
+	}
+	m.Binary = "rel"
+	if got := m.binaryName(&Release{Tag: "2020.07.001"}, "windows/amd64"); got != "rel-2020.07.001-windows-amd64.exe" {
+		t.Errorf("got %s", got)
+	}
+	if m.OutputDir() != DefaultMatrixOutput {
+		t.Errorf("output dir is %s", m.OutputDir())
+	}
+}
+
+func TestBuildMatrixCGO(t *testing.T) {
+	m := BuildMatrix{
+		Package: "./cmd/release",
+		Targets: []string{"linux/amd64", "linux/arm64", "windows/amd64"},
+		CGO:     []string{"linux/amd64", "linux/arm64"},
+		CC:      map[string]string{"linux/arm64": "aarch64-linux-gnu-gcc"},
+	}
+	if err := m.validate(); err != nil {
+		t.Fatal(err)
+	}
+	for target, want := range map[string][]string{
+		"linux/amd64":   {"GOARCH=amd64", "CGO_ENABLED=1"},
+		"linux/arm64":   {"CGO_ENABLED=1", "CC=aarch64-linux-gnu-gcc"},
+		"windows/amd64": {"GOOS=windows", "CGO_ENABLED=0"},
+	} {
+		env := m.env(target)
+		for _, v := range want {
+			if !contains(env, v) {
+				t.Errorf("%s: %s isn't set", target, v)
+			}
+		}
+	}
+}
+
+func TestBuildMatrixValidate(t *testing.T) {
+	for _, m := range []BuildMatrix{
+		{Targets: []string{"linux/amd64"}},
+		{Package: "./cmd/x"},
+		{Package: "./cmd/x", Targets: []string{"linux"}},
+		{Package: "./cmd/x", Targets: []string{"linux/"}},
+		{Package: "./cmd/x", Targets: []string{"linux/amd64"}, CGO: []string{"linux/arm64"}},
+		{Package: "./cmd/x", Targets: []string{"linux/amd64"}, CC: map[string]string{"linux/amd64": "gcc"}},
+	} {
+		if m.validate() == nil {
+			t.Errorf("%+v should be invalid", m)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// AuditNotesRef is the git notes ref audit events are recorded in, on the
+// commit of the release they're about
+const AuditNotesRef = "refs/notes/release/audit"
+
+// MetadataStore keeps what release records besides tags: which release is in
+// which environment, deployment statuses and the audit log. Small repos keep
+// it all in git, larger orgs can centralize it.
+type MetadataStore interface {
+	String() string
+	// SetEnvironment records that the commit of the component is in env
+	SetEnvironment(env, component, commit string) error
+	// Environments returns what's in every environment, without tags
+	Environments() ([]EnvRelease, error)
+	// AddDeployment records a deployment status of the release at commit
+	AddDeployment(d Deployment, commit string, sig object.Signature) error
+	Deployments() ([]Deployment, error)
+	// AddAuditEvent records an event about the release at commit
+	AddAuditEvent(e AuditEvent, commit string, sig object.Signature) error
+	AuditEvents() ([]AuditEvent, error)
+}
+
+// MetadataConfig selects the metadata store
+type MetadataConfig struct {
+	// Backend is git (refs and notes in the repository, the default), sqlite
+	// or http
+	Backend string `yaml:"backend"`
+	// Path is the SQLite database, relative to the repository, default
+	// .git/release.db
+	Path string `yaml:"path"`
+	// URL is a 'release serve' (or anything speaking its API) for http
+	URL string `yaml:"url"`
+	// TokenEnv holds the bearer token for http, default RELEASE_SERVE_TOKEN
+	TokenEnv string `yaml:"token_env"`
+}
+
+func (c *MetadataConfig) validate() error {
+	switch c.Backend {
+	case "", "git", "sqlite":
+	case "http":
+		if c.URL == "" {
+			return fmt.Errorf("metadata.url is required for the http backend")
+		}
+	default:
+		return fmt.Errorf("unknown metadata.backend '%s', must be git, sqlite or http", c.Backend)
+	}
+	return nil
+}
+
+// AuditEvent is something done to a release, kept in the audit log
+type AuditEvent struct {
+	Date   time.Time `json:"date"`
+	Action string    `json:"action"` // release, mark, deploy, prune, ...
+	Tag    string    `json:"tag,omitempty"`
+	Env    string    `json:"env,omitempty"`
+	By     string    `json:"by,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	// Commit is what the tag pointed to when the event was recorded, release
+	// verify --immutable checks releases against it
+	Commit string `json:"commit,omitempty"`
+}
+
+// UseMetadata switches the manager to the configured metadata store
+func (r *Manager) UseMetadata(cfg MetadataConfig) error {
+	switch cfg.Backend {
+	case "sqlite":
+		path := cfg.Path
+		if path == "" {
+			path = filepath.Join(".git", "release.db")
+		}
+		if !filepath.IsAbs(path) {
+			if r.repoDir == "" || r.tempDir != "" {
+				return fmt.Errorf("the sqlite metadata store needs a checkout, or an absolute metadata.path")
+			}
+			path = filepath.Join(r.repoDir, path)
+		}
+		store, err := openSQLiteStore(path)
+		if err != nil {
+			return err
+		}
+		r.store = store
+	case "http":
+		r.store = newHTTPStore(cfg)
+	default:
+		r.store = &gitStore{r}
+	}
+	return nil
+}
+
+// metadata returns the metadata store, the repository itself unless
+// UseMetadata picked another
+func (r *Manager) metadata() MetadataStore {
+	if r.store == nil {
+		r.store = &gitStore{r}
+	}
+	return r.store
+}
+
+// MetadataStore describes where the metadata is kept
+func (r *Manager) MetadataStore() string {
+	return r.metadata().String()
+}
+
+// MetadataInGit says whether the metadata is kept in the repository, only
+// then is it pushed and fetched with the environment refs
+func (r *Manager) MetadataInGit() bool {
+	_, ok := r.metadata().(*gitStore)
+	return ok
+}
+
+// Audit records an event in the audit log, on the commit of its tag (or HEAD
+// when the tag is gone)
+func (r *Manager) Audit(e AuditEvent, sig object.Signature) error {
+	if e.Date.IsZero() {
+		e.Date = sig.When
+	}
+	commit := ""
+	if rel := r.FindRelease(e.Tag); rel != nil {
+		commit = rel.Hash
+	} else if c, err := r.tagCommit(e.Tag); err == nil {
+		// Tags created since the releases were loaded
+		commit = c.Hash
+	}
+	if commit != "" && e.Commit == "" {
+		e.Commit = commit
+	}
+	if commit == "" {
+		head, err := r.releaseHead()
+		if err != nil {
+			return err
+		}
+		commit = head
+	}
+	return r.metadata().AddAuditEvent(e, commit, sig)
+}
+
+// AuditEvents returns the audit log, oldest first
+func (r *Manager) AuditEvents() ([]AuditEvent, error) {
+	events, err := r.metadata().AuditEvents()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events, nil
+}
+
+// gitStore keeps the metadata in the repository: environments as refs under
+// refs/releases/envs, deployments and audit events as JSON lines in notes
+type gitStore struct {
+	r *Manager
+}
+
+func (s *gitStore) String() string {
+	return "git"
+}
+
+func (s *gitStore) SetEnvironment(env, component, commit string) error {
+	return s.r.gitBackend().SetReference(envRefName(env, component), commit)
+}
+
+func (s *gitStore) Environments() ([]EnvRelease, error) {
+	refs, err := s.r.gitBackend().Refs(EnvRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	envs := []EnvRelease{}
+	for _, ref := range refs {
+		parts := strings.SplitN(strings.TrimPrefix(ref.Name.String(), EnvRefPrefix), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		envs = append(envs, EnvRelease{Env: parts[0], Component: parts[1], Hash: ref.Hash})
+	}
+	return envs, nil
+}
+
+func (s *gitStore) AddDeployment(d Deployment, commit string, sig object.Signature) error {
+	message := fmt.Sprintf("Deployment of %s to %s: %s\n", d.Tag, d.Env, d.Status)
+	return s.appendJSON(DeploymentNotesRef, commit, d, sig, message)
+}
+
+func (s *gitStore) Deployments() ([]Deployment, error) {
+	deployments := []Deployment{}
+	err := s.readJSON(DeploymentNotesRef, func(line []byte) {
+		var d Deployment
+		if json.Unmarshal(line, &d) == nil && d.Tag != "" {
+			deployments = append(deployments, d)
+		}
+	})
+	return deployments, err
+}
+
+func (s *gitStore) AddAuditEvent(e AuditEvent, commit string, sig object.Signature) error {
+	message := fmt.Sprintf("Audit: %s %s\n", e.Action, e.Tag)
+	return s.appendJSON(AuditNotesRef, commit, e, sig, message)
+}
+
+func (s *gitStore) AuditEvents() ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	err := s.readJSON(AuditNotesRef, func(line []byte) {
+		var e AuditEvent
+		if json.Unmarshal(line, &e) == nil && e.Action != "" {
+			events = append(events, e)
+		}
+	})
+	return events, err
+}
+
+// appendJSON adds v as a line of JSON to the note of the commit, readable in
+// 'git log --notes'
+func (s *gitStore) appendJSON(ref plumbing.ReferenceName, commit string, v interface{}, sig object.Signature, message string) error {
+	line := &bytes.Buffer{}
+	enc := json.NewEncoder(line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return s.r.appendNote(ref, commit, line.String(), sig, message)
+}
+
+// readJSON calls fn with every line of every note in the ref, lines that
+// aren't what fn expects (notes added by hand) are up to fn to skip
+func (s *gitStore) readJSON(ref plumbing.ReferenceName, fn func(line []byte)) error {
+	notes, err := s.r.readNotes(ref)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		scanner := bufio.NewScanner(strings.NewReader(note))
+		for scanner.Scan() {
+			fn(scanner.Bytes())
+		}
+	}
+	return nil
+}
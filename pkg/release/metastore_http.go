@@ -0,0 +1,126 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// DefaultMetadataTokenEnv holds the bearer token of the http metadata store,
+// the one 'release serve' checks
+const DefaultMetadataTokenEnv = "RELEASE_SERVE_TOKEN"
+
+// httpStore keeps the metadata on a server speaking the 'release serve' API:
+// GET and POST on /environments, /deployments and /audit
+type httpStore struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// EnvironmentUpdate is the body of POST /environments
+type EnvironmentUpdate struct {
+	Env       string `json:"env"`
+	Component string `json:"component"`
+	Hash      string `json:"hash"`
+}
+
+func newHTTPStore(cfg MetadataConfig) *httpStore {
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = DefaultMetadataTokenEnv
+	}
+	return &httpStore{
+		url:    strings.TrimRight(cfg.URL, "/"),
+		token:  keyring.Getenv(tokenEnv),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *httpStore) String() string {
+	return s.url
+}
+
+func (s *httpStore) do(method, path string, in, out interface{}) error {
+	if offline {
+		return fmt.Errorf("reaching the metadata store %s: %w", s.url, ErrOffline)
+	}
+	var body *bytes.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, s.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (s *httpStore) SetEnvironment(env, component, commit string) error {
+	return s.do("POST", "/environments", EnvironmentUpdate{Env: env, Component: component, Hash: commit}, nil)
+}
+
+func (s *httpStore) Environments() ([]EnvRelease, error) {
+	updates := []EnvironmentUpdate{}
+	if err := s.do("GET", "/environments", nil, &updates); err != nil {
+		return nil, err
+	}
+	envs := []EnvRelease{}
+	for _, u := range updates {
+		envs = append(envs, EnvRelease{Env: u.Env, Component: u.Component, Hash: u.Hash})
+	}
+	return envs, nil
+}
+
+func (s *httpStore) AddDeployment(d Deployment, commit string, sig object.Signature) error {
+	return s.do("POST", "/deployments", d, nil)
+}
+
+func (s *httpStore) Deployments() ([]Deployment, error) {
+	deployments := []Deployment{}
+	err := s.do("GET", "/deployments", nil, &deployments)
+	return deployments, err
+}
+
+func (s *httpStore) AddAuditEvent(e AuditEvent, commit string, sig object.Signature) error {
+	return s.do("POST", "/audit", e, nil)
+}
+
+func (s *httpStore) AuditEvents() ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	err := s.do("GET", "/audit", nil, &events)
+	return events, err
+}
@@ -0,0 +1,143 @@
+package release
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	// The SQLite driver for database/sql
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS environments (
+	env TEXT NOT NULL,
+	component TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	updated TEXT NOT NULL,
+	PRIMARY KEY (env, component)
+);
+CREATE TABLE IF NOT EXISTS deployments (
+	tag TEXT NOT NULL,
+	env TEXT NOT NULL,
+	status TEXT NOT NULL,
+	date TEXT NOT NULL,
+	released_by TEXT NOT NULL,
+	url TEXT NOT NULL,
+	description TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS audit (
+	date TEXT NOT NULL,
+	action TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	env TEXT NOT NULL,
+	released_by TEXT NOT NULL,
+	detail TEXT NOT NULL,
+	commit_hash TEXT NOT NULL DEFAULT ''
+);
+`
+
+// sqliteStore keeps the metadata in a local SQLite database
+type sqliteStore struct {
+	path string
+	db   *sql.DB
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up %s: %w", path, err)
+	}
+	// Audit logs from before the commit was recorded
+	if _, err := db.Exec(`ALTER TABLE audit ADD COLUMN commit_hash TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up %s: %w", path, err)
+	}
+	return &sqliteStore{path: path, db: db}, nil
+}
+
+func (s *sqliteStore) String() string {
+	return fmt.Sprintf("sqlite %s", s.path)
+}
+
+func (s *sqliteStore) SetEnvironment(env, component, commit string) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO environments (env, component, hash, updated) VALUES (?, ?, ?, ?)`,
+		env, component, commit, time.Now().Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *sqliteStore) Environments() ([]EnvRelease, error) {
+	rows, err := s.db.Query(`SELECT env, component, hash FROM environments`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	envs := []EnvRelease{}
+	for rows.Next() {
+		var e EnvRelease
+		var hash string
+		if err := rows.Scan(&e.Env, &e.Component, &hash); err != nil {
+			return nil, err
+		}
+		e.Hash = hash
+		envs = append(envs, e)
+	}
+	return envs, rows.Err()
+}
+
+func (s *sqliteStore) AddDeployment(d Deployment, commit string, sig object.Signature) error {
+	_, err := s.db.Exec(`INSERT INTO deployments (tag, env, status, date, released_by, url, description) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		d.Tag, d.Env, d.Status, d.Date.Format(time.RFC3339Nano), d.By, d.URL, d.Description)
+	return err
+}
+
+func (s *sqliteStore) Deployments() ([]Deployment, error) {
+	rows, err := s.db.Query(`SELECT tag, env, status, date, released_by, url, description FROM deployments ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deployments := []Deployment{}
+	for rows.Next() {
+		var d Deployment
+		var date string
+		if err := rows.Scan(&d.Tag, &d.Env, &d.Status, &date, &d.By, &d.URL, &d.Description); err != nil {
+			return nil, err
+		}
+		d.Date, _ = time.Parse(time.RFC3339Nano, date)
+		deployments = append(deployments, d)
+	}
+	return deployments, rows.Err()
+}
+
+func (s *sqliteStore) AddAuditEvent(e AuditEvent, commit string, sig object.Signature) error {
+	_, err := s.db.Exec(`INSERT INTO audit (date, action, tag, env, released_by, detail, commit_hash) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.Date.Format(time.RFC3339Nano), e.Action, e.Tag, e.Env, e.By, e.Detail, e.Commit)
+	return err
+}
+
+func (s *sqliteStore) AuditEvents() ([]AuditEvent, error) {
+	rows, err := s.db.Query(`SELECT date, action, tag, env, released_by, detail, commit_hash FROM audit ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []AuditEvent{}
+	for rows.Next() {
+		var e AuditEvent
+		var date string
+		if err := rows.Scan(&date, &e.Action, &e.Tag, &e.Env, &e.By, &e.Detail, &e.Commit); err != nil {
+			return nil, err
+		}
+		e.Date, _ = time.Parse(time.RFC3339Nano, date)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,178 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MigratedFromTrailer records the tag a migrated release was copied from
+const MigratedFromTrailer = "Migrated-From"
+
+// SchemeUsage is how many releases of a component use each scheme
+type SchemeUsage struct {
+	Component string
+	Releases  map[Scheme]int
+	Latest    map[Scheme]string // The newest release of each scheme
+}
+
+// SchemeUsage reports the schemes each component's releases use, sorted by
+// component
+func (r *Manager) SchemeUsage() []SchemeUsage {
+	byComponent := map[string]*SchemeUsage{}
+	latest := map[string]*calVerStandard{}
+	for _, release := range r.releases {
+		version, ok := parseCalVer(r.tagTemplate, release.Tag)
+		if !ok {
+			continue
+		}
+		component := release.Component()
+		u, ok := byComponent[component]
+		if !ok {
+			u = &SchemeUsage{Component: component, Releases: map[Scheme]int{}, Latest: map[Scheme]string{}}
+			byComponent[component] = u
+		}
+		scheme := versionScheme(version)
+		u.Releases[scheme]++
+		key := component + " " + string(scheme)
+		if latest[key] == nil || version.Compare(latest[key]) > 0 {
+			latest[key], u.Latest[scheme] = version, release.Tag
+		}
+	}
+	usage := []SchemeUsage{}
+	for _, u := range byComponent {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Component < usage[j].Component })
+	return usage
+}
+
+func versionScheme(version *calVerStandard) Scheme {
+	if version.IsOrdinal() {
+		return SchemeOrdinal
+	}
+	return SchemeMonthly
+}
+
+// SchemeMapping is a release that doesn't use the configured scheme and the
+// tag it gets in that scheme
+type SchemeMapping struct {
+	From string
+	To   string
+	Hash string // The commit both tags point to
+}
+
+// MigrationPlan maps every release that doesn't use the configured scheme of
+// its component to a tag in that scheme, unless the commit already has one. Monthly versions get the day the
+// commit was made if it's in the version's month and the first day of the
+// month otherwise, ordinal versions get their month. Increments continue
+// after the releases that already use the scheme, in version order.
+func (r *Manager) MigrationPlan(cfg *Config) []SchemeMapping {
+	type source struct {
+		release *Release
+		version *calVerStandard
+		suffix  string
+	}
+	// Existing versions in the target scheme, per component, to continue from
+	taken := map[string][]*calVerStandard{}
+	// Releases already migrated (or tagged in both schemes) by commit and
+	// suffix, so running the migration again doesn't copy them twice
+	migrated := map[string]bool{}
+	sources := []source{}
+	for idx := range r.releases {
+		release := &r.releases[idx]
+		version, suffix, ok := splitTag(r.tagTemplate, release.Tag)
+		if !ok {
+			continue
+		}
+		component := release.Component()
+		scheme := cfg.ForComponent(component).Scheme
+		if scheme == "" {
+			scheme = SchemeMonthly
+		}
+		if versionScheme(version) == scheme {
+			taken[component] = append(taken[component], version)
+			migrated[release.Hash+" "+suffix] = true
+			continue
+		}
+		sources = append(sources, source{release, version, suffix})
+	}
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].version.Compare(sources[j].version) < 0 })
+
+	mappings := []SchemeMapping{}
+	for _, s := range sources {
+		if migrated[s.release.Hash+" "+s.suffix] {
+			continue
+		}
+		component := s.release.Component()
+		policy := cfg.Increments.ResetPolicy(component)
+		var target *calVerStandard
+		if s.version.IsOrdinal() {
+			target = newCalVerStandard(s.version.Year, s.version.Month, 0)
+		} else {
+			date := s.release.Date().UTC()
+			if uint64(date.Year()) != s.version.Year || uint64(date.Month()) != s.version.Month {
+				date = time.Date(int(s.version.Year), time.Month(s.version.Month), 1, 0, 0, 0, 0, time.UTC)
+			}
+			target = newOrdinalCalVer(s.version.Year, uint64(date.YearDay()), 0)
+		}
+		for _, v := range taken[component] {
+			inScope := policy.sameScope(v, target)
+			if target.IsOrdinal() && policy != ResetYearly && policy != ResetNever {
+				inScope = v.Year == target.Year && v.Day == target.Day
+			}
+			if inScope && v.Release > target.Release {
+				target.Release = v.Release
+			}
+		}
+		target.Increase()
+		taken[component] = append(taken[component], target)
+		mappings = append(mappings, SchemeMapping{
+			From: s.release.Tag,
+			To:   target.FormatRelease(r.tagTemplate, s.suffix),
+			Hash: s.release.Hash,
+		})
+	}
+	return mappings
+}
+
+// MigrateTags creates the tags of the mappings, annotated if the release they
+// are copied from is, with the original message and a Migrated-From trailer.
+// With remove the original tags are deleted afterwards. If creating any tag
+// fails the tags created so far are deleted again.
+func (r *Manager) MigrateTags(mappings []SchemeMapping, user, email string, remove bool) error {
+	created := []string{}
+	rollback := func(cause error) error {
+		for _, tag := range created {
+			if err := r.DeleteTag(tag); err != nil {
+				return fmt.Errorf("%w (and failed to roll back tag %s: %s)", cause, tag, err)
+			}
+		}
+		return cause
+	}
+	for _, m := range mappings {
+		from := r.FindRelease(m.From)
+		if from == nil {
+			return rollback(fmt.Errorf("release %s not found", m.From))
+		}
+		kind := TagLightweight
+		message := ""
+		if from.Tagger != nil {
+			kind = TagAnnotated
+			message = AppendTrailer(from.ReleaseMessage, MigratedFromTrailer, m.From)
+		}
+		if _, err := r.CreateTagAt(m.To, m.Hash, message, user, email, kind); err != nil {
+			return rollback(Classify("creating tag "+m.To, err))
+		}
+		created = append(created, m.To)
+	}
+	if remove {
+		for _, m := range mappings {
+			if err := r.DeleteTag(m.From); err != nil {
+				return fmt.Errorf("deleting %s: %w", m.From, err)
+			}
+		}
+	}
+	r.Reload()
+	return nil
+}
@@ -0,0 +1,135 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+// MilestoneTrailer links a release to the forge milestone it completes
+const MilestoneTrailer = "Milestone"
+
+// MilestoneConfig ties releases to forge milestones
+type MilestoneConfig struct {
+	// Close closes the milestone of a release once it's pushed, like
+	// --close-milestone
+	Close bool `yaml:"close"`
+	// Title is a template for the milestone title with the fields of
+	// MessageData, like "Sprint {{.Version}}". By default milestones named
+	// like the tag or the version (with or without a v) match.
+	Title string `yaml:"title"`
+
+	title *template.Template
+}
+
+func (c *MilestoneConfig) validate() error {
+	if c.Title == "" {
+		return nil
+	}
+	t, err := template.New("milestone").Parse(c.Title)
+	if err != nil {
+		return fmt.Errorf("invalid milestones.title: %w", err)
+	}
+	c.title = t
+	return nil
+}
+
+// titles returns the milestone titles that belong to the tag
+func (c *MilestoneConfig) titles(rel *Release) ([]string, error) {
+	if c.title == nil {
+		version := rel.Version()
+		return []string{rel.Tag, version, "v" + version}, nil
+	}
+	var buf bytes.Buffer
+	if err := c.title.Execute(&buf, MessageData{Tag: rel.Tag, Component: rel.Component(), Version: rel.Version()}); err != nil {
+		return nil, fmt.Errorf("failed to render milestones.title: %w", err)
+	}
+	return []string{buf.String()}, nil
+}
+
+// FindMilestone returns the open milestone of the release, nil if there is
+// none
+func FindMilestone(f forge.Forge, cfg MilestoneConfig, rel *Release) (*forge.Milestone, error) {
+	forgeMilestones, ok := f.(forge.Milestones)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't have milestones", f.Name())
+	}
+	titles, err := cfg.titles(rel)
+	if err != nil {
+		return nil, err
+	}
+	open, err := forgeMilestones.OpenMilestones()
+	if err != nil {
+		return nil, Classify("listing milestones", err)
+	}
+	for _, title := range titles {
+		for idx := range open {
+			if strings.EqualFold(strings.TrimSpace(open[idx].Title), strings.TrimSpace(title)) {
+				return &open[idx], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// nextMilestone picks the milestone unfinished issues roll into: the one due
+// soonest, milestones without a due date last
+func nextMilestone(open []forge.Milestone, closing forge.Milestone) *forge.Milestone {
+	candidates := []forge.Milestone{}
+	for _, m := range open {
+		if m.ID != closing.ID {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return !a.Due.IsZero()
+		}
+		if !a.Due.Equal(b.Due) {
+			return a.Due.Before(b.Due)
+		}
+		return a.Title < b.Title
+	})
+	return &candidates[0]
+}
+
+// CloseMilestone moves the open issues of the milestone into the next one and
+// closes it. It's left open when issues can't be moved, or when there's no
+// next milestone for them.
+func CloseMilestone(f forge.Forge, m forge.Milestone) (int, *forge.Milestone, error) {
+	forgeMilestones, ok := f.(forge.Milestones)
+	if !ok {
+		return 0, nil, fmt.Errorf("%s doesn't have milestones", f.Name())
+	}
+	issues, err := forgeMilestones.OpenIssues(m)
+	if err != nil {
+		return 0, nil, Classify(fmt.Sprintf("listing the issues of milestone %s", m.Title), err)
+	}
+	var next *forge.Milestone
+	if len(issues) > 0 {
+		open, err := forgeMilestones.OpenMilestones()
+		if err != nil {
+			return 0, nil, Classify("listing milestones", err)
+		}
+		if next = nextMilestone(open, m); next == nil {
+			return 0, nil, fmt.Errorf("milestone %s has %d open issue(s) and there's no other open milestone to move them to", m.Title, len(issues))
+		}
+	}
+	for moved, issue := range issues {
+		if err := forgeMilestones.MoveIssue(issue, *next); err != nil {
+			return moved, next, Classify(fmt.Sprintf("moving issue #%d to milestone %s", issue, next.Title), err)
+		}
+	}
+	if err := forgeMilestones.CloseMilestone(m); err != nil {
+		return len(issues), next, Classify(fmt.Sprintf("closing milestone %s", m.Title), err)
+	}
+	return len(issues), next, nil
+}
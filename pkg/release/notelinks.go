@@ -0,0 +1,81 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+// issueRef matches issue references like #123 that aren't part of a word or
+// already a link
+var issueRef = regexp.MustCompile(`(^|[^\w\[/&])#(\d+)\b`)
+
+// NoteLinker turns commit hashes, issue references and release comparisons in
+// markdown release notes into links to the forge
+type NoteLinker struct {
+	links forge.Links
+}
+
+// NoteLinker returns the linker of the repository the remote points to, nil
+// if the remote isn't on a forge it can make links for
+func (r *Manager) NoteLinker(remote string, cfg forge.Config) (*NoteLinker, error) {
+	remoteURL, err := r.RemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := forge.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	links := forge.LinksFor(repo, cfg)
+	if links == (forge.Links{}) {
+		return nil, nil
+	}
+	return &NoteLinker{links: links}, nil
+}
+
+// Commit returns a link to the commit labeled with its short hash, just the
+// short hash without a template
+func (l *NoteLinker) Commit(hash string) string {
+	short := hash
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	if l == nil || l.links.Commit == "" {
+		return short
+	}
+	url := strings.NewReplacer("{hash}", hash, "{short}", short).Replace(l.links.Commit)
+	return fmt.Sprintf("[%s](%s)", short, url)
+}
+
+// Issues links the issue references in a note
+func (l *NoteLinker) Issues(note string) string {
+	if l == nil || l.links.Issue == "" {
+		return note
+	}
+	return issueRef.ReplaceAllStringFunc(note, func(m string) string {
+		sub := issueRef.FindStringSubmatch(m)
+		url := strings.ReplaceAll(l.links.Issue, "{issue}", sub[2])
+		return fmt.Sprintf("%s[#%s](%s)", sub[1], sub[2], url)
+	})
+}
+
+// Compare returns a link comparing two tags labeled with from, just from
+// without a template
+func (l *NoteLinker) Compare(from, to string) string {
+	if l == nil || l.links.Compare == "" {
+		return from
+	}
+	url := strings.NewReplacer("{from}", from, "{to}", to).Replace(l.links.Compare)
+	return fmt.Sprintf("[%s](%s)", from, url)
+}
+
+// Notes returns the release notes of the commits like ReleaseNotes, with
+// issue references linked and a link to the commit each note came from
+func (l *NoteLinker) Notes(commits []*Commit) []string {
+	return releaseNotes(commits, func(note string, c *Commit) string {
+		return fmt.Sprintf("%s (%s)", l.Issues(note), l.Commit(c.Hash))
+	})
+}
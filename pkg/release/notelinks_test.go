@@ -0,0 +1,39 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+func TestNoteLinker(t *testing.T) {
+	repo := &forge.Repo{Host: "git.example.com", Owner: "team", Name: "app"}
+	if links := forge.LinksFor(repo, forge.Config{}); links != (forge.Links{}) {
+		t.Errorf("got %+v for an unknown forge, want no links", links)
+	}
+	l := &NoteLinker{links: forge.LinksFor(repo, forge.Config{
+		Type:  "gitlab",
+		Links: forge.Links{Issue: "https://tracker.example.com/browse/APP-{issue}"},
+	})}
+
+	commits := []*Commit{
+		{Hash: "0123456789abcdef", Message: "Fix #12 and #13, not a#14 or [#15](x)"},
+		{Hash: "fedcba9876543210", Message: "Add X"},
+	}
+	want := []string{
+		"Fix [#12](https://tracker.example.com/browse/APP-12) and [#13](https://tracker.example.com/browse/APP-13), not a#14 or [#15](x) ([0123456](https://git.example.com/team/app/-/commit/0123456789abcdef))",
+		"Add X ([fedcba9](https://git.example.com/team/app/-/commit/fedcba9876543210))",
+	}
+	if got := l.Notes(commits); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := l.Compare("2020.07.001", "2020.07.002"); got != "[2020.07.001](https://git.example.com/team/app/-/compare/2020.07.001...2020.07.002)" {
+		t.Errorf("got compare link %s", got)
+	}
+
+	var none *NoteLinker
+	if got := none.Commit("0123456789abcdef"); got != "0123456" {
+		t.Errorf("got %s without links, want the short hash", got)
+	}
+}
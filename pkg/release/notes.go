@@ -0,0 +1,65 @@
+package release
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ReleaseNoteTrailer is the commit trailer holding a curated release note
+const ReleaseNoteTrailer = "Release-Note"
+
+// releaseNoteBlock matches Kubernetes style ```release-note fenced blocks
+var releaseNoteBlock = regexp.MustCompile("(?s)```release-note\\s*\\n(.*?)```")
+
+// CommitReleaseNotes returns the curated release notes of a commit message,
+// from ```release-note blocks and Release-Note trailers. A note of "NONE"
+// marks a commit as having nothing worth announcing, it's returned as an empty
+// (non-nil) list.
+func CommitReleaseNotes(message string) []string {
+	var notes []string
+	add := func(note string) {
+		note = strings.TrimSpace(note)
+		if notes == nil {
+			notes = []string{}
+		}
+		if note != "" && !strings.EqualFold(note, "none") {
+			notes = append(notes, note)
+		}
+	}
+	for _, m := range releaseNoteBlock.FindAllStringSubmatch(message, -1) {
+		add(m[1])
+	}
+	for _, t := range ParseTrailers(message) {
+		if strings.EqualFold(t.Key, ReleaseNoteTrailer) {
+			add(t.Value)
+		}
+	}
+	return notes
+}
+
+// ReleaseNotes turns commits into changelog entries. If any commit has curated
+// release notes only those are used, otherwise every commit subject is.
+func ReleaseNotes(commits []*Commit) []string {
+	return releaseNotes(commits, func(note string, c *Commit) string { return note })
+}
+
+// releaseNotes is ReleaseNotes with every note passed through format along
+// with the commit it came from
+func releaseNotes(commits []*Commit, format func(note string, c *Commit) string) []string {
+	curated := false
+	notes := []string{}
+	subjects := []string{}
+	for _, c := range commits {
+		if n := CommitReleaseNotes(c.Message); n != nil {
+			curated = true
+			for _, note := range n {
+				notes = append(notes, format(note, c))
+			}
+		}
+		subjects = append(subjects, format(Subject(c.Message), c))
+	}
+	if curated {
+		return notes
+	}
+	return subjects
+}
@@ -0,0 +1,42 @@
+package notify
+
+import "time"
+
+// discordMaxDescription is the longest description Discord accepts in an embed
+const discordMaxDescription = 4096
+
+// Discord posts release announcements as an embed to a Discord webhook
+type Discord struct {
+	*webhook
+}
+
+// NewDiscord creates a Discord notifier
+func NewDiscord(cfg WebhookConfig) (*Discord, error) {
+	w, err := newWebhook(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Discord{w}, nil
+}
+
+// Name returns the name of the notifier
+func (n *Discord) Name() string {
+	return "discord"
+}
+
+// Notify posts the event as a single embed, long changelogs are truncated to
+// what Discord allows
+func (n *Discord) Notify(e *Event) error {
+	title, body, err := n.render(e)
+	if err != nil {
+		return err
+	}
+	if len(body) > discordMaxDescription {
+		body = body[:discordMaxDescription-3] + "..."
+	}
+	return n.post(map[string]interface{}{
+		"embeds": []map[string]interface{}{
+			{"title": title, "description": body, "timestamp": e.Date.Format(time.RFC3339)},
+		},
+	})
+}
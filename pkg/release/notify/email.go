@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// EmailConfig configures the SMTP notifier
+type EmailConfig struct {
+	Host        string   `yaml:"host"`
+	Port        int      `yaml:"port"` // Defaults to 587, or 465 for tls: tls
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to"`
+	Username    string   `yaml:"username"`
+	PasswordEnv string   `yaml:"password_env"` // Environment variable holding the password
+	// TLS is one of "starttls" (default), "tls" (implicit TLS) or "none"
+	TLS     string `yaml:"tls"`
+	Subject string `yaml:"subject"` // Subject template, see DefaultSubject
+	Body    string `yaml:"body"`    // Body template, see DefaultBody
+}
+
+// Email sends release announcements over SMTP
+type Email struct {
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewEmail validates the config and creates an email notifier
+func NewEmail(cfg EmailConfig) (*Email, error) {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("host, from and at least one to address are required")
+	}
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = "starttls"
+	case "starttls", "tls", "none":
+	default:
+		return nil, fmt.Errorf("unknown tls mode '%s', must be starttls, tls or none", cfg.TLS)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS == "tls" {
+			cfg.Port = 465
+		}
+	}
+	subject, err := parseTemplate("subject", cfg.Subject, DefaultSubject)
+	if err != nil {
+		return nil, err
+	}
+	body, err := parseTemplate("body", cfg.Body, DefaultBody)
+	if err != nil {
+		return nil, err
+	}
+	return &Email{cfg: cfg, subject: subject, body: body}, nil
+}
+
+// Name returns the name of the notifier
+func (n *Email) Name() string {
+	return "email"
+}
+
+// Notify sends the event to every configured recipient
+func (n *Email) Notify(e *Event) error {
+	subject, err := render(n.subject, e)
+	if err != nil {
+		return err
+	}
+	body, err := render(n.body, e)
+	if err != nil {
+		return err
+	}
+	msg := strings.Join([]string{
+		"From: " + n.cfg.From,
+		"To: " + strings.Join(n.cfg.To, ", "),
+		"Subject: " + strings.TrimSpace(subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		strings.Replace(body, "\n", "\r\n", -1),
+	}, "\r\n")
+	return n.send([]byte(msg))
+}
+
+func (n *Email) send(msg []byte) error {
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+
+	var conn net.Conn
+	var err error
+	if n.cfg.TLS == "tls" {
+		conn, err = tls.Dial("tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if n.cfg.TLS == "starttls" {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if n.cfg.Username != "" {
+		auth := smtp.PlainAuth("", n.cfg.Username, keyring.Getenv(n.cfg.PasswordEnv), n.cfg.Host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,34 @@
+package notify
+
+// Generic posts the raw event (plus the rendered title and body) as JSON to
+// any endpoint, for change-management tools we don't have a driver for
+type Generic struct {
+	*webhook
+}
+
+// NewGeneric creates a generic JSON webhook notifier
+func NewGeneric(cfg WebhookConfig) (*Generic, error) {
+	w, err := newWebhook(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Generic{w}, nil
+}
+
+// Name returns the name of the notifier
+func (n *Generic) Name() string {
+	return "webhook"
+}
+
+// Notify posts the event
+func (n *Generic) Notify(e *Event) error {
+	title, body, err := n.render(e)
+	if err != nil {
+		return err
+	}
+	return n.post(map[string]interface{}{
+		"event": e,
+		"title": title,
+		"body":  body,
+	})
+}
@@ -0,0 +1,163 @@
+// Package notify sends release announcements to the places people actually
+// look, each driver renders the same Event through its own templates.
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event is the data every notification template is rendered with
+type Event struct {
+	Tag        string    `json:"tag"`         // The full tag name (2020.07.001-api)
+	Component  string    `json:"component"`   // The component that was released (api)
+	Remote     string    `json:"remote"`      // The remote the tag was pushed to
+	ReleasedBy string    `json:"released_by"` // Who performed the release
+	Message    string    `json:"message"`     // The release message, might be empty
+	Changelog  []string  `json:"changelog"`   // Release notes (or commit subjects) of this release, newest first
+	Breaking   bool      `json:"breaking"`    // The release has acknowledged breaking changes
+	Teams      []string  `json:"teams"`       // The teams owning the component, might be empty
+	Date       time.Time `json:"date"`        // When the release was created
+
+	// AffectedTeams own files the release changed besides the component's
+	// owners, the release is cross-cutting when there are any
+	AffectedTeams []string `json:"affected_teams,omitempty"`
+	// Risk is the release's risk, like "medium score=4 lines=812 authors=3
+	// days=21", empty if it wasn't scored
+	Risk string `json:"risk,omitempty"`
+}
+
+// Notifier sends an Event somewhere
+type Notifier interface {
+	// Name is a short human readable name used in logs
+	Name() string
+	// Notify sends the event
+	Notify(e *Event) error
+}
+
+// Config holds the configuration for all notification drivers, a nil driver
+// config means the driver is disabled
+type Config struct {
+	Email   *EmailConfig   `yaml:"email"`
+	Slack   *WebhookConfig `yaml:"slack"`
+	Teams   *WebhookConfig `yaml:"teams"`
+	Discord *WebhookConfig `yaml:"discord"`
+	// PagerDuty sends change events, Webhook posts the raw event to any
+	// change-management endpoint
+	PagerDuty *PagerDutyConfig `yaml:"pagerduty"`
+	Webhook   *WebhookConfig   `yaml:"webhook"`
+}
+
+// FromConfig creates all of the configured notifiers
+func FromConfig(cfg Config) ([]Notifier, error) {
+	notifiers := []Notifier{}
+	if cfg.Email != nil {
+		n, err := NewEmail(*cfg.Email)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Slack != nil {
+		n, err := NewSlack(*cfg.Slack)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slack notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Teams != nil {
+		n, err := NewTeams(*cfg.Teams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid teams notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Discord != nil {
+		n, err := NewDiscord(*cfg.Discord)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discord notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.PagerDuty != nil {
+		n, err := NewPagerDuty(*cfg.PagerDuty)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pagerduty notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cfg.Webhook != nil {
+		n, err := NewGeneric(*cfg.Webhook)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook notifier config: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// SendAll sends the event with every notifier, failures are logged and
+// counted but don't stop the other notifiers
+func SendAll(notifiers []Notifier, e *Event) int {
+	failed := 0
+	for _, n := range notifiers {
+		if err := n.Notify(e); err != nil {
+			log.Error().Err(err).Msgf("failed to send %s notification for %s", n.Name(), e.Tag)
+			failed++
+			continue
+		}
+		log.Debug().Msgf("sent %s notification for %s", n.Name(), e.Tag)
+	}
+	return failed
+}
+
+// DefaultSubject is used when a driver doesn't configure its own subject
+const DefaultSubject = `Released {{.Tag}}{{if .Breaking}} (breaking){{end}}`
+
+// DefaultBody is used when a driver doesn't configure its own body
+const DefaultBody = `{{.Component}} {{.Tag}} was released by {{.ReleasedBy}}
+{{- if .Teams}}, owned by {{range $i, $t := .Teams}}{{if $i}}, {{end}}{{$t}}{{end}}{{end}}
+{{- if .Breaking}}
+
+This release contains BREAKING CHANGES.
+{{- end}}
+{{- if .Risk}}
+
+Risk: {{.Risk}}
+{{- end}}
+{{- if .AffectedTeams}}
+
+This release is cross-cutting, it changes files owned by {{range $i, $t := .AffectedTeams}}{{if $i}}, {{end}}{{$t}}{{end}}.
+{{- end}}
+{{- if .Message}}
+
+{{.Message}}
+{{- end}}
+{{- if .Changelog}}
+
+Changes:
+{{- range .Changelog}}
+  * {{.}}
+{{- end}}
+{{- end}}
+`
+
+// parseTemplate parses text, falling back to def if text is empty
+func parseTemplate(name, text, def string) (*template.Template, error) {
+	if text == "" {
+		text = def
+	}
+	return template.New(name).Parse(text)
+}
+
+func render(t *template.Template, e *Event) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, e); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
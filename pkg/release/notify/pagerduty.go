@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// PagerDutyChangeURL is the Events API v2 endpoint for change events
+const PagerDutyChangeURL = "https://events.pagerduty.com/v2/change/enqueue"
+
+// DefaultChangeSummary is the summary responders see next to incidents
+const DefaultChangeSummary = `{{.Component}} {{.Tag}} released by {{.ReleasedBy}}`
+
+// PagerDutyConfig configures the PagerDuty change event notifier
+type PagerDutyConfig struct {
+	RoutingKey    string `yaml:"routing_key"`
+	RoutingKeyEnv string `yaml:"routing_key_env"` // Environment variable holding the routing key
+	Summary       string `yaml:"summary"`         // Summary template, see DefaultChangeSummary
+	URL           string `yaml:"url"`             // Defaults to PagerDutyChangeURL
+}
+
+// PagerDuty sends a change event for every release so on-call responders can
+// correlate releases with incidents
+type PagerDuty struct {
+	*webhook
+	routingKey string
+	summary    *template.Template
+}
+
+// NewPagerDuty creates a PagerDuty notifier
+func NewPagerDuty(cfg PagerDutyConfig) (*PagerDuty, error) {
+	key := cfg.RoutingKey
+	if cfg.RoutingKeyEnv != "" {
+		key = keyring.Getenv(cfg.RoutingKeyEnv)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("a routing_key or routing_key_env (that is set) is required")
+	}
+	url := cfg.URL
+	if url == "" {
+		url = PagerDutyChangeURL
+	}
+	summary, err := parseTemplate("summary", cfg.Summary, DefaultChangeSummary)
+	if err != nil {
+		return nil, err
+	}
+	w, err := newWebhook(WebhookConfig{URL: url})
+	if err != nil {
+		return nil, err
+	}
+	return &PagerDuty{webhook: w, routingKey: key, summary: summary}, nil
+}
+
+// Name returns the name of the notifier
+func (n *PagerDuty) Name() string {
+	return "pagerduty"
+}
+
+// Notify enqueues a change event, the changelog goes into the custom details
+func (n *PagerDuty) Notify(e *Event) error {
+	summary, err := render(n.summary, e)
+	if err != nil {
+		return err
+	}
+	// PagerDuty truncates summaries at 1024 characters
+	if len(summary) > 1024 {
+		summary = summary[:1024]
+	}
+	return n.post(map[string]interface{}{
+		"routing_key": n.routingKey,
+		"payload": map[string]interface{}{
+			"summary":   summary,
+			"timestamp": e.Date.Format(time.RFC3339),
+			"source":    e.Remote,
+			"custom_details": map[string]interface{}{
+				"tag":         e.Tag,
+				"component":   e.Component,
+				"released_by": e.ReleasedBy,
+				"message":     e.Message,
+				"changelog":   e.Changelog,
+			},
+		},
+	})
+}
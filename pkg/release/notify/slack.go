@@ -0,0 +1,33 @@
+package notify
+
+import "fmt"
+
+// Slack posts release announcements to a Slack incoming webhook
+type Slack struct {
+	*webhook
+}
+
+// NewSlack creates a Slack notifier
+func NewSlack(cfg WebhookConfig) (*Slack, error) {
+	w, err := newWebhook(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{w}, nil
+}
+
+// Name returns the name of the notifier
+func (n *Slack) Name() string {
+	return "slack"
+}
+
+// Notify posts the event as a simple mrkdwn message
+func (n *Slack) Notify(e *Event) error {
+	title, body, err := n.render(e)
+	if err != nil {
+		return err
+	}
+	return n.post(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", title, body),
+	})
+}
@@ -0,0 +1,45 @@
+package notify
+
+// Teams posts release announcements as an Adaptive Card to a Microsoft Teams
+// incoming webhook
+type Teams struct {
+	*webhook
+}
+
+// NewTeams creates a Teams notifier
+func NewTeams(cfg WebhookConfig) (*Teams, error) {
+	w, err := newWebhook(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Teams{w}, nil
+}
+
+// Name returns the name of the notifier
+func (n *Teams) Name() string {
+	return "teams"
+}
+
+// Notify posts the event as an Adaptive Card with the title and body as text
+// blocks
+func (n *Teams) Notify(e *Event) error {
+	title, body, err := n.render(e)
+	if err != nil {
+		return err
+	}
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.2",
+		"body": []map[string]interface{}{
+			{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "wrap": true},
+			{"type": "TextBlock", "text": body, "wrap": true},
+		},
+	}
+	return n.post(map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/fernferret/release/internal/keyring"
+)
+
+// WebhookConfig configures any of the chat notifiers that post to an incoming
+// webhook (Slack, Teams, Discord)
+type WebhookConfig struct {
+	URL    string `yaml:"url"`
+	URLEnv string `yaml:"url_env"` // Environment variable holding the URL, webhook URLs are secrets
+	Title  string `yaml:"title"`   // Title template, see DefaultSubject
+	Body   string `yaml:"body"`    // Body template, see DefaultBody
+}
+
+// webhook holds what all of the webhook drivers share, they only differ in
+// the payload they post
+type webhook struct {
+	url    string
+	title  *template.Template
+	body   *template.Template
+	client *http.Client
+}
+
+func newWebhook(cfg WebhookConfig) (*webhook, error) {
+	url := cfg.URL
+	if cfg.URLEnv != "" {
+		url = keyring.Getenv(cfg.URLEnv)
+	}
+	if url == "" {
+		return nil, fmt.Errorf("a url or url_env (that is set) is required")
+	}
+	title, err := parseTemplate("title", cfg.Title, DefaultSubject)
+	if err != nil {
+		return nil, err
+	}
+	body, err := parseTemplate("body", cfg.Body, DefaultBody)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook{
+		url:    url,
+		title:  title,
+		body:   body,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (w *webhook) render(e *Event) (string, string, error) {
+	title, err := render(w.title, e)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := render(w.body, e)
+	return title, body, err
+}
+
+func (w *webhook) post(payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, msg)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package release
+
+import "strings"
+
+// The object formats of repositories, see ObjectFormat
+const (
+	ObjectFormatSHA1   = "sha1"
+	ObjectFormatSHA256 = "sha256"
+)
+
+// ObjectFormat returns the hash the repository names objects with, from
+// extensions.objectFormat (git init --object-format=sha256)
+func (r *Manager) ObjectFormat() string {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return ObjectFormatSHA1
+	}
+	if format := strings.ToLower(cfg.Raw.Section("extensions").Option("objectformat")); format != "" {
+		return format
+	}
+	return ObjectFormatSHA1
+}
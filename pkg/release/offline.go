@@ -0,0 +1,47 @@
+package release
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+)
+
+// ErrOffline is returned by everything that needs the network in offline mode
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+var offline bool
+
+// GoOffline disables network access for the rest of the process. Git
+// transports other than file:// and every HTTP request made through the
+// default transport (forges, webhooks, the Go proxy) fail with ErrOffline.
+// It can't be undone.
+func GoOffline() {
+	offline = true
+	for _, scheme := range []string{"http", "https", "ssh", "git"} {
+		client.InstallProtocol(scheme, offlineTransport{})
+	}
+	http.DefaultTransport = offlineRoundTripper{}
+}
+
+// Offline reports whether GoOffline was called
+func Offline() bool {
+	return offline
+}
+
+type offlineTransport struct{}
+
+func (offlineTransport) NewUploadPackSession(*transport.Endpoint, transport.AuthMethod) (transport.UploadPackSession, error) {
+	return nil, ErrOffline
+}
+
+func (offlineTransport) NewReceivePackSession(*transport.Endpoint, transport.AuthMethod) (transport.ReceivePackSession, error) {
+	return nil, ErrOffline
+}
+
+type offlineRoundTripper struct{}
+
+func (offlineRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, ErrOffline
+}
@@ -0,0 +1,216 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// DefaultOIDCAudience is the audience requested for the CI job's ID token
+const DefaultOIDCAudience = "release"
+
+// DefaultOIDCTokenEnv is where GitLab CI has the ID token, from an id_tokens
+// entry in .gitlab-ci.yml
+const DefaultOIDCTokenEnv = "RELEASE_ID_TOKEN"
+
+// OIDCConfig exchanges the CI job's OIDC ID token for short-lived
+// credentials, so no long-lived secrets have to be stored in CI
+type OIDCConfig struct {
+	// Audience is requested from GitHub Actions, GitLab sets it in the
+	// id_tokens entry. Defaults to release.
+	Audience string `yaml:"audience"`
+	// TokenEnv holds the ID token on GitLab (and anything else that exports
+	// one), defaults to RELEASE_ID_TOKEN
+	TokenEnv string `yaml:"token_env"`
+	// ExchangeURL is an OAuth 2.0 token exchange (RFC 8693) endpoint that
+	// swaps the ID token for a forge token used for pushes and API calls
+	ExchangeURL string `yaml:"exchange_url"`
+	Scope       string `yaml:"scope"`
+	// Username goes with the token for https pushes, x-access-token (GitHub)
+	// or oauth2 (GitLab) if empty
+	Username string `yaml:"username"`
+	// TokenFile gets the ID token itself for cloud SDKs and signing tools,
+	// AWS_WEB_IDENTITY_TOKEN_FILE points at it for the commands release runs
+	TokenFile string `yaml:"token_file"`
+	// Host is the forge the exchanged token is for, it's only sent to https
+	// remotes there. Defaults to the host of the origin remote.
+	Host string `yaml:"host"`
+}
+
+// Enabled says whether anything is done with the ID token
+func (c *OIDCConfig) Enabled() bool {
+	return c.ExchangeURL != "" || c.TokenFile != ""
+}
+
+func (c *OIDCConfig) validate() error {
+	if c.ExchangeURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.ExchangeURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("oidc.exchange_url %s isn't an http(s) url", c.ExchangeURL)
+	}
+	return nil
+}
+
+// CICredential is what the CI job's ID token was exchanged for
+type CICredential struct {
+	IDToken string
+	Token   string // Empty without an exchange_url
+	Expires time.Time
+	Host    string // The only host the token is sent to, see OIDCConfig.Host
+}
+
+// IsFor says whether the token may be sent to the remote url, an http(s) url
+// on its host
+func (c *CICredential) IsFor(remoteURL string) bool {
+	u, err := url.Parse(remoteURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	return c.Host != "" && strings.EqualFold(u.Hostname(), c.Host)
+}
+
+// Auth returns the token as https credentials for the remote url, check IsFor
+// first
+func (c *CICredential) Auth(cfg OIDCConfig, remoteURL string) transport.AuthMethod {
+	user := cfg.Username
+	if user == "" {
+		user = "x-access-token"
+		if strings.Contains(remoteURL, "gitlab") {
+			user = "oauth2"
+		}
+	}
+	return &githttp.BasicAuth{Username: user, Password: c.Token}
+}
+
+// CIIDToken returns the OIDC ID token of the CI job: requested from GitHub
+// Actions, read from the environment elsewhere
+func CIIDToken(cfg OIDCConfig) (string, error) {
+	audience := cfg.Audience
+	if audience == "" {
+		audience = DefaultOIDCAudience
+	}
+	if reqURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"); reqURL != "" {
+		return githubIDToken(reqURL, os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"), audience)
+	}
+	env := cfg.TokenEnv
+	if env == "" {
+		env = DefaultOIDCTokenEnv
+	}
+	if t := os.Getenv(env); t != "" {
+		return t, nil
+	}
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		return "", fmt.Errorf("the job can't request an ID token, it needs 'permissions: id-token: write'")
+	}
+	return "", fmt.Errorf("there is no ID token in %s, add an id_tokens entry for it to the job", env)
+}
+
+func githubIDToken(reqURL, reqToken, audience string) (string, error) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("audience", audience)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+reqToken)
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(req, &body); err != nil {
+		return "", fmt.Errorf("failed to request an ID token from GitHub Actions: %s", err)
+	}
+	if body.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned an empty ID token")
+	}
+	return body.Value, nil
+}
+
+// OIDCCredential gets the CI job's ID token, writes it to the token file and
+// exchanges it for a forge token, as configured
+func OIDCCredential(cfg OIDCConfig) (*CICredential, error) {
+	if offline {
+		return nil, fmt.Errorf("OIDC credentials need the network")
+	}
+	idToken, err := CIIDToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cred := &CICredential{IDToken: idToken}
+	if cfg.TokenFile != "" {
+		if err := ioutil.WriteFile(cfg.TokenFile, []byte(idToken), 0600); err != nil {
+			return nil, err
+		}
+		if os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") == "" {
+			os.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", cfg.TokenFile)
+		}
+	}
+	if cfg.ExchangeURL == "" {
+		return cred, nil
+	}
+	form := url.Values{
+		"grant_type":         {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":      {idToken},
+		"subject_token_type": {"urn:ietf:params:oauth:token-type:jwt"},
+	}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	req, err := http.NewRequest("POST", cfg.ExchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// access_token is RFC 8693, token is what most forge token brokers return
+	var body struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(req, &body); err != nil {
+		return nil, fmt.Errorf("failed to exchange the ID token at %s: %s", cfg.ExchangeURL, err)
+	}
+	cred.Token = body.AccessToken
+	if cred.Token == "" {
+		cred.Token = body.Token
+	}
+	if cred.Token == "" {
+		return nil, fmt.Errorf("%s didn't return a token", cfg.ExchangeURL)
+	}
+	if body.ExpiresIn > 0 {
+		cred.Expires = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return cred, nil
+}
+
+func doJSON(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,23 @@
+package release
+
+import "testing"
+
+func TestCICredentialIsFor(t *testing.T) {
+	cred := &CICredential{Token: "token", Host: "github.com"}
+	for remote, want := range map[string]bool{
+		"https://github.com/fernferret/release.git":   true,
+		"https://GitHub.com/fernferret/release.git":   true,
+		"http://github.com:8080/fernferret/release":   true,
+		"https://mirror.example.com/release.git":      false,
+		"https://github.com.example.com/release.git":  false,
+		"git@github.com:fernferret/release.git":       false,
+		"ssh://git@github.com/fernferret/release.git": false,
+	} {
+		if got := cred.IsFor(remote); got != want {
+			t.Errorf("%s: got %v, want %v", remote, got, want)
+		}
+	}
+	if (&CICredential{Token: "token"}).IsFor("https://github.com/fernferret/release.git") {
+		t.Errorf("a token without a host should not be sent anywhere")
+	}
+}
@@ -0,0 +1,21 @@
+package release
+
+import "fmt"
+
+// Scheme is the format new release versions are created in, existing tags of
+// every scheme are always understood
+type Scheme string
+
+// The version schemes
+const (
+	SchemeMonthly Scheme = "YYYY.MM.RRR"
+	SchemeOrdinal Scheme = "YYYY.DDD.N" // Year, day of year and increment
+)
+
+func (s Scheme) validate() error {
+	switch s {
+	case "", SchemeMonthly, SchemeOrdinal:
+		return nil
+	}
+	return fmt.Errorf("scheme must be %s or %s, not '%s'", SchemeMonthly, SchemeOrdinal, s)
+}
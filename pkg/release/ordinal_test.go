@@ -0,0 +1,26 @@
+package release
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProposedReleaseOrdinal(t *testing.T) {
+	rm, _ := newTestManager(t, "2020.200.1-api", "2020.200.2-api", "2020.07.009-api")
+	rm.Scheme = SchemeOrdinal
+	day := time.Date(2020, time.July, 18, 12, 0, 0, 0, time.UTC) // Day 200
+	proposal, err := rm.GetProposedReleaseAt("api", day, ResetMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.TagName != "2020.200.3-api" {
+		t.Errorf("got %s, want 2020.200.3-api", proposal.TagName)
+	}
+	proposal, err = rm.GetProposedReleaseAt("api", day.AddDate(0, 0, 1), ResetMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.TagName != "2020.201.1-api" {
+		t.Errorf("got %s, want 2020.201.1-api", proposal.TagName)
+	}
+}
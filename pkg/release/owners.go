@@ -0,0 +1,148 @@
+package release
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CodeOwnersFiles are the places CODEOWNERS is looked for, in order
+var CodeOwnersFiles = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners maps paths to their owners, like GitHub and GitLab do
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners parses a CODEOWNERS file. GitLab sections ([Section]) are
+// ignored, their rules count like any other.
+func ParseCodeOwners(data []byte) *CodeOwners {
+	co := &CodeOwners{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		owners := []string{}
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "#") {
+				break
+			}
+			owners = append(owners, f)
+		}
+		co.rules = append(co.rules, codeOwnersRule{pattern: codeOwnersPattern(fields[0]), owners: owners})
+	}
+	return co
+}
+
+// codeOwnersPattern turns a gitignore style pattern into a regular expression
+// matching the pattern itself and everything below it
+func codeOwnersPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(/|$)")
+	return regexp.MustCompile(b.String())
+}
+
+// Owners returns the owners of a path (relative to the root of the
+// repository), the last matching rule wins
+func (co *CodeOwners) Owners(name string) []string {
+	name = strings.Trim(name, "/")
+	for idx := len(co.rules) - 1; idx >= 0; idx-- {
+		if co.rules[idx].pattern.MatchString(name) {
+			return co.rules[idx].owners
+		}
+	}
+	return nil
+}
+
+// LoadOwnership sets up who owns each component, used by Teams. Components
+// with a team in the config belong to it, the rest belong to the CODEOWNERS
+// owners of their paths.
+func (r *Manager) LoadOwnership(components map[string]ComponentConfig) error {
+	r.components = components
+	r.codeOwners = nil
+	for _, name := range CodeOwnersFiles {
+		data, err := r.readRepoFile(name)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		r.codeOwners = ParseCodeOwners(data)
+		return nil
+	}
+	return nil
+}
+
+// Teams returns the teams owning a component (pre-release markers are
+// ignored), sorted. It's empty when LoadOwnership wasn't called or nobody
+// owns the component.
+func (r *Manager) Teams(component string) []string {
+	component, _ = splitPreRelease(component)
+	cfg, ok := r.components[component]
+	if ok && cfg.Team != "" {
+		return []string{cfg.Team}
+	}
+	if r.codeOwners == nil {
+		return nil
+	}
+	paths := cfg.Paths
+	if len(paths) == 0 {
+		// A component without paths owns the whole repository
+		paths = []string{"."}
+	}
+	seen := map[string]bool{}
+	teams := []string{}
+	for _, p := range paths {
+		for _, owner := range r.codeOwners.Owners(p) {
+			if !seen[owner] {
+				seen[owner] = true
+				teams = append(teams, owner)
+			}
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// TeamMatches reports whether one of the owners is the given team. Teams
+// match by their full name (@org/payments) or just the team part (payments).
+func TeamMatches(owners []string, team string) bool {
+	team = strings.TrimPrefix(team, "@")
+	for _, owner := range owners {
+		owner = strings.TrimPrefix(owner, "@")
+		if strings.EqualFold(owner, team) || strings.EqualFold(owner[strings.LastIndex(owner, "/")+1:], team) {
+			return true
+		}
+	}
+	return false
+}
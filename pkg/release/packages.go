@@ -0,0 +1,144 @@
+package release
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/fernferret/release/internal/ecosystem"
+)
+
+// PackagesConfig lists the package manifests whose version has to match the
+// released tag
+type PackagesConfig struct {
+	// Policy is warn (default), block or bump. Bump rewrites the manifests and
+	// commits them before tagging.
+	Policy string        `yaml:"policy"`
+	Files  []PackageFile `yaml:"files"`
+}
+
+// PackageFile is a package manifest belonging to a component
+type PackageFile struct {
+	Component string `yaml:"component"`
+	Type      string `yaml:"type"` // npm or pypi
+	Path      string `yaml:"path"`
+}
+
+func (c PackagesConfig) validate() error {
+	switch c.Policy {
+	case "", "warn", "block", "bump":
+	default:
+		return fmt.Errorf("packages policy must be warn, block or bump, not '%s'", c.Policy)
+	}
+	for _, f := range c.Files {
+		if _, err := ecosystem.Get(f.Type); err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// PackageVersion is the version found in a package manifest compared with the
+// version it should have
+type PackageVersion struct {
+	File     PackageFile
+	Found    string
+	Expected string
+	manifest []byte
+}
+
+// InSync reports whether the manifest already has the expected version
+func (p *PackageVersion) InSync() bool {
+	return p.Found == p.Expected
+}
+
+// PackageVersions checks the manifests of the component being released against
+// the (not yet created) tag
+func (r *Manager) PackageVersions(cfg PackagesConfig, tag string) ([]*PackageVersion, error) {
+	version, ok := parseCalVer(r.tagTemplate, tag)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't a CalVer tag", tag)
+	}
+	rel := r.ParseRelease(tag)
+	v := ecosystem.Version{Year: int(version.Year), Month: int(version.Month), Day: int(version.Day), Release: int(version.Release), PreRelease: rel.PreRelease()}
+	versions := []*PackageVersion{}
+	for _, f := range cfg.Files {
+		if f.Component != rel.Component() {
+			continue
+		}
+		eco, err := ecosystem.Get(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := r.readRepoFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		found, err := eco.Version(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		versions = append(versions, &PackageVersion{File: f, Found: found, Expected: eco.Format(v), manifest: manifest})
+	}
+	return versions, nil
+}
+
+// BumpPackages writes the expected versions into the manifests that are out of
+// sync and commits them, so the tag lands on a commit with matching versions.
+// This needs a working directory, it doesn't work on clones made with --repo-url.
+func (r *Manager) BumpPackages(versions []*PackageVersion, message, user, email string) error {
+	if r.noWorktree {
+		return fmt.Errorf("package versions can only be bumped in a checkout")
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	paths := []string{}
+	for _, p := range versions {
+		if p.InSync() {
+			continue
+		}
+		eco, _ := ecosystem.Get(p.File.Type)
+		manifest, err := eco.SetVersion(p.manifest, p.Expected)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.File.Path, err)
+		}
+		f, err := wt.Filesystem.Create(p.File.Path)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(manifest)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		paths = append(paths, p.File.Path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return r.CommitFiles(paths, message, user, email)
+}
+
+// CommitFiles commits the given paths (relative to the repository root) as
+// they are in the working directory
+func (r *Manager) CommitFiles(paths []string, message, user, email string) error {
+	sig := object.Signature{Name: user, Email: email, When: time.Now()}
+	if h := r.history(); h != nil {
+		return h.commitFiles(paths, sig, message)
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if _, err := wt.Add(path); err != nil {
+			return err
+		}
+	}
+	_, err = wt.Commit(message, &git.CommitOptions{Author: &sig, Committer: &sig})
+	return err
+}
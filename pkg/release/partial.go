@@ -0,0 +1,70 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// PromisorRemote returns the remote the missing objects of a partial clone
+// (git clone --filter=...) come from, empty if the repository isn't one
+func (r *Manager) PromisorRemote() string {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return ""
+	}
+	for _, sub := range cfg.Raw.Section("remote").Subsections {
+		if strings.EqualFold(sub.Option("promisor"), "true") {
+			return sub.Name
+		}
+	}
+	// Partial clones made by git before 2.30 only set the extension
+	return cfg.Raw.Section("extensions").Option("partialclone")
+}
+
+// ensureObjects makes sure the objects are in the repository. go-git doesn't
+// know about partial clones so the missing ones are fetched from the promisor
+// remote by git, the way git itself fetches them on demand.
+func (r *Manager) ensureObjects(hashes ...plumbing.Hash) error {
+	missing := []string{}
+	for _, hash := range hashes {
+		if hash.IsZero() {
+			continue
+		}
+		if err := r.repo.Storer.HasEncodedObject(hash); err == plumbing.ErrObjectNotFound {
+			missing = append(missing, hash.String())
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	remote := r.PromisorRemote()
+	if remote == "" || r.repoDir == "" {
+		return fmt.Errorf("%d object(s) are missing from the repository, %s first: %w", len(missing), missing[0], plumbing.ErrObjectNotFound)
+	}
+	if offline {
+		return fmt.Errorf("fetching %d object(s) missing from the partial clone: %w", len(missing), ErrOffline)
+	}
+	log.Debug().Msgf("fetching %d object(s) missing from the partial clone from %s", len(missing), remote)
+	// Explicitly wanted objects are sent despite the filter, which keeps
+	// trees from bringing their blobs along
+	cmd := exec.Command("git", "-c", "fetch.negotiationAlgorithm=noop", "fetch", remote,
+		"--no-tags", "--recurse-submodules=no", "--filter=blob:none", "--stdin")
+	cmd.Dir = r.repoDir
+	cmd.Stdin = strings.NewReader(strings.Join(missing, "\n") + "\n")
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to fetch %d missing object(s) from %s: %s", len(missing), remote, strings.TrimSpace(stderr.String()))
+	}
+	// go-git only looks for packs once
+	if storage, ok := r.repo.Storer.(*filesystem.Storage); ok {
+		storage.Reindex()
+	}
+	return nil
+}
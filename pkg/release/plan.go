@@ -0,0 +1,171 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"gopkg.in/yaml.v2"
+)
+
+// Plan is a reviewed list of releases to create, written by 'release plan'
+// (or by hand) and executed by 'release apply'
+type Plan struct {
+	Releases []PlannedRelease `yaml:"releases"`
+}
+
+// PlannedRelease is a single release in a plan
+type PlannedRelease struct {
+	Component string `yaml:"component"`
+	// Tag is the tag that was reviewed, applying fails if the next release
+	// of the component would be something else by now. Computed if empty.
+	Tag string `yaml:"tag,omitempty"`
+	// Target is the commit or ref to tag, HEAD if empty
+	Target  string `yaml:"target,omitempty"`
+	Message string `yaml:"message,omitempty"`
+	// Notes are the release notes the message was drafted from, kept as
+	// they are when a summarizer wrote the message
+	Notes []string `yaml:"notes,omitempty"`
+	// Upstream is the release of a dependency this release cascaded from,
+	// the component itself didn't change
+	Upstream string `yaml:"upstream,omitempty"`
+	// Channel is recorded as a Release-Channel trailer (stable, beta, ...)
+	Channel string `yaml:"channel,omitempty"`
+}
+
+// ChannelTrailer records a planned release's channel in the tag annotation
+const ChannelTrailer = "Release-Channel"
+
+// ParsePlan parses a yaml plan
+func ParsePlan(data []byte) (*Plan, error) {
+	plan := &Plan{}
+	if err := yaml.UnmarshalStrict(data, plan); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for idx, p := range plan.Releases {
+		if p.Component == "" {
+			return nil, fmt.Errorf("release %d in the plan has no component", idx+1)
+		}
+		if seen[p.Component] {
+			return nil, fmt.Errorf("component %s is in the plan more than once", p.Component)
+		}
+		seen[p.Component] = true
+	}
+	return plan, nil
+}
+
+// LoadPlan reads a plan file
+func LoadPlan(path string) (*Plan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlan(data)
+}
+
+// Marshal renders the plan as yaml
+func (p *Plan) Marshal() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// ApplyOptions configures how a plan is applied
+type ApplyOptions struct {
+	User, Email string
+	Kind        TagKind
+	Now         time.Time // The release date, usually the train date
+	Increments  IncrementConfig
+	// Remote is pushed to when set, all tags go in a single push
+	Remote string
+	Auth   transport.AuthMethod
+	// Components configured with semver get their SemVer tag in the same
+	// transaction
+	Components map[string]ComponentConfig
+}
+
+// AppliedRelease is a release created by ApplyPlan
+type AppliedRelease struct {
+	Component string
+	Tag       string
+	Target    string // The full hash of the commit
+	Message   string
+	// SemVerTag is created next to Tag for components configured with
+	// semver
+	SemVerTag string
+}
+
+// resolvePlanned checks a planned release against the repository and returns the tag
+// and commit it will create
+func (r *Manager) resolvePlanned(p PlannedRelease, opts ApplyOptions) (*AppliedRelease, error) {
+	target := p.Target
+	if target == "" {
+		target = "HEAD"
+	}
+	hash, err := r.ResolveCommit(target)
+	if err != nil {
+		return nil, Classify(fmt.Sprintf("resolving target %s of %s", target, p.Component), err)
+	}
+	proposal, err := r.GetProposedReleaseAt(p.Component, opts.Now, opts.Increments.ResetPolicy(p.Component))
+	if err != nil {
+		return nil, err
+	}
+	if p.Tag != "" && p.Tag != proposal.TagName {
+		return nil, fmt.Errorf("the plan releases %s but the next release of %s is %s now, the plan is stale", p.Tag, p.Component, proposal.TagName)
+	}
+	message := p.Message
+	if p.Channel != "" {
+		message = AppendTrailer(message, ChannelTrailer, p.Channel)
+	}
+	applied := &AppliedRelease{Component: p.Component, Tag: proposal.TagName, Target: hash, Message: message}
+	if c := opts.Components[p.Component]; c.SemVer != nil {
+		if applied.SemVerTag, err = r.NextSemVerTag(c, hash); err != nil {
+			return nil, fmt.Errorf("failed to work out the SemVer tag of %s: %w", p.Component, err)
+		}
+	}
+	return applied, nil
+}
+
+// ApplyPlan creates (and pushes) every release in the plan as a single
+// transaction. Everything is checked before the first tag is created, and if
+// creating or pushing any tag fails the tags created so far are deleted again.
+func (r *Manager) ApplyPlan(plan *Plan, opts ApplyOptions) ([]*AppliedRelease, error) {
+	applied := []*AppliedRelease{}
+	for _, p := range plan.Releases {
+		a, err := r.resolvePlanned(p, opts)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+
+	created := []string{}
+	rollback := func(cause error) error {
+		for _, tag := range created {
+			if err := r.DeleteTag(tag); err != nil {
+				return fmt.Errorf("%w (and failed to roll back tag %s: %s)", cause, tag, err)
+			}
+		}
+		return cause
+	}
+	for _, a := range applied {
+		for _, tag := range []string{a.Tag, a.SemVerTag} {
+			if tag == "" {
+				continue
+			}
+			if _, err := r.CreateTagAt(tag, a.Target, a.Message, opts.User, opts.Email, opts.Kind); err != nil {
+				return nil, rollback(Classify("creating tag "+tag, err))
+			}
+			created = append(created, tag)
+		}
+	}
+
+	if opts.Remote != "" && len(created) > 0 {
+		msg, err := r.PushTagToRemoteWithOptions(created[0], opts.Remote, opts.Auth, PushOptions{RefSpecs: TagRefSpecs(created...)})
+		if err != nil {
+			return nil, rollback(fmt.Errorf("%s: %w", msg, err))
+		}
+	}
+	r.Reload()
+	return applied, nil
+}
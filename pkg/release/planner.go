@@ -0,0 +1,273 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog/log"
+)
+
+// PlanOptions configures DraftPlan
+type PlanOptions struct {
+	Now        time.Time // The release date, usually the train date
+	Increments IncrementConfig
+	// Components are the configured components, components that were
+	// released before but aren't configured own the whole repository
+	Components map[string]ComponentConfig
+	// Only limits the plan to these components when not empty
+	Only []string
+	// Target is the full hash of the commit released instead of HEAD when
+	// set
+	Target string
+	// NoCascade leaves out the components that depend on a changed one but
+	// didn't change themselves, see Dependencies
+	NoCascade bool
+}
+
+// DraftPlan proposes a release for every component that changed since its
+// last release. Releases target the current HEAD commit (or opts.Target) and
+// their messages are draft release notes, ready to be reviewed and passed to
+// ApplyPlan.
+func (r *Manager) DraftPlan(opts PlanOptions) (*Plan, error) {
+	target := opts.Target
+	if target == "" {
+		head, err := r.releaseHead()
+		if err != nil {
+			return nil, err
+		}
+		target = head
+	}
+	plan := &Plan{Releases: []PlannedRelease{}}
+	for _, component := range r.planComponents(opts) {
+		changelog, err := r.unreleased(component, opts, target)
+		if err != nil {
+			return nil, err
+		}
+		if changelog == nil {
+			continue
+		}
+		plan.Releases = append(plan.Releases, PlannedRelease{
+			Component: component,
+			Tag:       changelog.Next,
+			Target:    target,
+			Message:   noteList(changelog.Notes),
+			Notes:     changelog.Notes,
+		})
+	}
+	if opts.NoCascade || len(plan.Releases) == 0 {
+		return plan, nil
+	}
+	return plan, r.cascade(plan, opts, target)
+}
+
+// cascade adds a release for every component depending on one in the plan
+// that isn't in it yet, so nothing ships against an untagged dependency
+func (r *Manager) cascade(plan *Plan, opts PlanOptions, target string) error {
+	deps, err := r.Dependencies(opts.Components)
+	if err != nil {
+		return fmt.Errorf("failed to read the component dependencies: %w", err)
+	}
+	planned := map[string]string{}
+	changed := []string{}
+	for _, p := range plan.Releases {
+		planned[p.Component] = p.Tag
+		changed = append(changed, p.Component)
+	}
+	downstream := Downstream(deps, changed)
+	changelogs := map[string]*ComponentChangelog{}
+	for _, component := range sortedKeys(downstream) {
+		// Components left out with opts.Only may have changes of their own
+		changelog, err := r.unreleased(component, opts, target)
+		if err != nil {
+			return err
+		}
+		if changelog == nil {
+			proposal, err := r.GetProposedReleaseAt(component, opts.Now, opts.Increments.ResetPolicy(component))
+			if err != nil {
+				return err
+			}
+			changelog = &ComponentChangelog{Component: component, Next: proposal.TagName}
+		}
+		changelogs[component] = changelog
+		planned[component] = changelog.Next
+	}
+	for _, component := range sortedKeys(downstream) {
+		changelog := changelogs[component]
+		upstream := planned[downstream[component]]
+		log.Debug().Msgf("%s cascades to %s", upstream, component)
+		notes := append(changelog.Notes, fmt.Sprintf("Depends on %s, released as %s", downstream[component], upstream))
+		plan.Releases = append(plan.Releases, PlannedRelease{
+			Component: component,
+			Tag:       changelog.Next,
+			Target:    target,
+			Message:   noteList(notes),
+			Notes:     notes,
+			Upstream:  upstream,
+		})
+	}
+	return nil
+}
+
+// noteList renders release notes as a markdown list
+func noteList(notes []string) string {
+	lines := []string{}
+	for _, note := range notes {
+		lines = append(lines, "- "+note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unreleased returns the changes of the component between its last release
+// and head, nil if there are none
+func (r *Manager) unreleased(component string, opts PlanOptions, head string) (*ComponentChangelog, error) {
+	proposal, err := r.GetProposedReleaseAt(component, opts.Now, opts.Increments.ResetPolicy(component))
+	if err != nil {
+		return nil, err
+	}
+	since := ""
+	if prev := r.FindRelease(proposal.PreviousRelease); prev != nil {
+		since = prev.Hash
+	}
+	commits, err := r.commitsBetween(since, head)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := r.componentCommits(commits, opts.Components[component])
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		log.Debug().Msgf("%s has no changes since %s", component, proposal.PreviousRelease)
+		return nil, nil
+	}
+	return &ComponentChangelog{
+		Component: component,
+		Previous:  proposal.PreviousRelease,
+		Next:      proposal.TagName,
+		Commits:   changes,
+		Notes:     ReleaseNotes(changes),
+	}, nil
+}
+
+// planComponents returns the components DraftPlan looks at, sorted
+func (r *Manager) planComponents(opts PlanOptions) []string {
+	if len(opts.Only) > 0 {
+		components := append([]string{}, opts.Only...)
+		sort.Strings(components)
+		return components
+	}
+	seen := map[string]bool{}
+	for name := range opts.Components {
+		seen[name] = true
+	}
+	for _, rel := range r.releases {
+		if rel.Component() != "" && !rel.IsPreRelease() {
+			seen[rel.Component()] = true
+		}
+	}
+	components := []string{}
+	for name := range seen {
+		components = append(components, name)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// componentCommits returns the commits that touch the component's paths
+func (r *Manager) componentCommits(commits []*Commit, component ComponentConfig) ([]*Commit, error) {
+	if len(component.Paths) == 0 {
+		return commits, nil
+	}
+	hashes := []string{}
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash)
+	}
+	changed, err := r.changedFiles(hashes)
+	if err != nil {
+		return nil, err
+	}
+	matching := []*Commit{}
+	for _, c := range commits {
+		for _, name := range changed[c.Hash] {
+			if component.Owns(name) {
+				matching = append(matching, c)
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+// changedFiles returns the files each commit changed compared to its first
+// parent (every file for root commits), by commit. Only trees are compared,
+// in partial clones the missing ones are fetched at once.
+func (r *Manager) changedFiles(hashes []string) (map[string][]string, error) {
+	if h := r.history(); h != nil {
+		return h.changedFiles(hashes)
+	}
+	commits := []*object.Commit{}
+	trees := []plumbing.Hash{}
+	for _, hash := range hashes {
+		c, err := r.repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, c)
+		trees = append(trees, c.TreeHash)
+		if c.NumParents() > 0 {
+			parent, err := c.Parent(0)
+			if err != nil {
+				return nil, err
+			}
+			trees = append(trees, parent.TreeHash)
+		}
+	}
+	if err := r.ensureObjects(trees...); err != nil {
+		return nil, err
+	}
+	changed := map[string][]string{}
+	for _, c := range commits {
+		files, err := commitFiles(c)
+		if err != nil {
+			return nil, err
+		}
+		changed[c.Hash.String()] = files
+	}
+	return changed, nil
+}
+
+// commitFiles returns the files a commit changed compared to its first
+// parent, every file for root commits
+func commitFiles(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
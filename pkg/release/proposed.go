@@ -0,0 +1,45 @@
+package release
+
+import "time"
+
+// ProposedRelease is the next release of a component, with everything callers
+// would otherwise have to parse back out of the tag name
+type ProposedRelease struct {
+	Version         string       `json:"version"`                    // 2020.07.003
+	Component       string       `json:"component"`                  // api, without any pre-release marker
+	PreRelease      string       `json:"pre_release,omitempty"`      // rc.1
+	TagName         string       `json:"tag"`                        // 2020.07.003-api-rc.1
+	PreviousRelease string       `json:"previous_release,omitempty"` // The release the changelog starts from
+	TargetCommit    string       `json:"target_commit"`              // HEAD, what the tag will point to
+	Explanation     *Explanation `json:"-"`                          // How the version was computed
+}
+
+// GetProposedRelease proposes the next release for name (a component, maybe
+// with a pre-release marker, or empty)
+func (r *Manager) GetProposedRelease(name string) (*ProposedRelease, error) {
+	return r.GetProposedReleaseAt(name, time.Now(), ResetMonthly)
+}
+
+// GetProposedReleaseAt is GetProposedRelease at the given time and with the
+// given reset policy
+func (r *Manager) GetProposedReleaseAt(name string, t time.Time, policy ResetPolicy) (*ProposedRelease, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return nil, err
+	}
+	e := r.explainNext("", t, policy)
+	p := &ProposedRelease{
+		Version:      e.Version,
+		TagName:      e.Version,
+		TargetCommit: head,
+		Explanation:  e,
+	}
+	if name != "" {
+		p.TagName = e.next.FormatRelease(r.tagTemplate, name)
+		p.Component, p.PreRelease = splitPreRelease(name)
+	}
+	if prev := r.PreviousRelease(p.TagName); prev != nil {
+		p.PreviousRelease = prev.Tag
+	}
+	return p, nil
+}
@@ -0,0 +1,180 @@
+package release
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// go-git dials ssh through golang.org/x/net/proxy, which reads ALL_PROXY and
+// NO_PROXY and only knows socks5. Teach it http(s) CONNECT proxies and
+// ProxyCommand so corporate proxies work for ssh remotes too.
+func init() {
+	proxy.RegisterDialerType("http", newConnectDialer)
+	proxy.RegisterDialerType("https", newConnectDialer)
+	proxy.RegisterDialerType(proxyCommandScheme, func(*url.URL, proxy.Dialer) (proxy.Dialer, error) {
+		return commandDialer{}, nil
+	})
+}
+
+// UseProxy sends everything through the proxy: git over https, forge APIs,
+// webhooks and the Go module proxy (HTTP_PROXY/HTTPS_PROXY) as well as ssh
+// remotes (ALL_PROXY). http, https and socks5 proxies work. noProxy lists
+// hosts that are reached directly, like NO_PROXY. It has to be called before
+// anything touches the network, the environment is only read once.
+func UseProxy(proxyURL, noProxy string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("proxy url %s must be http://, https:// or socks5://", proxyURL)
+	}
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY"} {
+		os.Setenv(name, proxyURL)
+	}
+	if noProxy != "" {
+		os.Setenv("NO_PROXY", noProxy)
+	}
+	return nil
+}
+
+const proxyCommandScheme = "release-proxy-command"
+
+var sshProxyCommand string
+
+// UseSSHProxyCommand connects to ssh remotes through a command like ssh's
+// ProxyCommand, %h and %p are replaced with the host and port. The command's
+// stdin and stdout are the connection. Hosts in NO_PROXY are still reached
+// directly.
+func UseSSHProxyCommand(command string) {
+	sshProxyCommand = command
+	os.Setenv("ALL_PROXY", proxyCommandScheme+"://")
+}
+
+type commandDialer struct{}
+
+func (commandDialer) Dial(network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	command := strings.NewReplacer("%h", host, "%p", port, "%%", "%").Replace(sshProxyCommand)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting ssh proxy command: %w", err)
+	}
+	return &commandConn{cmd: cmd, Reader: stdout, WriteCloser: stdin, addr: addr}, nil
+}
+
+// commandConn is a connection over a proxy command's stdin and stdout
+type commandConn struct {
+	cmd *exec.Cmd
+	io.Reader
+	io.WriteCloser
+	addr string
+}
+
+func (c *commandConn) Close() error {
+	c.WriteCloser.Close()
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return commandAddr("proxy-command") }
+func (c *commandConn) RemoteAddr() net.Addr { return commandAddr(c.addr) }
+
+// Deadlines aren't supported by pipes, ssh doesn't need them
+func (c *commandConn) SetDeadline(time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(time.Time) error { return nil }
+
+type commandAddr string
+
+func (a commandAddr) Network() string { return "proxy-command" }
+func (a commandAddr) String() string  { return string(a) }
+
+// connectDialer tunnels connections through an http(s) proxy with CONNECT
+type connectDialer struct {
+	proxy   *url.URL
+	forward proxy.Dialer
+}
+
+func newConnectDialer(u *url.URL, forward proxy.Dialer) (proxy.Dialer, error) {
+	return &connectDialer{proxy: u, forward: forward}, nil
+}
+
+func (d *connectDialer) Dial(network, addr string) (net.Conn, error) {
+	host := d.proxy.Host
+	if d.proxy.Port() == "" {
+		host = net.JoinHostPort(d.proxy.Hostname(), map[string]string{"http": "80", "https": "443"}[d.proxy.Scheme])
+	}
+	conn, err := d.forward.Dial(network, host)
+	if err != nil {
+		return nil, err
+	}
+	if d.proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: d.proxy.Hostname()})
+	}
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if d.proxy.User != nil {
+		password, _ := d.proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// The server may talk first (ssh does), anything read past the response
+	// stays in the buffered reader
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", d.proxy.Host, addr, resp.Status)
+	}
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,86 @@
+package release
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// ParseAge parses a duration that also accepts days (90d) and weeks (2w) on
+// top of everything time.ParseDuration understands
+func ParseAge(age string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(age, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(age, suffix), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid age '%s'", age)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(age)
+}
+
+// PrunablePreReleases returns the pre-release tags that were released more
+// than olderThan before now and are superseded, meaning a final release of
+// the same component with the same or a newer version exists. Final releases
+// are never returned.
+func (r *Manager) PrunablePreReleases(olderThan time.Duration, now time.Time) []Release {
+	// Newest final version per component
+	finals := map[string]*calVerStandard{}
+	for _, rel := range r.releases {
+		version, ok := parseCalVer(r.tagTemplate, rel.Tag)
+		if !ok || rel.IsPreRelease() {
+			continue
+		}
+		if latest, ok := finals[rel.Component()]; !ok || version.Compare(latest) > 0 {
+			finals[rel.Component()] = version
+		}
+	}
+
+	cutoff := now.Add(-olderThan)
+	prunable := []Release{}
+	for _, rel := range r.releases {
+		version, ok := parseCalVer(r.tagTemplate, rel.Tag)
+		if !ok || !rel.IsPreRelease() || !rel.ReleasedBy().When.Before(cutoff) {
+			continue
+		}
+		if final, ok := finals[rel.Component()]; ok && final.Compare(version) >= 0 {
+			prunable = append(prunable, rel)
+		}
+	}
+	return prunable
+}
+
+// DeleteTag deletes a local tag
+func (r *Manager) DeleteTag(tag string) error {
+	name := plumbing.NewTagReferenceName(tag)
+	if _, err := r.gitBackend().Reference(name); err == plumbing.ErrReferenceNotFound {
+		return git.ErrTagNotFound
+	} else if err != nil {
+		return err
+	}
+	return r.gitBackend().DeleteReference(name)
+}
+
+// DeleteRemoteTags deletes the given tags from the remote in a single push
+func (r *Manager) DeleteRemoteTags(tags []string, remote string, auth transport.AuthMethod) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	refSpecs := []config.RefSpec{}
+	for _, tag := range tags {
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf(":refs/tags/%s", tag)))
+	}
+	_, err := r.push(&git.PushOptions{RemoteName: remote, RefSpecs: refSpecs, Auth: auth})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return Classify("deleting tags from "+remote, err)
+}
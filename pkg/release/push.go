@@ -0,0 +1,136 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// progressPat matches the progress counters remotes send while pushing
+// ("Resolving deltas: 100% (3/3), done."), they're dropped from the messages
+var progressPat = regexp.MustCompile(`^[A-Za-z ]+: +\d+% \(\d+/\d+\)`)
+
+// remoteOutput collects what a remote prints while pushing, the lines git
+// shows as "remote: ..."
+type remoteOutput struct {
+	buf bytes.Buffer
+	// tee gets the output as it arrives, for Manager.PushProgress
+	tee io.Writer
+}
+
+func (o *remoteOutput) Write(p []byte) (int, error) {
+	if o.tee != nil {
+		o.tee.Write(p)
+	}
+	return o.buf.Write(p)
+}
+
+// Messages returns the lines the remote sent, without progress counters
+func (o *remoteOutput) Messages() []string {
+	messages := []string{}
+	for _, line := range strings.Split(o.buf.String(), "\n") {
+		// Progress counters overwrite themselves with \r, keep the last one
+		if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || progressPat.MatchString(line) {
+			continue
+		}
+		messages = append(messages, line)
+	}
+	return messages
+}
+
+// Why a remote rejected a push, a RemoteError matches one of these with
+// errors.Is. All of them are also ErrPushRejected.
+var (
+	ErrPushRejected      = errors.New("the remote rejected the push")
+	ErrProtectedTag      = errors.New("the remote protects the tag")
+	ErrSignatureRequired = errors.New("the remote requires signatures")
+	ErrTagNameBlocked    = errors.New("the remote doesn't allow the tag name")
+)
+
+// rejections maps what remotes (GitHub rulesets and protected tags, GitLab
+// protected tags and push rules, custom pre-receive hooks) say when they
+// reject a push to why they did, checked in order
+var rejections = []struct {
+	reason   error
+	patterns []string
+}{
+	{ErrSignatureRequired, []string{"verified signature", "must be signed", "signature required", "signed tags", "unsigned", "gpg signature", "signature is required"}},
+	{ErrTagNameBlocked, []string{"does not match", "doesn't match", "must match", "tag name pattern", "not allowed by push rules", "naming convention", "invalid tag name"}},
+	{ErrProtectedTag, []string{"protected tag", "is protected", "repository rule violations", "creations being restricted", "not allowed to create", "not allowed to push", "deny updating"}},
+	{ErrPushRejected, []string{"hook declined", "[remote rejected]", "rejected"}},
+}
+
+// rejectionReason returns why the remote rejected a push, nil if it isn't a
+// rejection (a network problem, bad credentials, ...)
+func rejectionReason(err error, messages []string) error {
+	text := strings.ToLower(err.Error() + "\n" + strings.Join(messages, "\n"))
+	for _, r := range rejections {
+		for _, p := range r.patterns {
+			if strings.Contains(text, p) {
+				return r.reason
+			}
+		}
+	}
+	return nil
+}
+
+// RemoteError is a failed push with what the remote said about it, like the
+// output of a pre-receive hook that rejected it
+type RemoteError struct {
+	Err      error
+	Messages []string
+	// Reason is why the remote rejected the push, see ErrPushRejected, nil
+	// if it didn't
+	Reason error
+}
+
+func (e *RemoteError) Error() string {
+	if len(e.Messages) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s, the remote said: %s", e.Err, strings.Join(e.Messages, "; "))
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the reason of the rejection and ErrPushRejected for any
+// rejection
+func (e *RemoteError) Is(target error) bool {
+	return e.Reason != nil && (target == e.Reason || target == ErrPushRejected)
+}
+
+// push pushes and returns the messages of the remote. Failed pushes are
+// returned as a RemoteError when the remote said anything or rejected them.
+func (r *Manager) push(options *git.PushOptions) ([]string, error) {
+	output := &remoteOutput{tee: r.PushProgress}
+	options.Progress = output
+	err := r.gitBackend().Push(options)
+	messages := output.Messages()
+	if err == nil || err == git.NoErrAlreadyUpToDate {
+		return messages, err
+	}
+	if reason := rejectionReason(err, messages); reason != nil || len(messages) > 0 {
+		return messages, &RemoteError{Err: err, Messages: messages, Reason: reason}
+	}
+	return messages, err
+}
+
+// formatRemoteMessages appends the messages of the remote to msg the way git
+// shows them
+func formatRemoteMessages(msg string, messages []string) string {
+	for _, m := range messages {
+		msg += "\nremote: " + m
+	}
+	return msg
+}
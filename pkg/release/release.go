@@ -0,0 +1,824 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cactus/gostrftime"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/openpgp"
+)
+
+// CheckIfError checks if the given error is nil, if not it prints a message and
+// exits
+func CheckIfError(err error, msg string) {
+	if err == nil {
+		return
+	}
+
+	if hint := Hint(Classify(msg, err)); hint != "" {
+		log.Fatal().Err(err).Str("hint", hint).Msg(msg)
+	}
+	log.Fatal().Err(err).Msg(msg)
+}
+
+// Release represents a release
+type Release struct {
+	Tag            string            // The human readable name of the tag
+	Hash           string            // The hash of the git commit that the tag points to
+	ReleaseMessage string            // This is the tag message, might not be present
+	CommitMessage  string            // The commit message should always be present
+	Author         object.Signature  // The author of the tag
+	Committer      object.Signature  // The committer (person who merged/ran git commit)
+	Tagger         *object.Signature // The person who created a proper tag (will be nil for lightweight tags)
+	parsed         *tagInfo          // The parsed Tag, see info
+	ref            string            // The short name of the ref, when it isn't Tag
+	planned        bool              // Made up by PlanVersions, there's no tag
+	changes        []PathChange      // See Manager.LoadChanges
+}
+
+// RefName returns the short name of the ref the release was loaded from. It's
+// the tag name unless an annotated tag was stored under another name.
+func (r *Release) RefName() string {
+	if r.ref != "" {
+		return r.ref
+	}
+	return r.Tag
+}
+
+// Date returns the date of when the commit the tag points to happened
+func (r *Release) Date() time.Time {
+	return r.Committer.When
+}
+
+// ReleasedBy returns who the tag was released by. It looks at the Tagger first,
+// if nil, it defaults to the Committer
+func (r *Release) ReleasedBy() object.Signature {
+	if r.Tagger != nil {
+		return *r.Tagger
+	}
+	return r.Committer
+}
+
+// ReleasedByString gives a nice printable string of who performed the release
+func (r *Release) ReleasedByString(includeDate bool) string {
+	relBy := r.ReleasedBy()
+	if includeDate {
+		return fmt.Sprintf("%s <%s> on %s", relBy.Name, relBy.Email, gostrftime.Format("%Y-%m-%d %H:%M:%S", relBy.When))
+	}
+	return fmt.Sprintf("%s <%s>", relBy.Name, relBy.Email)
+}
+
+// Component returns the component that was released, this is empty if the tag
+// isn't a CalVer tag or doesn't include a component. Any pre-release marker is
+// not part of the component.
+func (r *Release) Component() string {
+	return r.info().component
+}
+
+// PreRelease returns the pre-release marker of the tag (rc.1 for
+// 2020.07.001-api-rc.1), empty for final releases
+func (r *Release) PreRelease() string {
+	return r.info().pre
+}
+
+// IsPreRelease returns true for rc/beta/alpha/pre tags
+func (r *Release) IsPreRelease() bool {
+	return r.PreRelease() != ""
+}
+
+// Message returns a friendly messaage for the commit, it uses the tagged
+// message if that's available and defaults to the commit message
+func (r *Release) Message() string {
+	if r.ReleaseMessage != "" {
+		return r.ReleaseMessage
+	}
+	return strings.SplitN(r.CommitMessage, "\n", 1)[0]
+}
+
+type releaseList []Release
+
+func (s releaseList) Len() int {
+	return len(s)
+}
+func (s releaseList) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s releaseList) Less(i, j int) bool {
+	if s[i].Date().Equal(s[j].Date()) {
+		return strings.Compare(s[i].Tag, s[j].Tag) == -1
+	}
+	return s[i].Date().After(s[j].Date())
+}
+
+// SortReleases sorts releases the way Releases returns them, newest first
+func SortReleases(releases []Release) {
+	sort.Sort(releaseList(releases))
+}
+
+// Manager is responsible for keeping the state required to perform releases
+type Manager struct {
+	// Git Items
+	repoDir             string // Empty if the manager was created from a repo
+	cwd                 string
+	noWorktree          bool   // Files are read from HEAD, see CloneManager
+	tempDir             string // Removed by Close
+	target              string // Released instead of HEAD, see UseTarget
+	repo                *git.Repository
+	worktrees           map[string]string // Checkouts by commit, removed by Close
+	tagTemplate         *TagTemplate      // See UseTagTemplate, the built-in format if nil
+	releases            releaseList
+	timeFmt             string
+	incFmt              string
+	AlwaysIncludeNumber bool
+	Scheme              Scheme // The version scheme new tags use, YYYY.MM.RRR if empty
+	// SignKey signs every tag created, signed tags are always annotated
+	SignKey *openpgp.Entity
+	// PushProgress gets everything remotes print while pushing as it arrives,
+	// the messages are also returned with push results and errors
+	PushProgress io.Writer
+	// ForgeToken is used by Forge when there is no token in the environment,
+	// like the short-lived token from OIDCCredential
+	ForgeToken string
+	// Links makes links of the commits, issues and comparisons in markdown
+	// release notes, see NoteLinker
+	Links *NoteLinker
+	// Tags have to be signed by one of these keys to be used when proposing
+	// versions, see SetTrustedKeys
+	keyring  string
+	verified map[string]verification
+	// Component ownership, see LoadOwnership
+	components map[string]ComponentConfig
+	codeOwners *CodeOwners
+	store      MetadataStore // See UseMetadata, the repository itself if nil
+	backend    GitBackend    // See UseBackend, go-git if nil
+	// Release tags skipped by the last load, see TagProblems
+	tagProblems []TagProblem
+	mailmap     *Mailmap // From HEAD, see loadMailmap
+}
+
+// FindRepoDir finds a git repository directory in the current or any parent
+// directory
+func FindRepoDir(path string) (string, error) {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return path, fmt.Errorf("no git directory found in any parent directory")
+	}
+	gitPath := filepath.Join(path, ".git")
+	if _, err := os.Stat(gitPath); os.IsNotExist(err) {
+		return FindRepoDir(filepath.Dir(path))
+	}
+	return path, nil
+}
+
+// NewManager creates a new release manager with a given directory
+func NewManager(cwd, timeFmt, incFmt string) (*Manager, error) {
+	repoDir, err := FindRepoDir(cwd)
+	log.Debug().Msgf("searching for git directory in: %s", cwd)
+	CheckIfError(err, "failed to find repo dir")
+	r, err := git.PlainOpen(repoDir)
+	CheckIfError(err, "failed to load git repository")
+
+	mgr := &Manager{
+		repoDir: repoDir,
+		cwd:     cwd,
+		repo:    r,
+		timeFmt: timeFmt,
+		incFmt:  incFmt,
+	}
+	// The backend has to be picked before tags are read through it, a broken
+	// config is reported by the commands that need the rest of it
+	cfg, err := mgr.LoadConfig()
+	if err != nil {
+		log.Debug().Err(err).Msgf("failed to load %s, using the default git backend", ConfigFile)
+		cfg = &Config{}
+	}
+	if err := mgr.UseBackend(cfg.Git); err != nil {
+		return nil, err
+	}
+	if err := mgr.UseTagTemplate(cfg.TagTemplate); err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("using the %s backend", mgr.GitBackend())
+	mgr.loadGitTags()
+	return mgr, nil
+}
+
+// NewManagerFromRepo creates a new release manager from an already opened
+// repository, this allows using in-memory storage or billy filesystems (for
+// servers, tests or freshly cloned repos) instead of a path on disk
+func NewManagerFromRepo(repo *git.Repository, timeFmt, incFmt string) (*Manager, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("a repository is required")
+	}
+	mgr := &Manager{
+		repo:    repo,
+		timeFmt: timeFmt,
+		incFmt:  incFmt,
+	}
+	if err := mgr.useConfiguredTagTemplate(); err != nil {
+		return nil, err
+	}
+	mgr.loadGitTags()
+	return mgr, nil
+}
+
+func tagToRefspec(tag string) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag))
+}
+
+// RepoDir returns the root of the working directory, empty for repositories
+// that only exist in memory
+func (r *Manager) RepoDir() string {
+	return r.repoDir
+}
+
+// CheckRemote performs a basic existence check on the remote and returns an
+// error if there is a problem
+func (r *Manager) CheckRemote(remote string) error {
+	_, err := r.repo.Remote(remote)
+	return err
+}
+
+// PushOptions customizes how a tag is pushed by PushTagToRemoteWithOptions
+type PushOptions struct {
+	// RefSpecs replaces the default refs/tags/<tag>:refs/tags/<tag> refspec
+	RefSpecs []config.RefSpec
+	// Force allows moving a tag that already exists on the remote. Anyone who
+	// already fetched the tag will keep the old one, so this has to be asked
+	// for explicitly.
+	Force bool
+}
+
+// ErrTagMoved is returned when a push would move a tag that already exists on
+// the remote and points somewhere else
+var ErrTagMoved = errors.New("tag already exists on the remote and points to a different object")
+
+// ErrForceRefSpec is returned when a force refspec (+src:dst) is pushed
+// without PushOptions.Force
+var ErrForceRefSpec = errors.New("refspec forces an update but force was not requested")
+
+// PushTagToRemote pushes the given local tag to the remote repository returns a
+// message to be displayed to the user along with an an optional error, If err
+// is nil, the operation was successful
+func (r *Manager) PushTagToRemote(tag, remote string, auth transport.AuthMethod) (string, error) {
+	return r.PushTagToRemoteWithOptions(tag, remote, auth, PushOptions{})
+}
+
+// PushTagToRemoteWithOptions is the same as PushTagToRemote but allows custom
+// refspecs and forcing. Without Force, any refspec that would move an existing
+// remote tag (or is a force refspec) is rejected.
+func (r *Manager) PushTagToRemoteWithOptions(tag, remote string, auth transport.AuthMethod, opts PushOptions) (string, error) {
+	// Copied, forcing adds a + to the caller's refspecs
+	refSpecs := append([]config.RefSpec{}, opts.RefSpecs...)
+	if len(refSpecs) == 0 {
+		refSpecs = []config.RefSpec{tagToRefspec(tag)}
+	}
+	for idx, rs := range refSpecs {
+		if err := rs.Validate(); err != nil {
+			return fmt.Sprintf("invalid refspec %s", rs), err
+		}
+		if rs.IsForceUpdate() && !opts.Force {
+			return fmt.Sprintf("refspec %s forces an update but force was not requested", rs), ErrForceRefSpec
+		}
+		if opts.Force && !rs.IsForceUpdate() {
+			refSpecs[idx] = config.RefSpec("+" + string(rs))
+		}
+	}
+
+	if opts.Force {
+		log.Warn().Msgf("FORCE pushing tag %s to remote %s, if the tag already exists it will be moved and anyone who fetched it will have a different release than the remote", tag, remote)
+	} else if err := r.checkRemoteTags(remote, auth, refSpecs); err != nil {
+		return fmt.Sprintf("refusing to push tag %s to remote %s, use force to move it", tag, remote), Classify(fmt.Sprintf("checking tag %s on remote %s", tag, remote), err)
+	}
+
+	options := &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   refSpecs,
+		Auth:       auth,
+	}
+	messages, err := r.push(options)
+	if err == git.NoErrAlreadyUpToDate {
+		return fmt.Sprintf("nothing pushed, tag %s already existed and was up to date in remote %s", tag, remote), nil
+	} else if err != nil {
+		return fmt.Sprintf("failed to push tag %s to remote %s", tag, remote), Classify(fmt.Sprintf("pushing tag %s to remote %s", tag, remote), err)
+	}
+	return formatRemoteMessages(fmt.Sprintf("pushed tag %s to remote %s", tag, remote), messages), err
+}
+
+// checkRemoteTags makes sure none of the refspecs would move a ref that already
+// exists on the remote
+func (r *Manager) checkRemoteTags(remote string, auth transport.AuthMethod, refSpecs []config.RefSpec) error {
+	remoteRefs, err := r.remoteReferences(remote, auth)
+	if err != nil {
+		return err
+	}
+	for _, rs := range refSpecs {
+		if rs.IsWildcard() || rs.IsDelete() {
+			continue
+		}
+		src := plumbing.ReferenceName(rs.Src())
+		local, err := r.gitBackend().Reference(src)
+		if err != nil {
+			return err
+		}
+		dst := rs.Dst(src)
+		for _, ref := range remoteRefs {
+			if ref.Name == dst && ref.Hash != local {
+				return fmt.Errorf("%w: %s", ErrTagMoved, dst)
+			}
+		}
+	}
+	return nil
+}
+
+// Reload reloads the releases from the repository, for long running callers
+// that create or fetch tags
+func (r *Manager) Reload() {
+	r.loadGitTags()
+}
+
+// Releases returns all loaded releases, newest first
+func (r *Manager) Releases() []Release {
+	releases := make([]Release, len(r.releases))
+	copy(releases, r.releases)
+	return releases
+}
+
+func (r *Manager) loadGitTags() {
+	r.releases = releaseList{}
+	r.tagProblems = nil
+	loader, ok := r.gitBackend().(releaseLoader)
+	if !ok {
+		r.loadTagRefs()
+	} else if releases, problems, err := loader.loadReleases(r.tagTemplate); err != nil {
+		log.Warn().Err(err).Msg("failed to read the release tags at once, reading them one by one")
+		r.loadTagRefs()
+	} else {
+		r.releases = releases
+		r.tagProblems = problems
+	}
+	r.loadMailmap()
+	r.mapReleases()
+	sort.Sort(r.releases)
+	if len(r.tagProblems) > 0 {
+		log.Warn().Msgf("skipped %d broken release tag(s), run 'release fsck-tags' for details", len(r.tagProblems))
+	}
+}
+
+// loadTagRefs loads the releases ref by ref, through the index if there is
+// one
+func (r *Manager) loadTagRefs() {
+	tagrefs, err := r.gitBackend().Refs("refs/tags/")
+	CheckIfError(err, "failed to load lightweight tags")
+	// Tags that didn't move since they were indexed aren't loaded again
+	indexed := map[string]indexedRelease{}
+	index, err := r.openIndex(false)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to open %s, reading every tag", IndexFile)
+	} else if index != nil {
+		defer index.close()
+		if indexed, err = index.load(r.tagTemplate); err != nil {
+			log.Warn().Err(err).Msgf("failed to read %s, reading every tag", IndexFile)
+			indexed = map[string]indexedRelease{}
+		}
+	}
+	loaded := []indexedRelease{}
+	for _, t := range tagrefs {
+		ref := t.Name.String()
+		if ir, ok := indexed[ref]; ok {
+			delete(indexed, ref)
+			if ir.target == t.Hash {
+				r.releases = append(r.releases, ir.rel)
+				continue
+			}
+		}
+		// Only releases are worth loading the objects of
+		info := parseTagInfo(r.tagTemplate, ref[len("refs/tags/"):])
+		if info.version == nil {
+			log.Debug().Msgf("skipping tag %s, it isn't a release", info.tag)
+			continue
+		}
+		newRelease, err := r.loadTag(t, info)
+		if err != nil {
+			log.Debug().Err(err).Msgf("skipping tag %s", info.tag)
+			r.tagProblems = append(r.tagProblems, TagProblem{Tag: info.tag, Target: t.Hash, Problem: err.Error()})
+			continue
+		}
+		r.releases = append(r.releases, newRelease)
+		loaded = append(loaded, indexedRelease{ref: ref, target: t.Hash, rel: newRelease})
+		if e := log.Debug(); e.Enabled() {
+			e.Str("hash", newRelease.Hash).Str("releaser", newRelease.ReleasedByString(true)).Msgf("loaded tag: %s", newRelease.Tag)
+		}
+	}
+	if index != nil {
+		// What's left in indexed are deleted tags
+		gone := []string{}
+		for ref := range indexed {
+			gone = append(gone, ref)
+		}
+		if err := index.update(loaded, gone); err != nil {
+			log.Warn().Err(err).Msgf("failed to update %s", IndexFile)
+		}
+	}
+}
+
+// loadTag reads the release of a tag ref from its objects, the error says
+// what's wrong with tags that can't be loaded. info is the parsed ref name.
+func (r *Manager) loadTag(t Ref, info *tagInfo) (Release, error) {
+	newRelease := Release{Tag: info.tag}
+	tag, obj, err := r.resolveTag(t.Hash)
+	if err != nil {
+		return newRelease, err
+	}
+	if tag != nil {
+		newRelease.Tag = tag.Name
+		newRelease.ReleaseMessage = tag.Message
+		newRelease.Tagger = &tag.Tagger
+		if tag.Name != info.tag {
+			newRelease.ref = info.tag
+		}
+	}
+	newRelease.Hash = obj.Hash
+	newRelease.CommitMessage = obj.Message
+	newRelease.Author = obj.Author
+	newRelease.Committer = obj.Committer
+	newRelease.parsed = info
+	if newRelease.Tag != info.tag {
+		newRelease.parsed = parseTagInfo(r.tagTemplate, newRelease.Tag)
+	}
+	return newRelease, nil
+}
+
+// TagKind decides between annotated and lightweight tags
+type TagKind int
+
+// The tag kinds, TagAuto annotates tags that have a message
+const (
+	TagAuto TagKind = iota
+	TagAnnotated
+	TagLightweight
+)
+
+// CreateTag creates a tag in the repo, if comment is specified it creates an
+// annotated tag
+func (r *Manager) CreateTag(name, comment, user, email string) (*Ref, error) {
+	return r.CreateTagOfKind(name, comment, user, email, TagAuto)
+}
+
+// CreateTagOfKind is CreateTag with the annotation decided by kind instead of
+// the message. Annotated tags without a message use the tag name as the
+// message, lightweight tags drop the message.
+func (r *Manager) CreateTagOfKind(name, comment, user, email string, kind TagKind) (*Ref, error) {
+	head, err := r.releaseHead()
+	if err != nil {
+		return nil, err
+	}
+	return r.CreateTagAt(name, head, comment, user, email, kind)
+}
+
+// CreateTagAt is CreateTagOfKind for any commit instead of HEAD. Creating a
+// tag that exists, even one created concurrently by another release, fails
+// with an *AlreadyExistsError. hash is the full hash of the commit.
+func (r *Manager) CreateTagAt(name, hash, comment, user, email string, kind TagKind) (*Ref, error) {
+	if kind == TagLightweight {
+		if r.SignKey != nil {
+			return nil, fmt.Errorf("signed tags can't be lightweight")
+		}
+		return r.createTagRef(name, hash)
+	}
+	if comment == "" && (r.SignKey != nil || kind == TagAnnotated) {
+		// Signatures live in the tag object so signed tags can't be lightweight
+		comment = name
+	}
+	if comment == "" {
+		return r.createTagRef(name, hash)
+	}
+	if user == "" || email == "" {
+		msg := "both user and email are required when specifying a message, something might be wrong with your ~/.gitconfig or you didn't specify --name and --email"
+		log.Fatal().Str(
+			"name", user,
+		).Str(
+			"email", email,
+		).Msg(msg)
+	}
+	if existing, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(name)); err == nil {
+		// Don't bother writing (and signing) a tag object that can't be used
+		return nil, &AlreadyExistsError{Tag: name, Hash: existing}
+	}
+	tagger := object.Signature{
+		Name:  user,
+		Email: email,
+		When:  time.Now(),
+	}
+	target, err := r.createTagObject(name, hash, comment, tagger)
+	if err != nil {
+		return nil, err
+	}
+	return r.createTagRef(name, target)
+}
+
+// calVerPatterns are the tag formats we know how to read, in order of
+// preference. The first one is what this tool creates, the rest are adjacent
+// formats that already exist in repos we've adopted so we don't need to
+// re-tag history. Every pattern has a year, month, (possibly empty) release and
+// (possibly empty) component group.
+var calVerPatterns = []*regexp.Regexp{
+	// 2020.07.001-release (what we create)
+	regexp.MustCompile(`^(?P<year>\d{4})\.(?P<month>\d{2})\.(?P<release>\d{3,})(?:-(?P<component>.*))?$`),
+	// 2020.7.1 (unpadded)
+	regexp.MustCompile(`^(?P<year>\d{4})\.(?P<month>\d{1,2})\.(?P<release>\d+)(?:-(?P<component>.*))?$`),
+	// 2020-07-001
+	regexp.MustCompile(`^(?P<year>\d{4})-(?P<month>\d{2})-(?P<release>\d{3,})(?:-(?P<component>.*))?$`),
+	// 2020.07 (no increment, treated as release 0)
+	regexp.MustCompile(`^(?P<year>\d{4})\.(?P<month>\d{2})(?P<release>)(?:-(?P<component>.*))?$`),
+}
+
+// ordinalPattern is the YYYY.DDD.N scheme (day of year), it's kept apart from
+// calVerPatterns since the middle group is a day rather than a month
+var ordinalPattern = regexp.MustCompile(`^(?P<year>\d{4})\.(?P<day>\d{3})\.(?P<release>\d+)(?:-(?P<component>.*))?$`)
+
+// tagInfo is what's parsed out of a tag name. Releases keep it so the patterns
+// run once per tag rather than on every Component or PreRelease call.
+type tagInfo struct {
+	tag       string
+	version   *calVerStandard // nil if the tag isn't a CalVer tag, don't modify
+	component string          // Without the pre-release marker
+	pre       string
+	template  *TagTemplate // The tag was read with, nil for the built-in formats
+}
+
+func parseTagInfo(t *TagTemplate, tag string) *tagInfo {
+	version, component, _ := splitTag(t, tag)
+	info := &tagInfo{tag: tag, version: version, template: t}
+	info.component, info.pre = splitPreRelease(component)
+	return info
+}
+
+// info returns the parsed tag, parsing it again if Tag was changed
+func (r *Release) info() *tagInfo {
+	if r.parsed == nil {
+		r.parsed = parseTagInfo(nil, r.Tag)
+	} else if r.parsed.tag != r.Tag {
+		r.parsed = parseTagInfo(r.parsed.template, r.Tag)
+	}
+	return r.parsed
+}
+
+// looksLikeCalVer is a cheap check every known pattern passes, four digits
+// followed by a . or -, so most other tags never reach the regexes
+func looksLikeCalVer(tag string) bool {
+	if len(tag) < 7 {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		if tag[i] < '0' || tag[i] > '9' {
+			return false
+		}
+	}
+	return tag[4] == '.' || tag[4] == '-'
+}
+
+// parseStandard reads the YYYY.MM.RRR[-component] tags this tool creates
+// without a regex, it matches exactly what the first of calVerPatterns does
+// (whose month and ordinal day lengths rule each other out)
+func parseStandard(tag string) (*calVerStandard, string, bool) {
+	if len(tag) < 11 || tag[4] != '.' || tag[7] != '.' {
+		return nil, "", false
+	}
+	digits := func(s string) (uint64, bool) {
+		var n uint64
+		for i := 0; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return 0, false
+			}
+			n = n*10 + uint64(s[i]-'0')
+		}
+		return n, true
+	}
+	year, ok := digits(tag[:4])
+	if !ok {
+		return nil, "", false
+	}
+	month, ok := digits(tag[5:7])
+	if !ok || month < 1 || month > 12 {
+		return nil, "", false
+	}
+	end := strings.IndexByte(tag[8:], '-')
+	component := ""
+	if end < 0 {
+		end = len(tag)
+	} else {
+		component = tag[8+end+1:]
+		end += 8
+	}
+	if end-8 < 3 {
+		return nil, "", false
+	}
+	relNum, ok := digits(tag[8:end])
+	if !ok {
+		return nil, "", false
+	}
+	return newCalVerStandard(year, month, relNum), component, true
+}
+
+// parseCalVer tries each of the known patterns against the given tag and
+// returns the parsed version, the second value is false if the tag isn't a
+// CalVer tag we understand
+func parseCalVer(t *TagTemplate, tag string) (*calVerStandard, bool) {
+	version, _, ok := splitTag(t, tag)
+	return version, ok
+}
+
+// splitTag is the same as parseCalVer but also returns the component (the part
+// after the version) of the tag. Tags are read with the tag_template t first
+// when there is one.
+func splitTag(t *TagTemplate, tag string) (*calVerStandard, string, bool) {
+	if t != nil {
+		if version, component, ok := t.parse(tag); ok {
+			return version, component, true
+		}
+	}
+	if !looksLikeCalVer(tag) {
+		return nil, "", false
+	}
+	if version, component, ok := parseStandard(tag); ok {
+		return version, component, true
+	}
+	if results := ordinalPattern.FindStringSubmatch(tag); results != nil {
+		year, _ := strconv.ParseUint(results[1], 10, 64)
+		day, _ := strconv.ParseUint(results[2], 10, 64)
+		relNum, _ := strconv.ParseUint(results[3], 10, 64)
+		if version := newOrdinalCalVer(year, day, relNum); version != nil {
+			return version, results[4], true
+		}
+	}
+	for _, pat := range calVerPatterns {
+		results := pat.FindStringSubmatch(tag)
+		if results == nil {
+			continue
+		}
+		year, _ := strconv.ParseUint(results[1], 10, 64)
+		month, _ := strconv.ParseUint(results[2], 10, 64)
+		if month < 1 || month > 12 {
+			continue
+		}
+		var relNum uint64
+		if results[3] != "" {
+			relNum, _ = strconv.ParseUint(results[3], 10, 64)
+		}
+		return newCalVerStandard(year, month, relNum), results[4], true
+	}
+	return nil, "", false
+}
+
+// preReleasePat matches a pre-release marker after the component, like
+// api-rc.1 or api-beta2. Components named rc, beta, ... are components.
+var preReleasePat = regexp.MustCompile(`^(.+)-((?:rc|beta|alpha|pre)(?:[.-]?\d+)?)$`)
+
+// tagComponent returns the component of a tag without any pre-release marker
+func tagComponent(t *TagTemplate, tag string) string {
+	_, component, _ := splitTag(t, tag)
+	component, _ = splitPreRelease(component)
+	return component
+}
+
+// splitPreRelease splits a component into the component itself and its
+// pre-release marker
+func splitPreRelease(component string) (string, string) {
+	results := preReleasePat.FindStringSubmatch(component)
+	if results == nil {
+		return component, ""
+	}
+	return results[1], results[2]
+}
+
+type calVerStandard struct {
+	Year    uint64
+	Month   uint64
+	Day     uint64 // Day of the year for YYYY.DDD.N versions, 0 otherwise
+	Release uint64
+}
+
+func newCalVerStandard(year, month, rel uint64) *calVerStandard {
+	return &calVerStandard{
+		Year:    year,
+		Month:   month,
+		Release: rel,
+	}
+}
+
+// newOrdinalCalVer creates a YYYY.DDD.N version, the month is filled in from
+// the day so it still compares with monthly versions. It returns nil for days
+// that don't exist in the year.
+func newOrdinalCalVer(year, day, rel uint64) *calVerStandard {
+	date := time.Date(int(year), time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(day)-1)
+	if day < 1 || date.Year() != int(year) {
+		return nil
+	}
+	return &calVerStandard{
+		Year:    year,
+		Month:   uint64(date.Month()),
+		Day:     day,
+		Release: rel,
+	}
+}
+
+// IsOrdinal returns true for YYYY.DDD.N versions
+func (c *calVerStandard) IsOrdinal() bool {
+	return c.Day != 0
+}
+
+func (c *calVerStandard) String() string {
+	return fmt.Sprintf("Release: %d.%02d.%03d", c.Year, c.Month, c.Release)
+}
+
+// FormatRelease names the tag of the version, using the tag_template t if
+// there is one for the version's scheme
+func (c *calVerStandard) FormatRelease(t *TagTemplate, release string) string {
+	if t != nil && t.ordinal == c.IsOrdinal() {
+		if name, err := t.format(c, release); err == nil {
+			return name
+		}
+	}
+	if c.IsOrdinal() {
+		if release == "" {
+			return fmt.Sprintf("%d.%03d.%d", c.Year, c.Day, c.Release)
+		}
+		return fmt.Sprintf("%d.%03d.%d-%s", c.Year, c.Day, c.Release, release)
+	}
+	if release == "" {
+		return fmt.Sprintf("%d.%02d.%03d", c.Year, c.Month, c.Release)
+	}
+	return fmt.Sprintf("%d.%02d.%03d-%s", c.Year, c.Month, c.Release, release)
+}
+
+// Compare returns -1, 0 or 1 if c is older, the same or newer than other
+func (c *calVerStandard) Compare(other *calVerStandard) int {
+	// Monthly versions have no day so they sort before the ordinal versions of
+	// the same month
+	for _, pair := range [][2]uint64{{c.Year, other.Year}, {c.Month, other.Month}, {c.Day, other.Day}, {c.Release, other.Release}} {
+		if pair[0] < pair[1] {
+			return -1
+		} else if pair[0] > pair[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (c *calVerStandard) IsSameMonth(other *calVerStandard) bool {
+	return other.Year == c.Year && other.Month == c.Month
+}
+
+func (c *calVerStandard) Increase() *calVerStandard {
+	c.Release++
+	return c
+}
+
+func (r *Manager) getNextDateString(name string, now time.Time) string {
+	return r.getNextVersionString(name, now, ResetMonthly)
+}
+
+// getNextVersionString proposes the next version for the current month (or
+// day for the ordinal scheme), see explainNext
+func (r *Manager) getNextVersionString(name string, now time.Time, policy ResetPolicy) string {
+	return r.explainNext(name, now, policy).Version
+}
+
+// GetProposedName returns a proposed name for the next release tag
+func (r *Manager) GetProposedName(name string) string {
+	now := time.Now()
+	return r.getNextDateString(name, now)
+}
+
+// GetProposedDate returns a proposed name for the next release tag
+func (r *Manager) GetProposedDate() string {
+	now := time.Now()
+	return r.getNextDateString("", now)
+}
+
+// GetProposedDateWithPolicy is GetProposedDate with a different increment
+// reset policy
+func (r *Manager) GetProposedDateWithPolicy(policy ResetPolicy) string {
+	return r.GetProposedDateAt(time.Now(), policy)
+}
+
+// GetProposedDateAt proposes the next version as if it were released at the
+// given time, used for release trains
+func (r *Manager) GetProposedDateAt(t time.Time, policy ResetPolicy) string {
+	return r.getNextVersionString("", t, policy)
+}
@@ -0,0 +1,152 @@
+package release
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+	"github.com/go-git/go-git/v5/config"
+)
+
+// newTestManager builds an in-memory repository with one commit per tag
+func newTestManager(t *testing.T, tags ...string) (*Manager, *releasetest.Repo) {
+	t.Helper()
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("initial commit", map[string]string{"README": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		if err := repo.CommitAndTag(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rm, repo
+}
+
+func TestSplitTag(t *testing.T) {
+	tests := []struct {
+		tag       string
+		version   calVerStandard
+		component string
+		pre       string
+	}{
+		{"2020.07.001", calVerStandard{Year: 2020, Month: 7, Release: 1}, "", ""},
+		{"2020.07.012-api", calVerStandard{Year: 2020, Month: 7, Release: 12}, "api", ""},
+		{"2020.07.003-api-rc.1", calVerStandard{Year: 2020, Month: 7, Release: 3}, "api", "rc.1"},
+		{"2020.07.003-api-beta2", calVerStandard{Year: 2020, Month: 7, Release: 3}, "api", "beta2"},
+		// Components named like a pre-release marker are components
+		{"2020.07.003-rc", calVerStandard{Year: 2020, Month: 7, Release: 3}, "rc", ""},
+		{"2020.07.003-beta", calVerStandard{Year: 2020, Month: 7, Release: 3}, "beta", ""},
+		// Adjacent third-party formats
+		{"2020.7.1", calVerStandard{Year: 2020, Month: 7, Release: 1}, "", ""},
+		{"2020.7.1-web", calVerStandard{Year: 2020, Month: 7, Release: 1}, "web", ""},
+		{"2020-07-001-web", calVerStandard{Year: 2020, Month: 7, Release: 1}, "web", ""},
+		{"2020.07", calVerStandard{Year: 2020, Month: 7}, "", ""},
+		{"2020.07-api", calVerStandard{Year: 2020, Month: 7}, "api", ""},
+		// Ordinal YYYY.DDD.N, the month is filled in from the day
+		{"2020.200.3", calVerStandard{Year: 2020, Month: 7, Day: 200, Release: 3}, "", ""},
+		{"2020.032.1-api", calVerStandard{Year: 2020, Month: 2, Day: 32, Release: 1}, "api", ""},
+	}
+	for _, test := range tests {
+		version, _, ok := splitTag(nil, test.tag)
+		if !ok {
+			t.Errorf("%s: not parsed", test.tag)
+			continue
+		}
+		if *version != test.version {
+			t.Errorf("%s: got version %+v, want %+v", test.tag, *version, test.version)
+		}
+		rel := Release{Tag: test.tag}
+		if rel.Component() != test.component || rel.PreRelease() != test.pre {
+			t.Errorf("%s: got component %q pre-release %q, want %q %q", test.tag, rel.Component(), rel.PreRelease(), test.component, test.pre)
+		}
+	}
+}
+
+func TestSplitTagInvalid(t *testing.T) {
+	for _, tag := range []string{"v1.2.3", "release", "2020.13.001", "2020.00.001", "2019.366.1", "2020.367.1", "20.07.001", ""} {
+		if version, _, ok := splitTag(nil, tag); ok {
+			t.Errorf("%s: parsed as %+v", tag, *version)
+		}
+	}
+}
+
+func TestFormatRelease(t *testing.T) {
+	tests := map[string]string{
+		"2020.07.001-api": "2020.07.001-api",
+		"2020.7.1-api":    "2020.07.001-api",
+		"2020-07-012":     "2020.07.012",
+		"2020.200.3-api":  "2020.200.3-api",
+	}
+	for tag, want := range tests {
+		version, component, _ := splitTag(nil, tag)
+		if got := version.FormatRelease(nil, component); got != want {
+			t.Errorf("%s: formatted as %s, want %s", tag, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Oldest first
+	ordered := []string{"2019.12.009", "2020.01.001", "2020.01.002", "2020.02.001", "2020.032.1", "2020.032.2", "2020.02.010"}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseCalVer(nil, ordered[i])
+			b, _ := parseCalVer(nil, ordered[j])
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			// Monthly versions of the same month sort before ordinal ones
+			if a.IsOrdinal() != b.IsOrdinal() && a.IsSameMonth(b) {
+				continue
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%s vs %s: got %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestPushTagRefSpecs(t *testing.T) {
+	repo, err := releasetest.NewTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Cleanup()
+	remote, err := repo.NewBareRemote("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Cleanup()
+	if err := repo.CommitAndTag("2020.07.001-api"); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManager(repo.Dir, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forced := PushOptions{RefSpecs: TagRefSpecs("2020.07.001-api")}
+	forced.RefSpecs[0] = "+" + forced.RefSpecs[0]
+	if _, err := rm.PushTagToRemoteWithOptions("2020.07.001-api", "origin", nil, forced); !errors.Is(err, ErrForceRefSpec) {
+		t.Errorf("a force refspec without force should be refused, got %v", err)
+	}
+
+	refSpecs := TagRefSpecs("2020.07.001-api")
+	if _, err := rm.PushTagToRemoteWithOptions("2020.07.001-api", "origin", nil, PushOptions{RefSpecs: refSpecs, Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := config.RefSpec("refs/tags/2020.07.001-api:refs/tags/2020.07.001-api"); refSpecs[0] != want {
+		t.Errorf("the caller's refspec was changed to %s", refSpecs[0])
+	}
+}
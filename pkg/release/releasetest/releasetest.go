@@ -0,0 +1,209 @@
+// Package releasetest builds throwaway git repositories with synthetic commits
+// and tags so scheme logic, change detection and push flows can be exercised
+// without a real checkout or network access.
+package releasetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// DefaultSignature is used for every commit and tag unless overridden with
+// Repo.Signature
+var DefaultSignature = object.Signature{
+	Name:  "Release Test",
+	Email: "releasetest@example.com",
+	When:  time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// Repo is a throwaway git repository
+type Repo struct {
+	Dir       string          // The directory on disk, empty for in-memory repos
+	Repo      *git.Repository // The underlying go-git repository
+	Signature object.Signature
+}
+
+// NewMemoryRepo creates a repository that only exists in memory
+func NewMemoryRepo() (*Repo, error) {
+	r, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Repo: r, Signature: DefaultSignature}, nil
+}
+
+// NewTempRepo creates a repository in a new temporary directory, call Cleanup
+// when done with it
+func NewTempRepo() (*Repo, error) {
+	dir, err := ioutil.TempDir("", "releasetest")
+	if err != nil {
+		return nil, err
+	}
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Repo{Dir: dir, Repo: r, Signature: DefaultSignature}, nil
+}
+
+// NewBareRemote creates a bare repository in a temporary directory and adds
+// it to this repository as a remote with the given name, so pushes can be
+// tested against something local. The returned Repo should also be cleaned up.
+func (r *Repo) NewBareRemote(name string) (*Repo, error) {
+	dir, err := ioutil.TempDir("", "releasetest-remote")
+	if err != nil {
+		return nil, err
+	}
+	bare, err := git.PlainInit(dir, true)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	_, err = r.Repo.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{dir}})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Repo{Dir: dir, Repo: bare, Signature: r.Signature}, nil
+}
+
+// Cleanup removes the repository from disk, it's a no-op for in-memory repos
+func (r *Repo) Cleanup() error {
+	if r.Dir == "" {
+		return nil
+	}
+	return os.RemoveAll(r.Dir)
+}
+
+// Advance moves the signature time forward, handy for making sure commits
+// and tags don't all share the same timestamp
+func (r *Repo) Advance(d time.Duration) {
+	r.Signature.When = r.Signature.When.Add(d)
+}
+
+// Commit writes the given files (path -> contents) and commits them along
+// with the message. Files may be nil to create an empty commit.
+func (r *Repo) Commit(msg string, files map[string]string) (plumbing.Hash, error) {
+	wt, err := r.Repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	for path, contents := range files {
+		if err := r.writeFile(wt, path, contents); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if _, err := wt.Add(path); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	sig := r.Signature
+	return wt.Commit(msg, &git.CommitOptions{Author: &sig, Committer: &sig})
+}
+
+func (r *Repo) writeFile(wt *git.Worktree, path, contents string) error {
+	if err := wt.Filesystem.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := wt.Filesystem.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write([]byte(contents))
+	return err
+}
+
+// Tag creates a tag pointing at HEAD, if msg is empty a lightweight tag is
+// created, otherwise an annotated one
+func (r *Repo) Tag(name, msg string) (*plumbing.Reference, error) {
+	head, err := r.Repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return r.TagCommit(name, msg, head.Hash())
+}
+
+// TagCommit is the same as Tag but points the tag at the given commit
+func (r *Repo) TagCommit(name, msg string, hash plumbing.Hash) (*plumbing.Reference, error) {
+	var opts *git.CreateTagOptions
+	if msg != "" {
+		sig := r.Signature
+		opts = &git.CreateTagOptions{Message: msg, Tagger: &sig}
+	}
+	return r.Repo.CreateTag(name, hash, opts)
+}
+
+// CommitAndTag is a shortcut for creating an empty commit and tagging it with
+// each of the given (lightweight) tags, the time is advanced by a minute
+// afterwards
+func (r *Repo) CommitAndTag(tags ...string) error {
+	hash, err := r.Commit(fmt.Sprintf("commit for %v", tags), nil)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := r.TagCommit(tag, "", hash); err != nil {
+			return err
+		}
+	}
+	r.Advance(time.Minute)
+	return nil
+}
+
+// SyntheticComponents are the components SyntheticTags spreads tags over
+var SyntheticComponents = []string{"api", "web", "worker"}
+
+// SyntheticTags creates count release tags (YYYY.MM.RRR-component) over a
+// linear history, ten tags per commit and a hundred per component and month
+// starting in 2000. Every tenth tag is annotated. Commits are written
+// directly, without a worktree, so large repositories build quickly.
+func (r *Repo) SyntheticTags(count int) error {
+	treeObj := r.Repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(treeObj); err != nil {
+		return err
+	}
+	tree, err := r.Repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		return err
+	}
+	var commit plumbing.Hash
+	for i := 0; i < count; i++ {
+		if i%10 == 0 {
+			c := &object.Commit{Author: r.Signature, Committer: r.Signature, Message: fmt.Sprintf("commit %d\n", i/10), TreeHash: tree}
+			if !commit.IsZero() {
+				c.ParentHashes = []plumbing.Hash{commit}
+			}
+			obj := r.Repo.Storer.NewEncodedObject()
+			if err := c.Encode(obj); err != nil {
+				return err
+			}
+			if commit, err = r.Repo.Storer.SetEncodedObject(obj); err != nil {
+				return err
+			}
+			r.Advance(time.Minute)
+		}
+		component := SyntheticComponents[i%len(SyntheticComponents)]
+		n := i / len(SyntheticComponents)
+		month := n / 100
+		name := fmt.Sprintf("%04d.%02d.%03d-%s", 2000+month/12, month%12+1, n%100+1, component)
+		msg := ""
+		if i%10 == 0 {
+			msg = "Release " + name
+		}
+		if _, err := r.TagCommit(name, msg, commit); err != nil {
+			return err
+		}
+	}
+	return r.Repo.Storer.SetReference(plumbing.NewHashReference(plumbing.Master, commit))
+}
@@ -0,0 +1,191 @@
+package release
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fernferret/release/internal/i18n"
+)
+
+// DefaultDigestMarkdown is the template of markdown digests
+const DefaultDigestMarkdown = `# {{t "Releases %s to %s" (.Since.Format "2006-01-02") (.Until.Format "2006-01-02")}}
+
+{{t "%d release(s) of %d component(s)." .Releases (len .Components)}}
+{{range .Components}}
+## {{.Component}}
+
+{{t "Released:"}} {{range $i, $r := .Releases}}{{if $i}}, {{end}}{{$r.Tag}}{{if $r.Breaking}} {{t "(breaking)"}}{{end}}{{end}}
+{{if .Highlights}}
+{{range .Highlights}}- {{.}}
+{{end}}{{end}}
+{{t "Contributors:"}} {{join .Contributors ", "}}
+{{end}}`
+
+// DefaultDigestHTML is the template of HTML digests, for emails
+const DefaultDigestHTML = `<html>
+<body>
+<h1>{{t "Releases %s to %s" (.Since.Format "2006-01-02") (.Until.Format "2006-01-02")}}</h1>
+<p>{{t "%d release(s) of %d component(s)." .Releases (len .Components)}}</p>
+{{range .Components}}<h2>{{.Component}}</h2>
+<p>{{t "Released:"}} {{range $i, $r := .Releases}}{{if $i}}, {{end}}<code>{{$r.Tag}}</code>{{if $r.Breaking}} {{t "(breaking)"}}{{end}}{{end}}</p>
+{{if .Highlights}}<ul>
+{{range .Highlights}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<p>{{t "Contributors:"}} {{join .Contributors ", "}}</p>
+{{end}}</body>
+</html>
+`
+
+// Digest summarizes the releases of a period per component, for reports to
+// people who don't read tags
+type Digest struct {
+	Since      time.Time
+	Until      time.Time
+	Releases   int // Releases in the period, all components
+	Components []*ComponentDigest
+}
+
+// ComponentDigest is what a component released in the period
+type ComponentDigest struct {
+	Component string
+	Releases  []DigestRelease // Oldest first
+	// Highlights are the release messages, or the release notes of the
+	// commits for releases without one
+	Highlights []string
+	// Contributors are the authors of the released commits, most commits
+	// first
+	Contributors []string
+}
+
+// DigestRelease is a release in a digest
+type DigestRelease struct {
+	Tag        string
+	Version    string
+	Date       time.Time
+	ReleasedBy string
+	PreRelease bool
+	Breaking   bool
+}
+
+// messageHighlights returns the lines of a tag message without its trailers
+// and list markers
+func messageHighlights(message string) []string {
+	body, lines := splitTrailers(message)
+	if len(lines) == 0 && len(annotationTrailers(message)) > 0 {
+		// Nothing but trailers
+		return nil
+	}
+	highlights := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+		if line != "" {
+			highlights = append(highlights, line)
+		}
+	}
+	return highlights
+}
+
+// Digest summarizes the releases created between since and until (the tag
+// date, or the commit date of lightweight tags). Pre-releases are left out
+// unless preReleases is set.
+func (r *Manager) Digest(since, until time.Time, preReleases bool) (*Digest, error) {
+	d := &Digest{Since: since, Until: until, Components: []*ComponentDigest{}}
+	byComponent := map[string]*ComponentDigest{}
+	commits := map[string]map[string]int{} // component -> author -> commits
+	for idx := range r.releases {
+		rel := &r.releases[idx]
+		when := rel.ReleasedBy().When
+		if when.Before(since) || !when.Before(until) || (rel.IsPreRelease() && !preReleases) {
+			continue
+		}
+		if _, ok := parseCalVer(r.tagTemplate, rel.Tag); !ok {
+			continue
+		}
+		cd, ok := byComponent[rel.Component()]
+		if !ok {
+			cd = &ComponentDigest{Component: rel.Component(), Highlights: []string{}}
+			byComponent[rel.Component()] = cd
+			commits[rel.Component()] = map[string]int{}
+			d.Components = append(d.Components, cd)
+		}
+		d.Releases++
+		cd.Releases = append(cd.Releases, DigestRelease{
+			Tag:        rel.Tag,
+			Version:    rel.Version(),
+			Date:       when,
+			ReleasedBy: rel.ReleasedBy().Name,
+			PreRelease: rel.IsPreRelease(),
+			Breaking:   rel.IsBreaking(),
+		})
+		changelog, err := r.Changelog(rel.Tag)
+		if err != nil {
+			return nil, err
+		}
+		highlights := messageHighlights(rel.ReleaseMessage)
+		if len(highlights) == 0 {
+			highlights = ReleaseNotes(changelog)
+		}
+		cd.Highlights = append(cd.Highlights, highlights...)
+		for _, c := range changelog {
+			commits[rel.Component()][c.Author.Name]++
+		}
+	}
+	sort.Slice(d.Components, func(i, j int) bool {
+		return d.Components[i].Component < d.Components[j].Component
+	})
+	for _, cd := range d.Components {
+		sort.Slice(cd.Releases, func(i, j int) bool {
+			return cd.Releases[i].Date.Before(cd.Releases[j].Date)
+		})
+		counts := commits[cd.Component]
+		for name := range counts {
+			cd.Contributors = append(cd.Contributors, name)
+		}
+		sort.Slice(cd.Contributors, func(i, j int) bool {
+			a, b := cd.Contributors[i], cd.Contributors[j]
+			if counts[a] != counts[b] {
+				return counts[a] > counts[b]
+			}
+			return a < b
+		})
+	}
+	return d, nil
+}
+
+// Render renders the digest as markdown or html, with the template in
+// templateFile or the default one for the format
+func (d *Digest) Render(format, templateFile string) (string, error) {
+	funcs := map[string]interface{}{"join": strings.Join, "t": i18n.T}
+	text := DefaultDigestMarkdown
+	if format == "html" {
+		text = DefaultDigestHTML
+	}
+	if templateFile != "" {
+		data, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return "", err
+		}
+		text = string(data)
+	}
+	b := &bytes.Buffer{}
+	if format == "html" {
+		// html/template escapes commit messages
+		tmpl, err := htmltemplate.New("digest").Funcs(funcs).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(b, d)
+		return b.String(), err
+	}
+	tmpl, err := template.New("digest").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(b, d)
+	return b.String(), err
+}
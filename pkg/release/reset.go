@@ -0,0 +1,65 @@
+package release
+
+import "fmt"
+
+// ResetPolicy decides when the release increment starts over at 001
+type ResetPolicy string
+
+// The reset policies, monthly is what the tool always did
+const (
+	ResetMonthly ResetPolicy = "monthly"
+	ResetYearly  ResetPolicy = "yearly"
+	ResetNever   ResetPolicy = "never"
+)
+
+// sameScope reports whether two versions share an increment counter. With
+// yearly and never policies the increment keeps counting up across months, so
+// versions still compare correctly by year, month and then increment.
+func (p ResetPolicy) sameScope(a, b *calVerStandard) bool {
+	switch p {
+	case ResetYearly:
+		return a.Year == b.Year
+	case ResetNever:
+		return true
+	}
+	return a.IsSameMonth(b)
+}
+
+func (p ResetPolicy) validate() error {
+	switch p {
+	case "", ResetMonthly, ResetYearly, ResetNever:
+		return nil
+	}
+	return fmt.Errorf("increment reset must be monthly, yearly or never, not '%s'", p)
+}
+
+// IncrementConfig configures when release increments reset
+type IncrementConfig struct {
+	Reset      ResetPolicy            `yaml:"reset"`
+	Components map[string]ResetPolicy `yaml:"components"` // Per component overrides
+}
+
+func (c IncrementConfig) validate() error {
+	if err := c.Reset.validate(); err != nil {
+		return err
+	}
+	for component, p := range c.Components {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("%s: %w", component, err)
+		}
+	}
+	return nil
+}
+
+// ResetPolicy returns the reset policy of a component (pre-release markers
+// are ignored), monthly unless configured otherwise
+func (c IncrementConfig) ResetPolicy(component string) ResetPolicy {
+	component, _ = splitPreRelease(component)
+	if p, ok := c.Components[component]; ok && p != "" {
+		return p
+	}
+	if c.Reset != "" {
+		return c.Reset
+	}
+	return ResetMonthly
+}
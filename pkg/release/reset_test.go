@@ -0,0 +1,74 @@
+package release
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetPolicySameScope(t *testing.T) {
+	jul, _ := parseCalVer(nil, "2020.07.004")
+	aug, _ := parseCalVer(nil, "2020.08.001")
+	nextJan, _ := parseCalVer(nil, "2021.01.001")
+	tests := []struct {
+		policy     ResetPolicy
+		a, b       *calVerStandard
+		sameCounts bool
+	}{
+		{ResetMonthly, jul, jul, true},
+		{ResetMonthly, jul, aug, false},
+		{ResetYearly, jul, aug, true},
+		{ResetYearly, aug, nextJan, false},
+		{ResetNever, jul, nextJan, true},
+	}
+	for _, test := range tests {
+		if got := test.policy.sameScope(test.a, test.b); got != test.sameCounts {
+			t.Errorf("%s: %v and %v share a counter: %v, want %v", test.policy, test.a, test.b, got, test.sameCounts)
+		}
+	}
+}
+
+func TestIncrementConfigResetPolicy(t *testing.T) {
+	cfg := IncrementConfig{Reset: ResetYearly, Components: map[string]ResetPolicy{"api": ResetNever}}
+	tests := map[string]ResetPolicy{
+		"api":      ResetNever,
+		"api-rc.1": ResetNever,
+		"web":      ResetYearly,
+	}
+	for component, want := range tests {
+		if got := cfg.ResetPolicy(component); got != want {
+			t.Errorf("%s: got %s, want %s", component, got, want)
+		}
+	}
+	if got := (IncrementConfig{}).ResetPolicy("api"); got != ResetMonthly {
+		t.Errorf("default: got %s, want %s", got, ResetMonthly)
+	}
+	if err := (IncrementConfig{Reset: "weekly"}).validate(); err == nil {
+		t.Error("weekly validated")
+	}
+}
+
+func TestProposedReleaseResetPolicy(t *testing.T) {
+	rm, _ := newTestManager(t, "2020.06.007-api", "2020.07.002-api", "2020.07.003-web", "2019.12.040-api")
+	aug := time.Date(2020, time.August, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy ResetPolicy
+		at     time.Time
+		want   string
+	}{
+		{ResetMonthly, time.Date(2020, time.July, 20, 12, 0, 0, 0, time.UTC), "2020.07.004-api"},
+		{ResetMonthly, aug, "2020.08.001-api"},
+		{ResetYearly, aug, "2020.08.008-api"},
+		{ResetNever, aug, "2020.08.041-api"},
+		// Releases in the future of the date are skipped
+		{ResetNever, time.Date(2020, time.January, 3, 12, 0, 0, 0, time.UTC), "2020.01.041-api"},
+	}
+	for _, test := range tests {
+		proposal, err := rm.GetProposedReleaseAt("api", test.at, test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proposal.TagName != test.want {
+			t.Errorf("%s at %s: got %s, want %s", test.policy, test.at.Format("2006-01-02"), proposal.TagName, test.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package release
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RiskTrailer records the risk of a release, see Risk
+const RiskTrailer = "Risk"
+
+// RiskConfig scores the risk of releases when they're created
+type RiskConfig struct {
+	// Enabled records the risk of every release in a RiskTrailer
+	Enabled bool `yaml:"enabled"`
+	// CriticalPaths are files and directories where any change makes a
+	// release riskier, like db/migrations
+	CriticalPaths []string `yaml:"critical_paths"`
+}
+
+// Risk is a rough score of how risky a release is, for reviewers to eyeball.
+// Big diffs, many authors, changes to critical paths and long gaps since the
+// last release all add to it.
+type Risk struct {
+	Score    int      `json:"score"`
+	Level    string   `json:"level"`              // low, medium or high
+	Lines    int      `json:"lines"`              // Added and deleted lines
+	Authors  int      `json:"authors"`            // Authors of the commits
+	Days     int      `json:"days"`               // Since the last release of the component
+	Critical []string `json:"critical,omitempty"` // The critical paths changed
+}
+
+// Risk levels by the lowest score they start at
+const (
+	riskMedium = 3
+	riskHigh   = 6
+)
+
+// score works out the score and level from the inputs
+func (r *Risk) score() {
+	r.Score = 0
+	for _, step := range []int{100, 500, 2000} {
+		if r.Lines >= step {
+			r.Score++
+		}
+	}
+	for _, step := range []int{2, 5} {
+		if r.Authors >= step {
+			r.Score++
+		}
+	}
+	for _, step := range []int{30, 90} {
+		if r.Days >= step {
+			r.Score++
+		}
+	}
+	if len(r.Critical) > 0 {
+		r.Score += 3
+	}
+	switch {
+	case r.Score >= riskHigh:
+		r.Level = "high"
+	case r.Score >= riskMedium:
+		r.Level = "medium"
+	default:
+		r.Level = "low"
+	}
+}
+
+// String formats the risk as the value of a RiskTrailer, like
+// "medium score=4 lines=812 authors=3 days=21 critical=db/migrations"
+func (r Risk) String() string {
+	s := fmt.Sprintf("%s score=%d lines=%d authors=%d days=%d", r.Level, r.Score, r.Lines, r.Authors, r.Days)
+	if len(r.Critical) > 0 {
+		s += " critical=" + strings.Join(r.Critical, ",")
+	}
+	return s
+}
+
+// ParseRisk parses the value of a RiskTrailer
+func ParseRisk(value string) (*Risk, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty risk")
+	}
+	risk := &Risk{Level: fields[0]}
+	for _, field := range fields[1:] {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid risk field '%s'", field)
+		}
+		if parts[0] == "critical" {
+			risk.Critical = strings.Split(parts[1], ",")
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid risk field '%s'", field)
+		}
+		switch parts[0] {
+		case "score":
+			risk.Score = n
+		case "lines":
+			risk.Lines = n
+		case "authors":
+			risk.Authors = n
+		case "days":
+			risk.Days = n
+		}
+	}
+	return risk, nil
+}
+
+// Risk returns the risk recorded when the release was created, nil if it
+// wasn't scored
+func (r *Release) Risk() *Risk {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == RiskTrailer {
+			if risk, err := ParseRisk(t.Value); err == nil {
+				return risk
+			}
+		}
+	}
+	return nil
+}
+
+// ScoreRisk scores the release of the component at target (HEAD if empty),
+// compared to the release before it, as of now
+func (r *Manager) ScoreRisk(tag, target string, component ComponentConfig, cfg RiskConfig, now time.Time) (*Risk, error) {
+	commits, err := r.releaseCommits(tag, target, component)
+	if err != nil {
+		return nil, err
+	}
+	risk := &Risk{}
+	authors := map[string]bool{}
+	hashes := []string{}
+	for _, c := range commits {
+		authors[strings.ToLower(c.Author.Email)] = true
+		hashes = append(hashes, c.Hash)
+	}
+	risk.Authors = len(authors)
+
+	head, err := r.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	from := ""
+	if prev := r.PreviousRelease(tag); prev != nil {
+		from = prev.Hash
+		risk.Days = int(now.Sub(prev.ReleasedBy().When).Hours() / 24)
+	}
+	stats, err := r.diffstat(from, head)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		if len(component.Paths) == 0 || component.Owns(stat.Path) {
+			risk.Lines += stat.Added + stat.Deleted
+		}
+	}
+
+	if len(cfg.CriticalPaths) > 0 {
+		changed, err := r.changedFiles(hashes)
+		if err != nil {
+			return nil, err
+		}
+		for _, critical := range cfg.CriticalPaths {
+			if touchesPath(changed, critical) {
+				risk.Critical = append(risk.Critical, critical)
+			}
+		}
+	}
+	risk.score()
+	return risk, nil
+}
+
+// touchesPath reports whether any of the files by commit is name or under it
+func touchesPath(changed map[string][]string, name string) bool {
+	for _, files := range changed {
+		for _, file := range files {
+			if underPath(file, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package release
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestRiskScore(t *testing.T) {
+	for _, tc := range []struct {
+		risk  Risk
+		score int
+		level string
+	}{
+		{Risk{Lines: 20, Authors: 1, Days: 3}, 0, "low"},
+		{Risk{Lines: 600, Authors: 2, Days: 3}, 3, "medium"},
+		{Risk{Lines: 50, Authors: 1, Days: 3, Critical: []string{"db"}}, 3, "medium"},
+		{Risk{Lines: 2500, Authors: 6, Days: 100}, 7, "high"},
+	} {
+		tc.risk.score()
+		if tc.risk.Score != tc.score || tc.risk.Level != tc.level {
+			t.Errorf("%+v: want %d %s", tc.risk, tc.score, tc.level)
+		}
+	}
+}
+
+func TestParseRisk(t *testing.T) {
+	risk := Risk{Score: 4, Level: "medium", Lines: 812, Authors: 3, Days: 21, Critical: []string{"db/migrations", "config"}}
+	parsed, err := ParseRisk(risk.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*parsed, risk) {
+		t.Errorf("got %+v from %q", parsed, risk.String())
+	}
+	for _, value := range []string{"", "high lines", "high lines=many"} {
+		if _, err := ParseRisk(value); err == nil {
+			t.Errorf("%q should be invalid", value)
+		}
+	}
+	rel := &Release{ReleaseMessage: AppendTrailer("release", RiskTrailer, risk.String())}
+	if got := rel.Risk(); got == nil || got.Score != 4 {
+		t.Errorf("got %+v from the trailer", got)
+	}
+}
+
+func TestScoreRisk(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("initial commit", map[string]string{"api/main.go": "package main\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.001-api", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("add a migration", map[string]string{"api/db/001.sql": strings.Repeat("select 1;\n", 120)}); err != nil {
+		t.Fatal(err)
+	}
+	repo.Signature.Email = "other@example.com"
+	if _, err := repo.Commit("change the web", map[string]string{"web/index.html": "hi\n"}); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	released := rm.FindRelease("2020.07.001-api").ReleasedBy().When
+	component := ComponentConfig{Paths: []string{"api"}}
+	cfg := RiskConfig{Enabled: true, CriticalPaths: []string{"api/db", "web"}}
+	risk, err := rm.ScoreRisk("2020.07.002-api", "", component, cfg, released.Add(40*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The web commit isn't part of the api release
+	want := Risk{Score: 5, Level: "medium", Lines: 120, Authors: 1, Days: 40, Critical: []string{"api/db"}}
+	if !reflect.DeepEqual(*risk, want) {
+		t.Errorf("got %+v, want %+v", risk, want)
+	}
+}
@@ -0,0 +1,113 @@
+package release
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RolloutTrailer records how a release should be rolled out, see Rollout
+const RolloutTrailer = "Rollout"
+
+// Rollout is how deployment automation should roll a release out: to a canary
+// first, then in waves, each stage baking for a while before the next. It's
+// recorded in the release, nothing here deploys anything.
+type Rollout struct {
+	// Canary is the percentage the canary gets first, 0 for none
+	Canary int `yaml:"canary" json:"canary,omitempty"`
+	// Waves are the percentages rolled out to after the canary, in order
+	Waves []int `yaml:"waves" json:"waves,omitempty"`
+	// Bake is how long each stage runs before the next, like 30m or 1d
+	Bake string `yaml:"bake" json:"bake,omitempty"`
+}
+
+func (r Rollout) validate() error {
+	if r.Canary < 0 || r.Canary > 100 {
+		return fmt.Errorf("rollout canary must be a percentage, not %d", r.Canary)
+	}
+	last := r.Canary
+	for _, wave := range r.Waves {
+		if wave <= last || wave > 100 {
+			return fmt.Errorf("rollout waves must be increasing percentages above the canary, up to 100")
+		}
+		last = wave
+	}
+	if r.Bake != "" {
+		if d, err := ParseAge(r.Bake); err != nil || d <= 0 {
+			return fmt.Errorf("invalid rollout bake '%s', must be a duration like 30m or 1d", r.Bake)
+		}
+	}
+	if r.Canary == 0 && len(r.Waves) == 0 {
+		return fmt.Errorf("a rollout needs a canary or waves")
+	}
+	return nil
+}
+
+// String formats the rollout as the value of a RolloutTrailer, like
+// "canary=5 waves=25,50,100 bake=1h"
+func (r Rollout) String() string {
+	parts := []string{}
+	if r.Canary > 0 {
+		parts = append(parts, fmt.Sprintf("canary=%d", r.Canary))
+	}
+	if len(r.Waves) > 0 {
+		waves := []string{}
+		for _, wave := range r.Waves {
+			waves = append(waves, strconv.Itoa(wave))
+		}
+		parts = append(parts, "waves="+strings.Join(waves, ","))
+	}
+	if r.Bake != "" {
+		parts = append(parts, "bake="+r.Bake)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseRollout parses a rollout in the format of Rollout.String, as given to
+// --rollout or found in a RolloutTrailer
+func ParseRollout(value string) (*Rollout, error) {
+	rollout := &Rollout{}
+	for _, field := range strings.Fields(value) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid rollout field '%s', expected canary=, waves= or bake=", field)
+		}
+		switch parts[0] {
+		case "canary":
+			n, err := strconv.Atoi(strings.TrimSuffix(parts[1], "%"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid rollout canary '%s'", parts[1])
+			}
+			rollout.Canary = n
+		case "waves":
+			for _, wave := range strings.Split(parts[1], ",") {
+				n, err := strconv.Atoi(strings.TrimSuffix(wave, "%"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid rollout wave '%s'", wave)
+				}
+				rollout.Waves = append(rollout.Waves, n)
+			}
+		case "bake":
+			rollout.Bake = parts[1]
+		default:
+			return nil, fmt.Errorf("invalid rollout field '%s', expected canary=, waves= or bake=", field)
+		}
+	}
+	if err := rollout.validate(); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Rollout returns the rollout recorded when the release was created, nil if
+// it has none
+func (r *Release) Rollout() *Rollout {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == RolloutTrailer {
+			if rollout, err := ParseRollout(t.Value); err == nil {
+				return rollout
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRollout(t *testing.T) {
+	rollout, err := ParseRollout("canary=5% waves=25,50,100 bake=1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rollout{Canary: 5, Waves: []int{25, 50, 100}, Bake: "1h"}
+	if !reflect.DeepEqual(*rollout, want) {
+		t.Errorf("got %+v, want %+v", rollout, want)
+	}
+	if rollout.String() != "canary=5 waves=25,50,100 bake=1h" {
+		t.Errorf("got %q", rollout.String())
+	}
+	for _, spec := range []string{"", "canary=200", "waves=50,25", "canary=50 waves=10", "bake=soon waves=100", "stages=3"} {
+		if _, err := ParseRollout(spec); err == nil {
+			t.Errorf("%q should be invalid", spec)
+		}
+	}
+
+	rel := &Release{ReleaseMessage: AppendTrailer("release", RolloutTrailer, want.String())}
+	if got := rel.Rollout(); got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v from the trailer", got)
+	}
+}
+
+func TestRolloutOverride(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+rollout: {waves: [50, 100]}
+components:
+  api: {rollout: {canary: 1, waves: [100]}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.ForComponent("api").Rollout; got.Canary != 1 {
+		t.Errorf("the component's rollout should win, got %+v", got)
+	}
+	if got := cfg.ForComponent("web").Rollout; got.Canary != 0 || len(got.Waves) != 2 {
+		t.Errorf("other components get the repository's rollout, got %+v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxMessageLength is the message length (in bytes) above which a
+// warning is given
+const DefaultMaxMessageLength = 16 * 1024
+
+// MessageConfig configures how tag messages are cleaned up before tagging
+type MessageConfig struct {
+	// Encoding of messages given to the tool, utf-8 (default) or latin1.
+	// Messages are always stored as UTF-8.
+	Encoding string `yaml:"encoding"`
+	// MaxLength warns about longer messages, DefaultMaxMessageLength if 0
+	MaxLength int `yaml:"max_length"`
+	// Template renders the tag message, see MessageData for what it gets.
+	// The message is used as it is if empty.
+	Template string `yaml:"template"`
+	// Summarize is a command the release notes are piped through, its
+	// output is offered as the tag message, see Summarize
+	Summarize string `yaml:"summarize"`
+	// CompressOver compresses messages longer than this many bytes: the full
+	// message is written to NotesFile and the tag gets its start, see
+	// CompressMessage. Off if 0.
+	CompressOver int `yaml:"compress_over"`
+	// NotesFile is where the full notes of compressed messages go, relative
+	// to the repository with {tag} replaced, DefaultNotesFile if empty
+	NotesFile string `yaml:"notes_file"`
+}
+
+func (c MessageConfig) validate() error {
+	if c.CompressOver < 0 {
+		return fmt.Errorf("compress_over can't be negative")
+	}
+	if _, err := template.New("message").Parse(c.Template); err != nil {
+		return fmt.Errorf("invalid message template: %w", err)
+	}
+	switch strings.ToLower(c.Encoding) {
+	case "", "utf-8", "utf8", "latin1", "iso-8859-1":
+		return nil
+	}
+	return fmt.Errorf("message encoding must be utf-8 or latin1, not '%s'", c.Encoding)
+}
+
+// MessageData is what message templates are rendered with
+type MessageData struct {
+	Tag       string
+	Component string
+	Version   string
+	Message   string   // The message given with --msg (or from the pull request)
+	Changelog []string // Release notes (or commit subjects) of the release
+}
+
+// RenderMessage renders the message template for a release. Trailers of the
+// message stay at the end of the rendered message.
+func RenderMessage(tmpl string, data MessageData) (string, error) {
+	t, err := template.New("message").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	body, lines := splitTrailers(data.Message)
+	trailers := ParseTrailers(data.Message)
+	if len(lines) == 0 {
+		if trailers = annotationTrailers(data.Message); trailers != nil {
+			body = ""
+		}
+	}
+	data.Message = body
+	buf := &strings.Builder{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	message := strings.TrimSpace(buf.String())
+	for _, trailer := range trailers {
+		message = AppendTrailer(message, trailer.Key, trailer.Value)
+	}
+	return message, nil
+}
+
+// ansiPat matches terminal escape sequences (CSI and OSC), note generators
+// piping colored output are the usual source
+var ansiPat = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|\x1b[@-Z\\\\-_]")
+
+// SanitizeMessage normalizes a tag message: it's decoded from the configured
+// encoding, terminal escapes and control characters are stripped, newlines
+// are normalized to \n, invalid UTF-8 is replaced and trailing whitespace is
+// trimmed. Warnings are returned for things worth telling the user about.
+func SanitizeMessage(message string, cfg MessageConfig) (string, []string) {
+	warnings := []string{}
+	switch strings.ToLower(cfg.Encoding) {
+	case "latin1", "iso-8859-1":
+		runes := make([]rune, len(message))
+		for idx := 0; idx < len(message); idx++ {
+			runes[idx] = rune(message[idx])
+		}
+		message = string(runes)
+	}
+	if !utf8.ValidString(message) {
+		warnings = append(warnings, "message isn't valid UTF-8, invalid bytes were replaced")
+		message = strings.ToValidUTF8(message, "�")
+	}
+	if stripped := ansiPat.ReplaceAllString(message, ""); stripped != message {
+		warnings = append(warnings, "removed terminal escape sequences from the message")
+		message = stripped
+	}
+	message = strings.Replace(message, "\r\n", "\n", -1)
+	message = strings.Replace(message, "\r", "\n", -1)
+	message = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, message)
+	lines := strings.Split(message, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimRight(line, " \t")
+	}
+	message = strings.Trim(strings.Join(lines, "\n"), "\n")
+
+	max := cfg.MaxLength
+	if max == 0 {
+		max = DefaultMaxMessageLength
+	}
+	if len(message) > max {
+		warnings = append(warnings, fmt.Sprintf("message is %d bytes long, tools showing tags may truncate it (max_length is %d)", len(message), max))
+	}
+	return message, warnings
+}
+
+// RenderReleaseMessage renders the message template for a release that is
+// about to be created from HEAD
+func (r *Manager) RenderReleaseMessage(tmpl, tag, message string) (string, error) {
+	rel := r.ParseRelease(tag)
+	data := MessageData{Tag: tag, Component: rel.Component(), Version: rel.Version(), Message: message}
+	head, err := r.releaseHead()
+	if err != nil {
+		return "", err
+	}
+	stop := ""
+	if prev := r.PreviousRelease(tag); prev != nil {
+		stop = prev.Hash
+	}
+	commits, err := r.commitsBetween(stop, head)
+	if err != nil {
+		return "", err
+	}
+	data.Changelog = ReleaseNotes(commits)
+	return RenderMessage(tmpl, data)
+}
@@ -0,0 +1,136 @@
+package release
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fernferret/release/pkg/release/forge"
+)
+
+// layoutDirs are the directories monorepos commonly keep their components in,
+// a component api is looked for in api/, then cmd/api/ and so on
+var layoutDirs = []string{"", "cmd", "services", "apps", "packages"}
+
+// Detection is what 'release init' found out about a repository
+type Detection struct {
+	// Scheme is the scheme of the newest release, SchemeMonthly without
+	// releases
+	Scheme Scheme
+	// Schemes counts the releases of each scheme
+	Schemes map[Scheme]int
+	// Components are the components found in tags and in the layout,
+	// mapped to the paths they were found at (none if they weren't)
+	Components map[string][]string
+	// Other are the tags that aren't CalVer
+	Other []string
+	// Remote is the remote to push to, empty for origin
+	Remote  string
+	Remotes []string
+	// Forge is the forge the remote is on, empty if it's unknown
+	Forge string
+}
+
+// DetectConfig inspects the tags, the layout of the HEAD commit and the
+// remotes of the repository to suggest a starting config
+func (r *Manager) DetectConfig() (*Detection, error) {
+	d := &Detection{Scheme: SchemeMonthly, Schemes: map[Scheme]int{}, Components: map[string][]string{}}
+	var newest *calVerStandard
+	for _, release := range r.releases {
+		version, ok := parseCalVer(r.tagTemplate, release.Tag)
+		if !ok {
+			d.Other = append(d.Other, release.Tag)
+			continue
+		}
+		scheme := SchemeMonthly
+		if version.IsOrdinal() {
+			scheme = SchemeOrdinal
+		}
+		d.Schemes[scheme]++
+		if newest == nil || version.Compare(newest) > 0 {
+			newest, d.Scheme = version, scheme
+		}
+		if component := release.Component(); component != "" {
+			d.Components[component] = nil
+		}
+	}
+	sort.Strings(d.Other)
+
+	dirs, err := r.headDirs()
+	if err != nil {
+		return nil, err
+	}
+	// Without component tags the layout is all there is to go on
+	if len(d.Components) == 0 {
+		for _, dir := range layoutDirs[1:] {
+			for name := range dirs {
+				if sub := strings.TrimPrefix(name, dir+"/"); sub != name && !strings.Contains(sub, "/") && !strings.HasPrefix(sub, ".") {
+					d.Components[sub] = nil
+				}
+			}
+		}
+	}
+	for component := range d.Components {
+		for _, dir := range layoutDirs {
+			if p := path.Join(dir, component); dirs[p] {
+				d.Components[component] = []string{p + "/"}
+				break
+			}
+		}
+	}
+
+	remotes, err := r.repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	urls := map[string]string{}
+	for _, remote := range remotes {
+		name := remote.Config().Name
+		d.Remotes = append(d.Remotes, name)
+		if len(remote.Config().URLs) > 0 {
+			urls[name] = remote.Config().URLs[0]
+		}
+	}
+	sort.Strings(d.Remotes)
+	if _, ok := urls["origin"]; !ok && len(d.Remotes) == 1 {
+		d.Remote = d.Remotes[0]
+	}
+	push := d.Remote
+	if push == "" {
+		push = "origin"
+	}
+	if repo, err := forge.ParseRemoteURL(urls[push]); err == nil {
+		if f, err := forge.New(repo, forge.Config{}); err == nil {
+			d.Forge = f.Name()
+		}
+	}
+	return d, nil
+}
+
+// YAML renders the detected settings as a commented starter config
+func (d *Detection) YAML() []byte {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# Written by 'release init', see the README for every setting\n")
+	fmt.Fprintf(b, "scheme: %s\n", d.Scheme)
+	if d.Remote != "" {
+		fmt.Fprintf(b, "remote: %s\n", d.Remote)
+	}
+	if len(d.Components) > 0 {
+		names := []string{}
+		for name := range d.Components {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(b, "components:\n")
+		for _, name := range names {
+			fmt.Fprintf(b, "  %s:\n", name)
+			if paths := d.Components[name]; len(paths) > 0 {
+				fmt.Fprintf(b, "    paths: [%s]\n", strings.Join(paths, ", "))
+			} else {
+				fmt.Fprintf(b, "    # paths: [%s/]\n", name)
+			}
+		}
+	}
+	return []byte(b.String())
+}
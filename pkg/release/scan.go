@@ -0,0 +1,169 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ScanTrailer summarizes the findings of a scanner in a tag annotation
+const ScanTrailer = "Scan"
+
+// ScanOverrideTrailer records why a release was made despite findings that
+// block it
+const ScanOverrideTrailer = "Scan-Override"
+
+// ScannerHook runs a license or dependency scanner before the release, its
+// JSON output is summarized in the tag annotation and the audit log
+type ScannerHook struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"` // Prints the findings as JSON on stdout
+	// Format is findings (default, {"findings": [{"id", "severity", "title",
+	// "package"}]}) or trivy (trivy --format json)
+	Format string `yaml:"format"`
+	// Block are the severities that stop the release unless overridden, like
+	// [critical, high]
+	Block []string `yaml:"block"`
+}
+
+func (s ScannerHook) validate() error {
+	if s.Name == "" || s.Command == "" {
+		return fmt.Errorf("scanners need a name and a command")
+	}
+	if s.Format != "" && s.Format != "findings" && s.Format != "trivy" {
+		return fmt.Errorf("scanner %s: format must be findings or trivy, not '%s'", s.Name, s.Format)
+	}
+	return nil
+}
+
+// Finding is a vulnerability or license problem a scanner found
+type Finding struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"` // Lower case
+	Title    string `json:"title,omitempty"`
+	Package  string `json:"package,omitempty"`
+}
+
+// ScanResult is what a scanner found for a release
+type ScanResult struct {
+	Scanner  string
+	Findings []Finding
+	// Blocking are the findings with a severity the scanner blocks on
+	Blocking []Finding
+}
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string
+			PkgName         string
+			Severity        string
+			Title           string
+		}
+		Licenses []struct {
+			Name     string
+			PkgName  string
+			Severity string
+		}
+	}
+}
+
+// parseFindings reads the findings from the output of a scanner
+func parseFindings(format string, data []byte) ([]Finding, error) {
+	findings := []Finding{}
+	if format == "trivy" {
+		var report trivyReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, err
+		}
+		for _, result := range report.Results {
+			for _, v := range result.Vulnerabilities {
+				findings = append(findings, Finding{ID: v.VulnerabilityID, Severity: v.Severity, Title: v.Title, Package: v.PkgName})
+			}
+			for _, l := range result.Licenses {
+				findings = append(findings, Finding{ID: l.Name, Severity: l.Severity, Title: "license " + l.Name, Package: l.PkgName})
+			}
+		}
+	} else {
+		var report struct {
+			Findings []Finding `json:"findings"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, err
+		}
+		findings = report.Findings
+	}
+	for idx := range findings {
+		findings[idx].Severity = strings.ToLower(findings[idx].Severity)
+	}
+	return findings, nil
+}
+
+// RunScanner runs the scanner with sh in dir with the environment of hooks and
+// parses what it prints. A failing command is an error like a failing hook.
+func RunScanner(s ScannerHook, dir string, rel *Release) (*ScanResult, error) {
+	cmd := exec.Command("sh", "-c", s.Command)
+	cmd.Dir = dir
+	cmd.Env = hookEnv(rel)
+	out := &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = out, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("scanner %s failed: %w", s.Name, err)
+	}
+	findings, err := parseFindings(s.Format, out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("scanner %s didn't print %s json: %w", s.Name, s.Format, err)
+	}
+	result := &ScanResult{Scanner: s.Name, Findings: findings}
+	blocks := map[string]bool{}
+	for _, severity := range s.Block {
+		blocks[strings.ToLower(severity)] = true
+	}
+	for _, f := range findings {
+		if blocks[f.Severity] {
+			result.Blocking = append(result.Blocking, f)
+		}
+	}
+	return result, nil
+}
+
+// Summary counts the findings by severity, like "trivy: 2 high, 5 medium"
+func (s *ScanResult) Summary() string {
+	if len(s.Findings) == 0 {
+		return s.Scanner + ": no findings"
+	}
+	counts := map[string]int{}
+	for _, f := range s.Findings {
+		counts[f.Severity]++
+	}
+	severities := []string{}
+	for severity := range counts {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		a, b := severityRank(severities[i]), severityRank(severities[j])
+		if a != b {
+			return a < b
+		}
+		return severities[i] < severities[j]
+	})
+	parts := []string{}
+	for _, severity := range severities {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+	}
+	return fmt.Sprintf("%s: %s", s.Scanner, strings.Join(parts, ", "))
+}
+
+// severityRank orders the usual severities from the worst, others go last
+func severityRank(severity string) int {
+	for idx, s := range []string{"critical", "high", "medium", "low"} {
+		if s == severity {
+			return idx
+		}
+	}
+	return 4
+}
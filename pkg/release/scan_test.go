@@ -0,0 +1,32 @@
+package release
+
+import "testing"
+
+func TestParseFindings(t *testing.T) {
+	trivy := []byte(`{"Results": [
+		{"Vulnerabilities": [{"VulnerabilityID": "CVE-1", "PkgName": "openssl", "Severity": "HIGH"}]},
+		{"Licenses": [{"Name": "GPL-3.0", "PkgName": "x", "Severity": "LOW"}]}
+	]}`)
+	findings, err := parseFindings("trivy", trivy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 || findings[0].ID != "CVE-1" || findings[0].Severity != "high" || findings[1].ID != "GPL-3.0" {
+		t.Errorf("got %+v", findings)
+	}
+
+	findings, err = parseFindings("", []byte(`{"findings": [{"id": "a", "severity": "Medium"}, {"id": "b", "severity": "critical"}, {"id": "c", "severity": "medium"}, {"id": "d", "severity": "info"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &ScanResult{Scanner: "deps", Findings: findings}
+	if got := result.Summary(); got != "deps: 1 critical, 2 medium, 1 info" {
+		t.Errorf("got summary %s", got)
+	}
+	if got := (&ScanResult{Scanner: "deps"}).Summary(); got != "deps: no findings" {
+		t.Errorf("got summary %s", got)
+	}
+	if _, err := parseFindings("", []byte("not json")); err == nil {
+		t.Error("parsed output that isn't json")
+	}
+}
@@ -0,0 +1,102 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ScheduleConfig is the cadence releases are planned on, like every other
+// Tuesday (every: 2w, start: a Tuesday). It doesn't release anything by
+// itself, release schedule previews the upcoming dates.
+type ScheduleConfig struct {
+	// Every is the time between releases in days, weeks or months: 1d, 2w, 1m
+	Every string `yaml:"every"`
+	// Start is the date of one release on the schedule, 2006-01-02. The
+	// others are every Every before and after it.
+	Start string `yaml:"start"`
+
+	count int
+	unit  byte
+	start time.Time
+}
+
+var everyPat = regexp.MustCompile(`^([1-9][0-9]*)([dwm])$`)
+
+func (c *ScheduleConfig) validate() error {
+	if c.Every == "" && c.Start == "" {
+		return nil
+	}
+	m := everyPat.FindStringSubmatch(c.Every)
+	if m == nil {
+		return fmt.Errorf("invalid schedule.every '%s', must be a number of days, weeks or months like 2w", c.Every)
+	}
+	c.count, _ = strconv.Atoi(m[1])
+	c.unit = m[2][0]
+	if c.Start == "" {
+		return fmt.Errorf("schedule.start is needed, the date of one release on the schedule")
+	}
+	start, err := time.ParseInLocation("2006-01-02", c.Start, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid schedule.start '%s', must be YYYY-MM-DD", c.Start)
+	}
+	c.start = start
+	return nil
+}
+
+// Configured reports whether there's a schedule
+func (c *ScheduleConfig) Configured() bool {
+	return c.Every != ""
+}
+
+// dateAt returns the idx-th release date from the start, negative ones are
+// before it
+func (c *ScheduleConfig) dateAt(idx int) time.Time {
+	switch c.unit {
+	case 'm':
+		return c.start.AddDate(0, idx*c.count, 0)
+	case 'w':
+		return c.start.AddDate(0, 0, idx*c.count*7)
+	}
+	return c.start.AddDate(0, 0, idx*c.count)
+}
+
+// Upcoming returns the next n release dates on the schedule, starting with
+// the day of from
+func (c *ScheduleConfig) Upcoming(from time.Time, n int) []time.Time {
+	if !c.Configured() || c.start.IsZero() {
+		return nil
+	}
+	from = from.In(time.Local)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	idx := 0
+	for c.dateAt(idx).After(day) {
+		idx--
+	}
+	for c.dateAt(idx).Before(day) {
+		idx++
+	}
+	dates := []time.Time{}
+	for ; len(dates) < n; idx++ {
+		dates = append(dates, c.dateAt(idx))
+	}
+	return dates
+}
+
+// PlanVersions proposes the tags of releases of name (a component or empty)
+// at each of dates, in order, as if each of them was made. The releases
+// aren't created, the manager is left as it was.
+func (r *Manager) PlanVersions(name string, dates []time.Time, policy ResetPolicy) []string {
+	releases := r.releases
+	defer func() { r.releases = releases }()
+	r.releases = append(releaseList{}, releases...)
+
+	tags := []string{}
+	for _, date := range dates {
+		tag := r.explainNext(name, date, policy).Version
+		tags = append(tags, tag)
+		r.releases = append(r.releases, Release{Tag: tag, parsed: parseTagInfo(r.tagTemplate, tag), planned: true})
+	}
+	return tags
+}
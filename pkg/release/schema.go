@@ -0,0 +1,235 @@
+package release
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaDraft is the JSON Schema version the schemas are written in, the one
+// editors support best
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaDocument is a document the tool reads or writes
+type schemaDocument struct {
+	title string
+	value interface{} // The document's zero value
+	tag   string      // The struct tag naming fields, yaml or json
+	// strict documents are parsed with yaml.UnmarshalStrict, unknown fields
+	// are an error
+	strict bool
+}
+
+var schemaDocuments = map[string]schemaDocument{
+	"config":          {title: ConfigFile, value: Config{}, tag: "yaml", strict: true},
+	"plan":            {title: "release plan", value: Plan{}, tag: "yaml", strict: true},
+	"build-manifest":  {title: "release build manifest", value: BuildManifest{}, tag: "json"},
+	"bundle-manifest": {title: "release bundle manifest", value: BundleManifest{}, tag: "json"},
+	"export":          {title: "release export and release list --json", value: []Export{}, tag: "json"},
+	"proposals":       {title: "release create --dry-run --json", value: []ProposedRelease{}, tag: "json"},
+}
+
+// schemaEnums are the values of string types that only take a few
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(Scheme("")):      {string(SchemeMonthly), string(SchemeOrdinal)},
+	reflect.TypeOf(ResetPolicy("")): {string(ResetMonthly), string(ResetYearly), string(ResetNever)},
+}
+
+// SchemaNames returns the documents there's a schema for, sorted
+func SchemaNames() []string {
+	names := []string{}
+	for name := range schemaDocuments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SchemaTitle describes the document a schema is for
+func SchemaTitle(name string) string {
+	return schemaDocuments[name].title
+}
+
+// Schema returns the JSON Schema of a document the tool reads or writes, see
+// SchemaNames. It's derived from the Go types so it can't drift from them.
+func Schema(name string) ([]byte, error) {
+	doc, ok := schemaDocuments[name]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s, pick one of %s", name, strings.Join(SchemaNames(), ", "))
+	}
+	b := &schemaBuilder{tag: doc.tag, strict: doc.strict, defs: map[string]interface{}{}}
+	t := reflect.TypeOf(doc.value)
+	var root map[string]interface{}
+	if t.Kind() == reflect.Struct {
+		// Inlined, draft-07 ignores whatever is next to a $ref
+		root = b.object(t)
+	} else {
+		root = b.schema(t)
+	}
+	schema := map[string]interface{}{
+		"$schema": SchemaDraft,
+		"title":   doc.title,
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+	if len(b.defs) > 0 {
+		schema["definitions"] = b.defs
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaBuilder struct {
+	tag    string
+	strict bool
+	defs   map[string]interface{}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema returns the schema of a type, structs are added to the definitions
+// and referenced
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case b.tag == "json" && t.Implements(jsonMarshalerType):
+		return map[string]interface{}{}
+	case t.Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && b.tag == "json" {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if pkg := path.Base(t.PkgPath()); pkg != "release" {
+			name = pkg + "." + name
+		}
+		if _, ok := b.defs[name]; !ok {
+			// Registered first so recursive types end up referencing it
+			b.defs[name] = nil
+			b.defs[name] = b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	}
+	// Interfaces and anything else take any value
+	return map[string]interface{}{}
+}
+
+// object returns the schema of a struct's fields, read the way the yaml or
+// json package reads them
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for idx := 0; idx < t.NumField(); idx++ {
+			f := t.Field(idx)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			parts := strings.Split(f.Tag.Get(b.tag), ",")
+			name, opts := parts[0], parts[1:]
+			if name == "-" && len(opts) == 0 {
+				continue
+			}
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if hasOpt(opts, "inline") || (b.tag == "json" && f.Anonymous && name == "" && ft.Kind() == reflect.Struct) {
+				addFields(ft)
+				continue
+			}
+			if f.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+				if b.tag == "yaml" {
+					name = strings.ToLower(name)
+				}
+			}
+			schema := b.schema(f.Type)
+			if b.tag == "json" && !hasOpt(opts, "omitempty") {
+				// Documents the tool writes always have the field, nil
+				// pointers, slices and maps are written as null
+				required = append(required, name)
+				switch f.Type.Kind() {
+				case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+					schema = nullable(schema)
+				}
+			}
+			properties[name] = schema
+		}
+	}
+	addFields(t)
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		object["required"] = required
+	}
+	if b.strict {
+		object["additionalProperties"] = false
+	}
+	return object
+}
+
+// nullable lets a schema take null too
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if kind, ok := schema["type"].(string); ok {
+		copied := map[string]interface{}{}
+		for key, value := range schema {
+			copied[key] = value
+		}
+		copied["type"] = []string{kind, "null"}
+		return copied
+	}
+	if len(schema) == 0 {
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+func hasOpt(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
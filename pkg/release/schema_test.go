@@ -0,0 +1,71 @@
+package release
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestSchemaPublished keeps the schemas in schema/ in sync with the types,
+// regenerate them with 'make schema'
+func TestSchemaPublished(t *testing.T) {
+	for _, name := range SchemaNames() {
+		want, err := Schema(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join("..", "..", "schema", name+".schema.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("schema/%s.schema.json is out of date, run 'make schema'", name)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema("config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Properties           map[string]map[string]interface{}
+		AdditionalProperties bool
+		Definitions          map[string]struct {
+			Properties map[string]interface{}
+			Required   []string
+		}
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config.Properties["tag_template"]; !ok || config.AdditionalProperties {
+		t.Errorf("config should have tag_template and no other properties, got %v", config.Properties)
+	}
+	if enum, _ := config.Properties["scheme"]["enum"].([]interface{}); len(enum) != 2 || enum[1] != string(SchemeOrdinal) {
+		t.Errorf("got scheme %v", config.Properties["scheme"])
+	}
+	if _, ok := config.Definitions["forge.Config"].Properties["token"]; ok {
+		t.Errorf("the forge token isn't read from the config")
+	}
+
+	data, err = Schema("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	required := map[string]bool{}
+	for _, name := range config.Definitions["Export"].Required {
+		required[name] = true
+	}
+	if !required["tag"] || required["supersedes"] {
+		t.Errorf("fields without omitempty should be required, got %v", config.Definitions["Export"].Required)
+	}
+	if _, err := Schema("nope"); err == nil {
+		t.Errorf("unknown documents should be an error")
+	}
+}
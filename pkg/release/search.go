@@ -0,0 +1,142 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// CommitNotesRef is where 'git notes' keeps notes unless told otherwise
+const CommitNotesRef = "refs/notes/commits"
+
+// SearchMatch is a line of a release that matched a search
+type SearchMatch struct {
+	// Source is where the line is from: tag, or the commit (or its note)
+	// like commit 1a2b3c4d
+	Source string `json:"source"`
+	Line   string `json:"line"`
+}
+
+// SearchHit is a release that matched a search, with the lines that did
+type SearchHit struct {
+	Tag     string        `json:"tag"`
+	Matches []SearchMatch `json:"matches"`
+}
+
+// SearchOptions configures Search
+type SearchOptions struct {
+	// Index only searches the tag messages and the messages of the released
+	// commits, in the index of releases. It's much faster than walking the
+	// history of every release.
+	Index bool
+	// Components are the configured components, a release of a component
+	// only has the commits touching its paths
+	Components map[string]ComponentConfig
+}
+
+// searchQuery are the lowercased words of a search
+type searchQuery []string
+
+// match adds the lines of text with any word of the query to the hit and
+// records the words found
+func (q searchQuery) match(hit *SearchHit, found map[string]bool, source, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		lower := strings.ToLower(line)
+		matched := false
+		for _, word := range q {
+			if strings.Contains(lower, word) {
+				found[word] = true
+				matched = true
+			}
+		}
+		if matched {
+			hit.Matches = append(hit.Matches, SearchMatch{Source: source, Line: strings.TrimSpace(line)})
+		}
+	}
+}
+
+// Search full-text searches releases: their tag messages, the messages of the
+// commits they released and the git notes (CommitNotesRef) of those commits.
+// A release matches when every word of the query is in it, case doesn't
+// matter. Hits are in the order of releases.
+func (r *Manager) Search(query string, releases []Release, opts SearchOptions) ([]*SearchHit, error) {
+	q := searchQuery(strings.Fields(strings.ToLower(query)))
+	if len(q) == 0 {
+		return nil, fmt.Errorf("nothing to search for")
+	}
+	var indexed map[string]Release
+	var notes map[string]string
+	var err error
+	if opts.Index {
+		if indexed, err = r.searchIndex(q); err != nil {
+			return nil, err
+		}
+	} else if notes, err = r.readNotes(plumbing.ReferenceName(CommitNotesRef)); err != nil {
+		return nil, err
+	}
+
+	hits := []*SearchHit{}
+	for _, rel := range releases {
+		hit := &SearchHit{Tag: rel.Tag, Matches: []SearchMatch{}}
+		found := map[string]bool{}
+		if opts.Index {
+			match, ok := indexed[rel.Tag]
+			if !ok {
+				continue
+			}
+			q.match(hit, found, "tag", match.ReleaseMessage)
+			q.match(hit, found, "commit "+match.Hash[:8], match.CommitMessage)
+		} else {
+			q.match(hit, found, "tag", rel.ReleaseMessage)
+			commits, err := r.releaseCommits(rel.Tag, rel.Hash, opts.Components[rel.Component()])
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the commits of %s: %w", rel.Tag, err)
+			}
+			for _, c := range commits {
+				q.match(hit, found, "commit "+c.Hash[:8], c.Message)
+				if note, ok := notes[c.Hash]; ok {
+					q.match(hit, found, "note "+c.Hash[:8], note)
+				}
+			}
+		}
+		if len(found) == len(q) {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+// searchIndex returns the releases in the index whose tag or commit message
+// has every word of the query, by tag
+func (r *Manager) searchIndex(q searchQuery) (map[string]Release, error) {
+	index, err := r.openIndex(false)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, fmt.Errorf("there's no index of releases, create it with 'release index'")
+	}
+	defer index.close()
+	where := []string{}
+	args := []interface{}{}
+	for _, word := range q {
+		// LIKE ignores the case of ASCII letters, match() does the rest
+		where = append(where, `(release_message || ' ' || commit_message) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word)+"%")
+	}
+	rows, err := index.db.Query(`SELECT tag, hash, release_message, commit_message FROM releases WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	matched := map[string]Release{}
+	for rows.Next() {
+		var rel Release
+		if err := rows.Scan(&rel.Tag, &rel.Hash, &rel.ReleaseMessage, &rel.CommitMessage); err != nil {
+			return nil, err
+		}
+		matched[rel.Tag] = rel
+	}
+	return matched, rows.Err()
+}
@@ -0,0 +1,57 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestSearch(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct{ commit, tag, msg string }{
+		{"add the payment client", "2020.07.001", ""},
+		{"fix payment retries\n\nThe gateway Timeout was too short.", "", ""},
+		{"docs", "2020.07.002", "retry payments longer"},
+	}
+	for _, s := range steps {
+		if _, err := repo.Commit(s.commit, nil); err != nil {
+			t.Fatal(err)
+		}
+		if s.tag != "" {
+			if _, err := repo.Tag(s.tag, s.msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := rm.Search("PAYMENT timeout", rm.Releases(), SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].Tag != "2020.07.002" {
+		t.Fatalf("expected only 2020.07.002 to match, got %+v", hits)
+	}
+	lines := map[string]bool{}
+	for _, m := range hits[0].Matches {
+		lines[m.Line] = true
+	}
+	for _, want := range []string{"retry payments longer", "fix payment retries", "The gateway Timeout was too short."} {
+		if !lines[want] {
+			t.Errorf("missing match %q in %+v", want, hits[0].Matches)
+		}
+	}
+
+	if hits, _ := rm.Search("client", rm.Releases(), SearchOptions{}); len(hits) != 1 || hits[0].Tag != "2020.07.001" {
+		t.Errorf("expected only 2020.07.001 to match, got %+v", hits)
+	}
+	if _, err := rm.Search("  ", rm.Releases(), SearchOptions{}); err == nil {
+		t.Error("an empty search should fail")
+	}
+}
@@ -0,0 +1,170 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+)
+
+// SemVerConfig gives every release of a component a SemVer tag next to its
+// CalVer one, for consumers that need SemVer (Go modules, package managers)
+// while deploys keep using CalVer. Both tags point at the same commit and are
+// pushed together.
+type SemVerConfig struct {
+	// Prefix goes before MAJOR.MINOR.PATCH, like sdk/v
+	Prefix string `yaml:"prefix"`
+	// Initial is the first version, 0.1.0 if empty
+	Initial string `yaml:"initial"`
+}
+
+func (c *SemVerConfig) validate() error {
+	if c.Prefix == "" {
+		return fmt.Errorf("semver.prefix is required, like sdk/v")
+	}
+	if strings.ContainsAny(c.Prefix, " ~^:?*[\\") || strings.HasSuffix(c.Prefix, "/") {
+		return fmt.Errorf("semver.prefix %s doesn't make valid tags, end it with v or a letter", c.Prefix)
+	}
+	if c.Initial != "" {
+		if _, ok := parseSemVer(c.Initial); !ok {
+			return fmt.Errorf("semver.initial %s isn't a version like 1.0.0", c.Initial)
+		}
+	}
+	return nil
+}
+
+type semVersion struct {
+	major, minor, patch int
+}
+
+var semVerSuffix = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)$`)
+
+// parseSemVer parses the MAJOR.MINOR.PATCH at the end of s, pre-releases
+// aren't counted
+func parseSemVer(s string) (semVersion, bool) {
+	m := semVerSuffix.FindStringSubmatch(s)
+	if m == nil {
+		return semVersion{}, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+	return semVersion{major, minor, patch}, true
+}
+
+func (v semVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+func (v semVersion) less(o semVersion) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+// conventionalFeature matches conventional commit subjects adding a feature
+var conventionalFeature = regexp.MustCompile(`^feat(?:\([^)]*\))?!?: `)
+
+// bump returns the next version after the commits: breaking changes bump the
+// major (the minor before 1.0.0), features the minor and anything else the
+// patch
+func (v semVersion) bump(commits []*Commit) semVersion {
+	breaking, feature := false, false
+	for _, c := range commits {
+		breaking = breaking || IsBreakingCommit(c.Message)
+		feature = feature || conventionalFeature.MatchString(Subject(c.Message))
+	}
+	switch {
+	case breaking && v.major > 0:
+		return semVersion{v.major + 1, 0, 0}
+	case breaking || feature:
+		return semVersion{v.major, v.minor + 1, 0}
+	}
+	return semVersion{v.major, v.minor, v.patch + 1}
+}
+
+// latestSemVer returns the highest SemVer tag with the prefix, empty if there
+// is none
+func (r *Manager) latestSemVer(prefix string) (string, semVersion, error) {
+	refs, err := r.gitBackend().Refs("refs/tags/" + prefix)
+	if err != nil {
+		return "", semVersion{}, err
+	}
+	latest, version := "", semVersion{}
+	for _, ref := range refs {
+		name := ref.Name.Short()
+		v, ok := parseSemVer(name)
+		if !ok || name != prefix+v.String() {
+			continue
+		}
+		if latest == "" || version.less(v) {
+			latest, version = name, v
+		}
+	}
+	return latest, version, nil
+}
+
+// NextSemVerTag returns the SemVer tag of the next release of the component
+// at target, bumped from its latest SemVer tag by the commits touching the
+// component since
+func (r *Manager) NextSemVerTag(component ComponentConfig, target string) (string, error) {
+	cfg := component.SemVer
+	latest, version, err := r.latestSemVer(cfg.Prefix)
+	if err != nil {
+		return "", err
+	}
+	if latest == "" {
+		initial, ok := parseSemVer(cfg.Initial)
+		if !ok {
+			initial = semVersion{0, 1, 0}
+		}
+		return cfg.Prefix + initial.String(), nil
+	}
+	since, err := r.tagCommit(latest)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", latest, err)
+	}
+	commits, err := r.commitsBetween(since.Hash, target)
+	if err != nil {
+		return "", err
+	}
+	if commits, err = r.componentCommits(commits, component); err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("nothing changed since %s, there's no new SemVer version to tag", latest)
+	}
+	return cfg.Prefix + version.bump(commits).String(), nil
+}
+
+// CreateSemVerTag tags the commit of a release that was just created with its
+// SemVer tag too. If that fails the release tag is deleted again, so either
+// both tags exist or neither does.
+func (r *Manager) CreateSemVerTag(tag, semverTag, message, user, email string, kind TagKind) error {
+	commit, err := r.tagCommit(tag)
+	if err == nil {
+		_, err = r.CreateTagAt(semverTag, commit.Hash, message, user, email, kind)
+	}
+	if err == nil {
+		return nil
+	}
+	if delErr := r.DeleteTag(tag); delErr != nil {
+		return fmt.Errorf("%w (and failed to delete %s again: %s)", err, tag, delErr)
+	}
+	return err
+}
+
+// TagRefSpecs returns the refspecs pushing the tags in a single push
+func TagRefSpecs(tags ...string) []config.RefSpec {
+	refSpecs := []config.RefSpec{}
+	for _, tag := range tags {
+		refSpecs = append(refSpecs, tagToRefspec(tag))
+	}
+	return refSpecs
+}
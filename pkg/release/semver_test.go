@@ -0,0 +1,66 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestCreateSemVerTagRollback(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("first", map[string]string{"a": "a\n"}); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rm.CreateTagOfKind("v1.0.0", "", "Jane Doe", "jane@example.com", TagLightweight); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rm.CreateTagOfKind("2020.07.001-api", "", "Jane Doe", "jane@example.com", TagLightweight); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.CreateSemVerTag("2020.07.001-api", "v1.0.0", "", "Jane Doe", "jane@example.com", TagLightweight); err == nil {
+		t.Fatal("creating an existing SemVer tag should fail")
+	}
+	if err := rm.DeleteTag("2020.07.001-api"); err == nil {
+		t.Errorf("the release tag should have been deleted again")
+	}
+}
+
+func TestSemVerBump(t *testing.T) {
+	commits := func(messages ...string) []*Commit {
+		c := []*Commit{}
+		for _, m := range messages {
+			c = append(c, &Commit{Message: m})
+		}
+		return c
+	}
+	for _, tc := range []struct {
+		from     string
+		messages []string
+		want     string
+	}{
+		{"sdk/v1.2.3", []string{"fix: typo"}, "1.2.4"},
+		{"sdk/v1.2.3", []string{"fix: typo", "feat(api): add Get"}, "1.3.0"},
+		{"sdk/v1.2.3", []string{"feat!: drop Get"}, "2.0.0"},
+		{"sdk/v1.2.3", []string{"refactor\n\nBREAKING CHANGE: renamed"}, "2.0.0"},
+		{"sdk/v0.4.1", []string{"feat!: drop Get"}, "0.5.0"},
+		{"sdk/v0.4.1", []string{"features"}, "0.4.2"},
+	} {
+		v, ok := parseSemVer(tc.from)
+		if !ok {
+			t.Fatalf("failed to parse %s", tc.from)
+		}
+		if got := v.bump(commits(tc.messages...)).String(); got != tc.want {
+			t.Errorf("%s after %q: got %s, want %s", tc.from, tc.messages, got, tc.want)
+		}
+	}
+	if _, ok := parseSemVer("sdk/v1.2"); ok {
+		t.Error("parsed sdk/v1.2")
+	}
+}
@@ -0,0 +1,72 @@
+package release
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultSkewTolerance is how far ahead a release can be dated before it's
+// reported, commits made on another machine a few seconds ago are fine
+const defaultSkewTolerance = time.Minute
+
+// ClockSkewConfig guards against releasing with a clock that's behind the
+// dates of existing releases, the versions and dates of the new release
+// would be out of order
+type ClockSkewConfig struct {
+	// Tolerance is how far in the future a release can be dated, like 5m
+	Tolerance string `yaml:"tolerance"`
+	// Refuse makes skew fatal instead of a warning, unless --allow-clock-skew
+	Refuse bool `yaml:"refuse"`
+
+	tolerance time.Duration
+}
+
+func (c *ClockSkewConfig) validate() error {
+	if c.Tolerance != "" {
+		d, err := ParseAge(c.Tolerance)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid clock_skew.tolerance '%s', must be a duration like 5m", c.Tolerance)
+		}
+		c.tolerance = d
+	}
+	return nil
+}
+
+// ClockSkew is a release dated after the local clock
+type ClockSkew struct {
+	Tag   string
+	Date  time.Time // The tagger or committer date, whichever is later
+	Ahead time.Duration
+}
+
+func (s *ClockSkew) String() string {
+	return fmt.Sprintf("%s is dated %s, %s after the local clock", s.Tag, s.Date.Format(time.RFC3339), s.Ahead.Round(time.Second))
+}
+
+// CheckClockSkew returns the release of now's month (or later) dated furthest
+// after now, nil if none are more than the tolerance ahead
+func (r *Manager) CheckClockSkew(now time.Time, cfg ClockSkewConfig) *ClockSkew {
+	tolerance := cfg.tolerance
+	if cfg.Tolerance == "" {
+		tolerance = defaultSkewTolerance
+	}
+	month := newCalVerStandard(uint64(now.Year()), uint64(now.Month()), 0)
+	var skew *ClockSkew
+	for idx := range r.releases {
+		rel := &r.releases[idx]
+		version := rel.info().version
+		if version == nil || comparePeriod(version, month) < 0 {
+			continue
+		}
+		date := rel.Committer.When
+		if rel.Tagger != nil && rel.Tagger.When.After(date) {
+			date = rel.Tagger.When
+		}
+		ahead := date.Sub(now)
+		if ahead <= tolerance || (skew != nil && ahead <= skew.Ahead) {
+			continue
+		}
+		skew = &ClockSkew{Tag: rel.Tag, Date: date, Ahead: ahead}
+	}
+	return skew
+}
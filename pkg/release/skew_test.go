@@ -0,0 +1,38 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Date(2020, 7, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) object.Signature {
+		return object.Signature{When: now.Add(d)}
+	}
+	tagger := at(3 * time.Hour)
+	rm := &Manager{releases: releaseList{
+		{Tag: "2020.07.001-api", Committer: at(-time.Hour)},
+		{Tag: "2020.07.002-api", Committer: at(30 * time.Second)},
+		{Tag: "2020.07.003-api", Committer: at(time.Hour), Tagger: &tagger},
+		// Older months don't matter, their versions sort first anyway
+		{Tag: "2020.06.009-api", Committer: at(24 * time.Hour)},
+	}}
+	skew := rm.CheckClockSkew(now, ClockSkewConfig{})
+	if skew == nil || skew.Tag != "2020.07.003-api" || skew.Ahead != 3*time.Hour {
+		t.Fatalf("got %+v", skew)
+	}
+
+	cfg := ClockSkewConfig{Tolerance: "4h"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if skew := rm.CheckClockSkew(now, cfg); skew != nil {
+		t.Errorf("3h is within the tolerance, got %+v", skew)
+	}
+	if err := (&ClockSkewConfig{Tolerance: "soon"}).validate(); err == nil {
+		t.Errorf("invalid tolerances should fail")
+	}
+}
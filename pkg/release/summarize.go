@@ -0,0 +1,39 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Summarize pipes the release notes of a new release, one "- note" per line,
+// through the configured summarizer command (messages.summarize) and returns
+// what it printed. The command is run with sh in dir and gets the tag,
+// component and version like hooks do. Nothing is built in, the command can
+// be anything that reads a list and writes a message.
+func Summarize(command, dir string, rel *Release, notes []string) (string, error) {
+	input := &strings.Builder{}
+	for _, note := range notes {
+		fmt.Fprintf(input, "- %s\n", note)
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"RELEASE_TAG="+rel.Tag,
+		"RELEASE_COMPONENT="+rel.Component(),
+		"RELEASE_VERSION="+rel.Version(),
+	)
+	cmd.Stdin = strings.NewReader(input.String())
+	stdout := &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("summarizer '%s' failed: %w", command, err)
+	}
+	summary := strings.TrimSpace(stdout.String())
+	if summary == "" {
+		return "", fmt.Errorf("summarizer '%s' printed nothing", command)
+	}
+	return summary, nil
+}
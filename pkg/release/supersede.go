@@ -0,0 +1,62 @@
+package release
+
+import (
+	"fmt"
+	"time"
+)
+
+// SupersedesTrailer records the release a release replaces. Release tags are
+// never moved, a broken release is fixed by a new version that supersedes it.
+const SupersedesTrailer = "Supersedes"
+
+// Supersedes returns the tag of the release this release replaces, empty if
+// it doesn't replace one
+func (r *Release) Supersedes() string {
+	for _, t := range annotationTrailers(r.ReleaseMessage) {
+		if t.Key == SupersedesTrailer {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// SupersededBy returns the release that replaces the given tag, nil if it
+// isn't superseded
+func (r *Manager) SupersededBy(tag string) *Release {
+	for idx := range r.releases {
+		if r.releases[idx].Supersedes() == tag {
+			return &r.releases[idx]
+		}
+	}
+	return nil
+}
+
+// ProposeSupersede proposes the release replacing tag with the corrected
+// target commit (HEAD if empty): the next version of the same component and
+// pre-release marker. Releases that are already superseded, or a target that
+// is the release's own commit, are an error.
+func (r *Manager) ProposeSupersede(tag, target string, now time.Time, policy ResetPolicy) (*ProposedRelease, error) {
+	old := r.FindRelease(tag)
+	if old == nil || old.info().version == nil {
+		return nil, fmt.Errorf("%s isn't a release", tag)
+	}
+	if newer := r.SupersededBy(tag); newer != nil {
+		return nil, fmt.Errorf("%s is already superseded by %s, supersede that instead", tag, newer.Tag)
+	}
+	hash, err := r.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if hash == old.Hash {
+		return nil, fmt.Errorf("%s already points at %s, a superseding release needs the corrected commit", tag, hash[:8])
+	}
+	name := old.Component()
+	if old.IsPreRelease() {
+		name += "-" + old.PreRelease()
+	}
+	// Proposed as if releasing the target, whatever the manager releases
+	// otherwise
+	defer r.UseTarget(r.target)
+	r.UseTarget(hash)
+	return r.GetProposedReleaseAt(name, now, policy)
+}
@@ -0,0 +1,59 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestProposeSupersede(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken, err := repo.Commit("broken", map[string]string{"main.go": "package main\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := repo.Commit("fixed", map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.AlwaysIncludeNumber = true
+	now := time.Date(2020, 7, 20, 0, 0, 0, 0, time.UTC)
+	if _, err := rm.CreateTagAt("2020.07.001-api", broken.String(), "broken", "Jane Doe", "jane@example.com", TagAnnotated); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+
+	if _, err := rm.ProposeSupersede("2020.07.001-api", broken.String(), now, ResetMonthly); err == nil {
+		t.Errorf("superseding with the release's own commit should fail")
+	}
+	if _, err := rm.ProposeSupersede("2020.07.001-web", "", now, ResetMonthly); err == nil {
+		t.Errorf("superseding a missing release should fail")
+	}
+	proposal, err := rm.ProposeSupersede("2020.07.001-api", "", now, ResetMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.TagName != "2020.07.002-api" || proposal.TargetCommit != fixed.String() {
+		t.Errorf("got %s at %s", proposal.TagName, proposal.TargetCommit)
+	}
+
+	message := AppendTrailer("fixed", SupersedesTrailer, "2020.07.001-api")
+	if _, err := rm.CreateTagAt(proposal.TagName, proposal.TargetCommit, message, "Jane Doe", "jane@example.com", TagAnnotated); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+	if newer := rm.SupersededBy("2020.07.001-api"); newer == nil || newer.Tag != "2020.07.002-api" {
+		t.Errorf("got %+v", newer)
+	}
+	if _, err := rm.ProposeSupersede("2020.07.001-api", "", now, ResetMonthly); err == nil {
+		t.Errorf("a superseded release should not be superseded again")
+	}
+}
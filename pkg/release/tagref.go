@@ -0,0 +1,96 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"golang.org/x/crypto/openpgp"
+)
+
+// AlreadyExistsError is returned when the tag being created already exists,
+// including when another release created it while we were creating ours
+type AlreadyExistsError struct {
+	Tag  string
+	Hash string // What the existing tag points to, empty if unknown
+}
+
+func (e *AlreadyExistsError) Error() string {
+	if e.Hash == "" {
+		return fmt.Sprintf("tag %s already exists", e.Tag)
+	}
+	return fmt.Sprintf("tag %s already exists (%s)", e.Tag, e.Hash)
+}
+
+// Is makes the error match git.ErrTagExists so callers checking for go-git's
+// error keep working
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == git.ErrTagExists
+}
+
+// createTagRef points refs/tags/name at target, but only if the ref doesn't
+// exist. The check and the write happen under the ref's lock so two
+// concurrent releases can't both create the same tag.
+func (r *Manager) createTagRef(name, target string) (*Ref, error) {
+	rname := plumbing.ReferenceName(path.Join("refs", "tags", name))
+	existing, err := r.gitBackend().Reference(rname)
+	switch err {
+	case nil:
+		return nil, &AlreadyExistsError{Tag: name, Hash: existing}
+	case plumbing.ErrReferenceNotFound:
+	default:
+		return nil, err
+	}
+	err = r.gitBackend().CreateReference(rname, target)
+	if errors.Is(err, storage.ErrReferenceHasChanged) {
+		return nil, &AlreadyExistsError{Tag: name}
+	} else if err != nil {
+		return nil, err
+	}
+	return &Ref{Name: rname, Hash: target}, nil
+}
+
+// createTagObject stores an annotated (and maybe signed) tag object for
+// target and returns its hash, the tag ref isn't touched
+func (r *Manager) createTagObject(name, target, message string, tagger object.Signature) (string, error) {
+	rawobj, err := r.gitBackend().Object(target)
+	if err != nil {
+		return "", err
+	}
+	payload, err := encodeTag(name, target, rawobj.Type(), tagger, strings.TrimSpace(message)+"\n")
+	if err != nil {
+		return "", err
+	}
+	if r.SignKey != nil {
+		signature := &bytes.Buffer{}
+		if err := openpgp.ArmoredDetachSign(signature, r.SignKey, bytes.NewReader(payload), nil); err != nil {
+			return "", err
+		}
+		payload = append(payload, signature.Bytes()...)
+	}
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(plumbing.TagObject)
+	if _, err := obj.Write(payload); err != nil {
+		return "", err
+	}
+	return r.gitBackend().WriteObject(obj)
+}
+
+// encodeTag encodes a tag object without a signature, the way go-git does
+// but with the full hash of the target so it works in SHA-256 repositories
+// too. The message has to end with a newline.
+func encodeTag(name, target string, targetType plumbing.ObjectType, tagger object.Signature, message string) ([]byte, error) {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "object %s\ntype %s\ntag %s\ntagger ", target, targetType, name)
+	if err := tagger.Encode(b); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(b, "\n\n%s", message)
+	return b.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+func TestCreateTagRefExists(t *testing.T) {
+	rm, repo := newTestManager(t, "2020.07.001")
+	head, err := repo.Repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rm.createTagRef("2020.07.002", head.Hash().String()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = rm.createTagRef("2020.07.002", plumbing.ZeroHash.String())
+	var exists *AlreadyExistsError
+	if !errors.As(err, &exists) {
+		t.Fatalf("got %v, want an *AlreadyExistsError", err)
+	}
+	if exists.Hash != head.Hash().String() {
+		t.Errorf("got the existing hash %s, want %s", exists.Hash, head.Hash())
+	}
+	if !errors.Is(err, git.ErrTagExists) {
+		t.Error("the error doesn't match git.ErrTagExists")
+	}
+}
+
+func TestCreateReferenceCheckAndSet(t *testing.T) {
+	rm, repo := newTestManager(t, "2020.07.001")
+	head, err := repo.Repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Someone else created the ref after createTagRef looked
+	name := plumbing.NewTagReferenceName("2020.07.001")
+	if err := rm.gitBackend().CreateReference(name, head.Hash().String()); !errors.Is(err, storage.ErrReferenceHasChanged) {
+		t.Errorf("got %v, want %v", err, storage.ErrReferenceHasChanged)
+	}
+}
+
+func TestCreateTagRefConcurrent(t *testing.T) {
+	repo, err := releasetest.NewTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Cleanup()
+	targets := []plumbing.Hash{}
+	for i := 0; i < 8; i++ {
+		hash, err := repo.Commit(fmt.Sprintf("commit %d", i), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, hash)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(targets))
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every release opens the repository itself, like separate processes
+			r, err := git.PlainOpen(repo.Dir)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			rm, err := NewManagerFromRepo(r, "%Y.%m.", "%03d")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			_, errs[i] = rm.createTagRef("2020.07.001", targets[i].String())
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for i, err := range errs {
+		var exists *AlreadyExistsError
+		switch {
+		case err == nil:
+			created++
+			ref, err := repo.Repo.Reference(plumbing.NewTagReferenceName("2020.07.001"), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ref.Hash() != targets[i] {
+				t.Errorf("release %d succeeded but the tag points at %s", i, ref.Hash())
+			}
+		case errors.As(err, &exists):
+		default:
+			t.Errorf("release %d: %s", i, err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d releases created the tag, want 1", created)
+	}
+}
@@ -0,0 +1,280 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TagData is what tag_template is executed with
+type TagData struct {
+	Year      uint64
+	Month     uint64
+	Day       uint64 // Day of the year, only set for the YYYY.DDD.N scheme
+	Inc       uint64
+	Component string
+}
+
+// TagTemplate is a tag_template along with the pattern that reads tags it
+// created back
+type TagTemplate struct {
+	text    string
+	tmpl    *template.Template
+	pattern *regexp.Regexp
+	ordinal bool // Uses .Day, so it's for the YYYY.DDD.N scheme
+	// prefix is the text before a {{.Component}} outside of an if, it's left
+	// out with the component for releases without one
+	prefix *string
+}
+
+// UseTagTemplate makes the manager name every tag with the given tag_template
+// and read them with the pattern derived from it, tags in the built-in
+// formats are still read. An empty template goes back to the built-in format.
+// Releases that were already loaded are read again.
+func (r *Manager) UseTagTemplate(text string) error {
+	var t *TagTemplate
+	if text != "" {
+		var err error
+		if t, err = ParseTagTemplate(text); err != nil {
+			return err
+		}
+	}
+	r.tagTemplate = t
+	if r.releases != nil {
+		r.loadGitTags()
+	}
+	return nil
+}
+
+// useConfiguredTagTemplate uses the tag_template of the repository's config,
+// for managers that aren't created from a working directory. A broken config
+// is reported by the commands that need the rest of it.
+func (r *Manager) useConfiguredTagTemplate() error {
+	cfg, err := r.LoadConfig()
+	if err != nil {
+		log.Debug().Err(err).Msgf("failed to load %s, using the built-in tag format", ConfigFile)
+		return nil
+	}
+	return r.UseTagTemplate(cfg.TagTemplate)
+}
+
+// TagTemplate returns the tag_template the manager names and reads tags with,
+// nil for the built-in format
+func (r *Manager) TagTemplate() *TagTemplate {
+	return r.tagTemplate
+}
+
+// ParseRelease returns the release of a tag read with the manager's
+// tag_template, for the Component, Version and PreRelease of tags that
+// haven't been created or loaded
+func (r *Manager) ParseRelease(tag string) *Release {
+	return &Release{Tag: tag, parsed: parseTagInfo(r.tagTemplate, tag)}
+}
+
+// tagFieldPatterns are the groups each field reads when it isn't padded
+var tagFieldPatterns = map[string]string{
+	"Year":      `\d{4}`,
+	"Month":     `\d{1,2}`,
+	"Day":       `\d{1,3}`,
+	"Inc":       `\d+`,
+	"Component": `.+`,
+}
+
+// padPat is the only printf format allowed for the numbers, %d or %0Nd
+var padPat = regexp.MustCompile(`^%(?:0(\d+))?d$`)
+
+// ParseTagTemplate parses a tag_template like
+// {{.Year}}.{{printf "%02d" .Month}}.{{printf "%03d" .Inc}}-{{.Component}}
+// and derives the pattern matching the tags it creates. Only the TagData
+// fields, printf with %d or %0Nd and {{if .Component}} are allowed so every
+// tag can be read back. Like the built-in format, the text right before
+// {{.Component}} is left out for releases without a component.
+func ParseTagTemplate(text string) (*TagTemplate, error) {
+	tmpl, err := template.New("tag_template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Tree == nil {
+		return nil, fmt.Errorf("tag_template is empty")
+	}
+	t := &TagTemplate{text: text, tmpl: tmpl}
+	seen := map[string]bool{}
+	expr, err := t.expr(tmpl.Tree.Root, seen, true)
+	if err != nil {
+		return nil, fmt.Errorf("tag_template: %w", err)
+	}
+	if !seen["Year"] || !seen["Inc"] || !(seen["Month"] || seen["Day"]) {
+		return nil, fmt.Errorf("tag_template needs .Year, .Inc and .Month or .Day")
+	}
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("tag_template: %w", err)
+	}
+	t.pattern, t.ordinal = pattern, seen["Day"]
+
+	// Tags have to read back as what they were created from, this catches
+	// fields running into each other like {{.Month}}{{.Inc}}
+	sample := newCalVerStandard(2020, 7, 1)
+	if t.ordinal {
+		sample = newOrdinalCalVer(2020, 189, 1)
+	}
+	for _, component := range []string{"api", ""} {
+		name, err := t.format(sample, component)
+		if err != nil {
+			return nil, fmt.Errorf("tag_template: %w", err)
+		}
+		version, got, ok := t.parse(name)
+		if !ok || version.Compare(sample) != 0 || got != component {
+			return nil, fmt.Errorf("tag_template creates %s, which doesn't read back", name)
+		}
+	}
+	return t, nil
+}
+
+// expr turns the template's nodes into a regular expression, recording the
+// fields used in seen. top is false inside {{if .Component}}.
+func (t *TagTemplate) expr(list *parse.ListNode, seen map[string]bool, top bool) (string, error) {
+	if list == nil {
+		return "", nil
+	}
+	var pieces []string
+	text := "" // The text right before the current node
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			text = string(n.Text)
+			pieces = append(pieces, regexp.QuoteMeta(text))
+			continue
+		case *parse.ActionNode:
+			expr, err := tagActionExpr(n.Pipe, seen)
+			if err != nil {
+				return "", err
+			}
+			if top && tagPipeField(n.Pipe) == "Component" {
+				prefix := text
+				t.prefix = &prefix
+				if prefix != "" {
+					pieces = pieces[:len(pieces)-1]
+				}
+				expr = "(?:" + regexp.QuoteMeta(prefix) + expr + ")?"
+			}
+			pieces = append(pieces, expr)
+		case *parse.IfNode:
+			if n.ElseList != nil || tagPipeField(n.Pipe) != "Component" {
+				return "", fmt.Errorf("only {{if .Component}}...{{end}} is supported, got %s", n)
+			}
+			inner, err := t.expr(n.List, seen, false)
+			if err != nil {
+				return "", err
+			}
+			if !seen["Component"] {
+				return "", fmt.Errorf("{{if .Component}} has to use .Component")
+			}
+			pieces = append(pieces, "(?:"+inner+")?")
+		default:
+			return "", fmt.Errorf("unsupported %s", n)
+		}
+		text = ""
+	}
+	return strings.Join(pieces, ""), nil
+}
+
+// tagActionExpr returns the group for {{.Field}} or {{printf "%0Nd" .Field}}
+func tagActionExpr(pipe *parse.PipeNode, seen map[string]bool) (string, error) {
+	field := tagPipeField(pipe)
+	width := ""
+	if field == "" && len(pipe.Decl) == 0 && len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 3 {
+		args := pipe.Cmds[0].Args
+		fn, isIdent := args[0].(*parse.IdentifierNode)
+		format, isString := args[1].(*parse.StringNode)
+		arg, isField := args[2].(*parse.FieldNode)
+		if isIdent && fn.Ident == "printf" && isString && isField && len(arg.Ident) == 1 {
+			results := padPat.FindStringSubmatch(format.Text)
+			if results == nil || arg.Ident[0] == "Component" {
+				return "", fmt.Errorf("only %%d and %%0Nd can format numbers, got %s", pipe)
+			}
+			field, width = arg.Ident[0], results[1]
+		}
+	}
+	pat, ok := tagFieldPatterns[field]
+	if !ok {
+		return "", fmt.Errorf("unsupported {{%s}}, use a field of TagData or printf", pipe)
+	}
+	if seen[field] {
+		return "", fmt.Errorf(".%s is used twice", field)
+	}
+	seen[field] = true
+	if width != "" {
+		pat = `\d{` + width + `,}`
+	}
+	return fmt.Sprintf("(?P<%s>%s)", strings.ToLower(field), pat), nil
+}
+
+// tagPipeField returns the field name of a pipeline that's only {{.Field}}
+func tagPipeField(pipe *parse.PipeNode) string {
+	if len(pipe.Decl) != 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return ""
+	}
+	if f, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode); ok && len(f.Ident) == 1 {
+		return f.Ident[0]
+	}
+	return ""
+}
+
+// String returns the template as it was configured
+func (t *TagTemplate) String() string {
+	return t.text
+}
+
+// componentMark stands in for an empty component so the text before it can be
+// left out
+const componentMark = "\x00"
+
+// format executes the template for the given version and component
+func (t *TagTemplate) format(c *calVerStandard, component string) (string, error) {
+	var b strings.Builder
+	data := TagData{Year: c.Year, Month: c.Month, Day: c.Day, Inc: c.Release, Component: component}
+	if component == "" && t.prefix != nil {
+		data.Component = componentMark
+	}
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	if data.Component == componentMark {
+		return strings.Replace(b.String(), *t.prefix+componentMark, "", 1), nil
+	}
+	return b.String(), nil
+}
+
+// parse reads a tag created by the template, like splitTag does for the
+// built-in formats
+func (t *TagTemplate) parse(tag string) (*calVerStandard, string, bool) {
+	results := t.pattern.FindStringSubmatch(tag)
+	if results == nil {
+		return nil, "", false
+	}
+	values := map[string]uint64{}
+	component := ""
+	for idx, name := range t.pattern.SubexpNames() {
+		switch name {
+		case "":
+		case "component":
+			component = results[idx]
+		default:
+			values[name], _ = strconv.ParseUint(results[idx], 10, 64)
+		}
+	}
+	if t.ordinal {
+		version := newOrdinalCalVer(values["year"], values["day"], values["inc"])
+		return version, component, version != nil
+	}
+	if values["month"] < 1 || values["month"] > 12 {
+		return nil, "", false
+	}
+	return newCalVerStandard(values["year"], values["month"], values["inc"]), component, true
+}
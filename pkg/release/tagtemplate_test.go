@@ -0,0 +1,118 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/fernferret/release/pkg/release/releasetest"
+)
+
+func TestTagTemplate(t *testing.T) {
+	tmpl, err := ParseTagTemplate(`v{{.Year}}{{printf "%02d" .Month}}.{{.Inc}}{{if .Component}}+{{.Component}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := newCalVerStandard(2020, 7, 12)
+	if got := version.FormatRelease(tmpl, "api"); got != "v202007.12+api" {
+		t.Errorf("got %s", got)
+	}
+	if got := version.FormatRelease(tmpl, ""); got != "v202007.12" {
+		t.Errorf("got %s", got)
+	}
+	for tag, component := range map[string]string{"v202007.12+api": "api", "v202007.12": "", "2020.07.012-api": "api"} {
+		parsed, got, ok := splitTag(tmpl, tag)
+		if !ok || parsed.Compare(version) != 0 || got != component {
+			t.Errorf("%s: got %v %q %v", tag, parsed, got, ok)
+		}
+	}
+	if _, _, ok := splitTag(tmpl, "v202013.1"); ok {
+		t.Errorf("month 13 should not parse")
+	}
+
+	// Ordinal versions still get the built-in format from a monthly template
+	if got := newOrdinalCalVer(2020, 183, 1).FormatRelease(tmpl, "api"); got != "2020.183.1-api" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestTagTemplateComponent(t *testing.T) {
+	tmpl, err := ParseTagTemplate(`{{.Year}}_{{printf "%02d" .Month}}_{{printf "%03d" .Inc}}-{{.Component}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := newCalVerStandard(2020, 7, 3)
+	for component, want := range map[string]string{"api": "2020_07_003-api", "": "2020_07_003"} {
+		got, err := tmpl.format(version, component)
+		if err != nil || got != want {
+			t.Errorf("got %s %v, want %s", got, err, want)
+		}
+		if _, parsed, ok := tmpl.parse(want); !ok || parsed != component {
+			t.Errorf("%s: got %q %v", want, parsed, ok)
+		}
+	}
+}
+
+func TestParseTagTemplateInvalid(t *testing.T) {
+	for _, text := range []string{
+		`{{.Year}}.{{.Inc}}`,
+		`{{.Year}}.{{.Month}}.{{.Inc}}.{{.Inc}}`,
+		`{{.Year}}{{.Month}}{{.Inc}}`,
+		`{{.Year}}.{{.Month}}.{{.Inc}}-{{.Branch}}`,
+		`{{.Year}}.{{printf "%x" .Month}}.{{.Inc}}`,
+		`{{.Year}}.{{.Month}}.{{.Inc}}{{if .Inc}}-{{.Component}}{{end}}`,
+		`{{.Year}}.{{.Month}}.{{.Inc}}-{{.Component | printf "%s"}}`,
+		`{{.Year}`,
+	} {
+		if _, err := ParseTagTemplate(text); err == nil {
+			t.Errorf("%s should be invalid", text)
+		}
+	}
+	if _, err := ParseConfig([]byte(`{scheme: YYYY.DDD.N, tag_template: "{{.Year}}.{{.Month}}.{{.Inc}}"}`)); err == nil {
+		t.Errorf("a monthly template should not be allowed with the ordinal scheme")
+	}
+	if _, err := ParseConfig([]byte(`{scheme: YYYY.DDD.N, tag_template: "{{.Year}}.{{printf \"%03d\" .Day}}.{{.Inc}}-{{.Component}}"}`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestManagerTagTemplate(t *testing.T) {
+	repo, err := releasetest.NewMemoryRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := "tag_template: \"v{{.Year}}{{printf \\\"%02d\\\" .Month}}.{{.Inc}}{{if .Component}}+{{.Component}}{{end}}\"\n"
+	if _, err := repo.Commit("config", map[string]string{ConfigFile: config}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("v202007.3+api", "api"); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.TagTemplate() == nil {
+		t.Fatalf("the tag_template of %s should be used", ConfigFile)
+	}
+	if releases := rm.Releases(); len(releases) != 1 || releases[0].Component() != "api" || releases[0].Version() != "v202007.3" {
+		t.Errorf("got %+v", releases)
+	}
+
+	// Another manager with the built-in format leaves the first one alone
+	other, err := NewManagerFromRepo(repo.Repo, "%Y.%m.", "%03d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.UseTagTemplate(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Releases()) != 0 {
+		t.Errorf("v202007.3+api should not be a release without the template")
+	}
+	if got := rm.ParseRelease("v202008.1+web").Component(); got != "web" {
+		t.Errorf("got %q", got)
+	}
+	if got := other.ParseRelease("v202008.1+web").Component(); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,191 @@
+package release
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// TLSFiles are the CA bundle and client certificate used to talk to a host
+// over https, empty fields use the defaults
+type TLSFiles struct {
+	CAFile   string // PEM bundle trusted on top of the system roots
+	CertFile string // Client certificate for mTLS
+	KeyFile  string // Its key, the certificate file is used if empty
+}
+
+func (f TLSFiles) empty() bool {
+	return f.CAFile == "" && f.CertFile == "" && f.KeyFile == ""
+}
+
+// merge returns f with the fields o sets replaced
+func (f TLSFiles) merge(o TLSFiles) TLSFiles {
+	if o.CAFile != "" {
+		f.CAFile = o.CAFile
+	}
+	if o.CertFile != "" {
+		f.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		f.KeyFile = o.KeyFile
+	}
+	return f
+}
+
+func (f TLSFiles) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if f.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := ioutil.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", f.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if f.CertFile != "" {
+		key := f.KeyFile
+		if key == "" {
+			key = f.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(f.CertFile, key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate %s: %w", f.CertFile, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	} else if f.KeyFile != "" {
+		return nil, fmt.Errorf("a client key (%s) needs a client certificate", f.KeyFile)
+	}
+	return cfg, nil
+}
+
+// GitTLSSettings reads the TLS settings git itself uses, so remotes behind a
+// corporate PKI work the same for release as for git: http.sslCAInfo,
+// http.sslCert and http.sslKey from ~/.gitconfig and the repository's config
+// (when repoDir isn't empty), the same keys in http.<url> sections for single
+// hosts, and the GIT_SSL_CAINFO, GIT_SSL_CERT and GIT_SSL_KEY environment
+// variables, which win over everything.
+func GitTLSSettings(repoDir string) (TLSFiles, map[string]TLSFiles, error) {
+	global := TLSFiles{}
+	hosts := map[string]TLSFiles{}
+	raws := []*format.Config{}
+	if cfg, err := config.LoadConfig(config.GlobalScope); err == nil {
+		raws = append(raws, cfg.Raw)
+	}
+	if repoDir != "" {
+		f, err := os.Open(filepath.Join(repoDir, ".git", "config"))
+		if err == nil {
+			raw := format.New()
+			err = format.NewDecoder(f).Decode(raw)
+			f.Close()
+			if err != nil {
+				return global, hosts, fmt.Errorf("reading the repository's git config: %w", err)
+			}
+			raws = append(raws, raw)
+		}
+	}
+	for _, raw := range raws {
+		section := raw.Section("http")
+		global = global.merge(tlsOptions(section.Options))
+		for _, sub := range section.Subsections {
+			u, err := url.Parse(sub.Name)
+			if err != nil || u.Host == "" {
+				continue
+			}
+			hosts[u.Host] = hosts[u.Host].merge(tlsOptions(sub.Options))
+		}
+	}
+	global = global.merge(TLSFiles{
+		CAFile:   os.Getenv("GIT_SSL_CAINFO"),
+		CertFile: os.Getenv("GIT_SSL_CERT"),
+		KeyFile:  os.Getenv("GIT_SSL_KEY"),
+	})
+	return global, hosts, nil
+}
+
+func tlsOptions(opts format.Options) TLSFiles {
+	return TLSFiles{
+		CAFile:   expandHome(opts.Get("sslCAInfo")),
+		CertFile: expandHome(opts.Get("sslCert")),
+		KeyFile:  expandHome(opts.Get("sslKey")),
+	}
+}
+
+// expandHome expands ~/ like git does for path settings
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// UseTLS makes every https request (git remotes, forge APIs, webhooks) trust
+// the CA bundles and present the client certificates configured for the
+// host, hosts without their own settings use the global ones. Hosts are
+// matched as host or host:port. Nothing changes if there are no settings.
+func UseTLS(global TLSFiles, hosts map[string]TLSFiles) error {
+	if global.empty() && len(hosts) == 0 {
+		return nil
+	}
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		// Offline mode or something else already replaced the transport
+		return nil
+	}
+	newTransport := func(files TLSFiles) (http.RoundTripper, error) {
+		if files.empty() {
+			return base, nil
+		}
+		cfg, err := files.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		t := base.Clone()
+		t.TLSClientConfig = cfg
+		return t, nil
+	}
+	t := &hostTransport{hosts: map[string]http.RoundTripper{}}
+	var err error
+	if t.fallback, err = newTransport(global); err != nil {
+		return err
+	}
+	for host, files := range hosts {
+		if t.hosts[host], err = newTransport(global.merge(files)); err != nil {
+			return fmt.Errorf("%s: %w", host, err)
+		}
+	}
+	http.DefaultTransport = t
+	return nil
+}
+
+// hostTransport picks the transport (and so the TLS settings) by host
+type hostTransport struct {
+	hosts    map[string]http.RoundTripper
+	fallback http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := t.hosts[req.URL.Host]; ok {
+		return rt.RoundTrip(req)
+	}
+	if rt, ok := t.hosts[req.URL.Hostname()]; ok {
+		return rt.RoundTrip(req)
+	}
+	return t.fallback.RoundTrip(req)
+}
@@ -0,0 +1,87 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Trailer is a git style "Key: value" line at the end of a message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+var trailerPat = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*): (.*)$`)
+
+// splitTrailers splits a message into its body and the trailer block, the
+// trailer block is the last paragraph if every line of it is a trailer and it
+// isn't the only paragraph
+func splitTrailers(message string) (string, []string) {
+	message = strings.TrimRight(message, "\n")
+	idx := strings.LastIndex(message, "\n\n")
+	if idx < 0 {
+		return message, nil
+	}
+	lines := strings.Split(message[idx+2:], "\n")
+	for _, line := range lines {
+		if !trailerPat.MatchString(line) {
+			return message, nil
+		}
+	}
+	return message[:idx], lines
+}
+
+// ParseTrailers returns the trailers at the end of a message
+func ParseTrailers(message string) []Trailer {
+	_, lines := splitTrailers(message)
+	trailers := []Trailer{}
+	for _, line := range lines {
+		m := trailerPat.FindStringSubmatch(line)
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// annotationTrailers is ParseTrailers for tag annotations, which AppendTrailer
+// leaves as nothing but a trailer block when no message was given
+func annotationTrailers(message string) []Trailer {
+	body, lines := splitTrailers(message)
+	if len(lines) > 0 {
+		return ParseTrailers(message)
+	}
+	trailers := []Trailer{}
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		m := trailerPat.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// AppendTrailer adds a "key: value" trailer to a message, joining an existing
+// trailer block if there is one. Adding the exact same trailer twice is a
+// no-op.
+func AppendTrailer(message, key, value string) string {
+	trailer := fmt.Sprintf("%s: %s", key, value)
+	message = strings.TrimRight(message, "\n")
+	if message == "" {
+		return trailer
+	}
+	body, lines := splitTrailers(message)
+	if len(lines) == 0 && annotationTrailers(message) != nil {
+		// The message is nothing but trailers from earlier calls
+		lines = strings.Split(message, "\n")
+	}
+	for _, line := range lines {
+		if line == trailer {
+			return message
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("%s\n\n%s", body, trailer)
+	}
+	return fmt.Sprintf("%s\n%s", message, trailer)
+}
@@ -0,0 +1,89 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrainConfig assigns releases to a release train instead of the wall clock
+// date, so a release cut after the cutoff gets the version of the next train
+// no matter when the month or day flips.
+type TrainConfig struct {
+	// Timezone the cutoff is in, an IANA name like Europe/Berlin. The local
+	// timezone is used if empty.
+	Timezone string `yaml:"timezone"`
+	// Cutoff is a daily "18:00" (later releases count as the next day) or a
+	// weekly "Fri 18:00" (later releases count as next week's train, dated on
+	// the following cutoff day)
+	Cutoff string `yaml:"cutoff"`
+
+	loc     *time.Location
+	weekday *time.Weekday
+	minutes int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func (c *TrainConfig) validate() error {
+	c.loc = time.Local
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid train timezone: %w", err)
+		}
+		c.loc = loc
+	}
+	if c.Cutoff == "" {
+		return nil
+	}
+	clock := c.Cutoff
+	if fields := strings.Fields(c.Cutoff); len(fields) == 2 {
+		name := strings.ToLower(fields[0])
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		day, ok := weekdays[name]
+		if !ok {
+			return fmt.Errorf("invalid train cutoff day '%s'", fields[0])
+		}
+		c.weekday = &day
+		clock = fields[1]
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return fmt.Errorf("invalid train cutoff '%s', must be HH:MM or Day HH:MM", c.Cutoff)
+	}
+	c.minutes = t.Hour()*60 + t.Minute()
+	return nil
+}
+
+// Date returns the date of the train a release made at now belongs to,
+// without a cutoff that's just today
+func (c *TrainConfig) Date(now time.Time) time.Time {
+	loc := c.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if c.Cutoff == "" {
+		return today
+	}
+	afterCutoff := now.Hour()*60+now.Minute() >= c.minutes
+	if c.weekday == nil {
+		if afterCutoff {
+			return today.AddDate(0, 0, 1)
+		}
+		return today
+	}
+	// The next cutoff that hasn't passed yet names the train
+	days := (int(*c.weekday) - int(now.Weekday()) + 7) % 7
+	if days == 0 && afterCutoff {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
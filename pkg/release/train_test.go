@@ -0,0 +1,71 @@
+package release
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrainDate(t *testing.T) {
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2020, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		cutoff string
+		now    time.Time
+		want   time.Time
+	}{
+		{"", utc(time.July, 31, 23, 59), utc(time.July, 31, 0, 0)},
+		// Daily, later releases are the next day's, even across the month
+		{"18:00", utc(time.July, 31, 17, 59), utc(time.July, 31, 0, 0)},
+		{"18:00", utc(time.July, 31, 18, 0), utc(time.August, 1, 0, 0)},
+		// Weekly, July 31st 2020 is a Friday
+		{"Fri 18:00", utc(time.July, 27, 9, 0), utc(time.July, 31, 0, 0)},
+		{"Fri 18:00", utc(time.July, 31, 17, 0), utc(time.July, 31, 0, 0)},
+		{"Fri 18:00", utc(time.July, 31, 18, 30), utc(time.August, 7, 0, 0)},
+		{"friday 18:00", utc(time.August, 1, 9, 0), utc(time.August, 7, 0, 0)},
+	}
+	for _, test := range tests {
+		cfg := TrainConfig{Timezone: "UTC", Cutoff: test.cutoff}
+		if err := cfg.validate(); err != nil {
+			t.Fatalf("%s: %s", test.cutoff, err)
+		}
+		if got := cfg.Date(test.now); !got.Equal(test.want) {
+			t.Errorf("%q at %s: got %s, want %s", test.cutoff, test.now, got, test.want)
+		}
+	}
+}
+
+func TestTrainTimezone(t *testing.T) {
+	cfg := TrainConfig{Timezone: "Asia/Tokyo", Cutoff: "18:00"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	// 10:00 UTC is 19:00 in Tokyo, past the cutoff on the last day of July
+	got := cfg.Date(time.Date(2020, time.July, 31, 10, 0, 0, 0, time.UTC))
+	if got.Month() != time.August || got.Day() != 1 {
+		t.Errorf("got %s, want August 1st", got)
+	}
+}
+
+func TestTrainInvalid(t *testing.T) {
+	for _, cfg := range []TrainConfig{{Cutoff: "25:00"}, {Cutoff: "Someday 18:00"}, {Cutoff: "6pm"}, {Timezone: "Nowhere/City"}} {
+		if err := cfg.validate(); err == nil {
+			t.Errorf("%+v validated", cfg)
+		}
+	}
+}
+
+func TestTrainProposedRelease(t *testing.T) {
+	rm, _ := newTestManager(t, "2020.07.004-api")
+	cfg := TrainConfig{Timezone: "UTC", Cutoff: "18:00"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	proposal, err := rm.GetProposedReleaseAt("api", cfg.Date(time.Date(2020, time.July, 31, 20, 0, 0, 0, time.UTC)), ResetMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.TagName != "2020.08.001-api" {
+		t.Errorf("got %s, want 2020.08.001-api", proposal.TagName)
+	}
+}
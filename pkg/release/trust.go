@@ -0,0 +1,184 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/openpgp"
+)
+
+// TrustConfig limits the prior tags used to compute the next increment to
+// the ones signed by trusted keys, so someone who can push tags can't skew
+// versioning with a bogus 2020.07.999 tag
+type TrustConfig struct {
+	// Keyring is a file of armored public keys, relative to the repository
+	Keyring string `yaml:"keyring"`
+	// Untrusted is what to do about untrusted tags: ignore (default) leaves
+	// them out of the increment, fail refuses to release
+	Untrusted string `yaml:"untrusted"`
+	// SigningKey is an armored private key file new tags are signed with,
+	// PassphraseEnv names the environment variable holding its passphrase
+	SigningKey    string `yaml:"signing_key"`
+	PassphraseEnv string `yaml:"passphrase_env"`
+}
+
+func (c TrustConfig) validate() error {
+	if c.Untrusted != "" && c.Untrusted != "ignore" && c.Untrusted != "fail" {
+		return fmt.Errorf("trust untrusted must be ignore or fail, not '%s'", c.Untrusted)
+	}
+	return nil
+}
+
+// errUnsigned is the reason given for lightweight and unsigned tags
+var errUnsigned = errors.New("tag is not signed")
+
+// pgpSignatureStart starts the signature at the end of signed tag objects
+const pgpSignatureStart = "-----BEGIN PGP SIGNATURE-----"
+
+// verification is the outcome of checking a tag ref against the keyring
+type verification struct {
+	target string // What the ref pointed to when it was checked
+	err    error  // Why it isn't trusted, nil if it is
+}
+
+// SetTrustedKeys only lets tags signed by one of the keys in the armored
+// keyring be used when proposing the next version. Tags are verified when
+// they're looked at, so tags loaded later (after Reload) have to be signed
+// too. The releases that aren't trusted now are returned with the reason.
+func (r *Manager) SetTrustedKeys(armoredKeyRing string) (map[string]error, error) {
+	if _, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKeyRing)); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	r.keyring = armoredKeyRing
+	r.verified = map[string]verification{}
+	return r.UntrustedReleases(), nil
+}
+
+// UntrustedReleases returns the loaded releases that aren't signed by a
+// trusted key along with the reason, nil if there is no keyring
+func (r *Manager) UntrustedReleases() map[string]error {
+	if r.keyring == "" {
+		return nil
+	}
+	untrusted := map[string]error{}
+	for idx := range r.releases {
+		if _, ok := parseCalVer(r.tagTemplate, r.releases[idx].Tag); !ok {
+			continue
+		}
+		if err := r.trustError(&r.releases[idx]); err != nil {
+			untrusted[r.releases[idx].Tag] = err
+		}
+	}
+	return untrusted
+}
+
+// trustError returns why a release may not be used to compute the next
+// increment, nil if it may. Results are kept until the tag moves.
+func (r *Manager) trustError(rel *Release) error {
+	if r.keyring == "" || rel.planned {
+		return nil
+	}
+	name := rel.RefName()
+	target, err := r.gitBackend().Reference(plumbing.NewTagReferenceName(name))
+	if err != nil {
+		return err
+	}
+	if v, ok := r.verified[name]; ok && v.target == target {
+		return v.err
+	}
+	err = r.verifyTag(target)
+	r.verified[name] = verification{target: target, err: err}
+	return err
+}
+
+// verifyTag checks the signature of a tag object against the keyring. The
+// signed payload is the raw object up to the signature, rather than go-git's
+// encoding of it which truncates SHA-256 hashes.
+func (r *Manager) verifyTag(hash string) error {
+	obj, err := r.gitBackend().Object(hash)
+	if err == plumbing.ErrObjectNotFound {
+		return errUnsigned
+	} else if err != nil {
+		return err
+	}
+	if obj.Type() != plumbing.TagObject {
+		return errUnsigned
+	}
+	content, err := objectContent(obj)
+	if err != nil {
+		return err
+	}
+	idx := bytes.Index(content, []byte(pgpSignatureStart))
+	if idx < 0 {
+		return errUnsigned
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(r.keyring))
+	if err != nil {
+		return err
+	}
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(content[:idx]), bytes.NewReader(content[idx:]))
+	return err
+}
+
+// isTrusted reports whether a release may be used to compute the next
+// increment
+func (r *Manager) isTrusted(rel *Release) bool {
+	return r.trustError(rel) == nil
+}
+
+// LoadSigningKey reads an armored private key to sign new tags with, it's
+// decrypted with the passphrase if it's protected
+func LoadSigningKey(path, passphrase string) (*openpgp.Entity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("no key found in %s", path)
+	}
+	key := entities[0]
+	if key.PrivateKey == nil {
+		return nil, fmt.Errorf("%s doesn't contain a private key", path)
+	}
+	if key.PrivateKey.Encrypted {
+		if err := key.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+		for _, sub := range key.Subkeys {
+			if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+				sub.PrivateKey.Decrypt([]byte(passphrase))
+			}
+		}
+	}
+	return key, nil
+}
+
+// ApplyTrust loads the configured keyring (read like the config, from the
+// working directory or HEAD) and signing key. The untrusted tags are returned,
+// nil if no keyring is configured.
+func (r *Manager) ApplyTrust(cfg TrustConfig) (map[string]error, error) {
+	if cfg.SigningKey != "" {
+		key, err := LoadSigningKey(cfg.SigningKey, os.Getenv(cfg.PassphraseEnv))
+		if err != nil {
+			return nil, err
+		}
+		r.SignKey = key
+	}
+	if cfg.Keyring == "" {
+		return nil, nil
+	}
+	keyring, err := r.readRepoFile(cfg.Keyring)
+	if err != nil {
+		return nil, err
+	}
+	return r.SetTrustedKeys(string(keyring))
+}
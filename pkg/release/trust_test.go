@@ -0,0 +1,103 @@
+package release
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+// armoredPublicKey returns the public key of the entity as an armored keyring
+func armoredPublicKey(t *testing.T, key *openpgp.Entity) string {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := key.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return b.String()
+}
+
+func TestTrust(t *testing.T) {
+	rm, repo := newTestManager(t, "2020.07.001")
+	key, err := openpgp.NewEntity("Release Test", "", "releasetest@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.SignKey = key
+	if _, err := rm.CreateTagAt("2020.07.002", head.Hash().String(), "signed", "Release Test", "releasetest@example.com", TagAnnotated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.009", "forged"); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+
+	untrusted, err := rm.SetTrustedKeys(armoredPublicKey(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(untrusted) != 2 || untrusted["2020.07.001"] == nil || untrusted["2020.07.009"] == nil {
+		t.Errorf("got untrusted %v, want the lightweight and the forged tag", untrusted)
+	}
+	july := time.Date(2020, time.July, 20, 12, 0, 0, 0, time.UTC)
+	next := func() string {
+		t.Helper()
+		proposal, err := rm.GetProposedReleaseAt("", july, ResetMonthly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proposal.TagName
+	}
+	if got := next(); got != "2020.07.003" {
+		t.Errorf("got %s, want 2020.07.003", got)
+	}
+
+	// Tags loaded later are verified too
+	if _, err := repo.Tag("2020.07.050", "forged later"); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+	if got := next(); got != "2020.07.003" {
+		t.Errorf("after a forged tag was loaded: got %s, want 2020.07.003", got)
+	}
+	if _, ok := rm.UntrustedReleases()["2020.07.050"]; !ok {
+		t.Error("the tag loaded later isn't untrusted")
+	}
+
+	// So are trusted tags that moved
+	if err := repo.Repo.Storer.RemoveReference(plumbing.NewTagReferenceName("2020.07.002")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Tag("2020.07.002", "forged over a signed tag"); err != nil {
+		t.Fatal(err)
+	}
+	rm.Reload()
+	if _, ok := rm.UntrustedReleases()["2020.07.002"]; !ok {
+		t.Error("the moved tag is still trusted")
+	}
+	if got := next(); got != "2020.07.001" {
+		t.Errorf("after the trusted tag moved: got %s, want 2020.07.001", got)
+	}
+}
+
+func TestTrustInvalidKeyring(t *testing.T) {
+	rm, _ := newTestManager(t, "2020.07.001")
+	if _, err := rm.SetTrustedKeys("not a keyring"); err == nil {
+		t.Error("an invalid keyring was accepted")
+	}
+	if untrusted := rm.UntrustedReleases(); untrusted != nil {
+		t.Errorf("without a keyring got untrusted %v", untrusted)
+	}
+}
@@ -0,0 +1,147 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// verifyRefPrefix is where remote tags that aren't available locally are
+// fetched to while they're verified
+const verifyRefPrefix = "refs/releases/verify/"
+
+// TagMismatch describes a release tag that doesn't point to the commit it was
+// released at. Hashes are of commits, annotated tags are peeled.
+type TagMismatch struct {
+	Tag      string // The name of the tag's ref
+	Recorded string // The commit the release was recorded at
+	Local    string // What the tag points to locally
+	Remote   string // What the tag points to on the remote, empty if missing
+	// FromAuditLog is set when Recorded comes from the audit log, otherwise
+	// there was no record and the local tag is trusted
+	FromAuditLog bool
+}
+
+// Missing returns true if the tag doesn't exist on the remote at all
+func (m TagMismatch) Missing() bool {
+	return m.Remote == ""
+}
+
+// MovedLocally returns true if the local tag no longer points to the recorded
+// commit, like after a 'git fetch --tags --force' of a moved tag
+func (m TagMismatch) MovedLocally() bool {
+	return m.Local != m.Recorded
+}
+
+// remoteReferences lists all references advertised by the remote, an empty
+// remote has no references rather than being an error
+func (r *Manager) remoteReferences(remote string, auth transport.AuthMethod) ([]Ref, error) {
+	refs, err := r.gitBackend().ListRemote(remote, auth)
+	if err == transport.ErrEmptyRemoteRepository {
+		return nil, nil
+	}
+	return refs, Classify("listing refs on remote "+remote, err)
+}
+
+// recordedTargets returns the commit every release was recorded at in the
+// audit log, the first release event of a tag wins
+func (r *Manager) recordedTargets() (map[string]string, error) {
+	events, err := r.AuditEvents()
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[string]string{}
+	for _, e := range events {
+		if e.Action != "release" || e.Commit == "" {
+			continue
+		}
+		if _, ok := recorded[e.Tag]; !ok {
+			recorded[e.Tag] = e.Commit
+		}
+	}
+	return recorded, nil
+}
+
+// peelRemoteTags returns the commits the remote tags point to. Tag objects
+// that aren't in the repository are fetched to a scratch ref first, which is
+// removed again.
+func (r *Manager) peelRemoteTags(remote string, auth transport.AuthMethod, tags map[string]string) (map[string]string, error) {
+	refSpecs := []config.RefSpec{}
+	for name, hash := range tags {
+		if _, err := r.gitBackend().Object(hash); err == plumbing.ErrObjectNotFound {
+			refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("+refs/tags/%s:%s%s", name, verifyRefPrefix, name)))
+		}
+	}
+	if len(refSpecs) > 0 {
+		err := r.gitBackend().Fetch(&git.FetchOptions{RemoteName: remote, RefSpecs: refSpecs, Tags: git.NoTags, Auth: auth})
+		defer func() {
+			for _, rs := range refSpecs {
+				r.gitBackend().DeleteReference(plumbing.ReferenceName(rs.Dst("")))
+			}
+		}()
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return nil, Classify("fetching the remote tags from "+remote, err)
+		}
+	}
+	peeled := map[string]string{}
+	for name, hash := range tags {
+		_, commit, err := r.resolveTag(hash)
+		if err != nil {
+			return nil, fmt.Errorf("remote tag %s %s", name, err)
+		}
+		peeled[name] = commit.Hash
+	}
+	return peeled, nil
+}
+
+// VerifyRemoteTags checks every release tag against the commit it was
+// released at, as recorded in the audit log, and returns the ones that moved
+// on the remote or locally (tampering or an accidental force push). Releases
+// the audit log has no record of are compared with the local tag. If
+// includeMissing is set tags that were never pushed are reported too.
+func (r *Manager) VerifyRemoteTags(remote string, auth transport.AuthMethod, includeMissing bool) ([]TagMismatch, error) {
+	recorded, err := r.recordedTargets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	refs, err := r.remoteReferences(remote, auth)
+	if err != nil {
+		return nil, err
+	}
+	releases := map[string]bool{}
+	for _, rel := range r.releases {
+		releases[rel.RefName()] = true
+	}
+	remoteTags := map[string]string{}
+	for _, ref := range refs {
+		if ref.Name.IsTag() && releases[ref.Name.Short()] {
+			remoteTags[ref.Name.Short()] = ref.Hash
+		}
+	}
+	remoteCommits, err := r.peelRemoteTags(remote, auth, remoteTags)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := []TagMismatch{}
+	for _, rel := range r.releases {
+		name := rel.RefName()
+		m := TagMismatch{Tag: name, Local: rel.Hash}
+		m.Recorded, m.FromAuditLog = recorded[rel.Tag]
+		if !m.FromAuditLog {
+			m.Recorded = m.Local
+		}
+		remoteCommit, onRemote := remoteCommits[name]
+		if !onRemote && !includeMissing && !m.MovedLocally() {
+			continue
+		}
+		m.Remote = remoteCommit
+		if m.MovedLocally() || (onRemote && remoteCommit != m.Recorded) || !onRemote {
+			mismatches = append(mismatches, m)
+		}
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,110 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotCalVer is returned by ParseTag for tags that aren't releases
+var ErrNotCalVer = errors.New("not a CalVer tag")
+
+// Version is the version part of a release tag, 2020.07.001 of
+// 2020.07.001-api-rc.1
+type Version struct {
+	Year  uint64
+	Month uint64 // Also set for YYYY.DDD.N versions, from the day
+	Day   uint64 // Day of the year for YYYY.DDD.N versions, 0 otherwise
+	Inc   uint64
+}
+
+// ParseTag is the parser every command uses, it reads tags of both schemes
+// and the formats adopted from other tools. It returns the version, the
+// component without its pre-release marker and the channel (rc for api-rc.2,
+// empty for final releases). Tags that aren't releases return ErrNotCalVer.
+// Use TagTemplate.ParseTag to read the tags of a tag_template too.
+func ParseTag(tag string) (Version, string, string, error) {
+	return (*TagTemplate)(nil).ParseTag(tag)
+}
+
+// ParseTag is ParseTag reading the tags of the template first, a nil template
+// only reads the built-in formats
+func (t *TagTemplate) ParseTag(tag string) (Version, string, string, error) {
+	info := parseTagInfo(t, tag)
+	if info.version == nil {
+		return Version{}, "", "", ErrNotCalVer
+	}
+	c := info.version
+	v := Version{Year: c.Year, Month: c.Month, Day: c.Day, Inc: c.Release}
+	return v, info.component, preReleaseChannel(info.pre), nil
+}
+
+func (v Version) calVer() *calVerStandard {
+	return &calVerStandard{Year: v.Year, Month: v.Month, Day: v.Day, Release: v.Inc}
+}
+
+// Scheme returns the scheme the version is in
+func (v Version) Scheme() Scheme {
+	if v.Day != 0 {
+		return SchemeOrdinal
+	}
+	return SchemeMonthly
+}
+
+// Compare returns -1, 0 or 1 if v is older, the same or newer than other
+func (v Version) Compare(other Version) int {
+	return v.calVer().Compare(other.calVer())
+}
+
+// Tag names the release of component (which may have a pre-release marker)
+// at this version in the built-in format, it's the reverse of ParseTag
+func (v Version) Tag(component string) string {
+	return (*TagTemplate)(nil).Tag(v, component)
+}
+
+// Tag names the release of component at version v with the template when it's
+// for the version's scheme, it's the reverse of TagTemplate.ParseTag
+func (t *TagTemplate) Tag(v Version, component string) string {
+	return v.calVer().FormatRelease(t, component)
+}
+
+func (v Version) String() string {
+	return v.Tag("")
+}
+
+// preReleaseChannel returns the channel of a pre-release marker, the marker
+// without its number
+func preReleaseChannel(pre string) string {
+	return strings.TrimRight(pre, "0123456789.-")
+}
+
+// CompareTags returns -1, 0 or 1 if release tag a is older, the same or newer
+// than b. Pre-releases are older than the final release of their version,
+// the components aren't compared. Use TagTemplate.CompareTags for the tags
+// of a tag_template.
+func CompareTags(a, b string) (int, error) {
+	return (*TagTemplate)(nil).CompareTags(a, b)
+}
+
+// CompareTags is CompareTags reading the tags with the template, see ParseTag
+func (t *TagTemplate) CompareTags(a, b string) (int, error) {
+	va, _, _, err := t.ParseTag(a)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", a, err)
+	}
+	vb, _, _, err := t.ParseTag(b)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", b, err)
+	}
+	if c := va.Compare(vb); c != 0 {
+		return c, nil
+	}
+	preA, preB := parseTagInfo(t, a).pre != "", parseTagInfo(t, b).pre != ""
+	switch {
+	case preA && !preB:
+		return -1, nil
+	case !preA && preB:
+		return 1, nil
+	}
+	return 0, nil
+}
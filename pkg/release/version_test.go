@@ -0,0 +1,61 @@
+package release
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	for tag, want := range map[string]struct {
+		version   Version
+		component string
+		channel   string
+	}{
+		"2020.07.001-api":      {Version{Year: 2020, Month: 7, Inc: 1}, "api", ""},
+		"2020.07.002-api-rc.2": {Version{Year: 2020, Month: 7, Inc: 2}, "api", "rc"},
+		"2020.7.3":             {Version{Year: 2020, Month: 7, Inc: 3}, "", ""},
+		"2020.183.4-web-beta1": {Version{Year: 2020, Month: 7, Day: 183, Inc: 4}, "web", "beta"},
+	} {
+		version, component, channel, err := ParseTag(tag)
+		if err != nil {
+			t.Errorf("%s: %v", tag, err)
+			continue
+		}
+		if version != want.version || component != want.component || channel != want.channel {
+			t.Errorf("%s: got %+v %q %q", tag, version, component, channel)
+		}
+	}
+	if _, _, _, err := ParseTag("v1.2.3"); !errors.Is(err, ErrNotCalVer) {
+		t.Errorf("got %v", err)
+	}
+
+	version, _, _, _ := ParseTag("2020.183.4-web")
+	if version.Scheme() != SchemeOrdinal || version.Tag("web") != "2020.183.4-web" || version.String() != "2020.183.4" {
+		t.Errorf("got %s %s", version.Scheme(), version)
+	}
+	older, _, _, _ := ParseTag("2020.07.001")
+	if older.Compare(version) != -1 || version.Compare(older) != 1 {
+		t.Errorf("2020.07.001 should sort before %s", version)
+	}
+}
+
+func TestCompareTags(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"2024.05.003-api", "2024.04.010-api", 1},
+		{"2024.04.010-api", "2024.05.003-api", -1},
+		{"2024.05.003-api-rc.1", "2024.05.003-api", -1},
+		{"2024.05.003-api", "2024.05.003-web", 0},
+		{"2024.120.2-api", "2024.05.001-api", -1},
+	} {
+		got, err := CompareTags(c.a, c.b)
+		if err != nil || got != c.want {
+			t.Errorf("%s %s: got %d %v, want %d", c.a, c.b, got, err, c.want)
+		}
+	}
+	if _, err := CompareTags("2024.05.003-api", "latest"); !errors.Is(err, ErrNotCalVer) {
+		t.Errorf("got %v", err)
+	}
+}